@@ -1,29 +1,63 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"math/rand"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/palettechain/onRobot/config"
 	"github.com/palettechain/onRobot/core"
+	"github.com/palettechain/onRobot/pkg/dao"
 	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/registry"
 )
 
 var (
 	loglevel   int    // log level [1: debug, 2: info]
 	configpath string //config file
 	Methods    string //Methods list in cmdline
+	reportpath string //report file path
+	scenario   string //scenario file path
+	parallel   int    //max number of methods running at the same time
+	overrides  setFlags
 )
 
+// setFlags collect repeated `-set Path.To.Field=value` flags.
+type setFlags []string
+
+func (s *setFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *setFlags) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
 func init() {
 	flag.StringVar(&configpath, "config", "config.json", "configpath of palette-tool")
 	flag.StringVar(&Methods, "t", "connect", "methods to run. use ',' to split methods")
 	flag.IntVar(&loglevel, "loglevel", 2, "loglevel [1: debug, 2: info]")
+	flag.StringVar(&scenario, "scenario", "", "scenario file path, methods list will be ignored if it's set")
+	flag.IntVar(&parallel, "parallel", 0, "max number of independent methods running concurrently, methods run in order if it's less than 2")
+	flag.StringVar(&reportpath, "report", "", "report file path, both json and junit xml report will be generated. e.g: report/robot")
+	flag.StringVar(&config.Profile, "profile", "", "config profile to use, e.g: local, test, prod. env ONROBOT_PROFILE works too")
+	flag.Var(&overrides, "set", "override config field, can be repeated. e.g: -set CrossChain.EthereumRPCUrl=http://127.0.0.1:8545")
 
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\nSub commands:\n%s%s%s%s", configUsage, deploymentsUsage, secretUsage, verifyBridgeUsage)
+	}
 	flag.Parse()
+	config.Overrides = overrides
 }
 
 func main() {
@@ -31,13 +65,291 @@ func main() {
 	defer time.Sleep(time.Second)
 
 	log.InitLog(loglevel, log.Stdout)
-	config.Init(configpath)
+	if flag.Arg(0) == "secret" {
+		if err := secretCommand(flag.Args()[1:]); err != nil {
+			log.Errorf("secret command failed, err: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.Arg(0) == "config" {
+		if err := configCommand(flag.Args()[1:]); err != nil {
+			log.Errorf("config command failed, err: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "deployments" {
+		if err := deploymentsCommand(flag.Args()[1:]); err != nil {
+			log.Errorf("deployments command failed, err: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "verify-bridge" {
+		if err := config.InitReadOnly(configpath); err != nil {
+			log.Errorf("verify bridge failed, err: %v", err)
+			os.Exit(1)
+		}
+		matrix, failed := core.BridgeMatrix(core.VerifyBridge())
+		fmt.Println(matrix)
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	config.Init(configpath, frame.Tool.Current)
 	core.Endpoint()
 
+	frame.Tool.SetReportPath(reportpath)
+	if scenario != "" {
+		s, err := frame.LoadScenario(scenario)
+		if err != nil {
+			log.Errorf("load scenario failed, err: %v", err)
+			return
+		}
+		if parallel > 0 {
+			s.Parallel = parallel
+		}
+		if err := s.Validate(frame.Tool); err != nil {
+			log.Errorf("invalid scenario, err: %v", err)
+			return
+		}
+		frame.Tool.RunScenario(s)
+		return
+	}
+
 	methods := make([]string, 0)
 	if Methods != "" {
 		methods = strings.Split(Methods, ",")
 	}
 
+	if parallel > 1 {
+		frame.Tool.StartParallel(methods, parallel)
+		return
+	}
 	frame.Tool.Start(methods)
 }
+
+const configUsage = `  config check                     validate config, check keystores decrypt and rpc endpoints answer
+`
+
+// configCommand validate config without running any method, e.g: robot -config=config.json -profile=test config check
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("unknown sub command\n%s", configUsage)
+	}
+
+	items, err := config.Check(configpath)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, item := range items {
+		if item.Err != nil {
+			failed++
+		}
+		fmt.Println(item.String())
+	}
+	fmt.Printf("profile [%s], %d items checked, %d failed\n", config.ProfileName, len(items), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d items failed", failed)
+	}
+	return nil
+}
+
+const deploymentsUsage = `  deployments list [chain]         list current contracts of environment
+  deployments history <chain> <name>  list all versions of contract
+  deployments diff <env> [env2]    compare current environment(or env2) with env
+  deployments diff config          compare contract addresses in config file with registry
+`
+
+// deploymentsCommand query the deployment registry of environment, e.g: robot -config=config.json -profile=test deployments list palette
+func deploymentsCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sub command missing\n%s", deploymentsUsage)
+	}
+	c, err := config.Load(configpath)
+	if err != nil {
+		return err
+	}
+	r, err := c.OpenDeployments("")
+	if err != nil {
+		return err
+	}
+
+	chains := func(rs ...*registry.Registry) []string {
+		list := []string{config.ChainPalette, config.ChainEthereum}
+		for _, r := range rs {
+			for _, chain := range r.Chains() {
+				if chain != config.ChainPalette && chain != config.ChainEthereum {
+					list = append(list, chain)
+				}
+			}
+		}
+		return list
+	}
+	printChanges := func(chain string, changes []*registry.Change) {
+		for _, change := range changes {
+			fmt.Printf("%-8s %s\n", chain, change.String())
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		list := r.Chains()
+		if len(args) > 1 {
+			list = args[1:]
+		}
+		for _, chain := range list {
+			for _, name := range r.Names(chain) {
+				rec, _ := r.Current(chain, name)
+				fmt.Printf("%-8s %-12s v%-3d %s owner %s tx %s %s\n", chain, name, rec.Version, rec.Address.Hex(),
+					rec.Owner.Hex(), rec.TxHash.Hex(), rec.Time.Format(time.RFC3339))
+			}
+		}
+
+	case "history":
+		if len(args) < 3 {
+			return fmt.Errorf("chain and name missing\n%s", deploymentsUsage)
+		}
+		list := r.History(args[1], args[2])
+		if len(list) == 0 {
+			return fmt.Errorf("contract %s not found on %s of %s", args[2], args[1], r.Env())
+		}
+		for _, rec := range list {
+			bz, _ := json.MarshalIndent(rec, "", "  ")
+			fmt.Printf("%s\n", bz)
+		}
+
+	case "diff":
+		if len(args) < 2 {
+			return fmt.Errorf("env missing\n%s", deploymentsUsage)
+		}
+		if args[1] == "config" {
+			for _, chain := range chains(r) {
+				printChanges(chain, registry.Diff(c.CrossChain.Snapshot(chain), r.Snapshot(chain, time.Time{}), "Address"))
+			}
+			return nil
+		}
+		a, err := c.OpenDeployments(args[1])
+		if err != nil {
+			return err
+		}
+		b := r
+		if len(args) > 2 {
+			if b, err = c.OpenDeployments(args[2]); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("diff %s => %s\n", a.Env(), b.Env())
+		for _, chain := range chains(a, b) {
+			printChanges(chain, registry.Diff(a.Snapshot(chain, time.Time{}), b.Snapshot(chain, time.Time{})))
+		}
+
+	default:
+		return fmt.Errorf("unknown sub command %s\n%s", args[0], deploymentsUsage)
+	}
+	return nil
+}
+
+const verifyBridgeUsage = `  verify-bridge                    check ownership, ccmp, bindings of both chains and poly side chain registration, exit 1 on mismatch
+`
+
+const secretUsage = `  secret list                      list cached keystore passwords, passwords are never printed
+  secret revoke <eth|plt|poly> <address>...  revoke passwords of accounts
+  secret revoke all                revoke all of the passwords
+  secret purge                     delete expired passwords
+  secret audit [n]                 show the latest n(default 50) records of which method used which password
+`
+
+// secretCommand manage the passwords cached in leveldb, e.g: robot -config=config.json secret list
+func secretCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sub command missing\n%s", secretUsage)
+	}
+	if err := config.InitSecretStore(configpath); err != nil {
+		return err
+	}
+	defer dao.Close()
+
+	const user = "cli"
+	switch args[0] {
+	case "list":
+		list, err := dao.ListSecrets()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, e := range list {
+			status := "valid"
+			if e.Expired(now) {
+				status = "expired"
+			}
+			expire := "never"
+			if !e.Expire.IsZero() {
+				expire = e.Expire.Format(time.RFC3339)
+			}
+			fmt.Printf("%-5s %s created %s expire %s %s\n", config.SecretTypeName(e.Type),
+				common.BytesToAddress(e.Account).Hex(), e.Created.Format(time.RFC3339), expire, status)
+		}
+		fmt.Printf("total %d\n", len(list))
+
+	case "revoke":
+		if len(args) == 2 && args[1] == "all" {
+			n, err := dao.RevokeAll(user)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("revoke %d passwords\n", n)
+			return nil
+		}
+		if len(args) < 3 {
+			return fmt.Errorf("type and address missing\n%s", secretUsage)
+		}
+		typ, err := config.ParseSecretType(args[1])
+		if err != nil {
+			return err
+		}
+		for _, addr := range args[2:] {
+			if !common.IsHexAddress(addr) {
+				return fmt.Errorf("invalid address %s", addr)
+			}
+			if err := dao.RevokeSecret(typ, common.HexToAddress(addr).Bytes(), user); err != nil {
+				return fmt.Errorf("revoke %s err: %v", addr, err)
+			}
+			fmt.Printf("revoke %s %s\n", args[1], addr)
+		}
+
+	case "purge":
+		n, err := dao.PurgeExpired()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("purge %d expired passwords\n", n)
+
+	case "audit":
+		limit := 50
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return err
+			}
+			limit = n
+		}
+		list, err := dao.Audit(limit)
+		if err != nil {
+			return err
+		}
+		for _, r := range list {
+			fmt.Println(r.String())
+		}
+
+	default:
+		return fmt.Errorf("unknown sub command %s\n%s", args[0], secretUsage)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/palettechain/onRobot/pkg/dao"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/signer"
+)
+
+const checkTimeout = 10 * time.Second
+
+// CheckItem is the result of checking one keystore, rpc or directory.
+type CheckItem struct {
+	Kind   string // dir, keystore, rpc
+	Target string
+	Err    error
+	Cost   time.Duration
+}
+
+func (i *CheckItem) String() string {
+	status := "ok"
+	if i.Err != nil {
+		status = "fail: " + i.Err.Error()
+	}
+	return fmt.Sprintf("%-8s %-60s %-8s %s", i.Kind, i.Target, i.Cost.Round(time.Millisecond), status)
+}
+
+type checker struct {
+	items []*CheckItem
+}
+
+func (ck *checker) run(kind, target string, fn func() error) {
+	start := time.Now()
+	ch := make(chan error, 1)
+	go func() { ch <- fn() }()
+
+	var err error
+	select {
+	case err = <-ch:
+	case <-time.After(checkTimeout):
+		err = fmt.Errorf("timeout after %s", checkTimeout)
+	}
+	ck.items = append(ck.items, &CheckItem{Kind: kind, Target: target, Err: err, Cost: time.Since(start)})
+}
+
+// Check load and validate config, then make sure that all of the keystores decrypt and all of the
+// rpc endpoints answer, passwords are never read from stdin while checking. it returns error only if
+// the config itself is invalid, failures of items are reported in the item list.
+func Check(filepath string) ([]*CheckItem, error) {
+	_ = os.Setenv(NonInteractiveEnv, "1")
+
+	c, err := Load(filepath)
+	if err != nil {
+		return nil, err
+	}
+	ConfigFilePath, Conf = filepath, c
+
+	ck := new(checker)
+	workspace := c.Environment.WorkSpace()
+	for _, dir := range []string{workspace, path.Join(workspace, keystoreDir), path.Join(workspace, ethKeystoreDir), path.Join(workspace, polyKeystoreDir)} {
+		dir := dir
+		ck.run("dir", dir, func() error {
+			if fi, err := os.Stat(dir); err != nil {
+				return err
+			} else if !fi.IsDir() {
+				return fmt.Errorf("not a directory")
+			}
+			return nil
+		})
+	}
+	if len(ck.items) > 0 && ck.items[0].Err != nil {
+		return ck.items, nil
+	}
+
+	dao.NewDao(path.Join(workspace, dataDir))
+	defer dao.Close()
+	if err := c.initSecretStore(); err != nil {
+		return nil, err
+	}
+	if err := c.initHDWallet(); err != nil {
+		return nil, err
+	}
+
+	ck.checkKeystores(c)
+	ck.checkRPCs(c)
+	return ck.items, nil
+}
+
+func (ck *checker) checkKeystores(c *Config) {
+	palette := []common.Address{c.AdminAccount, c.CrossChainAdminAccount}
+	palette = append(palette, c.Accounts...)
+	for _, n := range c.Nodes {
+		palette = append(palette, common.HexToAddress(n.StakeAccount))
+	}
+	visited := make(map[common.Address]bool)
+	for _, addr := range palette {
+		if visited[addr] {
+			continue
+		}
+		visited[addr] = true
+		addr := addr
+		ck.run("keystore", "palette "+addr.Hex(), func() error {
+			_, err := LoadPaletteSigner(addr)
+			return err
+		})
+	}
+
+	for _, n := range c.Nodes {
+		n := n
+		ck.run("keystore", fmt.Sprintf("node%d key", n.Index), func() error {
+			key, err := hexToKey(n.NodeKey)
+			if err != nil {
+				return err
+			}
+			if addr := crypto.PubkeyToAddress(key.PublicKey); n.Address != "" && addr != common.HexToAddress(n.Address) {
+				return fmt.Errorf("node key belongs to %s, not %s", addr.Hex(), n.Address)
+			}
+			return nil
+		})
+	}
+
+	cc := c.CrossChain
+	if cc.EthereumAccount != (common.Address{}) {
+		ck.run("keystore", "ethereum "+cc.EthereumAccount.Hex(), func() error {
+			_, err := cc.LoadETHSigner()
+			return err
+		})
+	}
+	if cc.EthereumOwner != (common.Address{}) && cc.EthereumOwner != cc.EthereumAccount {
+		ck.run("keystore", "ethereum "+cc.EthereumOwner.Hex(), func() error {
+			_, err := cc.LoadETHOwnerSigner()
+			return err
+		})
+	}
+
+	dir := path.Join(c.Environment.WorkSpace(), polyKeystoreDir)
+	if fs, err := ioutil.ReadDir(dir); err == nil {
+		for _, f := range fs {
+			file := path.Join(dir, f.Name())
+			ck.run("keystore", "poly "+f.Name(), func() error {
+				_, err := cc.LoadPolyAccount(file)
+				return err
+			})
+		}
+	}
+}
+
+func (ck *checker) checkRPCs(c *Config) {
+	urls := []string{c.Rpc}
+	for _, n := range c.Nodes {
+		urls = append(urls, fmt.Sprintf("http://%s:%s", n.Host, n.RPCPort))
+	}
+	urls = append(urls, c.CrossChain.EthereumRPCUrl)
+	visited := make(map[string]bool)
+	for _, url := range urls {
+		if visited[url] {
+			continue
+		}
+		visited[url] = true
+		url := url
+		ck.run("rpc", url, func() error {
+			return blockNumber(url)
+		})
+	}
+
+	ck.run("rpc", c.CrossChain.PolyRPCAddress, func() error {
+		cli, err := poly.NewPolyClient(c.CrossChain.PolyRPCAddress, nil)
+		if err != nil {
+			return err
+		}
+		_, err = cli.GetCurrentBlockHeight()
+		return err
+	})
+
+	for _, sc := range c.Signers {
+		if sc.Type != SignerTypeRemote {
+			continue
+		}
+		sc := sc
+		ck.run("rpc", sc.Url, func() error {
+			s, err := signer.NewRemoteSigner(sc.Url, sc.Address)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+			defer cancel()
+			_, err = s.Version(ctx)
+			return err
+		})
+	}
+}
+
+func blockNumber(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var height hexutil.Uint64
+	return client.CallContext(ctx, &height, "eth_blockNumber")
+}
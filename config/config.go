@@ -1,20 +1,16 @@
 package config
 
 import (
-	"bufio"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"path"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ontio/ontology-crypto/keypair"
@@ -24,6 +20,7 @@ import (
 	"github.com/palettechain/onRobot/pkg/log"
 	"github.com/palettechain/onRobot/pkg/poly"
 	"github.com/palettechain/onRobot/pkg/sdk"
+	"github.com/palettechain/onRobot/pkg/signer"
 	polysdk "github.com/polynetwork/poly-go-sdk"
 )
 
@@ -47,11 +44,11 @@ const (
 )
 
 var (
-	Conf, BakConf                = new(Config), new(Config)
-	AdminKey, CrossChainAdminKey *ecdsa.PrivateKey
-	ConfigFilePath               string
-	ethPwdSession                = make(map[common.Address]string)
-	pltPwdSession                = make(map[common.Address]string)
+	Conf, BakConf                      = new(Config), new(Config)
+	AdminSigner, CrossChainAdminSigner signer.Signer
+	ConfigFilePath                     string
+	ethPwdSession                      = make(map[common.Address]string)
+	pltPwdSession                      = make(map[common.Address]string)
 )
 
 type Config struct {
@@ -67,6 +64,10 @@ type Config struct {
 	DeployGasLimit         uint64
 	BlockPeriod            encode.Duration
 	RewardEffectivePeriod  int // 区块奖励周期/参数生效周期
+	Wait                   *WaitConfig
+	HDWallet               *HDWalletConfig
+	Signers                []*SignerConfig
+	Secret                 *SecretConfig
 	Nodes                  []*Node
 	CrossChain             *CrossChainConfig
 	FinalOwner             *FinalOwner
@@ -242,6 +243,7 @@ func (n *Node) RPCAddr() string {
 }
 
 type Env struct {
+	Name            string // workspace子目录, 为空时使用环境变量ONROBOT
 	Remote          bool
 	LocalWorkspace  string
 	RemoteWorkspace string
@@ -253,16 +255,8 @@ type Env struct {
 	NFTServer       string
 }
 
-var (
-	envOnce sync.Once
-	env     string
-)
-
 func (e *Env) WorkSpace() string {
-	envOnce.Do(func() {
-		env = os.Getenv(envName)
-	})
-	return path.Join(e.LocalWorkspace, env)
+	return path.Join(e.LocalWorkspace, e.Name)
 }
 
 type Network struct {
@@ -271,31 +265,42 @@ type Network struct {
 	ValidatorsNumber  int
 }
 
-func Init(filepath string) {
+// Init load config and keys, `currentFn` returns the method running on the calling goroutine which is
+// recorded in secret audit and contract registry, it could be nil.
+func Init(filepath string, currentFn func() string) {
 	ConfigFilePath = filepath
-	err := LoadConfig(ConfigFilePath, Conf)
+	current = currentFn
+	c, err := Load(ConfigFilePath)
 	if err != nil {
 		panic(err)
 	}
+	Conf = c
+	log.Infof("config %s loaded, profile [%s], workspace %s", filepath, ProfileName, Conf.Environment.WorkSpace())
 
 	// init leveldb
 	dir := path.Join(Conf.Environment.WorkSpace(), dataDir)
 	dao.NewDao(dir)
-
-	// sort nodes with node index
-	sort.Slice(Conf.Nodes, func(i, j int) bool {
-		return Conf.Nodes[i].Index < Conf.Nodes[j].Index
-	})
+	if err := Conf.initSecretStore(); err != nil {
+		panic(err)
+	}
+	if err := Conf.initDeployments(); err != nil {
+		panic(err)
+	}
 
 	// load nodes privateKey
 	sdk.Init(Conf.GasLimit, Conf.DeployGasLimit, time.Duration(Conf.BlockPeriod))
+	sdk.SetPollOptions(Conf.PollOptions())
 
-	AdminKey, err = LoadPaletteAccount(Conf.AdminAccount)
+	if err := Conf.initHDWallet(); err != nil {
+		panic(err)
+	}
+
+	AdminSigner, err = LoadPaletteSigner(Conf.AdminAccount)
 	if err != nil {
 		panic(err)
 	}
 
-	CrossChainAdminKey, err = LoadPaletteAccount(Conf.CrossChainAdminAccount)
+	CrossChainAdminSigner, err = LoadPaletteSigner(Conf.CrossChainAdminAccount)
 	if err != nil {
 		panic(err)
 	}
@@ -303,6 +308,23 @@ func Init(filepath string) {
 	BakConf = Conf.DeepCopy()
 }
 
+// InitReadOnly load config and contract addresses in deployment registry without opening leveldb or
+// loading any account, it's used by sub-commands which only need rpc access, e.g: verify-bridge.
+func InitReadOnly(filepath string) error {
+	c, err := Load(filepath)
+	if err != nil {
+		return err
+	}
+	ConfigFilePath = filepath
+	Conf = c
+	if err := Conf.initDeployments(); err != nil {
+		return err
+	}
+	sdk.Init(Conf.GasLimit, Conf.DeployGasLimit, time.Duration(Conf.BlockPeriod))
+	sdk.SetPollOptions(Conf.PollOptions())
+	return nil
+}
+
 func LoadConfig(filepath string, ins interface{}) error {
 	data, err := files.ReadFile(filepath)
 	if err != nil {
@@ -315,120 +337,40 @@ func LoadConfig(filepath string, ins interface{}) error {
 	return nil
 }
 
-func SaveConfig(c *Config) error {
-	type XCrossChainConfig struct {
-		// poly account and node rpc url
-		PolyAccountDefaultPassphrase string
-		PolyRPCAddress               string
-
-		// poly side chain configuration
-		PaletteSideChainID   uint64
-		PaletteSideChainName string
-		PaletteECCD          common.Address
-		PaletteECCM          common.Address
-		PaletteCCMP          common.Address
-		PaletteNFTProxy      common.Address
-		PalettePLTWrapper    common.Address
-		PaletteNFTWrapper    common.Address
-
-		// ethereum side chain configuration
-		EthereumSideChainID   uint64
-		EthereumSideChainName string
-		EthereumECCD          common.Address
-		EthereumECCM          common.Address
-		EthereumCCMP          common.Address
-		EthereumPLTAsset      common.Address
-		EthereumPLTProxy      common.Address
-		EthereumNFTProxy      common.Address
-		EthereumPLTWrapper    common.Address
-		EthereumNFTWrapper    common.Address
-
-		// ethereum node rpc and account
-		EthereumRPCUrl          string
-		EthereumAccount         common.Address
-		EthereumAccountPassword string
-		EthereumOwner           common.Address
-		EthereumOwnerPassword   string
-	}
-
-	type XConfig struct {
-		Environment            *Env
-		Network                *Network
-		DefaultPassphrase      string
-		Rpc                    string
-		AdminAccount           common.Address
-		CrossChainAdminAccount common.Address
-		BaseRewardPool         common.Address
-		Accounts               []common.Address
-		GasLimit               uint64
-		DeployGasLimit         uint64
-		BlockPeriod            encode.Duration
-		RewardEffectivePeriod  int // 区块奖励周期/参数生效周期
-		Nodes                  []*Node
-		CrossChain             *XCrossChainConfig
-		FinalOwner             *FinalOwner
-	}
-
-	x := new(XConfig)
-	x.Environment = c.Environment
-	x.Network = c.Network
-	x.DefaultPassphrase = c.DefaultPassphrase
-	x.Rpc = c.Rpc
-	x.AdminAccount = c.AdminAccount
-	x.CrossChainAdminAccount = c.CrossChainAdminAccount
-	x.BaseRewardPool = c.BaseRewardPool
-	x.GasLimit = c.GasLimit
-	x.DeployGasLimit = c.DeployGasLimit
-	x.BlockPeriod = c.BlockPeriod
-	x.RewardEffectivePeriod = c.RewardEffectivePeriod
-	x.Nodes = c.Nodes
-	x.FinalOwner = c.FinalOwner
-	x.Accounts = make([]common.Address, 0)
-	for _, acc := range c.Accounts {
-		x.Accounts = append(x.Accounts, acc)
-	}
-
-	xc := new(XCrossChainConfig)
-	xc.PolyAccountDefaultPassphrase = c.CrossChain.PolyAccountDefaultPassphrase
-	xc.PolyRPCAddress = c.CrossChain.PolyRPCAddress
+// ParamsFunc returns the fields which override cases file for the calling method, e.g: params of
+// scenario step, it's registered by the methods runner.
+type ParamsFunc func(fileName string) (map[string]interface{}, bool)
 
-	// poly side chain configuration
-	xc.PaletteSideChainID = c.CrossChain.PaletteSideChainID
-	xc.PaletteSideChainName = c.CrossChain.PaletteSideChainName
-	xc.PaletteECCD = c.CrossChain.PaletteECCD
-	xc.PaletteECCM = c.CrossChain.PaletteECCM
-	xc.PaletteCCMP = c.CrossChain.PaletteCCMP
-	xc.PaletteNFTProxy = c.CrossChain.PaletteNFTProxy
-
-	// ethereum side chain configuration
-	xc.EthereumSideChainID = c.CrossChain.EthereumSideChainID
-	xc.EthereumSideChainName = c.CrossChain.EthereumSideChainName
-	xc.EthereumECCD = c.CrossChain.EthereumECCD
-	xc.EthereumECCM = c.CrossChain.EthereumECCM
-	xc.EthereumCCMP = c.CrossChain.EthereumCCMP
-	xc.EthereumPLTAsset = c.CrossChain.EthereumPLTAsset
-	xc.EthereumPLTProxy = c.CrossChain.EthereumPLTProxy
-	xc.EthereumNFTProxy = c.CrossChain.EthereumNFTProxy
+var paramsFunc ParamsFunc
 
-	// ethereum node rpc and account
-	xc.EthereumRPCUrl = c.CrossChain.EthereumRPCUrl
-	xc.EthereumAccount = c.CrossChain.EthereumAccount
-	xc.EthereumAccountPassword = c.CrossChain.EthereumAccountPassword
-	xc.EthereumOwner = c.CrossChain.EthereumOwner
-	xc.EthereumOwnerPassword = c.CrossChain.EthereumOwnerPassword
-	x.CrossChain = xc
-
-	enc, err := json.Marshal(x)
-	if err != nil {
-		return err
-	}
-	return ioutil.WriteFile(ConfigFilePath, enc, os.ModePerm)
+func RegParamsFunc(fn ParamsFunc) {
+	paramsFunc = fn
 }
 
 func LoadParams(fileName string, data interface{}) error {
+	var (
+		fields   map[string]interface{}
+		overrode bool
+	)
+	if paramsFunc != nil {
+		fields, overrode = paramsFunc(fileName)
+	}
+
 	filePath := files.FullPath(Conf.Environment.WorkSpace(), testCaseDir, fileName)
 	bz, err := ioutil.ReadFile(filePath)
-	if err != nil {
+	if err != nil && !overrode {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(bz, data); err != nil {
+			return err
+		}
+	}
+	if !overrode {
+		return nil
+	}
+
+	if bz, err = json.Marshal(fields); err != nil {
 		return err
 	}
 	return json.Unmarshal(bz, data)
@@ -568,66 +510,6 @@ func (c *CrossChainConfig) CustomLoadEthAccount(acc common.Address, pwd string)
 	return key.PrivateKey, nil
 }
 
-func (c *CrossChainConfig) StorePaletteECCD(addr common.Address) error {
-	c.PaletteECCD = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StorePaletteECCM(addr common.Address) error {
-	c.PaletteECCM = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StorePaletteCCMP(addr common.Address) error {
-	c.PaletteCCMP = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StorePaletteNFTProxy(addr common.Address) error {
-	c.PaletteNFTProxy = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StorePalettePLTWrapper(addr common.Address) error {
-	c.PalettePLTWrapper = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StorePaletteNFTWrapper(addr common.Address) error {
-	c.PaletteNFTWrapper = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumECCD(addr common.Address) error {
-	c.EthereumECCD = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumECCM(addr common.Address) error {
-	c.EthereumECCM = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumCCMP(addr common.Address) error {
-	c.EthereumCCMP = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumNFTProxy(addr common.Address) error {
-	c.EthereumNFTProxy = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumPLTAsset(addr common.Address) error {
-	c.EthereumPLTAsset = addr
-	return SaveConfig(Conf)
-}
-
-func (c *CrossChainConfig) StoreEthereumPLTProxy(addr common.Address) error {
-	c.EthereumPLTProxy = addr
-	return SaveConfig(Conf)
-}
-
 type FinalOwner struct {
 	PaletteFinalOwner  common.Address
 	EthereumFinalOwner common.Address
@@ -645,24 +527,12 @@ func getPolyAccountByPassword(sdk *polysdk.PolySdk, path string, pwd []byte) (
 		return acc, nil
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	for i := 0; i < 10; i++ {
-		curPwd, err := reader.ReadString('\n')
-		if err != nil {
-			log.Infof("input error, try it again......")
-			continue
-		}
-		curPwd = strings.Trim(curPwd, " ")
-		curPwd = strings.Trim(curPwd, "\r")
-		curPwd = strings.Trim(curPwd, "\n")
-		if acc, err := wallet.GetDefaultAccount([]byte(curPwd)); err == nil {
-			return acc, nil
-		} else {
-			log.Infof("password invalid, err %s, try it again......", err.Error())
-		}
-	}
-
-	return acc, nil
+	log.Infof("please input password for poly wallet %s", path)
+	err = readPassword(func(curPwd string) error {
+		acc, err = wallet.GetDefaultAccount([]byte(curPwd))
+		return err
+	})
+	return acc, err
 }
 
 func LoadContract(fileName string, data interface{}) error {
@@ -694,39 +564,6 @@ func GenesisNodeNumber() int {
 	return len(nodes)
 }
 
-func repeatDecrypt(enc []byte, account common.Address, pwd string, typ pwdSessionType) (key *keystore.Key, err error) {
-	if existPwd, err := getPwdSession(account, typ); err == nil {
-		return keystore.DecryptKey(enc, existPwd)
-	}
-
-	if key, err = keystore.DecryptKey(enc, pwd); err == nil {
-		_ = setPwdSession(account, pwd, typ)
-		return
-	}
-
-	log.Infof("please input password for ethereum account %s", account.Hex())
-
-	reader := bufio.NewReader(os.Stdin)
-	var curPwd string
-	for i := 0; i < 10; i++ {
-		curPwd, err = reader.ReadString('\n')
-		if err != nil {
-			log.Infof("input error, try it again......")
-			continue
-		}
-		curPwd = strings.Trim(curPwd, " ")
-		curPwd = strings.Trim(curPwd, "\r")
-		curPwd = strings.Trim(curPwd, "\n")
-		if key, err = keystore.DecryptKey(enc, curPwd); err == nil {
-			_ = setPwdSession(account, curPwd, typ)
-			return
-		} else {
-			log.Infof("password invalid, err %s, try it again......", err.Error())
-		}
-	}
-	return
-}
-
 func readWalletFile(storeDir string, acc common.Address) (enc []byte, err error) {
 	dir := path.Join(Conf.Environment.WorkSpace(), storeDir)
 	normalAddr := path.Join(dir, acc.Hex())
@@ -738,15 +575,3 @@ func readWalletFile(storeDir string, acc common.Address) (enc []byte, err error)
 	}
 	return
 }
-
-func setPwdSession(acc common.Address, pwd string, typ pwdSessionType) error {
-	return dao.SavePwd(byte(typ), acc.Bytes(), []byte(pwd))
-}
-
-func getPwdSession(acc common.Address, typ pwdSessionType) (string, error) {
-	bz, err := dao.GetPwd(byte(typ), acc.Bytes())
-	if err != nil {
-		return "", err
-	}
-	return string(bz), nil
-}
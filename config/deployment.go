@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/registry"
+)
+
+const (
+	deploymentDir  = "deployments"
+	defaultEnvName = "default"
+)
+
+const (
+	ChainPalette  = "palette"
+	ChainEthereum = "ethereum"
+)
+
+const (
+	ContractECCD       = "eccd"
+	ContractECCM       = "eccm"
+	ContractCCMP       = "ccmp"
+	ContractNFTProxy   = "nft-proxy"
+	ContractPLTAsset   = "plt-asset"
+	ContractPLTProxy   = "plt-proxy"
+	ContractPLTWrapper = "plt-wrapper"
+	ContractNFTWrapper = "nft-wrapper"
+)
+
+// Deployments is the deployment registry of current environment, contracts addresses in registry
+// override the hand-edited ones in config file.
+var Deployments *registry.Registry
+
+// deploymentFields maps contract in registry to the address field of `CrossChainConfig`.
+var deploymentFields = []struct {
+	chain, name string
+	field       func(c *CrossChainConfig) *common.Address
+}{
+	{ChainPalette, ContractECCD, func(c *CrossChainConfig) *common.Address { return &c.PaletteECCD }},
+	{ChainPalette, ContractECCM, func(c *CrossChainConfig) *common.Address { return &c.PaletteECCM }},
+	{ChainPalette, ContractCCMP, func(c *CrossChainConfig) *common.Address { return &c.PaletteCCMP }},
+	{ChainPalette, ContractNFTProxy, func(c *CrossChainConfig) *common.Address { return &c.PaletteNFTProxy }},
+	{ChainPalette, ContractPLTWrapper, func(c *CrossChainConfig) *common.Address { return &c.PalettePLTWrapper }},
+	{ChainPalette, ContractNFTWrapper, func(c *CrossChainConfig) *common.Address { return &c.PaletteNFTWrapper }},
+	{ChainEthereum, ContractECCD, func(c *CrossChainConfig) *common.Address { return &c.EthereumECCD }},
+	{ChainEthereum, ContractECCM, func(c *CrossChainConfig) *common.Address { return &c.EthereumECCM }},
+	{ChainEthereum, ContractCCMP, func(c *CrossChainConfig) *common.Address { return &c.EthereumCCMP }},
+	{ChainEthereum, ContractPLTAsset, func(c *CrossChainConfig) *common.Address { return &c.EthereumPLTAsset }},
+	{ChainEthereum, ContractPLTProxy, func(c *CrossChainConfig) *common.Address { return &c.EthereumPLTProxy }},
+	{ChainEthereum, ContractNFTProxy, func(c *CrossChainConfig) *common.Address { return &c.EthereumNFTProxy }},
+	{ChainEthereum, ContractPLTWrapper, func(c *CrossChainConfig) *common.Address { return &c.EthereumPLTWrapper }},
+	{ChainEthereum, ContractNFTWrapper, func(c *CrossChainConfig) *common.Address { return &c.EthereumNFTWrapper }},
+}
+
+func (c *CrossChainConfig) addressField(chain, name string) *common.Address {
+	for _, f := range deploymentFields {
+		if f.chain == chain && f.name == name {
+			return f.field(c)
+		}
+	}
+	return nil
+}
+
+// DeploymentEnv returns the environment name of registry, it's the workspace sub directory.
+func (c *Config) DeploymentEnv() string {
+	if c.Environment.Name == "" {
+		return defaultEnvName
+	}
+	return c.Environment.Name
+}
+
+// DeploymentDir is shared by all environments, so that deployments of environments can be compared.
+func (c *Config) DeploymentDir() string {
+	return path.Join(c.Environment.LocalWorkspace, deploymentDir)
+}
+
+// OpenDeployments open the registry of env, current environment is used if env is empty.
+func (c *Config) OpenDeployments(env string) (*registry.Registry, error) {
+	if env == "" {
+		env = c.DeploymentEnv()
+	}
+	return registry.Open(c.DeploymentDir(), env)
+}
+
+func (c *Config) initDeployments() error {
+	r, err := c.OpenDeployments("")
+	if err != nil {
+		return fmt.Errorf("open deployment registry err: %v", err)
+	}
+	Deployments = r
+
+	for _, f := range deploymentFields {
+		rec, ok := r.Current(f.chain, f.name)
+		if !ok {
+			continue
+		}
+		field := f.field(c.CrossChain)
+		if *field != (common.Address{}) && *field != rec.Address {
+			log.Warnf("%s %s %s in config is overridden by deployment registry %s v%d",
+				f.chain, f.name, field.Hex(), rec.Address.Hex(), rec.Version)
+		}
+		*field = rec.Address
+	}
+	return nil
+}
+
+// Snapshot returns the hand-edited addresses in config file as registry records, it's used to
+// compare config file with registry.
+func (c *CrossChainConfig) Snapshot(chain string) map[string]*registry.Record {
+	snapshot := make(map[string]*registry.Record)
+	for _, f := range deploymentFields {
+		if addr := *f.field(c); f.chain == chain && addr != (common.Address{}) {
+			snapshot[f.name] = &registry.Record{Name: f.name, Address: addr}
+		}
+	}
+	return snapshot
+}
+
+// RecordDeployment save the new deployed contract in registry, and the contract address is used
+// by the following methods immediately.
+func (c *CrossChainConfig) RecordDeployment(chain string, rec *registry.Record) error {
+	rec.Method = callingMethod()
+	rec, err := Deployments.Add(chain, rec)
+	if err != nil {
+		return err
+	}
+	if field := c.addressField(chain, rec.Name); field != nil {
+		*field = rec.Address
+	}
+	log.Infof("record %s %s %s v%d in %s registry", chain, rec.Name, rec.Address.Hex(), rec.Version, Deployments.Env())
+	return nil
+}
+
+// UpdateDeployment append a new version of contract after its owner or bindings changed, the
+// contract which is only hand-edited in config file is imported first.
+func (c *CrossChainConfig) UpdateDeployment(chain, name, note string, fn func(rec *registry.Record)) error {
+	if _, ok := Deployments.Current(chain, name); !ok {
+		field := c.addressField(chain, name)
+		if field == nil || *field == (common.Address{}) {
+			return fmt.Errorf("contract %s not found on %s", name, chain)
+		}
+		if _, err := Deployments.Add(chain, &registry.Record{Name: name, Address: *field, Note: "import from config", Method: callingMethod()}); err != nil {
+			return err
+		}
+	}
+	rec, err := Deployments.Update(chain, name, note, callingMethod(), fn)
+	if err != nil {
+		return err
+	}
+	log.Infof("record %s %s %s v%d: %s", chain, name, rec.Address.Hex(), rec.Version, note)
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/palettechain/onRobot/pkg/hdwallet"
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// DefaultRoles maps account role to bip44 `account` field of derivation path.
+var DefaultRoles = map[string]uint32{
+	"fans":        1,
+	"stakers":     2,
+	"nft-holders": 3,
+}
+
+const defaultRoleScan = 100
+
+// HDWalletConfig is used to derive test accounts instead of creating keystore files.
+type HDWalletConfig struct {
+	Mnemonic string            // 助记词, 为空时使用环境变量ONROBOT_MNEMONIC, 仍为空时仅local profile使用公开的内置助记词
+	Roles    map[string]uint32 // 自定义角色及其派生路径中的account序号, 0为保留值
+	Scan     int               // 根据地址查找私钥时每个角色最多派生的账户数量
+}
+
+// LocalProfile is the only profile in which the public built-in mnemonic could be used.
+const LocalProfile = "local"
+
+// initHDWallet the built-in mnemonic is allowed in local profile only, role accounts derived from it on a
+// shared network can be spent by anyone. the wallet is disabled if no mnemonic set in other profiles.
+func (c *Config) initHDWallet() error {
+	mnemonic := ""
+	if c.HDWallet != nil {
+		mnemonic = c.HDWallet.Mnemonic
+	}
+	builtin, err := hdwallet.Init(mnemonic, ProfileName == LocalProfile)
+	if err != nil {
+		return err
+	}
+	if builtin {
+		log.Warnf("!!! hd wallet is using the PUBLIC built-in mnemonic, role accounts are spendable by anyone, " +
+			"never fund them on a shared network !!!")
+	} else if mnemonic == "" && os.Getenv(hdwallet.MnemonicEnv) == "" {
+		log.Warnf("hd wallet disabled in profile [%s], set HDWallet.Mnemonic or env %s to use role accounts",
+			ProfileName, hdwallet.MnemonicEnv)
+	}
+	return nil
+}
+
+// Roles returns the default roles merged with the roles in config.
+func (c *Config) Roles() map[string]uint32 {
+	roles := make(map[string]uint32)
+	for role, account := range DefaultRoles {
+		roles[role] = account
+	}
+	if c.HDWallet != nil {
+		for role, account := range c.HDWallet.Roles {
+			roles[role] = account
+		}
+	}
+	return roles
+}
+
+// RoleAccounts derive the first `n` accounts of role.
+func (c *Config) RoleAccounts(role string, n int) ([]*hdwallet.RoleAccount, error) {
+	account, ok := c.Roles()[role]
+	if !ok {
+		return nil, fmt.Errorf("unknown account role %s", role)
+	}
+	return hdwallet.DeriveRole(role, account, n)
+}
+
+func (c *Config) RoleScan() int {
+	if c.HDWallet == nil || c.HDWallet.Scan <= 0 {
+		return defaultRoleScan
+	}
+	return c.HDWallet.Scan
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/palettechain/onRobot/pkg/files"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/profile"
+)
+
+const (
+	// ProfileEnv select the profile if it's not set in cli.
+	ProfileEnv = "ONROBOT_PROFILE"
+
+	// OverrideEnvPrefix is the prefix of env which overrides config field, path is separated by
+	// double underscore, e.g: ONROBOT_CFG_CrossChain__EthereumRPCUrl=http://127.0.0.1:8545
+	OverrideEnvPrefix = "ONROBOT_CFG_"
+)
+
+var (
+	// Profile is the profile name set by cli flag `-profile`.
+	Profile string
+
+	// Overrides are set by cli flag `-set`, e.g: -set Nodes.0.Host=10.0.0.1
+	Overrides []string
+
+	// ProfileName is the profile which is actually used.
+	ProfileName string
+)
+
+// Load read config file, resolve profile, apply env and cli overrides and validate the result.
+// the priority is cli > env > profile > base document, and all of the errors are reported at once.
+func Load(filepath string) (*Config, error) {
+	data, err := files.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := profile.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %v", filepath, err)
+	}
+
+	name := Profile
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+	if doc, name, err = profile.Resolve(doc, name); err != nil {
+		return nil, fmt.Errorf("config %s: %v", filepath, err)
+	}
+
+	var errs profile.Errors
+	overrides := profile.ParseEnv(os.Environ(), OverrideEnvPrefix)
+	for _, s := range Overrides {
+		o, err := profile.ParseSet(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		overrides = append(overrides, o)
+	}
+	if err := profile.Apply(doc, overrides); err != nil {
+		errs = append(errs, err.(profile.Errors)...)
+	}
+	for _, o := range overrides {
+		log.Debugf("config override by %s", o.From)
+	}
+
+	c := new(Config)
+	errs = append(errs, profile.Decode(doc, c)...)
+	if len(errs) == 0 {
+		c.normalize()
+		if err := c.Validate(); err != nil {
+			errs = append(errs, err.(profile.Errors)...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("config %s profile [%s] invalid, %v", filepath, name, errs)
+	}
+
+	ProfileName = name
+	return c, nil
+}
+
+// normalize fill the fields which used to be read implicitly and sort nodes with node index.
+func (c *Config) normalize() {
+	if c.Environment != nil && c.Environment.Name == "" {
+		c.Environment.Name = os.Getenv(envName)
+	}
+	sort.Slice(c.Nodes, func(i, j int) bool {
+		return c.Nodes[i].Index < c.Nodes[j].Index
+	})
+}
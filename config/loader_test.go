@@ -0,0 +1,117 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `{
+  "Profile": "local",
+  "Profiles": {
+    "local": {},
+    "test": {"Extends": "local", "Rpc": "http://10.0.0.1:22000", "Network": {"ValidatorsNumber": 1}}
+  },
+  "Environment": {"Name": "local", "LocalWorkspace": "/tmp/onrobot"},
+  "Network": {"NodeIndexStart": 0, "GenesisNodeNumber": 1, "ValidatorsNumber": 0},
+  "Rpc": "http://127.0.0.1:22000",
+  "AdminAccount": "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+  "CrossChainAdminAccount": "0x70997970c51812dc3a010c7d01b50e0d17dc79c8",
+  "GasLimit": 2100000,
+  "DeployGasLimit": 10000000000,
+  "BlockPeriod": "7s",
+  "Nodes": [{
+    "Index": 0,
+    "Address": "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc",
+    "NodeKey": "3d9c828244d3b2da70233a0a2aea7430feda17bded6edd7f0c474163802a431c",
+    "StakeAccount": "0x90f79bf6eb2c4f870365e785982e1f101e93b906",
+    "Host": "127.0.0.1",
+    "RPCPort": "22000",
+    "P2PPort": "30300"
+  }],
+  "CrossChain": {
+    "PolyRPCAddress": "http://127.0.0.1:40336",
+    "EthereumRPCUrl": "http://127.0.0.1:8545"
+  }
+}`
+
+func writeTestConfig(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "onrobot-config")
+	assert.NoError(t, err)
+	file := path.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	return file, func() { _ = os.RemoveAll(dir) }
+}
+
+func TestLoadProfile(t *testing.T) {
+	file, clean := writeTestConfig(t, testConfig)
+	defer clean()
+
+	c, err := Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", ProfileName)
+	assert.Equal(t, "/tmp/onrobot/local", c.Environment.WorkSpace())
+	assert.Equal(t, 7*time.Second, time.Duration(c.BlockPeriod))
+
+	// cli profile has higher priority than env, and cli override is higher than env override
+	Profile = "test"
+	Overrides = []string{"Nodes.0.RPCPort=22001"}
+	defer func() { Profile, Overrides = "", nil }()
+	_ = os.Setenv(ProfileEnv, "prod")
+	_ = os.Setenv(OverrideEnvPrefix+"NODES__0__RPCPORT", "22002")
+	_ = os.Setenv(OverrideEnvPrefix+"GasLimit", "3000000")
+	defer os.Unsetenv(ProfileEnv)
+	defer os.Unsetenv(OverrideEnvPrefix + "NODES__0__RPCPORT")
+	defer os.Unsetenv(OverrideEnvPrefix + "GasLimit")
+
+	// profile test requires 2 nodes
+	_, err = Load(file)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "exceeds 1 nodes"))
+
+	Overrides = append(Overrides, "Network.ValidatorsNumber=0")
+	c, err = Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", ProfileName)
+	assert.Equal(t, "http://10.0.0.1:22000", c.Rpc)
+	assert.Equal(t, "22001", c.Nodes[0].RPCPort)
+	assert.Equal(t, uint64(3000000), c.GasLimit)
+}
+
+func TestLoadErrors(t *testing.T) {
+	content := strings.NewReplacer(
+		`"GasLimit": 2100000`, `"GasLimit": "2100000", "GasLimt": 1`,
+		`"EthereumRPCUrl": "http://127.0.0.1:8545"`, `"EthereumRPCUrl": ""`,
+		`"NodeIndexStart": 0`, `"NodeIndexStart": 3`,
+	).Replace(testConfig)
+	file, clean := writeTestConfig(t, content)
+	defer clean()
+
+	// decode errors are reported together
+	_, err := Load(file)
+	assert.Error(t, err)
+	for _, field := range []string{"GasLimit: expect uint64", "GasLimt: unknown field"} {
+		assert.True(t, strings.Contains(err.Error(), field), field)
+	}
+
+	// validation errors are reported together
+	content = strings.Replace(content, `"GasLimit": "2100000", "GasLimt": 1`, `"GasLimit": 2100000`, 1)
+	file, clean = writeTestConfig(t, content)
+	defer clean()
+	_, err = Load(file)
+	assert.Error(t, err)
+	for _, field := range []string{"CrossChain.EthereumRPCUrl: required", "Network: NodeIndexStart(3)"} {
+		assert.True(t, strings.Contains(err.Error(), field), field)
+	}
+
+	file, clean = writeTestConfig(t, "{\n  \"Rpc\": \"http://127.0.0.1:22000\"\n  \"GasLimit\": 1\n}")
+	defer clean()
+	_, err = Load(file)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "line 3"))
+}
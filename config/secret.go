@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/dao"
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+const (
+	// NonInteractiveEnv disable reading password from stdin, the env `CI` works too.
+	NonInteractiveEnv = "ONROBOT_NONINTERACTIVE"
+
+	maxPasswordRetry = 10
+)
+
+// SecretConfig is used to cache keystore passwords in leveldb, passwords are encrypted with the
+// master passphrase, and never cached if the passphrase is not set.
+type SecretConfig struct {
+	Passphrase string          // 主密码, 为空时使用环境变量ONROBOT_MASTER_KEY, 均为空时不缓存密码
+	TTL        encode.Duration // 密码缓存有效期, 默认12h
+}
+
+func (c *Config) initSecretStore() error {
+	passphrase, ttl := "", dao.DefaultTTL
+	if c.Secret != nil {
+		passphrase = c.Secret.Passphrase
+		if c.Secret.TTL > 0 {
+			ttl = time.Duration(c.Secret.TTL)
+		}
+	}
+	dao.SetTTL(ttl)
+
+	switch err := dao.SetMasterKey(passphrase); err {
+	case nil:
+		if n, err := dao.PurgeExpired(); err == nil && n > 0 {
+			log.Infof("purge %d expired passwords", n)
+		}
+		return nil
+	case dao.ErrNoMasterKey:
+		log.Infof("master key not set, keystore passwords will not be cached")
+		return nil
+	default:
+		return fmt.Errorf("init secret store err: %v", err)
+	}
+}
+
+// InitSecretStore open the leveldb and secret store only, it's used by sub-commands which
+// should not load any account. secrets can be listed and revoked even if the master key is wrong.
+func InitSecretStore(filepath string) error {
+	c, err := Load(filepath)
+	if err != nil {
+		return err
+	}
+	Conf = c
+	dao.NewDao(path.Join(Conf.Environment.WorkSpace(), dataDir))
+	if err := Conf.initSecretStore(); err != nil {
+		log.Warnf("%v, only list, revoke and audit are available", err)
+	}
+	return nil
+}
+
+// SecretTypeName returns the readable name of password session type.
+func SecretTypeName(typ byte) string {
+	switch pwdSessionType(typ) {
+	case pwdSessionETH:
+		return "eth"
+	case pwdSessionPLT:
+		return "plt"
+	case pwdSessionPoly:
+		return "poly"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSecretType is the reverse of `SecretTypeName`.
+func ParseSecretType(name string) (byte, error) {
+	for _, typ := range []pwdSessionType{pwdSessionETH, pwdSessionPLT, pwdSessionPoly} {
+		if SecretTypeName(byte(typ)) == name {
+			return byte(typ), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown secret type %s, should be one of eth, plt and poly", name)
+}
+
+func repeatDecrypt(enc []byte, account common.Address, pwd string, typ pwdSessionType) (key *keystore.Key, err error) {
+	if existPwd, err := getPwdSession(account, typ); err == nil {
+		if key, err := keystore.DecryptKey(enc, existPwd); err == nil {
+			return key, nil
+		}
+	}
+
+	if key, err = keystore.DecryptKey(enc, pwd); err == nil {
+		_ = setPwdSession(account, pwd, typ)
+		return
+	}
+
+	log.Infof("please input password for %s account %s", SecretTypeName(byte(typ)), account.Hex())
+	err = readPassword(func(curPwd string) error {
+		if key, err = keystore.DecryptKey(enc, curPwd); err == nil {
+			_ = setPwdSession(account, curPwd, typ)
+		}
+		return err
+	})
+	return
+}
+
+// readPassword read password from stdin until `try` succeed, it fails immediately if the robot
+// is running non-interactively, e.g: in CI.
+func readPassword(try func(pwd string) error) error {
+	if !interactive() {
+		return fmt.Errorf("password invalid and stdin is not interactive, set it in config or env instead")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	err := fmt.Errorf("no password input")
+	for i := 0; i < maxPasswordRetry; i++ {
+		curPwd, rerr := reader.ReadString('\n')
+		if rerr != nil {
+			log.Infof("input error, try it again......")
+			continue
+		}
+		curPwd = strings.Trim(curPwd, " ")
+		curPwd = strings.Trim(curPwd, "\r")
+		curPwd = strings.Trim(curPwd, "\n")
+		if err = try(curPwd); err == nil {
+			return nil
+		}
+		log.Infof("password invalid, err %s, try it again......", err.Error())
+	}
+	return err
+}
+
+func interactive() bool {
+	if os.Getenv(NonInteractiveEnv) != "" || os.Getenv("CI") != "" {
+		return false
+	}
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// current is passed in by `Init`, it returns the method which is running on the calling goroutine.
+var current func() string
+
+// callingMethod returns the method which is using the secret or registry, or `init` outside of methods.
+func callingMethod() string {
+	if current == nil {
+		return "init"
+	}
+	if name := current(); name != "" {
+		return name
+	}
+	return "init"
+}
+
+func setPwdSession(acc common.Address, pwd string, typ pwdSessionType) error {
+	if !dao.Enabled() {
+		return dao.ErrNoMasterKey
+	}
+	return dao.SaveSecret(byte(typ), acc.Bytes(), []byte(pwd), callingMethod())
+}
+
+func getPwdSession(acc common.Address, typ pwdSessionType) (string, error) {
+	bz, err := dao.GetSecret(byte(typ), acc.Bytes(), callingMethod())
+	if err != nil {
+		return "", err
+	}
+	return string(bz), nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/pkg/signer"
+)
+
+const (
+	SignerTypeKey      = "key"      // 明文私钥, 仅用于测试网
+	SignerTypeKeystore = "keystore" // keystore文件
+	SignerTypeHD       = "hd"       // 机器人hd钱包的派生路径
+	SignerTypeRemote   = "remote"   // clef风格的远程签名服务, 私钥不进入机器人进程
+)
+
+// SignerConfig specify how to sign txs for an account, accounts which are not configured are
+// still loaded from the keystore dir of workspace.
+type SignerConfig struct {
+	Address    common.Address
+	Type       string
+	Key        string // type key: hex private key
+	Keystore   string // type keystore: 文件路径, 相对路径基于workspace
+	Passphrase string // type keystore: 为空时使用DefaultPassphrase
+	Path       string // type hd: 派生路径, e.g: m/44'/60'/0'/0/1
+	Url        string // type remote: 签名服务json-rpc地址
+}
+
+var signers sync.Map // address => signer.Signer
+
+func (sc *SignerConfig) build() (s signer.Signer, err error) {
+	switch sc.Type {
+	case SignerTypeKey:
+		var key *ecdsa.PrivateKey
+		if key, err = hexToKey(sc.Key); err == nil {
+			s, err = signer.NewKeySigner(key)
+		}
+	case SignerTypeKeystore:
+		file := sc.Keystore
+		if !path.IsAbs(file) {
+			file = path.Join(Conf.Environment.WorkSpace(), file)
+		}
+		pwd := sc.Passphrase
+		if pwd == "" {
+			pwd = Conf.DefaultPassphrase
+		}
+		s, err = signer.NewKeystoreSigner(file, pwd)
+	case SignerTypeHD:
+		s, err = signer.NewHDSigner(sc.Path)
+	case SignerTypeRemote:
+		s, err = signer.NewRemoteSigner(sc.Url, sc.Address)
+	default:
+		err = fmt.Errorf("unknown signer type %s", sc.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("account %s signer: [%v]", sc.Address.Hex(), err)
+	}
+	if s.Address() != sc.Address {
+		return nil, fmt.Errorf("account %s signer: %v, got %s", sc.Address.Hex(), signer.ErrAddressMismatch, s.Address().Hex())
+	}
+	return s, nil
+}
+
+// configuredSigner returns the signer in config, both palette and ethereum accounts are supported.
+func configuredSigner(addr common.Address) (signer.Signer, bool, error) {
+	if s, ok := signers.Load(addr); ok {
+		return s.(signer.Signer), true, nil
+	}
+	for _, sc := range Conf.Signers {
+		if sc.Address != addr {
+			continue
+		}
+		s, err := sc.build()
+		if err != nil {
+			return nil, true, err
+		}
+		actual, _ := signers.LoadOrStore(addr, s)
+		return actual.(signer.Signer), true, nil
+	}
+	return nil, false, nil
+}
+
+// LoadPaletteSigner returns the configured signer of account, or the key signer loaded from keystore dir.
+func LoadPaletteSigner(addr common.Address) (signer.Signer, error) {
+	if s, ok, err := configuredSigner(addr); ok {
+		return s, err
+	}
+	key, err := LoadPaletteAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	return newKeySigner(key)
+}
+
+func (c *CrossChainConfig) LoadETHSigner() (signer.Signer, error) {
+	return c.CustomLoadEthSigner(c.EthereumAccount, c.EthereumAccountPassword)
+}
+
+func (c *CrossChainConfig) LoadETHOwnerSigner() (signer.Signer, error) {
+	return c.CustomLoadEthSigner(c.EthereumOwner, c.EthereumOwnerPassword)
+}
+
+// CustomLoadEthSigner returns the configured signer of account, or the key signer loaded from eth keystore dir.
+func (c *CrossChainConfig) CustomLoadEthSigner(acc common.Address, pwd string) (signer.Signer, error) {
+	if s, ok, err := configuredSigner(acc); ok {
+		return s, err
+	}
+	key, err := c.CustomLoadEthAccount(acc, pwd)
+	if err != nil {
+		return nil, err
+	}
+	return newKeySigner(key)
+}
+
+// newKeySigner avoid returning typed nil signer on error.
+func newKeySigner(key *ecdsa.PrivateKey) (signer.Signer, error) {
+	s, err := signer.NewKeySigner(key)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func hexToKey(str string) (*ecdsa.PrivateKey, error) {
+	return crypto.HexToECDSA(strings.TrimPrefix(str, "0x"))
+}
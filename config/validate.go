@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/hex"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/profile"
+)
+
+// Validate check required fields and ranges, all of the errors are returned as `profile.Errors`.
+func (c *Config) Validate() error {
+	var errs profile.Errors
+
+	if c.Environment == nil {
+		errs.Add("Environment: required")
+	} else if c.Environment.LocalWorkspace == "" {
+		errs.Add("Environment.LocalWorkspace: required")
+	}
+
+	checkURL(&errs, "Rpc", c.Rpc)
+	if c.AdminAccount == (common.Address{}) {
+		errs.Add("AdminAccount: required")
+	}
+	if c.CrossChainAdminAccount == (common.Address{}) {
+		errs.Add("CrossChainAdminAccount: required")
+	}
+	if c.GasLimit == 0 {
+		errs.Add("GasLimit: should be greater than 0")
+	}
+	if c.DeployGasLimit == 0 {
+		errs.Add("DeployGasLimit: should be greater than 0")
+	}
+	if c.BlockPeriod <= 0 {
+		errs.Add("BlockPeriod: should be greater than 0")
+	}
+
+	c.validateNodes(&errs)
+
+	if cc := c.CrossChain; cc == nil {
+		errs.Add("CrossChain: required")
+	} else {
+		checkURL(&errs, "CrossChain.EthereumRPCUrl", cc.EthereumRPCUrl)
+		checkURL(&errs, "CrossChain.PolyRPCAddress", cc.PolyRPCAddress)
+	}
+
+	if w := c.Wait; w != nil {
+		names := []string{"Timeout", "Interval", "MaxInterval", "CrossChainTimeout"}
+		for i, d := range []encode.Duration{w.Timeout, w.Interval, w.MaxInterval, w.CrossChainTimeout} {
+			if d < 0 {
+				errs.Add("Wait.%s: should not be negative", names[i])
+			}
+		}
+		if w.Multiplier != 0 && w.Multiplier < 1 {
+			errs.Add("Wait.Multiplier: should be 0(default) or not less than 1, got %v", w.Multiplier)
+		}
+	}
+
+	if c.HDWallet != nil {
+		for role, account := range c.HDWallet.Roles {
+			if account == 0 {
+				errs.Add("HDWallet.Roles.%s: account 0 is reserved", role)
+			}
+		}
+	}
+
+	seen := make(map[common.Address]bool)
+	for i, sc := range c.Signers {
+		sc.validate(&errs, i)
+		if seen[sc.Address] {
+			errs.Add("Signers[%d]: duplicate address %s", i, sc.Address.Hex())
+		}
+		seen[sc.Address] = true
+	}
+
+	return errs.Err()
+}
+
+func (c *Config) validateNodes(errs *profile.Errors) {
+	indexes := make(map[int]bool)
+	for i, n := range c.Nodes {
+		if n == nil {
+			errs.Add("Nodes[%d]: should not be null", i)
+			continue
+		}
+		if indexes[n.Index] {
+			errs.Add("Nodes[%d].Index: duplicate index %d", i, n.Index)
+		}
+		indexes[n.Index] = true
+		if n.Host == "" {
+			errs.Add("Nodes[%d].Host: required", i)
+		}
+		checkPort(errs, i, "RPCPort", n.RPCPort)
+		checkPort(errs, i, "P2PPort", n.P2PPort)
+		if bz, err := hex.DecodeString(n.NodeKey); err != nil || len(bz) != 32 {
+			errs.Add("Nodes[%d].NodeKey: should be 64 hex characters", i)
+		}
+		if !common.IsHexAddress(n.StakeAccount) {
+			errs.Add("Nodes[%d].StakeAccount: invalid address %q", i, n.StakeAccount)
+		}
+	}
+
+	nw := c.Network
+	if nw == nil {
+		errs.Add("Network: required")
+		return
+	}
+	if nw.NodeIndexStart < 0 {
+		errs.Add("Network.NodeIndexStart: should not be negative")
+	}
+	if nw.GenesisNodeNumber <= 0 {
+		errs.Add("Network.GenesisNodeNumber: should be greater than 0")
+	}
+	if nw.ValidatorsNumber < 0 {
+		errs.Add("Network.ValidatorsNumber: should not be negative")
+	}
+	if end := nw.NodeIndexStart + nw.GenesisNodeNumber + nw.ValidatorsNumber; end > len(c.Nodes) {
+		errs.Add("Network: NodeIndexStart(%d) + GenesisNodeNumber(%d) + ValidatorsNumber(%d) exceeds %d nodes",
+			nw.NodeIndexStart, nw.GenesisNodeNumber, nw.ValidatorsNumber, len(c.Nodes))
+	}
+}
+
+func (sc *SignerConfig) validate(errs *profile.Errors, i int) {
+	if sc.Address == (common.Address{}) {
+		errs.Add("Signers[%d].Address: required", i)
+	}
+	switch sc.Type {
+	case SignerTypeKey:
+		if _, err := hexToKey(sc.Key); err != nil {
+			errs.Add("Signers[%d].Key: invalid private key", i)
+		}
+	case SignerTypeKeystore:
+		if sc.Keystore == "" {
+			errs.Add("Signers[%d].Keystore: required by type keystore", i)
+		}
+	case SignerTypeHD:
+		if sc.Path == "" {
+			errs.Add("Signers[%d].Path: required by type hd", i)
+		}
+	case SignerTypeRemote:
+		checkURL(errs, "Signers["+strconv.Itoa(i)+"].Url", sc.Url)
+	default:
+		errs.Add("Signers[%d].Type: should be one of key, keystore, hd and remote, got %q", i, sc.Type)
+	}
+}
+
+func checkURL(errs *profile.Errors, field, s string) {
+	if s == "" {
+		errs.Add("%s: required", field)
+		return
+	}
+	if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+		errs.Add("%s: invalid url %q", field, s)
+	}
+}
+
+func checkPort(errs *profile.Errors, i int, field, port string) {
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		errs.Add("Nodes[%d].%s: invalid port %q", i, field, port)
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/poll"
+)
+
+const defaultCrossChainTimeout = 10 * time.Minute
+
+// WaitConfig controls condition polling, zero value means default.
+type WaitConfig struct {
+	Timeout           encode.Duration // 普通条件等待超时时间
+	Interval          encode.Duration // 初始轮询间隔
+	MaxInterval       encode.Duration // 最大轮询间隔
+	Multiplier        float64         // 轮询间隔增长倍数
+	CrossChainTimeout encode.Duration // 跨链交易经过palette, poly, ethereum三条链, 超时时间需要更长
+}
+
+// PollOptions return options for common waiting, e.g: receipt, block height, proposal state.
+func (c *Config) PollOptions() poll.Options {
+	opt := poll.Default
+	if c.Wait == nil {
+		return opt
+	}
+	if c.Wait.Timeout > 0 {
+		opt.Timeout = time.Duration(c.Wait.Timeout)
+	}
+	if c.Wait.Interval > 0 {
+		opt.Interval = time.Duration(c.Wait.Interval)
+	}
+	if c.Wait.MaxInterval > 0 {
+		opt.MaxInterval = time.Duration(c.Wait.MaxInterval)
+	}
+	if c.Wait.Multiplier > 0 {
+		opt.Multiplier = c.Wait.Multiplier
+	}
+	return opt
+}
+
+// CrossChainPollOptions return options for waiting cross chain tx landing on the other side.
+func (c *Config) CrossChainPollOptions() poll.Options {
+	timeout := defaultCrossChainTimeout
+	if c.Wait != nil && c.Wait.CrossChainTimeout > 0 {
+		timeout = time.Duration(c.Wait.CrossChainTimeout)
+	}
+	return c.PollOptions().WithTimeout(timeout)
+}
+
+// BlocksPollOptions return options for waiting n blocks, the timeout is three times of expected duration.
+func (c *Config) BlocksPollOptions(n int) poll.Options {
+	timeout := 3 * time.Duration(c.BlockPeriod) * time.Duration(n)
+	if timeout <= 0 {
+		timeout = poll.Default.Timeout
+	}
+	opt := c.PollOptions().WithTimeout(timeout)
+	if period := time.Duration(c.BlockPeriod); period > 0 && opt.MaxInterval > period {
+		opt.MaxInterval = period
+	}
+	return opt
+}
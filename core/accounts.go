@@ -0,0 +1,195 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/native/plt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/hdwallet"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+const defaultFundBatch = 20
+
+// roleKeys caches the private keys of derived accounts, so that `customLoadAccount` is able to
+// find them without keystore files.
+var roleKeys sync.Map
+
+// roleAccounts derive the first `n` accounts of role, e.g: fans, stakers, nft-holders.
+func roleAccounts(role string, n int) ([]*hdwallet.RoleAccount, error) {
+	list, err := config.Conf.RoleAccounts(role, n)
+	if err != nil {
+		return nil, err
+	}
+	for _, acc := range list {
+		roleKeys.Store(acc.Address, acc.Key)
+	}
+	return list, nil
+}
+
+// roleOrFreshAccounts derive accounts of role, or generate fresh keys if role is empty or the hd wallet
+// is disabled, e.g: non-local profile without mnemonic.
+func roleOrFreshAccounts(role string, n int) ([]*hdwallet.RoleAccount, error) {
+	if role != "" {
+		list, err := roleAccounts(role, n)
+		if !errors.Is(err, hdwallet.ErrNoMnemonic) {
+			return list, err
+		}
+		log.Warnf("derive %s accounts failed, err: %v, use fresh accounts instead", role, err)
+	}
+	return freshAccounts(n)
+}
+
+// freshAccounts generate n random accounts which are different in every run.
+func freshAccounts(n int) ([]*hdwallet.RoleAccount, error) {
+	list := make([]*hdwallet.RoleAccount, 0, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &hdwallet.RoleAccount{Index: i, Address: crypto.PubkeyToAddress(key.PublicKey), Key: key})
+	}
+	return list, nil
+}
+
+// loadRoleKey find private key of address in derived accounts of all roles.
+func loadRoleKey(addr common.Address) *ecdsa.PrivateKey {
+	if key, ok := roleKeys.Load(addr); ok {
+		return key.(*ecdsa.PrivateKey)
+	}
+	for role := range config.Conf.Roles() {
+		if _, err := roleAccounts(role, config.Conf.RoleScan()); err != nil {
+			log.Warnf("derive %s accounts failed, err: %v", role, err)
+		}
+	}
+	if key, ok := roleKeys.Load(addr); ok {
+		return key.(*ecdsa.PrivateKey)
+	}
+	return nil
+}
+
+// fundAccounts transfer `amount` from admin to every account, at most `batch` txs are sent before
+// waiting for them.
+func fundAccounts(list []*hdwallet.RoleAccount, amount *big.Int, batch int) error {
+	if batch <= 0 {
+		batch = defaultFundBatch
+	}
+	admcli := getPaletteCli(pltCTypeAdmin)
+	for start := 0; start < len(list); start += batch {
+		end := start + batch
+		if end > len(list) {
+			end = len(list)
+		}
+		hashes := make([]common.Hash, 0, end-start)
+		for _, acc := range list[start:end] {
+			hash, err := admcli.PLTTransferWithoutWaiting(acc.Address, amount)
+			if err != nil {
+				return fmt.Errorf("fund %s %d failed, err: %v", acc.Role, acc.Index, err)
+			}
+			hashes = append(hashes, hash)
+		}
+		for _, hash := range hashes {
+			if err := admcli.WaitTransaction(hash); err != nil {
+				return err
+			}
+		}
+		log.Infof("funded accounts %d-%d with %d PLT each", start, end-1, plt.PrintUPLT(amount))
+	}
+	return nil
+}
+
+// sweepAccounts transfer the whole balance of every account back to admin, gas price is 0 on palette.
+func sweepAccounts(list []*hdwallet.RoleAccount) (*big.Int, error) {
+	total := big.NewInt(0)
+	url := config.Conf.Rpc
+	hashes := make([]common.Hash, 0, len(list))
+	clients := make([]*sdk.Client, 0, len(list))
+	for _, acc := range list {
+		cli := sdk.NewSender(url, acc.Key)
+		balance, err := cli.BalanceOf(acc.Address, "latest")
+		if err != nil {
+			return total, err
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+		hash, err := cli.PLTTransferWithoutWaiting(config.Conf.AdminAccount, balance)
+		if err != nil {
+			return total, fmt.Errorf("sweep %s %d failed, err: %v", acc.Role, acc.Index, err)
+		}
+		hashes = append(hashes, hash)
+		clients = append(clients, cli)
+		total.Add(total, balance)
+	}
+	for i, hash := range hashes {
+		if err := clients[i].WaitTransaction(hash); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// 派生测试账户:
+// 根据助记词派生Role角色的前Number个账户, 管理员分批给每个账户转账Amount PLT, 输出账户地址列表
+func Provision(res *frame.Result) error {
+	var params struct {
+		Role   string
+		Number int
+		Amount int
+		Batch  int
+	}
+	if err := config.LoadParams("Provision.json", &params); err != nil {
+		return err
+	}
+
+	list, err := roleAccounts(params.Role, params.Number)
+	if err != nil {
+		return err
+	}
+	addrs := make([]string, len(list))
+	for i, acc := range list {
+		addrs[i] = acc.Address.Hex()
+		log.Infof("%s %d %s %s", acc.Role, acc.Index, acc.Path, acc.Address.Hex())
+	}
+	if params.Amount > 0 {
+		if err := fundAccounts(list, plt.MultiPLT(params.Amount), params.Batch); err != nil {
+			return err
+		}
+	}
+	res.Output("addresses", addrs)
+	res.Metric("accounts", float64(len(list)), "")
+	return nil
+}
+
+// 回收测试账户:
+// 将Role角色前Number个账户的PLT余额全部转回管理员账户
+func Sweep(res *frame.Result) error {
+	var params struct {
+		Role   string
+		Number int
+	}
+	if err := config.LoadParams("Sweep.json", &params); err != nil {
+		return err
+	}
+
+	list, err := roleAccounts(params.Role, params.Number)
+	if err != nil {
+		return err
+	}
+	total, err := sweepAccounts(list)
+	log.Infof("swept %d PLT from %d %s accounts", plt.PrintUPLT(total), len(list), params.Role)
+	if err != nil {
+		return err
+	}
+	res.Metric("swept", float64(plt.PrintUPLT(total)), "PLT")
+	return nil
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/audit"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// 检查数据一致性(重要):
+// 1.默认比较config.Conf.Nodes中的所有节点, 也可以通过NodeIndex指定
+// 2.区块范围为[StartBlock, EndBlock], EndBlock为0时取所有节点中最低高度减去Lag, 非验证节点同步速度可能会慢上几个块,
+// StartBlock未设置或大于EndBlock时检查EndBlock之前的Range个区块, 设置为0时从创世块开始检查
+// 3.逐个高度比较区块hash, state root, 有效验证人集合, 分润记录, 全局参数以及指定账户的PLT余额
+// 4.输出每对节点首个不一致的高度, 以及所有不一致的字段, Output不为空时将报告写入文件, 任何查询失败都视为不一致
+func Consistency(res *frame.Result) error {
+	var params struct {
+		NodeIndex     []int
+		StartBlock    *uint64 // nil表示未设置, 0为创世块
+		EndBlock      uint64
+		Lag           uint64
+		Range         uint64
+		ProposalTypes []uint8
+		Balances      []common.Address
+		Output        string
+	}
+	if err := config.LoadParams("Consistency.json", &params); err != nil {
+		return err
+	}
+	if params.Lag == 0 {
+		params.Lag = 10
+	}
+	if params.Range == 0 {
+		params.Range = 10
+	}
+	if len(params.ProposalTypes) == 0 {
+		params.ProposalTypes = []uint8{1, 2}
+	}
+
+	nodes := config.Conf.Nodes
+	if len(params.NodeIndex) > 0 {
+		nodes = make([]*config.Node, 0, len(params.NodeIndex))
+		for _, index := range params.NodeIndex {
+			node := config.Conf.GetNodeByIndex(index)
+			if node == nil {
+				return fmt.Errorf("node %d not exist", index)
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) < 2 {
+		return fmt.Errorf("at least 2 nodes required, got %d", len(nodes))
+	}
+
+	names := make([]string, len(nodes))
+	clients := make([]*sdk.Client, len(nodes))
+	for i, node := range nodes {
+		names[i] = fmt.Sprintf("node%d", node.Index)
+		clients[i] = sdk.NewSignerSender(node.RPCAddr(), config.AdminSigner)
+	}
+
+	end := params.EndBlock
+	if end == 0 {
+		for i, cli := range clients {
+			height, err := cli.BlockNumber()
+			if err != nil {
+				return fmt.Errorf("%s get block number failed, err: %v", names[i], err)
+			}
+			if end == 0 || height < end {
+				end = height
+			}
+		}
+		if end <= params.Lag {
+			return fmt.Errorf("block height %d too low", end)
+		}
+		end -= params.Lag
+	}
+	var start uint64
+	if params.StartBlock != nil && *params.StartBlock <= end {
+		start = *params.StartBlock
+	} else if end > params.Range {
+		start = end - params.Range
+	}
+
+	logsplit()
+	log.Infof("audit %d nodes from block %d to %d...", len(nodes), start, end)
+	auditor := audit.New(names)
+	var wg sync.WaitGroup
+	for i, cli := range clients {
+		wg.Add(1)
+		go func(name string, cli *sdk.Client) {
+			defer wg.Done()
+			for height := start; height <= end; height++ {
+				observeConsistency(auditor, name, cli, height, params.ProposalTypes, params.Balances)
+			}
+		}(names[i], cli)
+	}
+	wg.Wait()
+
+	report := auditor.Report()
+	for _, line := range strings.Split(strings.TrimSpace(report.Text()), "\n") {
+		log.Info(line)
+	}
+	if params.Output != "" {
+		bz, err := report.JSON()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(params.Output, bz, 0644); err != nil {
+			return err
+		}
+		log.Infof("audit report saved to %s", params.Output)
+	}
+
+	res.Metric("audited-blocks", float64(end-start+1), "block")
+	res.Metric("diffs", float64(len(report.Diffs)), "")
+	res.Metric("fetch-errors", float64(len(report.Errors)), "")
+	for _, d := range report.Divergences {
+		res.Assert(d.NodeA+"-"+d.NodeB, false, "diverge at height %d, fields %s", d.Height, strings.Join(d.Fields, ","))
+	}
+	// fields which failed to fetch are not compared, so the report is not trustworthy
+	res.Assert("consistent", report.Consistent && len(report.Errors) == 0,
+		"%d fields diverged, %d fetches failed", len(report.Diffs), len(report.Errors))
+	return nil
+}
+
+func observeConsistency(
+	auditor *audit.Auditor,
+	name string,
+	cli *sdk.Client,
+	height uint64,
+	proposalTypes []uint8,
+	balances []common.Address,
+) {
+	blockNum := BlockNumber2Hex(height)
+
+	if block, err := cli.GetBlockByNumber(height); err != nil {
+		auditor.Fail(name, height, "block", err)
+	} else {
+		auditor.Observe(name, height, "hash", block.Hash().Hex())
+		auditor.Observe(name, height, "stateRoot", block.Root().Hex())
+	}
+
+	if list := cli.GetEffectiveValidators(blockNum); list == nil {
+		auditor.Fail(name, height, "validators", fmt.Errorf("failed to get effective validators"))
+	} else {
+		hexes := make([]string, len(list))
+		for i, addr := range list {
+			hexes[i] = addr.Hex()
+		}
+		auditor.Observe(name, height, "validators", strings.Join(hexes, ","))
+	}
+
+	if data, err := cli.GetRewardRecordBlock(blockNum); err != nil {
+		auditor.Fail(name, height, "rewardRecordBlock", err)
+	} else {
+		auditor.Observe(name, height, "rewardRecordBlock", data.String())
+	}
+	if data, err := cli.GetLatestRewardProposer(blockNum); err != nil {
+		auditor.Fail(name, height, "rewardProposer", err)
+	} else {
+		auditor.Observe(name, height, "rewardProposer", data.Hex())
+	}
+
+	for _, typ := range proposalTypes {
+		field := fmt.Sprintf("globalParams%d", typ)
+		if data, err := cli.GetGlobalParams(typ, blockNum); err != nil {
+			auditor.Fail(name, height, field, err)
+		} else {
+			auditor.Observe(name, height, field, data.String())
+		}
+	}
+
+	for _, addr := range balances {
+		field := "balance." + addr.Hex()
+		if data, err := cli.BalanceOf(addr, blockNum); err != nil {
+			auditor.Fail(name, height, field, err)
+		} else {
+			auditor.Observe(name, height, field, data.String())
+		}
+	}
+}
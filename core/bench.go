@@ -0,0 +1,296 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/native/plt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/bench"
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poll"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+const (
+	benchTransfer = "transfer"
+	benchStake    = "stake"
+	benchNFTMint  = "nft-mint"
+	benchCall     = "call"
+)
+
+type benchParams struct {
+	TPS         float64
+	Duration    encode.Duration
+	Accounts    int
+	Workers     int
+	FundAmount  int // 每个压测账户初始PLT数量
+	StakeAmount int // 每笔stake交易的PLT数量
+	NodeIndex   int // stake目标节点
+	Mix         map[string]int
+	NFTAsset    common.Address // nft-mint交易的资产合约, 由跨链管理员mint
+	Contract    common.Address // call交易的目标合约
+	Payload     hexutil.Bytes  // call交易的calldata
+	DrainBlocks int            // 压测结束后最多等待的区块数量
+	Output      string         // 压测结果输出文件
+}
+
+// 压测(发版验收):
+// 1.生成N个随机账户, 管理员给每个账户转账FundAmount PLT
+// 2.按照目标TPS在Duration时间内持续发送交易, 交易类型按Mix权重随机选择: 转账, 质押, nft铸造, 合约调用
+// 3.扫描新区块统计交易上链数量, 从提交到上链的延迟, 以及每个区块的交易数量
+// 4.压测结束后继续等待DrainBlocks个区块, 未上链的交易记为not-included错误
+// 5.输出提交/上链数量, 延迟百分位, 错误分类
+func Bench(res *frame.Result) error {
+	var params benchParams
+	if err := config.LoadParams("Bench.json", &params); err != nil {
+		return err
+	}
+	opt := bench.Options{TPS: params.TPS, Duration: time.Duration(params.Duration), Workers: params.Workers}
+	if opt.Workers == 0 {
+		opt.Workers = params.Accounts
+	}
+	if err := opt.Validate(); err != nil {
+		return err
+	}
+	if params.Accounts <= 0 {
+		return fmt.Errorf("invalid accounts number %d", params.Accounts)
+	}
+	mix, err := bench.NewMix(params.Mix)
+	if err != nil {
+		return err
+	}
+	if params.DrainBlocks <= 0 {
+		params.DrainBlocks = 10
+	}
+
+	logsplit()
+	log.Infof("prepare %d accounts with %d PLT each...", params.Accounts, params.FundAmount)
+	clients, err := prepareBenchAccounts(params.Accounts, plt.MultiPLT(params.FundAmount))
+	if err != nil {
+		return err
+	}
+
+	senders, err := newBenchSenders(mix, &params)
+	if err != nil {
+		return err
+	}
+
+	var (
+		stats   = bench.NewStats()
+		watcher = sdk.NewReader(config.Conf.Rpc)
+		stop    = make(chan struct{})
+		done    = make(chan error)
+	)
+	start, err := watcher.BlockNumber()
+	if err != nil {
+		return err
+	}
+	go func() {
+		done <- scanBenchBlocks(watcher, stats, start+1, stop, params.DrainBlocks)
+	}()
+
+	logsplit()
+	log.Infof("start load, target tps %v, duration %s, mix %v", params.TPS, opt.Duration, params.Mix)
+	var mu sync.Mutex
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	logger := log.Current()
+	dropped, err := bench.Run(opt, func(seq int) {
+		mu.Lock()
+		kind := mix.Pick(r)
+		mu.Unlock()
+
+		cli := clients[seq%len(clients)]
+		hash, err := senders[kind](cli, seq)
+		if err != nil {
+			category := bench.Categorize(err)
+			if _, ok := sdk.IsRevert(err); ok {
+				category = bench.ErrRevert
+			}
+			stats.Failed(kind, category)
+			logger.Debugf("bench %s tx failed, err: %v", kind, err)
+			return
+		}
+		stats.Submitted(kind, hash.Hex(), time.Now())
+	})
+	if err != nil {
+		close(stop)
+		<-done
+		return err
+	}
+	log.Infof("load finished, wait for at most %d blocks...", params.DrainBlocks)
+	close(stop)
+	if err := <-done; err != nil {
+		log.Warnf("scan blocks failed, err: %v", err)
+	}
+
+	sum := stats.Summary()
+	reportBenchSummary(res, sum, dropped)
+	if params.Output != "" {
+		bz, _ := json.MarshalIndent(sum, "", "\t")
+		if err := ioutil.WriteFile(params.Output, bz, 0644); err != nil {
+			log.Warnf("failed to write bench summary to %s, err: %v", params.Output, err)
+		}
+	}
+	res.Assert("all-included", sum.Pending == 0, "%d txs not included", sum.Pending)
+	return nil
+}
+
+func prepareBenchAccounts(num int, amount *big.Int) ([]*sdk.Client, error) {
+	url := config.Conf.Rpc
+	admcli := getPaletteCli(pltCTypeAdmin)
+	clients := make([]*sdk.Client, num)
+	hashes := make([]common.Hash, num)
+	for i := 0; i < num; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		clients[i] = sdk.NewSender(url, key)
+		if hashes[i], err = admcli.PLTTransferWithoutWaiting(clients[i].Address(), amount); err != nil {
+			return nil, fmt.Errorf("fund account %s failed, err: %v", clients[i].Address().Hex(), err)
+		}
+	}
+	for _, hash := range hashes {
+		if err := admcli.WaitTransaction(hash); err != nil {
+			return nil, err
+		}
+	}
+	return clients, nil
+}
+
+type benchSender func(cli *sdk.Client, seq int) (common.Hash, error)
+
+func newBenchSenders(mix *bench.Mix, params *benchParams) (map[string]benchSender, error) {
+	senders := make(map[string]benchSender)
+	for _, kind := range mix.Kinds() {
+		switch kind {
+		case benchTransfer:
+			senders[kind] = func(cli *sdk.Client, seq int) (common.Hash, error) {
+				to := common.BigToAddress(big.NewInt(int64(seq + 1)))
+				return cli.PLTTransferWithoutWaiting(to, big.NewInt(1))
+			}
+
+		case benchStake:
+			if params.NodeIndex < 0 || params.NodeIndex >= len(config.Conf.Nodes) {
+				return nil, fmt.Errorf("invalid stake node index %d", params.NodeIndex)
+			}
+			if params.StakeAmount <= 0 {
+				return nil, fmt.Errorf("invalid stake amount %d", params.StakeAmount)
+			}
+			node := config.Conf.Nodes[params.NodeIndex]
+			amount := plt.MultiPLT(params.StakeAmount)
+			senders[kind] = func(cli *sdk.Client, seq int) (common.Hash, error) {
+				return cli.StakeWithoutWaiting(node.NodeAddr(), node.StakeAddr(), amount, false)
+			}
+
+		case benchNFTMint:
+			if params.NFTAsset == (common.Address{}) {
+				return nil, fmt.Errorf("nft asset should be set for %s", kind)
+			}
+			minter := getPaletteCli(pltCTypeCrossChainAdmin)
+			base := uint64(time.Now().Unix()) << 20
+			senders[kind] = func(cli *sdk.Client, seq int) (common.Hash, error) {
+				tokenID := new(big.Int).SetUint64(base + uint64(seq))
+				return minter.NFTMintWithoutWaiting(params.NFTAsset, cli.Address(), tokenID, fmt.Sprintf("bench%d", seq))
+			}
+
+		case benchCall:
+			if params.Contract == (common.Address{}) {
+				return nil, fmt.Errorf("contract should be set for %s", kind)
+			}
+			senders[kind] = func(cli *sdk.Client, seq int) (common.Hash, error) {
+				return cli.SendTransaction(params.Contract, params.Payload)
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown transaction kind %s", kind)
+		}
+	}
+	return senders, nil
+}
+
+// scanBenchBlocks feed new blocks to stats until stopped and all txs included, or `drain` blocks passed.
+func scanBenchBlocks(cli *sdk.Client, stats *bench.Stats, next uint64, stop chan struct{}, drain int) error {
+	var (
+		interval = time.Duration(config.Conf.BlockPeriod) / 4
+		stopped  = false
+		last     uint64
+	)
+	if interval <= 0 {
+		interval = poll.Default.Interval
+	}
+	for {
+		if !stopped {
+			select {
+			case <-stop:
+				stopped = true
+				last = next + uint64(drain)
+			default:
+			}
+		}
+		if stopped && (stats.Pending() == 0 || next > last) {
+			return nil
+		}
+
+		height, err := cli.BlockNumber()
+		if err != nil {
+			return err
+		}
+		for ; next <= height; next++ {
+			block, err := cli.GetBlockByNumber(next)
+			if err != nil {
+				return err
+			}
+			hashes := make([]string, 0, len(block.Transactions()))
+			for _, tx := range block.Transactions() {
+				hashes = append(hashes, tx.Hash().Hex())
+			}
+			stats.Block(next, hashes, time.Now())
+		}
+		time.Sleep(interval)
+	}
+}
+
+func reportBenchSummary(res *frame.Result, sum *bench.Summary, dropped int) {
+	logsplit()
+	log.Infof("submitted %d, included %d, failed %d, pending %d, dropped %d",
+		sum.Submitted, sum.Included, sum.Failed, sum.Pending, dropped)
+	log.Infof("submit tps %.2f, include tps %.2f", sum.SubmitTPS, sum.IncludeTPS)
+	log.Infof("latency min %s, mean %s, p50 %s, p90 %s, p99 %s, max %s", sum.Latency.Min, sum.Latency.Mean,
+		sum.Latency.P50, sum.Latency.P90, sum.Latency.P99, sum.Latency.Max)
+	for kind, ks := range sum.Kinds {
+		log.Infof("%s: submitted %d, included %d, failed %d", kind, ks.Submitted, ks.Included, ks.Failed)
+	}
+	for _, blk := range sum.Blocks {
+		log.Infof("block %d txs %d, bench txs %d", blk.Number, blk.Txs, blk.Ours)
+	}
+	for category, n := range sum.Errors {
+		log.Infof("error %s: %d", category, n)
+	}
+
+	res.Metric("submitted", float64(sum.Submitted), "tx")
+	res.Metric("included", float64(sum.Included), "tx")
+	res.Metric("failed", float64(sum.Failed), "tx")
+	res.Metric("dropped", float64(dropped), "tx")
+	res.Metric("submit-tps", sum.SubmitTPS, "tx/s")
+	res.Metric("include-tps", sum.IncludeTPS, "tx/s")
+	res.Metric("latency-p50", sum.Latency.P50.Seconds(), "s")
+	res.Metric("latency-p90", sum.Latency.P90.Seconds(), "s")
+	res.Metric("latency-p99", sum.Latency.P99.Seconds(), "s")
+	res.Metric("latency-max", sum.Latency.Max.Seconds(), "s")
+	for category, n := range sum.Errors {
+		res.Metric("error-"+category, float64(n), "tx")
+	}
+	res.Output("included", sum.Included)
+}
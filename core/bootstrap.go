@@ -0,0 +1,418 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/native"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/bootstrap"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/registry"
+	"github.com/palettechain/onRobot/pkg/sdk"
+	polyutils "github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	groupEthereum = "ethereum"
+	groupPalette  = "palette"
+	groupPoly     = "poly"
+)
+
+type BootstrapParams struct {
+	DryRun            bool     // 只输出部署计划, 不发送任何交易
+	From              string   // 从该步骤开始执行, 之前的步骤跳过
+	Skip              []string // 跳过的步骤
+	TransferOwnership bool     // 是否将ccmp, proxy等合约所有权转移到FinalOwner
+}
+
+// BootstrapCrossChain 部署palette<->ethereum跨链环境
+// 1. 按照crosschain.md及部署脚本中的顺序生成部署计划, 每个步骤即一个已有的plt-*/eth-*方法
+// 2. 每个步骤执行前通过合约注册表及链上查询(合约代码, owner, 绑定关系, poly侧链注册状态等)检测是否已经完成, 已完成的步骤跳过
+// 3. 步骤执行后再次检测, 检测失败视为该步骤失败, 失败时停止并将进度记录在deployments/<env>/progress/bootstrap-crosschain.json中
+// 4. 再次执行时从失败的步骤继续, DryRun为true时只输出部署计划
+func BootstrapCrossChain(res *frame.Result) error {
+	params, err := loadBootstrapParams()
+	if err != nil {
+		return err
+	}
+	return bootstrapCrossChain(res, params)
+}
+
+// BootstrapCrossChainPlan 输出部署计划, 等同于DryRun
+func BootstrapCrossChainPlan(res *frame.Result) error {
+	params, err := loadBootstrapParams()
+	if err != nil {
+		return err
+	}
+	params.DryRun = true
+	return bootstrapCrossChain(res, params)
+}
+
+// loadBootstrapParams the params file is optional, all of the steps run by default.
+func loadBootstrapParams() (*BootstrapParams, error) {
+	params := new(BootstrapParams)
+	if err := config.LoadParams("Bootstrap.json", params); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return params, nil
+}
+
+func bootstrapCrossChain(res *frame.Result, params *BootstrapParams) error {
+	progress, err := bootstrap.LoadProgress(path.Join(config.Deployments.Dir(), "progress", "bootstrap-crosschain.json"))
+	if err != nil {
+		return err
+	}
+	if progress.Failed != "" {
+		log.Infof("last bootstrap failed at step %s, err: %s", progress.Failed, progress.Error)
+	}
+
+	if params.TransferOwnership && config.Conf.FinalOwner == nil {
+		return fmt.Errorf("final owner not configured, can not transfer ownership")
+	}
+	b := newCrossChainBootstrap()
+	runner, err := bootstrap.New(b.steps(params.TransferOwnership), bootstrap.Options{From: params.From, Skip: params.Skip}, progress)
+	if err != nil {
+		return err
+	}
+
+	plan := runner.Plan()
+	log.Infof("bootstrap cross chain plan of %s:\n%s", config.Deployments.Env(), plan.String())
+	res.Output("pending", plan.Pending())
+	if params.DryRun {
+		return nil
+	}
+
+	executed, err := runner.Run(func(item *bootstrap.Item, err error) {
+		switch {
+		case err != nil:
+			log.Errorf("step %s failed, err: %v", item.Step.Name, err)
+		case item.Status == bootstrap.StatusDone:
+			log.Infof("step %s done", item.Step.Name)
+		}
+	})
+	res.Metric("executed", float64(executed), "steps")
+	if err != nil {
+		return fmt.Errorf("bootstrap stopped, fix it and run again to resume, err: %v", err)
+	}
+	log.Infof("bootstrap cross chain success, %d steps executed", executed)
+	return nil
+}
+
+type crossChainBootstrap struct {
+	plt        *sdk.Client
+	eth        *eth.EthInvoker
+	pltBackend registry.Backend
+	ethBackend registry.Backend
+	polyCli    *poly.PolyClient
+}
+
+func newCrossChainBootstrap() *crossChainBootstrap {
+	plt := getPaletteCli(pltCTypeCrossChainAdmin)
+	ethCli := getEthereumCli(ethCTypeOwner)
+	return &crossChainBootstrap{
+		plt:        plt,
+		eth:        ethCli,
+		pltBackend: ethclient.NewClient(plt.Client),
+		ethBackend: ethCli.Tools.GetEthClient(),
+	}
+}
+
+func (b *crossChainBootstrap) steps(transferOwnership bool) []*bootstrap.Step {
+	cc := config.Conf.CrossChain
+	fo := config.Conf.FinalOwner
+	pltNative := common.HexToAddress(native.PLTContractAddress)
+
+	steps := []*bootstrap.Step{
+		// ethereum contracts, the proxies are in the white list of eccm
+		b.deploy("eth-deploy-plt", config.ChainEthereum, config.ContractPLTAsset, &cc.EthereumPLTAsset, nil, methodAction(ETHDeployPLTAsset)),
+		b.deploy("eth-deploy-plt-proxy", config.ChainEthereum, config.ContractPLTProxy, &cc.EthereumPLTProxy, nil, methodAction(ETHDeployPLTProxy)),
+		b.deploy("eth-deploy-nft-proxy", config.ChainEthereum, config.ContractNFTProxy, &cc.EthereumNFTProxy, nil, methodAction(ETHDeployNFTProxy)),
+		b.deploy("eth-deploy-eccd", config.ChainEthereum, config.ContractECCD, &cc.EthereumECCD, nil, methodAction(ETHDeployECCD)),
+		b.deploy("eth-deploy-eccm", config.ChainEthereum, config.ContractECCM, &cc.EthereumECCM, deployDeps{config.ContractECCD: &cc.EthereumECCD}, methodAction(ETHDeployECCM)),
+		b.deploy("eth-deploy-ccmp", config.ChainEthereum, config.ContractCCMP, &cc.EthereumCCMP, deployDeps{config.ContractECCM: &cc.EthereumECCM}, methodAction(ETHDeployCCMP)),
+		b.ethStep("eth-eccd-ownership", ETHTransferECCDOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.ECCDOwnership(cc.EthereumECCD) }, cc.EthereumECCM, cc.EthereumECCD, cc.EthereumECCM)
+		}),
+		b.ethStep("eth-eccm-ownership", ETHTransferECCMOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.ECCMOwnership(cc.EthereumECCM) }, cc.EthereumCCMP, cc.EthereumECCM, cc.EthereumCCMP)
+		}),
+		b.ethStep("eth-plt-ccmp", ETHSetPLTCCMP, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.GetPLTCCMP(cc.EthereumPLTProxy) }, cc.EthereumCCMP, cc.EthereumPLTProxy, cc.EthereumCCMP)
+		}),
+		b.ethStep("eth-nft-ccmp", ETHSetNFTCCMP, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.GetNFTCCMP(cc.EthereumNFTProxy) }, cc.EthereumCCMP, cc.EthereumNFTProxy, cc.EthereumCCMP)
+		}),
+
+		// palette contracts, nft proxy is in the white list of eccm
+		b.deploy("plt-deploy-nft-proxy", config.ChainPalette, config.ContractNFTProxy, &cc.PaletteNFTProxy, nil, methodAction(PLTDeployNFTProxy)),
+		b.deploy("plt-deploy-eccd", config.ChainPalette, config.ContractECCD, &cc.PaletteECCD, nil, caseAction(PLTDeployECCD)),
+		b.deploy("plt-deploy-eccm", config.ChainPalette, config.ContractECCM, &cc.PaletteECCM, deployDeps{config.ContractECCD: &cc.PaletteECCD}, caseAction(PLTDeployECCM)),
+		b.deploy("plt-deploy-ccmp", config.ChainPalette, config.ContractCCMP, &cc.PaletteCCMP, deployDeps{config.ContractECCM: &cc.PaletteECCM}, caseAction(PLTDeployCCMP)),
+		b.pltStep("plt-eccd-ownership", PLTTransferECCDOwnerShip, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.ECCDOwnership(cc.PaletteECCD) }, cc.PaletteECCM, cc.PaletteECCD, cc.PaletteECCM)
+		}),
+		b.pltStep("plt-eccm-ownership", PLTTransferECCMOwnerShip, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.ECCMOwnership(cc.PaletteECCM) }, cc.PaletteCCMP, cc.PaletteECCM, cc.PaletteCCMP)
+		}),
+		b.pltStep("plt-plt-ccmp", PLTSetCCMP, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.GetPLTCCMP("latest") }, cc.PaletteCCMP, cc.PaletteCCMP)
+		}),
+		b.pltStep("plt-nft-ccmp", PLTSetNFTCCMP, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.GetNFTCCMP(cc.PaletteNFTProxy) }, cc.PaletteCCMP, cc.PaletteNFTProxy, cc.PaletteCCMP)
+		}),
+
+		// register side chains and sync genesis headers
+		b.polyStep("eth-registerSideChain", ETHRegisterSideChain, func() (bool, error) {
+			return b.sideChainRegistered(cc.EthereumSideChainID, false)
+		}),
+		b.polyStep("eth-approveRegisterSideChain", ETHApproveRegisterSideChain, func() (bool, error) {
+			return b.sideChainRegistered(cc.EthereumSideChainID, true)
+		}),
+		b.polyStep("plt-registerSideChain", PLTRegisterSideChain, func() (bool, error) {
+			return b.sideChainRegistered(cc.PaletteSideChainID, false)
+		}),
+		b.polyStep("plt-approveRegisterSideChain", PLTApproveRegisterSideChain, func() (bool, error) {
+			return b.sideChainRegistered(cc.PaletteSideChainID, true)
+		}),
+		b.polyStep("eth-sync-eth-genesis", ETHSyncEthGenesis, func() (bool, error) {
+			return b.genesisSynced(cc.EthereumSideChainID, polyutils.ETH_ROUTER)
+		}),
+		b.ethStep("eth-sync-poly-genesis", ETHSyncPolyGenesis, func() (bool, error) {
+			return polyGenesisSynced(b.ethBackend, cc.EthereumECCD)
+		}),
+		b.polyStep("plt-sync-plt-genesis", PLTSyncPLTGenesis, func() (bool, error) {
+			return b.genesisSynced(cc.PaletteSideChainID, polyutils.QUORUM_ROUTER)
+		}),
+		b.pltStep("plt-sync-poly-genesis", PLTSyncPolyGenesis, func() (bool, error) {
+			return polyGenesisSynced(b.pltBackend, cc.PaletteECCD)
+		}),
+
+		// bind proxies and assets on both chains
+		b.ethStep("eth-bind-plt-proxy", ETHBindPLTProxy, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) {
+				return b.eth.GetBoundPLTProxy(cc.EthereumPLTProxy, cc.PaletteSideChainID)
+			}, pltNative, cc.EthereumPLTProxy)
+		}),
+		b.ethStep("eth-bind-nft-proxy", ETHBindNFTProxy, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) {
+				return b.eth.GetBoundNFTProxy(cc.EthereumNFTProxy, cc.PaletteSideChainID)
+			}, cc.PaletteNFTProxy, cc.EthereumNFTProxy)
+		}),
+		b.ethStep("eth-bind-plt-asset", ETHBindPLTAsset, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) {
+				return b.eth.GetBoundPLTAsset(cc.EthereumPLTProxy, cc.EthereumPLTAsset, cc.PaletteSideChainID)
+			}, pltNative, cc.EthereumPLTProxy, cc.EthereumPLTAsset)
+		}),
+		b.pltStep("plt-bind-plt-proxy", PLTBindPLTProxy, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) {
+				return b.plt.GetBindPLTProxy(cc.EthereumSideChainID, "latest")
+			}, cc.EthereumPLTProxy)
+		}),
+		b.pltStep("plt-bind-nft-proxy", PLTBindNFTProxy, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) {
+				return b.plt.GetBoundNFTProxy(cc.PaletteNFTProxy, cc.EthereumSideChainID)
+			}, cc.EthereumNFTProxy, cc.PaletteNFTProxy)
+		}),
+		b.pltStep("plt-bind-plt-asset", PLTBindPLTAsset, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) {
+				return b.plt.GetBindPLTAsset(cc.EthereumSideChainID, "latest")
+			}, cc.EthereumPLTAsset)
+		}),
+	}
+	if !transferOwnership {
+		return steps
+	}
+
+	// transfer ownership to final owners, same as transfer_eth_ownership.sh and transfer_plt_ownership.sh
+	return append(steps,
+		b.ethStep("eth-ccmp-ownership", ETHTransferCCMPOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.CCMPOwnership(cc.EthereumCCMP) }, fo.EthereumFinalOwner, cc.EthereumCCMP)
+		}),
+		b.ethStep("eth-plt-asset-ownership", ETHTransferPLTAssetOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.PLTAssetOwnership(cc.EthereumPLTAsset) }, fo.EthereumFinalOwner, cc.EthereumPLTAsset)
+		}),
+		b.ethStep("eth-plt-proxy-ownership", ETHTransferPLTProxyOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.PLTProxyOwnership(cc.EthereumPLTProxy) }, fo.EthereumFinalOwner, cc.EthereumPLTProxy)
+		}),
+		b.ethStep("eth-nft-proxy-ownership", ETHTransferNFTProxyOwnership, func() (bool, error) {
+			return b.ethEqual(func() (common.Address, error) { return b.eth.NFTProxyOwnership(cc.EthereumNFTProxy) }, fo.EthereumFinalOwner, cc.EthereumNFTProxy)
+		}),
+		b.pltStep("plt-ccmp-ownership", PLTTransferCCMPOwnerShip, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.CCMPOwnership(cc.PaletteCCMP) }, fo.PaletteFinalOwner, cc.PaletteCCMP)
+		}),
+		b.pltStep("plt-nft-proxy-ownership", PLTTransferNFTProxyOwnership, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.NFTProxyOwnership(cc.PaletteNFTProxy) }, fo.PaletteFinalOwner, cc.PaletteNFTProxy)
+		}),
+		b.pltStep("plt-cross-chain-admin-ownership", PLTTransferCrossChainAdminOwnership, func() (bool, error) {
+			return b.pltEqual(func() (common.Address, error) { return b.plt.CrossChainAdminOwnership("latest") }, fo.PaletteFinalOwner)
+		}),
+	)
+}
+
+func methodAction(method frame.Method) func() error {
+	return func() error {
+		return frame.AdaptMethod(method)(frame.NewResult())
+	}
+}
+
+func (b *crossChainBootstrap) ethStep(name string, method frame.Method, done func() (bool, error)) *bootstrap.Step {
+	return &bootstrap.Step{Name: name, Group: groupEthereum, Done: done, Run: methodAction(method)}
+}
+
+func (b *crossChainBootstrap) pltStep(name string, method frame.Method, done func() (bool, error)) *bootstrap.Step {
+	return &bootstrap.Step{Name: name, Group: groupPalette, Done: done, Run: methodAction(method)}
+}
+
+func (b *crossChainBootstrap) polyStep(name string, method frame.Method, done func() (bool, error)) *bootstrap.Step {
+	return &bootstrap.Step{Name: name, Group: groupPoly, Done: done, Run: methodAction(method)}
+}
+
+func caseAction(c frame.Case) func() error {
+	return func() error {
+		return c(frame.NewResult())
+	}
+}
+
+// deployDeps maps the contracts name to address fields in config, e.g: eccd of eccm.
+type deployDeps map[string]*common.Address
+
+// deploy step is done if the contract address has code, and the contracts it depends on are the
+// same as the ones recorded in registry, e.g: eccm should be deployed again after eccd redeployed.
+func (b *crossChainBootstrap) deploy(name, chain, contract string, addr *common.Address, deps deployDeps, run func() error) *bootstrap.Step {
+	backend, group := b.ethBackend, groupEthereum
+	if chain == config.ChainPalette {
+		backend, group = b.pltBackend, groupPalette
+	}
+	return &bootstrap.Step{
+		Name:  name,
+		Group: group,
+		Done: func() (bool, error) {
+			return deployed(backend, chain, contract, *addr, deps)
+		},
+		Run: run,
+	}
+}
+
+func deployed(backend registry.Backend, chain, contract string, addr common.Address, deps deployDeps) (bool, error) {
+	if ok, err := hasCode(backend, addr); !ok || err != nil {
+		return false, err
+	}
+	rec, ok := config.Deployments.Current(chain, contract)
+	if !ok || rec.Address != addr {
+		return true, nil
+	}
+	for name, dep := range deps {
+		if bound, ok := rec.Bindings[name]; ok && !strings.EqualFold(bound, dep.Hex()) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func hasCode(backend registry.Backend, addrs ...common.Address) (bool, error) {
+	for _, addr := range addrs {
+		if addr == (common.Address{}) {
+			return false, nil
+		}
+		code, err := backend.CodeAt(context.Background(), addr, nil)
+		if err != nil {
+			return false, err
+		}
+		if len(code) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ethEqual compare the value read by getter with expect, contracts should be deployed before reading.
+func (b *crossChainBootstrap) ethEqual(getter func() (common.Address, error), expect common.Address, contracts ...common.Address) (bool, error) {
+	return equalAfterDeployed(b.ethBackend, getter, expect, contracts...)
+}
+
+// pltEqual same as `ethEqual`, the contracts on palette should be deployed before reading.
+func (b *crossChainBootstrap) pltEqual(getter func() (common.Address, error), expect common.Address, contracts ...common.Address) (bool, error) {
+	return equalAfterDeployed(b.pltBackend, getter, expect, contracts...)
+}
+
+func equalAfterDeployed(backend registry.Backend, getter func() (common.Address, error), expect common.Address, contracts ...common.Address) (bool, error) {
+	if expect == (common.Address{}) {
+		return false, nil
+	}
+	if ok, err := hasCode(backend, contracts...); !ok || err != nil {
+		return false, err
+	}
+	actual, err := getter()
+	if err != nil {
+		return false, err
+	}
+	return actual == expect, nil
+}
+
+func (b *crossChainBootstrap) poly() (*poly.PolyClient, error) {
+	if b.polyCli != nil {
+		return b.polyCli, nil
+	}
+	cli, err := poly.NewPolyClient(config.Conf.CrossChain.PolyRPCAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	b.polyCli = cli
+	return cli, nil
+}
+
+// sideChainRegistered check the side chain registering is requested, or approved if `approved` is true.
+func (b *crossChainBootstrap) sideChainRegistered(chainID uint64, approved bool) (bool, error) {
+	cli, err := b.poly()
+	if err != nil {
+		return false, err
+	}
+	applied, registered, err := cli.SideChainState(chainID)
+	if err != nil {
+		return false, err
+	}
+	if approved {
+		return registered, nil
+	}
+	return applied || registered, nil
+}
+
+func (b *crossChainBootstrap) genesisSynced(chainID, router uint64) (bool, error) {
+	cli, err := b.poly()
+	if err != nil {
+		return false, err
+	}
+	return cli.GenesisSynced(chainID, router)
+}
+
+// selector of eccd `getCurEpochConPubKeyBytes()`
+var curEpochConPubKeySelector = crypto.Keccak256([]byte("getCurEpochConPubKeyBytes()"))[:4]
+
+// polyGenesisSynced check that the poly book keepers are stored in eccd, `initGenesisBlock` of eccm
+// is the only way to set them at the first time.
+func polyGenesisSynced(backend registry.Backend, eccd common.Address) (bool, error) {
+	if ok, err := hasCode(backend, eccd); !ok || err != nil {
+		return false, err
+	}
+	out, err := backend.CallContract(context.Background(), ethereum.CallMsg{To: &eccd, Data: curEpochConPubKeySelector}, nil)
+	if err != nil {
+		return false, err
+	}
+	// abi encoded bytes: offset, length, data
+	if len(out) < 2*common.HashLength {
+		return false, nil
+	}
+	return new(big.Int).SetBytes(out[common.HashLength:2*common.HashLength]).Sign() > 0, nil
+}
@@ -44,8 +44,12 @@ func NFTLock() (succeed bool) {
 
 	// generate new sender
 	baseUrl := config.Conf.Nodes[0].RPCAddr()
-	privKey := customLoadAccount(from)
-	cli := sdk.NewSender(baseUrl, privKey)
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	cli := sdk.NewSignerSender(baseUrl, s)
 	ethInvoker := getEthereumCli(ethCTypeInvoker)
 
 	// mint or transfer ownership
@@ -97,12 +101,14 @@ func NFTLock() (succeed bool) {
 		return
 	}
 
+	track := startCrossChainTrack(sideChainID)
 	hash, err := cli.NFTSafeTransferFrom(asset, from, proxy, token, to, sideChainID)
 	if err != nil {
 		log.Error(err)
 		return
 	}
 
+	arrived := false
 	for i := 0; i < 100; i++ {
 		fromBalanceAfterLockOnPalette, err := cli.NFTBalance(asset, from, "latest")
 		if err != nil {
@@ -130,11 +136,16 @@ func NFTLock() (succeed bool) {
 		zero := big.NewInt(0)
 		if new(big.Int).Sub(subFrom, amount).Cmp(zero) == 0 && new(big.Int).Sub(subTo, amount).Cmp(zero) == 0 {
 			log.Infof("lock tx hash %s success!", hash.Hex())
+			arrived = true
 			break
 		}
 		logsplit()
 		wait(1)
 	}
+	if _, stopped := track.report(config.Conf.CrossChain.PaletteSideChainID, hash); !arrived {
+		log.Errorf("lock tx %s not arrived on ethereum, stopped at %s", hash.Hex(), stopped)
+		return
+	}
 
 	uri, err := ethInvoker.NFTTokenUri(params.ETHNFTAsset, token)
 	if err != nil {
@@ -176,10 +187,15 @@ func NFTUnLock() (succeed bool) {
 	amount := big.NewInt(1)
 	cli := getPaletteCli(pltCTypeCustomer)
 
-	invoker := eth.NewEInvoker(
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	invoker := eth.NewSignerInvoker(
 		config.Conf.CrossChain.EthereumSideChainID,
 		config.Conf.CrossChain.EthereumRPCUrl,
-		customLoadAccount(from),
+		s,
 	)
 
 	// check ownership
@@ -210,12 +226,14 @@ func NFTUnLock() (succeed bool) {
 		return
 	}
 
+	track := startCrossChainTrack(targetSideChainID)
 	hash, err := invoker.NFTSafeTransferFrom(asset, from, proxy, token, to, targetSideChainID)
 	if err != nil {
 		log.Error(err)
 		return
 	}
 
+	arrived := false
 	for i := 0; i < 100; i++ {
 		fromBalanceAfterLockOnEthereum, err := invoker.NFTBalance(asset, from)
 		if err != nil {
@@ -243,11 +261,16 @@ func NFTUnLock() (succeed bool) {
 		zero := big.NewInt(0)
 		if new(big.Int).Sub(subFrom, amount).Cmp(zero) == 0 && new(big.Int).Sub(subTo, amount).Cmp(zero) == 0 {
 			log.Infof("lock tx hash %s success!", hash.Hex())
+			arrived = true
 			break
 		}
 		logsplit()
 		wait(1)
 	}
+	if _, stopped := track.report(config.Conf.CrossChain.EthereumSideChainID, hash); !arrived {
+		log.Errorf("unlock tx %s not arrived on palette, stopped at %s", hash.Hex(), stopped)
+		return
+	}
 
 	expectUri, err := invoker.NFTTokenUri(asset, token)
 	if err != nil {
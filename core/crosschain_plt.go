@@ -1,13 +1,16 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native/plt"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/palettechain/onRobot/config"
 	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
 	"github.com/palettechain/onRobot/pkg/sdk"
 )
@@ -89,22 +92,24 @@ func PLTBurn() (succeed bool) {
 	return true
 }
 
-func PLTLock() (succeed bool) {
+func PLTLock(res *frame.Result) error {
 	var params struct {
 		From   common.Address
 		To     common.Address
 		Amount int
 	}
 	if err := config.LoadParams("PLT-Lock.json", &params); err != nil {
-		log.Error(err)
-		return
+		return err
 	}
 
 	baseUrl := config.Conf.Nodes[0].RPCAddr()
-	privKey := customLoadAccount(params.From)
 	userAddr := params.From
 	bindTo := params.To
-	cli := sdk.NewSender(baseUrl, privKey)
+	s, err := customLoadSigner(params.From)
+	if err != nil {
+		return err
+	}
+	cli := sdk.NewSignerSender(baseUrl, s)
 	amount := plt.MultiPLT(params.Amount)
 	targetSideChainID := config.Conf.CrossChain.EthereumSideChainID
 	ethAsset := config.Conf.CrossChain.EthereumPLTAsset
@@ -113,70 +118,56 @@ func PLTLock() (succeed bool) {
 
 	fromBalanceBeforeLockOnPalette, err := cli.BalanceOf(userAddr, "latest")
 	if err != nil {
-		log.Error(err)
-		return
+		return err
 	}
 	if fromBalanceBeforeLockOnPalette.Cmp(amount) < 0 {
 		logsplit()
 		log.Infof("prepare test account balance...")
 		if _, err := admcli.PLTTransfer(userAddr, amount); err != nil {
-			log.Error(err)
-			return
+			return fmt.Errorf("failed to prepare test account balance, err: %v", err)
 		}
 		fromBalanceBeforeLockOnPalette, _ = cli.BalanceOf(userAddr, "latest")
 	}
 
 	toBalanceBeforeLockOnEthereum, err := ethInvoker.PLTBalanceOf(ethAsset, bindTo)
 	if err != nil {
-		log.Error(err)
-		return
+		return err
 	}
 
 	logsplit()
+	track := startCrossChainTrack(targetSideChainID)
 	hash, err := cli.LockPLT(targetSideChainID, bindTo, amount)
 	if err != nil {
-		log.Errorf("failed to call `lock` err: %v", err)
-		return
-	} else {
-		log.Infof("lock plt on palette, tx hash %s", hash.Hex())
+		return fmt.Errorf("failed to call `lock` err: %v", err)
 	}
+	log.Infof("lock plt on palette, tx hash %s", hash.Hex())
+	lockTime := time.Now()
 
 	logsplit()
 	log.Info("check balance on both of palette chain and ethereum chain...")
-	for i := 0; i < 100; i++ {
-		fromBalanceAfterLockOnPalette, err := cli.BalanceOf(userAddr, "latest")
-		if err != nil {
-			log.Error(err)
-			return
-		}
-		toBalanceAfterLockOnEthereum, err := ethInvoker.PLTBalanceOf(ethAsset, bindTo)
-		if err != nil {
-			log.Error(err)
-			return
-		}
-
-		log.Infof("palette %s: balance before lock [%d], balance after lock [%d]",
-			params.From.Hex(),
-			plt.PrintUPLT(fromBalanceBeforeLockOnPalette),
-			plt.PrintUPLT(fromBalanceAfterLockOnPalette),
-		)
-		log.Infof("ethereum %s: balance before lock [%d], balance after lock [%d]",
-			params.To.Hex(),
-			plt.PrintUPLT(toBalanceBeforeLockOnEthereum),
-			plt.PrintUPLT(toBalanceAfterLockOnEthereum),
-		)
-		subFrom := utils.SafeSub(fromBalanceBeforeLockOnPalette, fromBalanceAfterLockOnPalette)
-		subTo := utils.SafeSub(toBalanceAfterLockOnEthereum, toBalanceBeforeLockOnEthereum)
-		zero := big.NewInt(0)
-		if new(big.Int).Sub(subFrom, amount).Cmp(zero) == 0 && new(big.Int).Sub(subTo, amount).Cmp(zero) == 0 {
-			log.Infof("lock tx hash %s success!", hash.Hex())
-			break
-		}
-		logsplit()
-		wait(1)
-	}
-
-	return true
+	if err := waitBalanceDelta(
+		fmt.Sprintf("palette %s", userAddr.Hex()),
+		fromBalanceBeforeLockOnPalette,
+		new(big.Int).Neg(amount),
+		func() (*big.Int, error) { return cli.BalanceOf(userAddr, "latest") },
+	); err != nil {
+		return err
+	}
+	if err := waitBalanceDelta(
+		fmt.Sprintf("ethereum %s", bindTo.Hex()),
+		toBalanceBeforeLockOnEthereum,
+		amount,
+		func() (*big.Int, error) { return ethInvoker.PLTBalanceOf(ethAsset, bindTo) },
+	); err != nil {
+		_, stopped := track.report(config.Conf.CrossChain.PaletteSideChainID, hash)
+		return fmt.Errorf("lock tx %s not arrived on ethereum, stopped at %s, err: %v", hash.Hex(), stopped, err)
+	}
+
+	log.Infof("lock tx hash %s success!", hash.Hex())
+	res.Metric("cross-chain-duration", time.Since(lockTime).Seconds(), "s")
+	trace, _ := track.report(config.Conf.CrossChain.PaletteSideChainID, hash)
+	recordTraceMetrics(res, trace)
+	return nil
 }
 
 // 以太坊lock对应到palette的unlock:
@@ -207,10 +198,15 @@ func PLTUnlock() (succeed bool) {
 	asset := config.Conf.CrossChain.EthereumPLTAsset
 	amount := plt.MultiPLT(params.Amount)
 	cli := getPaletteCli(pltCTypeCustomer)
-	invoker := eth.NewEInvoker(
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	invoker := eth.NewSignerInvoker(
 		config.Conf.CrossChain.EthereumSideChainID,
 		config.Conf.CrossChain.EthereumRPCUrl,
-		customLoadAccount(from),
+		s,
 	)
 
 	// please make sure that eth account's balance is enough for gas fee.
@@ -236,6 +232,7 @@ func PLTUnlock() (succeed bool) {
 		log.Error(err)
 		return
 	}
+	track := startCrossChainTrack(targetSideChainID)
 	hash, err := invoker.PLTLock(proxy, asset, targetSideChainID, to, amount)
 	if err != nil {
 		log.Error(err)
@@ -246,39 +243,28 @@ func PLTUnlock() (succeed bool) {
 
 	logsplit()
 	log.Info("check balance on both of palette chain and ethereum chain...")
-	for i := 0; i < 100; i++ {
-		fromBalanceAfterLockOnEthereum, err := invoker.PLTBalanceOf(asset, from)
-		if err != nil {
-			log.Error(err)
-			return
-		}
-		toBalanceAfterLockOnPalette, err := cli.BalanceOf(to, "latest")
-		if err != nil {
-			log.Error(err)
-			return
-		}
-
-		log.Infof("ethereum %s: balance before lock [%d], balance after lock [%d]",
-			params.From.Hex(),
-			plt.PrintUPLT(fromBalanceBeforeLockOnEthereum),
-			plt.PrintUPLT(fromBalanceAfterLockOnEthereum),
-		)
-		log.Infof("palette %s: balance before lock [%d], balance after lock [%d]",
-			params.To.Hex(),
-			plt.PrintUPLT(toBalanceBeforeLockOnPalette),
-			plt.PrintUPLT(toBalanceAfterLockOnPalette),
-		)
-		subFrom := utils.SafeSub(fromBalanceBeforeLockOnEthereum, fromBalanceAfterLockOnEthereum)
-		subTo := utils.SafeSub(toBalanceAfterLockOnPalette, toBalanceBeforeLockOnPalette)
-		zero := big.NewInt(0)
-		if new(big.Int).Sub(subFrom, amount).Cmp(zero) == 0 && new(big.Int).Sub(subTo, amount).Cmp(zero) == 0 {
-			log.Infof("lock tx hash %s success!", hash.Hex())
-			break
-		}
-		logsplit()
-		wait(1)
+	if err := waitBalanceDelta(
+		fmt.Sprintf("ethereum %s", from.Hex()),
+		fromBalanceBeforeLockOnEthereum,
+		new(big.Int).Neg(amount),
+		func() (*big.Int, error) { return invoker.PLTBalanceOf(asset, from) },
+	); err != nil {
+		log.Error(err)
+		return
+	}
+	if err := waitBalanceDelta(
+		fmt.Sprintf("palette %s", to.Hex()),
+		toBalanceBeforeLockOnPalette,
+		amount,
+		func() (*big.Int, error) { return cli.BalanceOf(to, "latest") },
+	); err != nil {
+		_, stopped := track.report(config.Conf.CrossChain.EthereumSideChainID, hash)
+		log.Errorf("lock tx %s not arrived on palette, stopped at %s, err: %v", hash.Hex(), stopped, err)
+		return
 	}
 
+	log.Infof("lock tx hash %s success!", hash.Hex())
+	track.report(config.Conf.CrossChain.EthereumSideChainID, hash)
 	return true
 }
 
@@ -302,10 +288,15 @@ func EthWrapperPLTLock() (succeed bool) {
 	fee := big.NewInt(0)
 	id := big.NewInt(0)
 	cli := getPaletteCli(pltCTypeCustomer)
-	invoker := eth.NewEInvoker(
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	invoker := eth.NewSignerInvoker(
 		config.Conf.CrossChain.EthereumSideChainID,
 		config.Conf.CrossChain.EthereumRPCUrl,
-		customLoadAccount(from),
+		s,
 	)
 
 	// please make sure that eth account's balance is enough for gas fee.
@@ -397,10 +388,15 @@ func EthWrapperNFTLock() (succeed bool) {
 	fee := big.NewInt(0)
 	id := big.NewInt(0)
 	cli := getPaletteCli(pltCTypeCustomer)
-	invoker := eth.NewEInvoker(
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	invoker := eth.NewSignerInvoker(
 		config.Conf.CrossChain.EthereumSideChainID,
 		config.Conf.CrossChain.EthereumRPCUrl,
-		customLoadAccount(from),
+		s,
 	)
 
 	// please make sure that eth account's balance is enough for gas fee.
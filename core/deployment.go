@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/registry"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// recordPaletteDeployment 记录palette上部署的合约
+// 1. 根据部署交易从链上读取部署账户, 区块, 代码hash及合约owner
+// 2. 写入当前环境的合约注册表, 同时更新内存中的合约地址, 配置文件不会被修改
+func recordPaletteDeployment(cli *sdk.Client, name string, addr common.Address, hash common.Hash, bindings map[string]common.Address) error {
+	return recordDeployment(config.ChainPalette, ethclient.NewClient(cli.Client), name, addr, hash, bindings)
+}
+
+// recordEthereumDeployment 记录ethereum上部署的合约, 流程同palette
+func recordEthereumDeployment(cli *eth.EthInvoker, name string, addr common.Address, hash common.Hash, bindings map[string]common.Address) error {
+	return recordDeployment(config.ChainEthereum, cli.Tools.GetEthClient(), name, addr, hash, bindings)
+}
+
+func recordDeployment(chain string, backend registry.Backend, name string, addr common.Address, hash common.Hash, bindings map[string]common.Address) error {
+	rec, err := registry.Inspect(context.Background(), backend, name, addr, hash)
+	if err != nil {
+		return fmt.Errorf("inspect %s %s failed, err: %v", chain, name, err)
+	}
+	rec.Bindings = make(map[string]string)
+	for k, v := range bindings {
+		rec.Bindings[k] = v.Hex()
+	}
+	if err := config.Conf.CrossChain.RecordDeployment(chain, rec); err != nil {
+		return fmt.Errorf("record %s %s failed, err: %v", chain, name, err)
+	}
+	return nil
+}
+
+// recordOwner 记录合约owner变更, 链上操作已经成功, 记录失败时只打印警告
+func recordOwner(chain, name string, owner common.Address) {
+	note := fmt.Sprintf("transfer ownership to %s", owner.Hex())
+	if err := config.Conf.CrossChain.UpdateDeployment(chain, name, note, func(rec *registry.Record) {
+		rec.Owner = owner
+	}); err != nil {
+		log.Warnf("record %s %s owner failed, err: %v", chain, name, err)
+	}
+}
+
+// recordBinding 记录合约绑定关系变更, e.g: proxy@2 => 0x.., 记录失败时只打印警告
+func recordBinding(chain, name, key string, value common.Address) {
+	note := fmt.Sprintf("bind %s to %s", key, value.Hex())
+	if err := config.Conf.CrossChain.UpdateDeployment(chain, name, note, func(rec *registry.Record) {
+		rec.Bindings[key] = value.Hex()
+	}); err != nil {
+		log.Warnf("record %s %s binding failed, err: %v", chain, name, err)
+	}
+}
+
+func proxyBindingKey(sideChainID uint64) string {
+	return fmt.Sprintf("proxy@%d", sideChainID)
+}
+
+func assetBindingKey(fromAsset common.Address, sideChainID uint64) string {
+	return fmt.Sprintf("asset:%s@%d", fromAsset.Hex(), sideChainID)
+}
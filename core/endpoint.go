@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/palettechain/onRobot/config"
 	"github.com/palettechain/onRobot/pkg/frame"
 )
 
@@ -12,6 +13,7 @@ func Endpoint() {
 
 	// gc function
 	frame.Tool.RegGCFunc(gc)
+	config.RegParamsFunc(frame.Tool.Params)
 	frame.Tool.RegMethod("demo", Demo)
 
 	// remote construct
@@ -52,8 +54,10 @@ func Endpoint() {
 	// uncle
 	frame.Tool.RegMethod("blockNumber", BlockNumber)
 	frame.Tool.RegMethod("nonce", Nonce)
-	frame.Tool.RegMethod("consistency", Consistency)
+	frame.Tool.RegCase("consistency", Consistency)
 	frame.Tool.RegMethod("deposit", Deposit)
+	frame.Tool.RegCase("provision", Provision)
+	frame.Tool.RegCase("sweep", Sweep)
 
 	// plt
 	frame.Tool.RegMethod("totalSupply", TotalSupply)
@@ -72,19 +76,30 @@ func Endpoint() {
 	frame.Tool.RegMethod("fakeReward", FakeReward)
 	frame.Tool.RegMethod("delegate", Delegate)
 	frame.Tool.RegMethod("showDelegate", ShowDelegateAmount)
-	frame.Tool.RegMethod("proposal", Proposal)
+	frame.Tool.RegCase("proposal", Proposal)
+	frame.Tool.RegMethod("proposalList", ProposalList)
+	frame.Tool.RegMethod("proposalVote", ProposalVote)
+	frame.Tool.RegCase("proposalLifecycle", ProposalLifecycle)
 	frame.Tool.RegMethod("globalParams", GlobalParams)
 	frame.Tool.RegMethod("spare", SpareNode)
 	frame.Tool.RegMethod("delValidators", DelValidators)
 	frame.Tool.RegMethod("period", RewardPeriod)
 	frame.Tool.RegMethod("stakeAmount", StakeAmount)
 	frame.Tool.RegMethod("stable", Stable)
+	frame.Tool.RegCase("bench", Bench)
+	frame.Tool.RegMethod("monitor", Monitor)
+	frame.Tool.RegMethod("signer-stub", SignerStub)
 	frame.Tool.RegMethod("dumpBlock", DumpBlock)
 
+	// cross chain environment bootstrap
+	frame.Tool.RegCase("bootstrap-crosschain", BootstrapCrossChain)
+	frame.Tool.RegCase("bootstrap-crosschain-plan", BootstrapCrossChainPlan)
+	frame.Tool.RegCase("verify-bridge", VerifyBridgeCase)
+
 	// palette side chain environment
-	frame.Tool.RegMethod("plt-deploy-eccd", PLTDeployECCD)
-	frame.Tool.RegMethod("plt-deploy-eccm", PLTDeployECCM)
-	frame.Tool.RegMethod("plt-deploy-ccmp", PLTDeployCCMP)
+	frame.Tool.RegCase("plt-deploy-eccd", PLTDeployECCD)
+	frame.Tool.RegCase("plt-deploy-eccm", PLTDeployECCM)
+	frame.Tool.RegCase("plt-deploy-ccmp", PLTDeployCCMP)
 	frame.Tool.RegMethod("plt-eccd-ownership", PLTTransferECCDOwnerShip)
 	frame.Tool.RegMethod("plt-eccm-ownership", PLTTransferECCMOwnerShip)
 	frame.Tool.RegMethod("plt-ccmp-ownership", PLTTransferCCMPOwnerShip)
@@ -144,15 +159,22 @@ func Endpoint() {
 	// plt cross chain
 	frame.Tool.RegMethod("plt-mint", PLTMint)
 	frame.Tool.RegMethod("plt-burn", PLTBurn)
-	frame.Tool.RegMethod("plt-lock", PLTLock)
+	frame.Tool.RegCase("plt-lock", PLTLock)
 	frame.Tool.RegMethod("plt-unlock", PLTUnlock)
+	frame.Tool.RegCase("round-trip", RoundTrip)
 	frame.Tool.RegMethod("plt-dump-contract", PLTDumpContractCode)
+	frame.Tool.RegCase("track-tx", TrackTx)
+	frame.Tool.RegMethod("relayer", Relayer)
+	frame.Tool.RegCase("verify-proof", VerifyProof)
+	frame.Tool.RegMethod("decode-payload", DecodePayload)
 
 	// plt cross chain wrapper contract
 	frame.Tool.RegMethod("plt-deploy-plt-wrap", PLTDeployPLTWrap)
 	frame.Tool.RegMethod("plt-wrap-lock", PLTWrapperLock)
 	frame.Tool.RegMethod("plt-unpack-lock-event", PLTWrapperUnpackLockEvent)
 	frame.Tool.RegMethod("plt-unpack-unlock-event", PLTWrapperUnpackUnlockEvent)
+	frame.Tool.RegMethod("wrapper-admin", WrapperAdmin)
+	frame.Tool.RegCase("wrapper-fee", WrapperFee)
 
 	// nft
 	frame.Tool.RegMethod("plt-deploy-nft-asset", NFTDeploy)
@@ -160,6 +182,9 @@ func Endpoint() {
 	frame.Tool.RegMethod("nft-balance", NFTBalance)
 	frame.Tool.RegMethod("nft-token-owner", NFTTokenOwner)
 	frame.Tool.RegMethod("nft-set-uri", NFTSetUri)
+	frame.Tool.RegMethod("nft-batch", NFTBatch)
+	frame.Tool.RegMethod("nft-tokens", NFTTokens)
+	frame.Tool.RegCase("nft-snapshot", NFTSnapshot)
 
 	// nft cross chain
 	frame.Tool.RegMethod("nft-mint", NFTMint)
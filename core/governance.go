@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/ethereum/go-ethereum/contracts/native/plt"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poll"
 	"github.com/palettechain/onRobot/pkg/sdk"
 )
 
@@ -304,18 +307,36 @@ func Delegate() (succeed bool) {
 			NodeIndex int
 		}
 		WaitBlock int
+		Role      string // 设置后未指定Address的fan使用该角色的派生账户
 	}
 
 	if err := config.LoadParams("Delegate.json", &params); err != nil {
 		log.Error(err)
 		return
 	}
+	if params.Role != "" {
+		accounts, err := roleAccounts(params.Role, len(params.Fans))
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		for i := range params.Fans {
+			if params.Fans[i].Address == (common.Address{}) {
+				params.Fans[i].Address = accounts[i].Address
+			}
+		}
+	}
 	admcli := getPaletteCli(pltCTypeAdmin)
 
 	// fans transfer back to admin
 	clients := make(map[string]*sdk.Client)
 	for _, fan := range params.Fans {
-		clients[fan.Address.Hex()] = sdk.NewSender(config.Conf.Nodes[0].RPCAddr(), customLoadAccount(fan.Address))
+		cli, err := customPaletteCli(fan.Address)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		clients[fan.Address.Hex()] = cli
 	}
 
 	checkBalance := func(mark string) map[common.Address]float64 {
@@ -686,13 +707,13 @@ func DelValidators() (succeed bool) {
 	return true
 }
 
-// 提案修改全局参数，使用配置文件中的3个validator来做这件事情，该测试方案只用于mintPrice和gasFee，rewardPeriod另外测试.
+// 提案修改全局参数，使用配置文件中的3个validator来做这件事情，手续费类提案的值以PLT为单位，rewardPeriod以区块为单位.
 // 1.node5提案修改某个全局参数，
 // 2.提案前需要检查其是否为可用的validator。
 // 3.提案后根据hash查询receipt，并打印log；
 // 4.voteNodeIndexList代表的validator进行投票，需要满足stakeAmount 2/3原则。
 // 5.等待一个分润周期后检查proposal状态，必须为passed，并查询global params是否正常变更。
-func Proposal() (succeed bool) {
+func Proposal(res *frame.Result) error {
 	var (
 		params struct {
 			ProposerNodeIndex int
@@ -701,9 +722,9 @@ func Proposal() (succeed bool) {
 			VoteNodeIndexList []int
 		}
 
-		proposalValue *big.Int
-		proposerNode  *config.Node
-		voteNodes     config.Nodes
+		value        *big.Int
+		proposerNode *config.Node
+		voteNodes    config.Nodes
 
 		proposalID common.Address
 		proposal   *governance.MethodGetProposalOutput
@@ -715,21 +736,18 @@ func Proposal() (succeed bool) {
 	{
 		log.Infof("check proposal params......")
 		if err = config.LoadParams("Proposal.json", &params); err != nil {
-			log.Error(err)
-			return
+			return err
 		}
 
 		// check proposal type
-		if params.ProposalType == 0 || params.ProposalType > 2 {
-			log.Errorf("invalid proposal type %d", params.ProposalType)
-			return
+		if !validProposalType(params.ProposalType) {
+			return fmt.Errorf("invalid proposal type %d", params.ProposalType)
 		}
 		// check proposal value
 		if params.ProposalValue < 0 {
-			log.Errorf("invalid proposal value %d", params.ProposalValue)
-			return
+			return fmt.Errorf("invalid proposal value %d", params.ProposalValue)
 		}
-		proposalValue = plt.MultiPLT(params.ProposalValue)
+		value = proposalValue(params.ProposalType, params.ProposalValue)
 	}
 
 	// get and check validators
@@ -737,8 +755,7 @@ func Proposal() (succeed bool) {
 		log.Infof("get and check validator authority......")
 		nodes, err := getAndCheckValidator(cli, append(params.VoteNodeIndexList, params.ProposerNodeIndex))
 		if err != nil {
-			log.Error(err)
-			return
+			return err
 		}
 		proposerNode = nodes[0]
 		voteNodes = nodes[1:]
@@ -750,19 +767,18 @@ func Proposal() (succeed bool) {
 		log.Infof("propose new proposal......")
 		proposerCli := sdk.NewSender(config.Conf.Nodes[0].RPCAddr(), proposerNode.PrivateKey())
 
-		if hash, err = proposerCli.Propose(params.ProposalType, proposalValue); err != nil {
-			log.Errorf("%s failed to propose, err %v", proposerNode.NodeAddr().Hex(), err)
-			return
+		if hash, err = proposerCli.Propose(params.ProposalType, value); err != nil {
+			return fmt.Errorf("%s failed to propose, err %v", proposerNode.NodeAddr().Hex(), err)
 		}
 		wait(2)
 
 		if proposalID, proposal, err = cli.GetProposalFromReceipt(hash); err != nil {
-			log.Error(err)
-			return
-		} else {
-			log.Infof("proposalID %s, hash %s, proposer %s, proposal type %d, value %v, end block %d",
-				proposalID.Hex(), hash.Hex(), proposerNode.NodeAddr().Hex(), proposal.ProposalType, proposalValue, proposal.EndBlock.Uint64())
+			return err
 		}
+		log.Infof("proposalID %s, hash %s, proposer %s, proposal type %d, value %v, end block %d",
+			proposalID.Hex(), hash.Hex(), proposerNode.NodeAddr().Hex(), proposal.ProposalType, value, proposal.EndBlock.Uint64())
+		res.Output("proposalID", proposalID.Hex())
+		res.Output("hash", hash.Hex())
 	}
 
 	// vote and dump hash list
@@ -771,44 +787,43 @@ func Proposal() (succeed bool) {
 		for _, voteNode := range voteNodes {
 			voteNodeCli := sdk.NewSender(config.Conf.Nodes[0].RPCAddr(), voteNode.PrivateKey())
 			if _, err = voteNodeCli.Vote(proposalID); err != nil {
-				log.Error(err)
-				return
+				return fmt.Errorf("%s failed to vote, err %v", voteNode.NodeAddr().Hex(), err)
 			}
 			log.Infof("%s vote to proposalID %s", voteNode.NodeAddr().Hex(), proposalID.Hex())
 		}
+		res.Metric("voters", float64(len(voteNodes)), "")
 	}
 
-	wait(config.Conf.RewardEffectivePeriod)
-
 	// check proposal status
 	{
-		if proposal, err = cli.GetProposal(proposalID, "latest"); err != nil {
-			log.Errorf("failed to get proposal, err %v", err)
-			return
-		}
-		if proposal.Passed != true {
-			log.Errorf("proposal %s should be passed", proposalID.Hex())
-			return
+		opt := config.Conf.BlocksPollOptions(config.Conf.RewardEffectivePeriod)
+		if proposal, err = cli.WaitProposal(proposalID, "passed", func(p *governance.MethodGetProposalOutput) bool {
+			return p.Passed
+		}, opt); err != nil {
+			return fmt.Errorf("proposal %s should be passed, err %v", proposalID.Hex(), err)
 		}
 	}
 
 	// check global params
 	{
-		data, err := cli.GetGlobalParams(params.ProposalType, "latest")
-		if err != nil {
-			log.Error("failed to get global params, err %v", err)
-			return
+		expect, actual := value, new(big.Int)
+		opt := config.Conf.BlocksPollOptions(config.Conf.RewardEffectivePeriod)
+		if err := poll.Until("global params changed", opt, func() (bool, error) {
+			data, err := cli.GetGlobalParams(params.ProposalType, "latest")
+			if err != nil {
+				return false, err
+			}
+			actual = data
+			return actual.Cmp(expect) == 0, nil
+		}); err != nil && !poll.IsTimeout(err) {
+			return fmt.Errorf("failed to get global params, err %v", err)
 		}
-
-		expect, actual := params.ProposalValue, int(plt.PrintUPLT(data))
-		if expect != actual {
-			log.Errorf("proposal failed to set global params, expect %d, actual %d", expect, actual)
-		} else {
-			log.Infof("global params changed to %d", actual)
+		if res.Equal("global-params", expect, actual) {
+			log.Infof("global params changed to %s", actual)
 		}
 	}
 
-	return true
+	return nil
 }
 
 // 提案修改分润周期
@@ -934,7 +949,7 @@ func GlobalParams() (succeed bool) {
 	cli := getPaletteCli(pltCTypeCustomer)
 
 	log.Infof("check proposal type......")
-	if params.ProposalType == 0 || params.ProposalType > 3 {
+	if !validProposalType(params.ProposalType) {
 		log.Errorf("invalid proposal type %d", params.ProposalType)
 		return
 	}
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/monitor"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// chainPoly is the alert target of poly, which has no deployments in registry
+const chainPoly = "poly"
+
+type monitorBalance struct {
+	Chain   string // palette或ethereum
+	Name    string
+	Address common.Address
+	Min     float64 // 余额低于Min(PLT)时告警, 0表示不检查
+}
+
+type monitorParams struct {
+	Interval       encode.Duration // 轮询间隔
+	Listen         string          // prometheus `/metrics` 监听地址, e.g: `:9100`
+	StallTimeout   encode.Duration // 区块高度超过该时间未增长则告警
+	MaxPeerLag     uint64          // 节点落后最高节点的区块数量告警阈值
+	RewardMaxDelay uint64          // 最近一次分润距当前高度的区块数量告警阈值, 0表示不检查
+	Poly           bool            // 是否监控poly rpc
+	Ethereum       bool            // 是否监控ethereum rpc
+	Balances       []*monitorBalance
+	Webhook        string // 告警webhook地址
+	AlertFile      string // 告警文件, 每行一个json
+}
+
+// 监控(常驻):
+// 1.周期性查询config.Conf.Nodes中每个节点的区块高度, 检查高度停滞以及节点间的落后区块数量
+// 2.查询有效验证人集合及最近分润区块, 验证人集合变化时通知, 长时间未分润时告警
+// 3.可选查询poly及ethereum区块高度, 检查停滞
+// 4.查询跨链proxy及wrapper合约余额, 低于阈值时告警
+// 5.告警发送到日志, webhook以及文件, 指标通过prometheus `/metrics` 暴露, 收到SIGINT/SIGTERM后退出
+func Monitor() (succeed bool) {
+	var params monitorParams
+	if err := config.LoadParams("Monitor.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+	interval := time.Duration(params.Interval)
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if params.StallTimeout <= 0 {
+		params.StallTimeout = encode.Duration(time.Duration(config.Conf.BlockPeriod) * 10)
+	}
+	if params.MaxPeerLag == 0 {
+		params.MaxPeerLag = 5
+	}
+
+	sinks := []monitor.Sink{new(monitor.LogSink)}
+	if params.Webhook != "" {
+		sinks = append(sinks, monitor.NewWebhookSink(params.Webhook, 5*time.Second))
+	}
+	if params.AlertFile != "" {
+		sinks = append(sinks, monitor.NewFileSink(params.AlertFile))
+	}
+	m := monitor.New(sinks...)
+	declareMonitorMetrics(m.Metrics)
+	if params.Listen != "" {
+		m.Serve(params.Listen)
+	}
+
+	heights := monitor.NewHeightTracker()
+	changes := monitor.NewChangeTracker()
+
+	m.AddProbe("palette-nodes", interval, func(m *monitor.Monitor) error {
+		return probePaletteNodes(m, heights, &params)
+	})
+	m.AddProbe("palette-governance", interval, func(m *monitor.Monitor) error {
+		return probeGovernance(m, heights, changes, &params)
+	})
+	if params.Poly {
+		var polyCli *poly.PolyClient
+		m.AddProbe("poly", interval, func(m *monitor.Monitor) error {
+			if polyCli == nil {
+				cli, err := poly.NewPolyClient(config.Conf.CrossChain.PolyRPCAddress, nil)
+				if err != nil {
+					return monitorUnreachable(m, chainPoly, err)
+				}
+				polyCli = cli
+			}
+			height, err := polyCli.GetCurrentBlockHeight()
+			if err != nil {
+				return monitorUnreachable(m, chainPoly, err)
+			}
+			observeChainHeight(m, heights, chainPoly, uint64(height), time.Duration(params.StallTimeout))
+			return nil
+		})
+	}
+	if params.Ethereum {
+		tools := eth.NewEthTools(config.Conf.CrossChain.EthereumRPCUrl)
+		if tools == nil {
+			log.Errorf("dial ethereum %s failed", config.Conf.CrossChain.EthereumRPCUrl)
+			return
+		}
+		m.AddProbe("ethereum", interval, func(m *monitor.Monitor) error {
+			height, err := tools.GetNodeHeight()
+			if err != nil {
+				return monitorUnreachable(m, config.ChainEthereum, err)
+			}
+			observeChainHeight(m, heights, config.ChainEthereum, height, time.Duration(params.StallTimeout))
+			return nil
+		})
+		m.AddProbe("balances", interval, func(m *monitor.Monitor) error {
+			return probeBalances(m, tools, defaultMonitorBalances(params.Balances))
+		})
+	} else {
+		m.AddProbe("balances", interval, func(m *monitor.Monitor) error {
+			return probeBalances(m, nil, defaultMonitorBalances(params.Balances))
+		})
+	}
+
+	log.Infof("monitor started, interval %s, press ctrl+c to stop", interval)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-ch
+	log.Infof("monitor received signal %s, stopping...", sig)
+	m.Stop()
+	return true
+}
+
+func declareMonitorMetrics(r *monitor.Registry) {
+	r.Gauge("onrobot_block_height", "current block height of node or chain")
+	r.Gauge("onrobot_block_stall_seconds", "seconds since the block height increased")
+	r.Gauge("onrobot_peer_lag_blocks", "blocks behind the highest palette node")
+	r.Gauge("onrobot_node_up", "1 if the rpc is reachable")
+	r.Gauge("onrobot_validators", "number of effective validators")
+	r.Counter("onrobot_validator_changes_total", "number of validator set changes")
+	r.Gauge("onrobot_last_reward_block", "block number of the latest reward")
+	r.Gauge("onrobot_reward_delay_blocks", "blocks since the latest reward")
+	r.Gauge("onrobot_crosschain_balance_plt", "PLT balance of cross chain proxy and wrapper contracts")
+}
+
+func monitorUnreachable(m *monitor.Monitor, target string, err error) error {
+	m.Metrics.Set("onrobot_node_up", map[string]string{"target": target}, 0)
+	m.Fire(monitor.LevelCritical, "unreachable", target, "rpc unreachable, err: %v", err)
+	return err
+}
+
+func observeChainHeight(m *monitor.Monitor, heights *monitor.HeightTracker, target string, height uint64, stallTimeout time.Duration) {
+	labels := map[string]string{"target": target}
+	m.Metrics.Set("onrobot_node_up", labels, 1)
+	m.Resolve("unreachable", target, "rpc recovered")
+
+	stalled := heights.Update(target, height, time.Now())
+	m.Metrics.Set("onrobot_block_height", labels, float64(height))
+	m.Metrics.Set("onrobot_block_stall_seconds", labels, stalled.Seconds())
+	if stalled >= stallTimeout {
+		m.Fire(monitor.LevelCritical, "stall", target, "block height %d not increased for %s", height, stalled)
+	} else if stalled == 0 {
+		m.Resolve("stall", target, "block height increased to %d", height)
+	}
+}
+
+func paletteNodeTarget(node *config.Node) string {
+	return fmt.Sprintf("node%d", node.Index)
+}
+
+func probePaletteNodes(m *monitor.Monitor, heights *monitor.HeightTracker, params *monitorParams) error {
+	type nodeHeight struct {
+		target string
+		height uint64
+	}
+	list := make([]*nodeHeight, 0, len(config.Conf.Nodes))
+	failed := 0
+	for _, node := range config.Conf.Nodes {
+		target := paletteNodeTarget(node)
+		height, err := sdk.NewReader(node.RPCAddr()).BlockNumber()
+		if err != nil {
+			monitorUnreachable(m, target, err)
+			failed += 1
+			continue
+		}
+		observeChainHeight(m, heights, target, height, time.Duration(params.StallTimeout))
+		list = append(list, &nodeHeight{target: target, height: height})
+	}
+
+	var max uint64
+	for _, nh := range list {
+		if nh.height > max {
+			max = nh.height
+		}
+	}
+	for _, nh := range list {
+		lag := max - nh.height
+		m.Metrics.Set("onrobot_peer_lag_blocks", map[string]string{"target": nh.target}, float64(lag))
+		if lag > params.MaxPeerLag {
+			m.Fire(monitor.LevelWarn, "peer-lag", nh.target, "%d blocks behind the highest node", lag)
+		} else {
+			m.Resolve("peer-lag", nh.target, "caught up, lag %d blocks", lag)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d nodes unreachable", failed)
+	}
+	return nil
+}
+
+func probeGovernance(
+	m *monitor.Monitor,
+	heights *monitor.HeightTracker,
+	changes *monitor.ChangeTracker,
+	params *monitorParams,
+) error {
+	cli := sdk.NewReader(config.Conf.Rpc)
+
+	list := cli.GetEffectiveValidators("latest")
+	if list == nil {
+		return fmt.Errorf("failed to get effective validators")
+	}
+	hexes := make([]string, len(list))
+	for i, addr := range list {
+		hexes[i] = addr.Hex()
+	}
+	m.Metrics.Set("onrobot_validators", nil, float64(len(list)))
+	if prev, changed := changes.Update("validators", strings.Join(hexes, ",")); changed {
+		m.Metrics.Add("onrobot_validator_changes_total", nil, 1)
+		m.Notify(monitor.LevelWarn, "validators", config.ChainPalette, "effective validators changed from [%s] to [%s]",
+			prev, strings.Join(hexes, ","))
+	}
+
+	last, err := cli.GetLastRewardBlock("latest")
+	if err != nil {
+		return err
+	}
+	m.Metrics.Set("onrobot_last_reward_block", nil, float64(last.Uint64()))
+	// ethereum and poly heights are tracked in the same tracker
+	targets := make([]string, len(config.Conf.Nodes))
+	for i, node := range config.Conf.Nodes {
+		targets[i] = paletteNodeTarget(node)
+	}
+	if current := heights.Max(targets...); current > last.Uint64() {
+		delay := current - last.Uint64()
+		m.Metrics.Set("onrobot_reward_delay_blocks", nil, float64(delay))
+		if params.RewardMaxDelay > 0 && delay > params.RewardMaxDelay {
+			m.Fire(monitor.LevelWarn, "reward", config.ChainPalette, "no reward for %d blocks, last reward block %d", delay, last)
+		} else {
+			m.Resolve("reward", config.ChainPalette, "rewarded at block %d", last)
+		}
+	}
+	return nil
+}
+
+// defaultMonitorBalances add the cross chain contracts in config if no balance configured.
+func defaultMonitorBalances(list []*monitorBalance) []*monitorBalance {
+	if len(list) > 0 {
+		return list
+	}
+	cc := config.Conf.CrossChain
+	defaults := []*monitorBalance{
+		{Chain: config.ChainPalette, Name: "plt-wrapper", Address: cc.PalettePLTWrapper},
+		{Chain: config.ChainEthereum, Name: "plt-proxy", Address: cc.EthereumPLTProxy},
+		{Chain: config.ChainEthereum, Name: "plt-wrapper", Address: cc.EthereumPLTWrapper},
+	}
+	list = make([]*monitorBalance, 0, len(defaults))
+	for _, b := range defaults {
+		if b.Address != (common.Address{}) {
+			list = append(list, b)
+		}
+	}
+	return list
+}
+
+func probeBalances(m *monitor.Monitor, tools *eth.ETHTools, list []*monitorBalance) error {
+	cli := sdk.NewReader(config.Conf.Rpc)
+	for _, b := range list {
+		var (
+			balance *big.Int
+			err     error
+		)
+		switch b.Chain {
+		case config.ChainPalette:
+			balance, err = cli.BalanceOf(b.Address, "latest")
+		case config.ChainEthereum:
+			if tools == nil {
+				continue
+			}
+			balance, err = tools.PLTBalanceOf(config.Conf.CrossChain.EthereumPLTAsset, b.Address)
+		default:
+			err = fmt.Errorf("unknown chain %s", b.Chain)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s balance, err: %v", b.Chain, b.Name, err)
+		}
+
+		target := b.Chain + "/" + b.Name
+		value, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), big.NewFloat(1e18)).Float64()
+		m.Metrics.Set("onrobot_crosschain_balance_plt", map[string]string{
+			"chain":   b.Chain,
+			"name":    b.Name,
+			"address": b.Address.Hex(),
+		}, value)
+		if b.Min > 0 && value < b.Min {
+			m.Fire(monitor.LevelWarn, "balance", target, "balance %.4f PLT lower than %.4f", value, b.Min)
+		} else {
+			m.Resolve("balance", target, "balance %.4f PLT", value)
+		}
+	}
+	return nil
+}
@@ -3,11 +3,15 @@ package core
 import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/collection"
+	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
 	"github.com/palettechain/onRobot/pkg/sdk"
 	"strings"
+	"time"
 
 	//polycm "github.com/polynetwork/poly/common"
 	"math/big"
@@ -149,7 +153,12 @@ func NFTSetUri() (succeed bool) {
 			continue
 		}
 
-		cli := sdk.NewSender(rpc, customLoadAccount(owner))
+		s, err := customLoadSigner(owner)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		cli := sdk.NewSignerSender(rpc, s)
 		suffix := getSuffix(asset)
 		uri := params.Storage + suffix
 		hash, err := cli.NFTSetBaseUri(asset, uri)
@@ -197,7 +206,12 @@ func NFTTransfer() (succeed bool) {
 		url := valcli.Url()
 		from := params.To
 		to := valcli.Address()
-		cli := sdk.NewSender(url, customLoadAccount(from))
+		s, err := customLoadSigner(from)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		cli := sdk.NewSignerSender(url, s)
 
 		if _, err := cli.NFTTransferFrom(asset, from, to, token); err != nil {
 			log.Error(err)
@@ -252,9 +266,174 @@ func NFTTokenOwner() (succeed bool) {
 	return true
 }
 
+// NFTBatch 批量mint, 转账或销毁palette上的NFT, 交易以流水线的方式发送, 每Batch笔等待一次
+// 1. mint由跨链管理员(即资产owner)执行, 将[StartID, StartID+Count)的token mint给To, uri为Uri加上token id
+// 2. transfer由From将这些token转给To, burn由From销毁这些token, From为空时使用验证人账户
+// 3. 检查To(burn时为From)的余额变化等于Count
+func NFTBatch() (succeed bool) {
+	var params struct {
+		Asset   common.Address
+		Action  string
+		From    common.Address
+		To      common.Address
+		StartID uint64
+		Count   int
+		Uri     string
+		Batch   int
+	}
+	if err := config.LoadParams("NFT-Batch.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	cli := getPaletteCli(pltCTypeInvoker)
+	if params.From != utils.EmptyAddress {
+		s, err := customLoadSigner(params.From)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		cli = sdk.NewSignerSender(config.Conf.Rpc, s)
+	}
+	tokenIDs := make([]*big.Int, 0, params.Count)
+	for i := 0; i < params.Count; i++ {
+		tokenIDs = append(tokenIDs, new(big.Int).SetUint64(params.StartID+uint64(i)))
+	}
+
+	owner, delta := params.To, int64(params.Count)
+	if params.Action == "burn" {
+		owner, delta = cli.Address(), -delta
+	}
+	before, err := cli.NFTBalance(params.Asset, owner, "latest")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	start := time.Now()
+	var hashes []common.Hash
+	switch params.Action {
+	case "mint":
+		tokens := make([]*collection.Token, 0, len(tokenIDs))
+		for _, tokenID := range tokenIDs {
+			tokens = append(tokens, &collection.Token{ID: tokenID, Owner: params.To, URI: fmt.Sprintf("%s%d", params.Uri, tokenID)})
+		}
+		hashes, err = getPaletteCli(pltCTypeCrossChainAdmin).NFTBatchMint(params.Asset, tokens, params.Batch)
+	case "transfer":
+		hashes, err = cli.NFTBatchTransfer(params.Asset, params.To, tokenIDs, params.Batch)
+	case "burn":
+		hashes, err = cli.NFTBatchBurn(params.Asset, tokenIDs, params.Batch)
+	default:
+		log.Errorf("unknown nft batch action %s", params.Action)
+		return
+	}
+	if err != nil {
+		log.Errorf("nft batch %s failed after %d txs, err: %v", params.Action, len(hashes), err)
+		return
+	}
+	log.Infof("nft batch %s %d tokens in %v", params.Action, len(hashes), time.Since(start))
+
+	after, err := cli.NFTBalance(params.Asset, owner, "latest")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if actual := new(big.Int).Sub(after, before); actual.Int64() != delta {
+		log.Errorf("%s nft balance delta expect %d, actual %d", owner.Hex(), delta, actual)
+		return
+	}
+	log.Infof("%s nft balance before %d, after %d", owner.Hex(), before, after)
+	return true
+}
+
+// NFTTokens 列出某个地址在palette上持有的NFT, 资产支持`tokenOfOwnerByIndex`时直接枚举, 否则从StartHeight开始回放Transfer事件
+func NFTTokens() (succeed bool) {
+	var params struct {
+		Asset       common.Address
+		Owner       common.Address
+		StartHeight uint64
+	}
+	if err := config.LoadParams("NFT-Tokens.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	cli := getPaletteCli(pltCTypeCustomer)
+	height := cli.GetBlockNumber()
+	list, err := cli.NFTTokensOf(params.Asset, params.Owner, params.StartHeight, height)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	for _, tokenID := range list {
+		uri, err := cli.NFTTokenURI(params.Asset, tokenID, hexutil.EncodeUint64(height))
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		log.Infof("token %s, uri %s", tokenID, uri)
+	}
+	log.Infof("asset %s, owner %s has %d tokens at block %d", params.Asset.Hex(), params.Owner.Hex(), len(list), height)
+	return true
+}
+
+// NFTSnapshot 导出palette上某个NFT资产全部token的owner, uri及approval
+// 1. 从StartHeight(不晚于资产部署高度)开始回放Transfer事件得到全部token, 并与totalSupply比较, Height为0表示最新区块
+// 2. Output不为空时将快照保存为json文件
+// 3. Compare不为空时与之前保存的快照比较token的owner及uri, Approval为true时同时比较approval, 跨链往返之后approval会被清空
+func NFTSnapshot(res *frame.Result) error {
+	var params struct {
+		Asset       common.Address
+		StartHeight uint64
+		Height      uint64
+		Output      string
+		Compare     string
+		Approval    bool
+	}
+	if err := config.LoadParams("NFT-Snapshot.json", &params); err != nil {
+		return err
+	}
+
+	cli := getPaletteCli(pltCTypeCustomer)
+	height := params.Height
+	if height == 0 {
+		height = cli.GetBlockNumber()
+	}
+	snapshot, err := cli.NFTSnapshot(params.Asset, params.StartHeight, height)
+	if err != nil {
+		return err
+	}
+	for owner, n := range snapshot.Owners() {
+		log.Infof("owner %s has %d tokens", owner.Hex(), n)
+	}
+	log.Infof("asset %s has %d tokens at block %d", params.Asset.Hex(), len(snapshot.Tokens), height)
+	res.Metric("tokens", float64(len(snapshot.Tokens)), "")
+	res.Output("height", height)
+
+	if params.Output != "" {
+		if err := snapshot.Save(params.Output); err != nil {
+			return err
+		}
+		log.Infof("snapshot saved to %s", params.Output)
+		res.Output("snapshot", params.Output)
+	}
+	if params.Compare != "" {
+		expect, err := collection.Load(params.Compare)
+		if err != nil {
+			return err
+		}
+		diff := collection.Diff(expect, snapshot, params.Approval)
+		for _, d := range diff {
+			log.Warn(d)
+		}
+		res.Assert(fmt.Sprintf("collection equals snapshot at block %d", expect.Height), len(diff) == 0, "%d differences", len(diff))
+	}
+	return nil
+}
+
 //func nftTransferBack(asset common.Address, tokenID *big.Int, from common.Address) (succeed bool) {
 //	url := valcli.Url()
-//	cli := sdk.NewSender(url, customLoadAccount(from))
+//	cli := sdk.NewSignerSender(url, customLoadSigner(from))
 //	to := valcli.Address()
 //
 //	if _, err := cli.NFTTransferFrom(asset, from, to, tokenID); err != nil {
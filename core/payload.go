@@ -0,0 +1,52 @@
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/codec"
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// DecodePayload 将跨链交易中的原始数据解析为可读的形式
+// 1. Type为param(默认)时按poly MakeTxParam解析, 即eccm CrossChainEvent中的rawdata, 其中proxy的unlock参数按PLT或NFT格式解析
+// 2. Type为plt, nft时解析proxy的unlock参数, safe-transfer解析NFT safeTransferFrom到lock proxy的callData
+// 3. Type为wrapper, nft-wrapper时解析wrapper lock交易的input, 包括手续费及id
+// 4. 未指定Data而指定Hash时, 从palette交易中eccm的CrossChainEvent读取rawdata
+func DecodePayload() (succeed bool) {
+	var params struct {
+		Type string
+		Data string
+		Hash common.Hash
+	}
+	if err := config.LoadParams("DecodePayload.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	var raw []byte
+	if params.Data != "" {
+		bz, err := hexutil.Decode(params.Data)
+		if err != nil {
+			log.Errorf("invalid hex data, err: %v", err)
+			return
+		}
+		raw = bz
+	} else {
+		evt, err := paletteCrossChainEvent(params.Hash)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		params.Type, raw = codec.TypeParam, evt.Value
+		log.Infof("rawdata of tx %s: %s", params.Hash.Hex(), hexutil.Encode(raw))
+	}
+
+	value, err := codec.Decode(params.Type, raw)
+	if err != nil {
+		log.Errorf("decode %s payload failed, err: %v", params.Type, err)
+		return
+	}
+	log.Infof("%s", value)
+	return true
+}
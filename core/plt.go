@@ -195,10 +195,14 @@ func Approve() (succeed bool) {
 	}
 
 	baseUrl := config.Conf.Rpc
-	key := customLoadAccount(params.Owner)
-	cli := sdk.NewSender(baseUrl, key)
+	s, err := customLoadSigner(params.Owner)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	cli := sdk.NewSignerSender(baseUrl, s)
 
-	owner := PrivKey2Addr(key)
+	owner := cli.Address()
 	spender := params.Spender
 	amount := plt.MultiPLT(params.Amount)
 
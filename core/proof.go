@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/proof"
+)
+
+// VerifyProof 验证palette `eth_getProof`返回的存储证明
+// 1. 指定TxHash时, 从该交易中eccm的CrossChainEvent解析跨链交易id, 计算eccd中EthToPolyTxHashMap的存储位置,
+// 默认在交易所在区块验证, 并检查证明的值等于跨链参数的hash, 即relayer提交给poly的证明
+// 2. 未指定TxHash时, 在BlockNumber区块验证Contract(默认palette eccd)的Keys, BlockNumber为0表示最新区块
+// 3. 用区块头的state root验证账户证明, 再用账户的storage hash验证每个存储证明, 每项检查记录为断言
+func VerifyProof(res *frame.Result) error {
+	var params struct {
+		TxHash      common.Hash
+		Contract    common.Address
+		Keys        []common.Hash
+		BlockNumber uint64
+	}
+	if err := config.LoadParams("VerifyProof.json", &params); err != nil {
+		return err
+	}
+
+	cc := config.Conf.CrossChain
+	cli := getPaletteCli(pltCTypeCustomer)
+	contract, keys, height := params.Contract, params.Keys, params.BlockNumber
+	if contract == (common.Address{}) {
+		contract = cc.PaletteECCD
+	}
+	expect := make(map[common.Hash]common.Hash)
+
+	if params.TxHash != (common.Hash{}) {
+		evt, err := paletteCrossChainEvent(params.TxHash)
+		if err != nil {
+			return err
+		}
+		if height == 0 {
+			height = evt.Height
+		}
+		key := proof.ECCDTxHashKey(evt.Txid)
+		log.Infof("cross chain tx id %s, eccd key %s", hexutil.Encode(evt.Txid), key.Hex())
+		contract = cc.PaletteECCD
+		keys = append(keys, key)
+		expect[key] = crypto.Keccak256Hash(evt.Value)
+	}
+	if height == 0 {
+		height = cli.GetBlockNumber()
+	}
+
+	block, err := cli.GetBlockByNumber(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed, err: %v", height, err)
+	}
+	list := make([]string, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key.Hex())
+	}
+	p, err := cli.GetProofs(contract, list, hexutil.EncodeUint64(height))
+	if err != nil {
+		return fmt.Errorf("get proof of %s at block %d failed, err: %v", contract.Hex(), height, err)
+	}
+	log.Infof("block %d state root %s, contract %s storage hash %s", height, block.Root().Hex(), contract.Hex(), p.StorageHash.Hex())
+	res.Output("state-root", block.Root().Hex())
+	res.Output("storage-hash", p.StorageHash.Hex())
+
+	err = p.VerifyAccount(block.Root())
+	res.Assert("account proof", err == nil, "err: %v", err)
+	if err != nil {
+		return nil
+	}
+	err = p.VerifyStorage()
+	res.Assert("storage proof", err == nil, "err: %v", err)
+
+	for _, key := range keys {
+		value, ok := p.Storage(key)
+		if !res.Assert(fmt.Sprintf("storage %s", key.Hex()), ok, "not returned by node") {
+			continue
+		}
+		log.Infof("storage %s = %s", key.Hex(), common.BigToHash(value).Hex())
+		res.Output(key.Hex(), common.BigToHash(value).Hex())
+		if hash, ok := expect[key]; ok {
+			res.Equal("cross chain param hash", hash.Hex(), common.BigToHash(value).Hex())
+		}
+	}
+	return nil
+}
+
+// paletteCrossChainEvent 从palette交易中解析eccm的CrossChainEvent
+func paletteCrossChainEvent(hash common.Hash) (*eth.LockEvent, error) {
+	receipt, err := getPaletteCli(pltCTypeCustomer).GetReceipt(hash)
+	if err != nil {
+		return nil, fmt.Errorf("get receipt of %s failed, err: %v", hash.Hex(), err)
+	}
+	tools := eth.NewEthTools(config.Conf.Rpc)
+	if tools == nil {
+		return nil, fmt.Errorf("dial palette %s failed", config.Conf.Rpc)
+	}
+	locks, _, err := tools.GetSmartContractEventByBlock(config.Conf.CrossChain.PaletteECCM.Hex(), receipt.BlockNumber.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	for _, evt := range locks {
+		if strings.EqualFold(evt.TxHash, hash.Hex()) {
+			return evt, nil
+		}
+	}
+	return nil, fmt.Errorf("eccm CrossChainEvent not found in tx %s", hash.Hex())
+}
@@ -0,0 +1,397 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/native/governance"
+	"github.com/ethereum/go-ethereum/contracts/native/plt"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/proposal"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// ProposalList 从governance合约的提案事件中列出提案及其当前状态
+// 1. 扫描[StartHeight, EndHeight]区块内的提案事件, EndHeight为0时取最新区块
+// 2. 逐个查询提案是否通过, 结合截止区块得到active, passed或expired状态, Status不为空时只列出该状态的提案
+func ProposalList() (succeed bool) {
+	var params struct {
+		StartHeight uint64
+		EndHeight   uint64
+		Status      string
+	}
+	if err := config.LoadParams("ProposalList.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	cli := getPaletteCli(pltCTypeCustomer)
+	height := cli.GetBlockNumber()
+	end := params.EndHeight
+	if end == 0 || end > height {
+		end = height
+	}
+	list, err := cli.ProposalEvents(params.StartHeight, end)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	count := 0
+	for _, evt := range list {
+		data, err := cli.GetProposal(evt.ID, "latest")
+		if err != nil {
+			log.Errorf("get proposal %s failed, err: %v", evt.ID.Hex(), err)
+			return
+		}
+		status := proposal.Status(data.Passed, evt.EndBlock, height)
+		if params.Status != "" && params.Status != status {
+			continue
+		}
+		count++
+		name, _ := proposal.TypeName(evt.Type)
+		log.Infof("proposal %s, type %s, proposer %s, proposed at %d, end block %d, status %s, tx %s",
+			evt.ID.Hex(), name, evt.Proposer.Hex(), evt.Height, evt.EndBlock, status, evt.TxHash.Hex())
+	}
+	log.Infof("%d of %d proposals listed in blocks [%d, %d]", count, len(list), params.StartHeight, end)
+	return true
+}
+
+// ProposalVote 使用任意一组validator账户对提案投票
+// 1. 投票前检查提案仍在投票期内, 并根据各validator的总质押量计算这组账户能否达到2/3阈值
+// 2. 依次投票, 任何一票失败都会中止, 提案通过之后剩余的voter不再投票
+// 3. 提案通过之后根据通过的区块, 上次分润区块及分润周期预测参数生效的区块
+func ProposalVote() (succeed bool) {
+	var params struct {
+		ProposalID common.Address
+		Voters     []common.Address
+	}
+	if err := config.LoadParams("ProposalVote.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	cli := getPaletteCli(pltCTypeCustomer)
+	data, err := cli.GetProposal(params.ProposalID, "latest")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if status := proposal.Status(data.Passed, data.EndBlock.Uint64(), cli.GetBlockNumber()); status != proposal.StatusActive {
+		log.Errorf("proposal %s is %s", params.ProposalID.Hex(), status)
+		return
+	}
+	tally, err := cli.ProposalTally(params.Voters, "latest")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	for _, outsider := range tally.Outsiders {
+		log.Warnf("%s is not effective validator", outsider.Hex())
+	}
+	log.Infof("proposal %s %s, lack %s", params.ProposalID.Hex(), tally, tally.Lack())
+
+	var hash common.Hash
+	for i, voter := range params.Voters {
+		voterCli, err := customPaletteCli(voter)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+		if hash, err = voterCli.Vote(params.ProposalID); err != nil {
+			log.Errorf("%s failed to vote, err: %v", voter.Hex(), err)
+			return
+		}
+		log.Infof("%s vote to proposal %s, hash %s", voter.Hex(), params.ProposalID.Hex(), hash.Hex())
+		if data, err = cli.GetProposal(params.ProposalID, "latest"); err != nil {
+			log.Error(err)
+			return
+		}
+		// votes to passed proposal are rejected, the rest voters are skipped
+		if data.Passed {
+			if rest := params.Voters[i+1:]; len(rest) > 0 {
+				log.Infof("proposal %s passed, skip voters %v", params.ProposalID.Hex(), rest)
+			}
+			break
+		}
+	}
+
+	if !data.Passed {
+		log.Infof("proposal %s not passed yet, end block %d", params.ProposalID.Hex(), data.EndBlock.Uint64())
+		return true
+	}
+	passHeight, err := receiptHeight(cli, hash)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	effective, err := predictEffectiveHeight(cli, passHeight)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	log.Infof("proposal %s passed at block %d, predicted to take effect at block %d", params.ProposalID.Hex(), passHeight, effective)
+	return true
+}
+
+// ProposalLifecycle 测试提案的完整生命周期及异常情况
+// 1. 根据各validator的总质押量计算Voters能否达到2/3阈值, 不能达到时直接失败, 同时取Voters中未达到阈值的最长前缀用于阈值以下的用例
+// 2. Proposer提案, NonValidator投票必须被拒绝(投票前补足gas); voter依次投票, 达到阈值的voter投票之后提案必须通过, 其后的voter不再投票,
+// 第一个voter单独未达到阈值时, 其重复投票必须被拒绝. 拒绝即合约revert, Reasons中配置的内容必须包含在revert reason中
+// 3. 预测参数生效的区块, 检查该区块的全局参数等于提案值, 且前一个区块仍为旧值(旧值与提案值相同时不检查)
+// 4. Proposer以当前值再次提案, 只由阈值以下的voter投票, 截止区块之后提案必须为expired, 此时其他voter投票必须失败
+func ProposalLifecycle(res *frame.Result) error {
+	var params struct {
+		Proposer      common.Address
+		ProposalType  string
+		ProposalValue int
+		Voters        []common.Address
+		NonValidator  common.Address
+		// Reasons are expected in the revert reason of rejected votes, any reason is accepted if empty
+		Reasons struct {
+			DoubleVote   string
+			NonValidator string
+			Expired      string
+		}
+	}
+	if err := config.LoadParams("ProposalLifecycle.json", &params); err != nil {
+		return err
+	}
+	typ, err := proposal.ParseType(params.ProposalType)
+	if err != nil {
+		return err
+	}
+	if params.ProposalValue < 0 {
+		return fmt.Errorf("invalid proposal value %d", params.ProposalValue)
+	}
+	value := proposalValue(typ, params.ProposalValue)
+
+	cli := getPaletteCli(pltCTypeCustomer)
+	proposer, err := customPaletteCli(params.Proposer)
+	if err != nil {
+		return err
+	}
+	stakes, err := cli.ValidatorStakes("latest")
+	if err != nil {
+		return err
+	}
+	tally := proposal.NewTally(stakes, params.Voters)
+	if len(tally.Outsiders) > 0 || !tally.Met() {
+		return fmt.Errorf("voters should be effective validators and reach the threshold, %s, outsiders %v", tally, tally.Outsiders)
+	}
+	below := make([]common.Address, 0)
+	for _, voter := range params.Voters {
+		if proposal.NewTally(stakes, append(below, voter)).Met() {
+			break
+		}
+		below = append(below, voter)
+	}
+	log.Infof("voters %s, %d of them are below threshold", tally, len(below))
+
+	// propose, vote and wait passed
+	logsplit()
+	id, hash, err := propose(cli, proposer, typ, value)
+	if err != nil {
+		return err
+	}
+	res.Output("proposalID", id.Hex())
+	if params.NonValidator != (common.Address{}) {
+		if err := prepareProposalGas(params.NonValidator); err != nil {
+			return err
+		}
+		nonValidator, err := customPaletteCli(params.NonValidator)
+		if err != nil {
+			return err
+		}
+		expectVoteFailed(res, "non-validator vote", nonValidator, id, params.Reasons.NonValidator)
+	}
+	// the voter at len(below) reaches the threshold, votes after it would be rejected by the passed proposal
+	for i, voter := range params.Voters[:len(below)+1] {
+		voterCli, err := customPaletteCli(voter)
+		if err != nil {
+			return err
+		}
+		if hash, err = voterCli.Vote(id); err != nil {
+			return fmt.Errorf("%s failed to vote, err: %v", voter.Hex(), err)
+		}
+		log.Infof("%s vote to proposal %s, hash %s", voter.Hex(), id.Hex(), hash.Hex())
+		if i > 0 {
+			continue
+		}
+		if len(below) == 0 {
+			log.Warnf("%s reaches the threshold alone, skip double vote", voter.Hex())
+			continue
+		}
+		expectVoteFailed(res, "double vote", voterCli, id, params.Reasons.DoubleVote)
+	}
+	passHeight, err := receiptHeight(cli, hash)
+	if err != nil {
+		return err
+	}
+	if _, err := cli.WaitProposal(id, "passed", func(p *governance.MethodGetProposalOutput) bool {
+		return p.Passed
+	}, config.Conf.BlocksPollOptions(config.Conf.RewardEffectivePeriod)); err != nil {
+		return fmt.Errorf("proposal %s should be passed, err: %v", id.Hex(), err)
+	}
+
+	// check effective height
+	logsplit()
+	before, err := cli.GetGlobalParams(typ, hexutil.EncodeUint64(passHeight))
+	if err != nil {
+		return err
+	}
+	effective, err := predictEffectiveHeight(cli, passHeight)
+	if err != nil {
+		return err
+	}
+	log.Infof("proposal %s passed at block %d, predicted to take effect at block %d", id.Hex(), passHeight, effective)
+	res.Output("effectiveHeight", effective)
+	if err := waitProposalHeight(cli, effective); err != nil {
+		return err
+	}
+	actual, err := cli.GetGlobalParams(typ, hexutil.EncodeUint64(effective))
+	if err != nil {
+		return err
+	}
+	res.Equal("global params at predicted height", value.String(), actual.String())
+	if before.Cmp(value) != 0 && effective > passHeight+1 {
+		prev, err := cli.GetGlobalParams(typ, hexutil.EncodeUint64(effective-1))
+		if err != nil {
+			return err
+		}
+		res.Equal("global params before predicted height", before.String(), prev.String())
+	}
+
+	// below threshold and expired
+	logsplit()
+	if len(below) == 0 {
+		log.Warnf("%s reaches the threshold alone, skip below threshold proposal", params.Voters[0].Hex())
+		return nil
+	}
+	current, err := cli.GetGlobalParams(typ, "latest")
+	if err != nil {
+		return err
+	}
+	id, _, err = propose(cli, proposer, typ, current)
+	if err != nil {
+		return err
+	}
+	data, err := cli.GetProposal(id, "latest")
+	if err != nil {
+		return err
+	}
+	for _, voter := range below {
+		voterCli, err := customPaletteCli(voter)
+		if err != nil {
+			return err
+		}
+		if hash, err = voterCli.Vote(id); err != nil {
+			return fmt.Errorf("%s failed to vote, err: %v", voter.Hex(), err)
+		}
+		log.Infof("%s vote to proposal %s, hash %s", voter.Hex(), id.Hex(), hash.Hex())
+	}
+	endBlock := data.EndBlock.Uint64()
+	if err := waitProposalHeight(cli, endBlock+1); err != nil {
+		return err
+	}
+	if data, err = cli.GetProposal(id, "latest"); err != nil {
+		return err
+	}
+	res.Equal("below threshold proposal status", proposal.StatusExpired, proposal.Status(data.Passed, endBlock, cli.GetBlockNumber()))
+	lateVoter, err := customPaletteCli(params.Voters[len(below)])
+	if err != nil {
+		return err
+	}
+	expectVoteFailed(res, "expired proposal vote", lateVoter, id, params.Reasons.Expired)
+	if data, err = cli.GetProposal(id, "latest"); err != nil {
+		return err
+	}
+	res.Assert("expired proposal not passed", !data.Passed, "proposal %s passed after end block %d", id.Hex(), endBlock)
+	return nil
+}
+
+func validProposalType(typ uint8) bool {
+	_, ok := proposal.TypeName(typ)
+	return ok
+}
+
+// proposalValue 手续费类提案的值以PLT为单位, 分润周期以区块为单位
+func proposalValue(typ uint8, value int) *big.Int {
+	if proposal.InPLT(typ) {
+		return plt.MultiPLT(value)
+	}
+	return big.NewInt(int64(value))
+}
+
+func propose(cli, proposer *sdk.Client, typ uint8, value *big.Int) (common.Address, common.Hash, error) {
+	hash, err := proposer.Propose(typ, value)
+	if err != nil {
+		return common.Address{}, hash, fmt.Errorf("%s failed to propose, err: %v", proposer.Address().Hex(), err)
+	}
+	id, data, err := cli.GetProposalFromReceipt(hash)
+	if err != nil {
+		return common.Address{}, hash, err
+	}
+	log.Infof("proposalID %s, hash %s, proposer %s, proposal type %d, value %s, end block %d",
+		id.Hex(), hash.Hex(), proposer.Address().Hex(), data.ProposalType, value, data.EndBlock.Uint64())
+	return id, hash, nil
+}
+
+// expectVoteFailed 投票必须被governance合约拒绝, 余额不足等其他原因导致的失败不算
+func expectVoteFailed(res *frame.Result, name string, voter *sdk.Client, id common.Address, reason string) {
+	hash, err := voter.Vote(id)
+	if err == nil {
+		log.Errorf("%s: %s vote to proposal %s succeed, hash %s", name, voter.Address().Hex(), id.Hex(), hash.Hex())
+	}
+	assertReverted(res, name+" rejected", err, reason)
+}
+
+// prepareProposalGas 非validator账户余额不足1 PLT时由admin转账补足, 保证投票交易因合约规则而失败
+func prepareProposalGas(addr common.Address) error {
+	need := plt.MultiPLT(1)
+	balance, err := getPaletteCli(pltCTypeCustomer).BalanceOf(addr, "latest")
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(need) >= 0 {
+		return nil
+	}
+	if _, err := getPaletteCli(pltCTypeAdmin).PLTTransfer(addr, new(big.Int).Sub(need, balance)); err != nil {
+		return fmt.Errorf("transfer gas to %s failed, err: %v", addr.Hex(), err)
+	}
+	return nil
+}
+
+func receiptHeight(cli *sdk.Client, hash common.Hash) (uint64, error) {
+	receipt, err := cli.GetReceipt(hash)
+	if err != nil {
+		return 0, err
+	}
+	return receipt.BlockNumber.Uint64(), nil
+}
+
+// predictEffectiveHeight 根据通过提案时的上次分润区块及分润周期预测提案生效的区块
+func predictEffectiveHeight(cli *sdk.Client, passHeight uint64) (uint64, error) {
+	blockNum := hexutil.EncodeUint64(passHeight)
+	lastReward, err := cli.GetLastRewardBlock(blockNum)
+	if err != nil {
+		return 0, err
+	}
+	period, err := cli.GetGlobalParams(proposal.TypeRewardPeriod, blockNum)
+	if err != nil {
+		return 0, err
+	}
+	return proposal.EffectiveHeight(passHeight, lastReward.Uint64(), period.Uint64()), nil
+}
+
+func waitProposalHeight(cli *sdk.Client, height uint64) error {
+	cur := cli.GetBlockNumber()
+	if cur >= height {
+		return nil
+	}
+	return cli.WaitBlockHeight(height, config.Conf.BlocksPollOptions(int(height-cur)+1))
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/relayer"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// relaySide 通过eth rpc扫描eccm事件及查询区块头, palette和ethereum共用
+type relaySide struct {
+	name  string
+	id    uint64
+	eccd  common.Address
+	eccm  common.Address
+	tools *eth.ETHTools
+}
+
+func (s *relaySide) Name() string        { return s.name }
+func (s *relaySide) SideChainID() uint64 { return s.id }
+
+func (s *relaySide) Height() (uint64, error) {
+	return s.tools.GetNodeHeight()
+}
+
+func (s *relaySide) Events(start, end uint64) ([]*relayer.Event, error) {
+	locks, _, err := s.tools.GetSmartContractEventByRange(s.eccm.Hex(), start, end)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*relayer.Event, 0, len(locks))
+	for _, evt := range locks {
+		list = append(list, &relayer.Event{
+			TxHash:    evt.TxHash,
+			TxID:      evt.Txid,
+			ToChainID: uint64(evt.Tchain),
+			Rawdata:   evt.Value,
+			Height:    evt.Height,
+		})
+	}
+	return list, nil
+}
+
+func (s *relaySide) Header(height uint64) ([]byte, error) {
+	hdr, err := s.tools.GetBlockHeader(height)
+	if err != nil {
+		return nil, err
+	}
+	if hdr == nil {
+		return nil, fmt.Errorf("%s header %d not found", s.name, height)
+	}
+	return json.Marshal(hdr)
+}
+
+// paletteRelaySide 使用palette sdk获取eccd存储证明及执行poly交易
+type paletteRelaySide struct {
+	*relaySide
+	cli *sdk.Client
+}
+
+func (s *paletteRelaySide) Proof(key string, height uint64) ([]byte, error) {
+	proof, err := s.cli.GetProof(s.eccd, key, hexutil.EncodeUint64(height))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(proof)
+}
+
+func (s *paletteRelaySide) Executed(fromChainID uint64, polyTxHash []byte) (bool, error) {
+	return s.cli.CheckIfFromChainTxExist(s.eccd, fromChainID, polyTxHash)
+}
+
+func (s *paletteRelaySide) EpochStartHeight() (uint64, error) {
+	return s.cli.GetCurEpochStartHeight(s.eccd)
+}
+
+func (s *paletteRelaySide) Execute(d *relayer.Delivery) (string, error) {
+	hash, err := s.cli.VerifyHeaderAndExecuteTx(s.eccm, d.Proof, d.RawHeader, d.HeaderProof, d.CurRawHeader, d.HeaderSig)
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+// ethereumRelaySide 使用eth invoker获取eccd存储证明及执行poly交易
+type ethereumRelaySide struct {
+	*relaySide
+	invoker *eth.EthInvoker
+}
+
+func (s *ethereumRelaySide) Proof(key string, height uint64) ([]byte, error) {
+	return s.tools.GetProof(s.eccd, key, height)
+}
+
+func (s *ethereumRelaySide) Executed(fromChainID uint64, polyTxHash []byte) (bool, error) {
+	return s.invoker.CheckIfFromChainTxExist(s.eccd, fromChainID, polyTxHash)
+}
+
+func (s *ethereumRelaySide) EpochStartHeight() (uint64, error) {
+	return s.invoker.GetCurEpochStartHeight(s.eccd)
+}
+
+func (s *ethereumRelaySide) Execute(d *relayer.Delivery) (string, error) {
+	hash, err := s.invoker.VerifyAndExecuteTx(s.eccm, d.Proof, d.RawHeader, d.HeaderProof, d.CurRawHeader, d.HeaderSig)
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex(), nil
+}
+
+type relayerParams struct {
+	PaletteStart       uint64          // palette起始扫描高度, 0表示最新区块
+	EthereumStart      uint64          // ethereum起始扫描高度, 0表示最新区块
+	PolyStart          uint64          // poly起始扫描高度, 0表示最新区块
+	Interval           encode.Duration // 轮询间隔
+	SyncEthereumHeader bool            // 是否同步ethereum区块头到poly, 没有其他relayer同步时需要打开
+	MaxAttempts        int             // 交易失败重试次数, 超过后丢弃
+}
+
+// newRelayer 连接palette, poly及ethereum, 组装palette<->ethereum双向的relayer
+func newRelayer(params *relayerParams) (*relayer.Relayer, error) {
+	cc := config.Conf.CrossChain
+	polyCli, err := poly.NewPolyClient(cc.PolyRPCAddress, cc.LoadPolyAccountList())
+	if err != nil {
+		return nil, fmt.Errorf("connect poly %s failed, err: %v", cc.PolyRPCAddress, err)
+	}
+	relayPoly, err := poly.NewRelayPoly(polyCli)
+	if err != nil {
+		return nil, err
+	}
+
+	paletteTools := eth.NewEthTools(config.Conf.Rpc)
+	if paletteTools == nil {
+		return nil, fmt.Errorf("dial palette %s failed", config.Conf.Rpc)
+	}
+	palette := &paletteRelaySide{
+		relaySide: &relaySide{name: groupPalette, id: cc.PaletteSideChainID, eccd: cc.PaletteECCD, eccm: cc.PaletteECCM, tools: paletteTools},
+		cli:       getPaletteCli(pltCTypeInvoker),
+	}
+
+	invoker := getEthereumCli(ethCTypeInvoker)
+	ethereum := &ethereumRelaySide{
+		relaySide: &relaySide{name: groupEthereum, id: cc.EthereumSideChainID, eccd: cc.EthereumECCD, eccm: cc.EthereumECCM, tools: invoker.Tools},
+		invoker:   invoker,
+	}
+	ethereumChain := &relayer.Chain{Side: ethereum, SyncHeader: params.SyncEthereumHeader, Start: params.EthereumStart}
+	if params.SyncEthereumHeader {
+		sideChain, err := polyCli.GetSideChain(cc.EthereumSideChainID)
+		if err != nil {
+			return nil, fmt.Errorf("get poly side chain %d failed, err: %v", cc.EthereumSideChainID, err)
+		}
+		if sideChain == nil {
+			return nil, fmt.Errorf("side chain %d not registered on poly", cc.EthereumSideChainID)
+		}
+		ethereumChain.Confirmations = sideChain.BlocksToWait
+	}
+
+	opts := relayer.Options{PolyStart: params.PolyStart, MaxAttempts: params.MaxAttempts}
+	r, err := relayer.New(relayPoly, opts, &relayer.Chain{Side: palette, Start: params.PaletteStart}, ethereumChain)
+	if err != nil {
+		return nil, err
+	}
+	// relayer logs in its own goroutine, carry the method log prefix
+	r.Logf = log.Current().Infof
+	return r, nil
+}
+
+// Relayer 本地跨链测试使用的简易relayer(常驻):
+// 1.扫描palette及ethereum eccm的CrossChainEvent, 从eccd获取跨链交易的存储证明
+// 2.palette交易携带区块头提交到poly, ethereum交易等待区块头同步到poly并达到确认数后提交, 可选由relayer同步ethereum区块头
+// 3.扫描poly cross chain manager的makeProof事件, 获取poly的跨链状态证明, 区块头及签名
+// 4.调用目标链eccm的verifyHeaderAndExecuteTx执行跨链交易, 已被其他relayer执行的交易跳过
+// 5.失败的交易在下一轮重试, 超过MaxAttempts后丢弃并打印日志, 收到SIGINT/SIGTERM后退出
+func Relayer() (succeed bool) {
+	var params relayerParams
+	if err := config.LoadParams("Relayer.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+	interval := time.Duration(params.Interval)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	r, err := newRelayer(&params)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		r.Run(interval, stop)
+		close(done)
+	}()
+
+	log.Infof("relayer started, interval %s, press ctrl+c to stop", interval)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-ch
+	log.Infof("relayer received signal %s, stopping...", sig)
+	close(stop)
+	<-done
+	log.Infof("relayer stopped, %s", r.Stats())
+	return true
+}
@@ -0,0 +1,341 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/native"
+	"github.com/ethereum/go-ethereum/contracts/native/plt"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/collection"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poll"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// RoundTrip 测试palette->ethereum->palette往返跨链之后PLT及NFT守恒
+// 1. 每一轮随机生成[MinAmount, MaxAmount]个PLT(精确到最小单位), 由PaletteUser lock到以太上的EthereumUser, 到账之后再由EthereumUser lock回PaletteUser
+// 2. 随机生成TokenCount个token id并mint给PaletteUser, 每一轮全部跨链到以太, 到账之后再全部跨链回palette
+// 3. 初始状态以及每一跳到账之后检查守恒: palette PLT lock proxy(即native plt合约)锁定的PLT等于以太上PLT的totalSupply,
+// token在以太上存在期间由palette nft proxy持有, 回到palette之后属于PaletteUser且以太上不再属于EthereumUser
+// 4. 任何不一致都作为失败的断言记录, 并附上该跳涉及的交易hash. Seed为0时使用当前时间, 失败时可以用输出的seed复现
+func RoundTrip(res *frame.Result) error {
+	var params struct {
+		PaletteUser  common.Address
+		EthereumUser common.Address
+		Rounds       int
+		MinAmount    int
+		MaxAmount    int
+		PLTNFTAsset  common.Address
+		ETHNFTAsset  common.Address
+		TokenCount   int
+		Uri          string
+		Seed         int64
+	}
+	if err := config.LoadParams("RoundTrip.json", &params); err != nil {
+		return err
+	}
+	if params.MinAmount <= 0 || params.MaxAmount < params.MinAmount {
+		return fmt.Errorf("invalid amount range [%d, %d]", params.MinAmount, params.MaxAmount)
+	}
+	if params.Seed == 0 {
+		params.Seed = time.Now().UnixNano()
+	}
+	log.Infof("round trip seed %d", params.Seed)
+	res.Output("seed", params.Seed)
+
+	t, err := newRoundTrip(res, params.PaletteUser, params.EthereumUser, params.PLTNFTAsset, params.ETHNFTAsset)
+	if err != nil {
+		return err
+	}
+	t.rnd = rand.New(rand.NewSource(params.Seed))
+	if err := t.prepareTokens(params.TokenCount, params.Uri); err != nil {
+		return err
+	}
+	if err := t.check("initial"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	for i := 0; i < params.Rounds; i++ {
+		logsplit()
+		amount := t.randomAmount(plt.MultiPLT(params.MinAmount), plt.MultiPLT(params.MaxAmount))
+		log.Infof("round %d, amount %d PLT, %d tokens", i, plt.PrintUPLT(amount), len(t.tokens))
+		if err := t.pltToEthereum(i, amount); err != nil {
+			return err
+		}
+		if err := t.pltToPalette(i, amount); err != nil {
+			return err
+		}
+		if len(t.tokens) == 0 {
+			continue
+		}
+		if err := t.nftToEthereum(i); err != nil {
+			return err
+		}
+		if err := t.nftToPalette(i); err != nil {
+			return err
+		}
+	}
+	res.Metric("round-trip-duration", time.Since(start).Seconds(), "s")
+	return nil
+}
+
+// roundTrip 记录往返跨链的两端账户及客户端, tokens为参与跨链的全部token id
+type roundTrip struct {
+	res      *frame.Result
+	rnd      *rand.Rand
+	pltUser  common.Address
+	ethUser  common.Address
+	pltNFT   common.Address
+	ethNFT   common.Address
+	tokens   []*big.Int
+	onRemote bool // token当前是否在以太上
+
+	pltCli    *sdk.Client
+	ethCli    *eth.EthInvoker
+	pltLocker common.Address
+	ethAsset  common.Address
+}
+
+func newRoundTrip(res *frame.Result, pltUser, ethUser, pltNFT, ethNFT common.Address) (*roundTrip, error) {
+	cc := config.Conf.CrossChain
+	pltCli, err := customPaletteCli(pltUser)
+	if err != nil {
+		return nil, err
+	}
+	ethCli, err := customEthereumCli(ethUser)
+	if err != nil {
+		return nil, err
+	}
+	return &roundTrip{
+		res:       res,
+		pltUser:   pltUser,
+		ethUser:   ethUser,
+		pltNFT:    pltNFT,
+		ethNFT:    ethNFT,
+		pltCli:    pltCli,
+		ethCli:    ethCli,
+		pltLocker: common.HexToAddress(native.PLTContractAddress),
+		ethAsset:  cc.EthereumPLTAsset,
+	}, nil
+}
+
+// randomAmount 在[min, max]之间均匀随机
+func (t *roundTrip) randomAmount(min, max *big.Int) *big.Int {
+	span := new(big.Int).Sub(max, min)
+	return new(big.Int).Add(min, new(big.Int).Rand(t.rnd, span.Add(span, big.NewInt(1))))
+}
+
+// prepareTokens 随机生成互不相同的token id, 由资产owner批量mint给palette用户
+func (t *roundTrip) prepareTokens(count int, uri string) error {
+	if count <= 0 {
+		return nil
+	}
+	if t.pltNFT == (common.Address{}) || t.ethNFT == (common.Address{}) {
+		return fmt.Errorf("nft asset on palette or ethereum not configured")
+	}
+
+	exist := make(map[uint64]bool)
+	tokens := make([]*collection.Token, 0, count)
+	for len(tokens) < count {
+		id := uint64(t.rnd.Int63())
+		if exist[id] {
+			continue
+		}
+		exist[id] = true
+		tokenID := new(big.Int).SetUint64(id)
+		tokens = append(tokens, &collection.Token{ID: tokenID, Owner: t.pltUser, URI: fmt.Sprintf("%s%d", uri, id)})
+		t.tokens = append(t.tokens, tokenID)
+	}
+
+	logsplit()
+	log.Infof("mint %d tokens to %s on palette......", count, t.pltUser.Hex())
+	if _, err := getPaletteCli(pltCTypeCrossChainAdmin).NFTBatchMint(t.pltNFT, tokens, 0); err != nil {
+		return fmt.Errorf("mint tokens on palette failed, err: %v", err)
+	}
+	return nil
+}
+
+// pltToEthereum palette用户lock PLT到以太用户, 余额不足时由admin转账补足
+func (t *roundTrip) pltToEthereum(round int, amount *big.Int) error {
+	cc := config.Conf.CrossChain
+	balance, err := t.pltCli.BalanceOf(t.pltUser, "latest")
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(amount) <= 0 {
+		// 额外转1个PLT作为gas
+		if _, err := getPaletteCli(pltCTypeAdmin).PLTTransfer(t.pltUser, new(big.Int).Add(amount, plt.MultiPLT(1))); err != nil {
+			return fmt.Errorf("failed to prepare palette user balance, err: %v", err)
+		}
+	}
+	before, err := t.ethCli.PLTBalanceOf(t.ethAsset, t.ethUser)
+	if err != nil {
+		return err
+	}
+
+	track := startCrossChainTrack(cc.EthereumSideChainID)
+	hash, err := t.pltCli.LockPLT(cc.EthereumSideChainID, t.ethUser, amount)
+	if err != nil {
+		return fmt.Errorf("lock plt on palette failed, err: %v", err)
+	}
+	log.Infof("lock plt on palette, tx hash %s", hash.Hex())
+
+	if err := waitBalanceDelta(
+		fmt.Sprintf("ethereum %s", t.ethUser.Hex()),
+		before,
+		amount,
+		func() (*big.Int, error) { return t.ethCli.PLTBalanceOf(t.ethAsset, t.ethUser) },
+	); err != nil {
+		_, stopped := track.report(cc.PaletteSideChainID, hash)
+		return fmt.Errorf("round %d lock tx %s not arrived on ethereum, stopped at %s, err: %v", round, hash.Hex(), stopped, err)
+	}
+	return t.check(fmt.Sprintf("round %d plt palette->ethereum", round), hash)
+}
+
+// pltToPalette 以太用户通过PLT proxy将PLT lock回palette用户
+func (t *roundTrip) pltToPalette(round int, amount *big.Int) error {
+	cc := config.Conf.CrossChain
+	if err := prepareAllowance(t.ethCli, t.ethUser, cc.EthereumPLTProxy, amount); err != nil {
+		return err
+	}
+	before, err := t.pltCli.BalanceOf(t.pltUser, "latest")
+	if err != nil {
+		return err
+	}
+
+	track := startCrossChainTrack(cc.PaletteSideChainID)
+	hash, err := t.ethCli.PLTLock(cc.EthereumPLTProxy, t.ethAsset, cc.PaletteSideChainID, t.pltUser, amount)
+	if err != nil {
+		return fmt.Errorf("lock plt on ethereum failed, err: %v", err)
+	}
+	log.Infof("lock plt on ethereum, tx hash %s", hash.Hex())
+
+	if err := waitBalanceDelta(
+		fmt.Sprintf("palette %s", t.pltUser.Hex()),
+		before,
+		amount,
+		func() (*big.Int, error) { return t.pltCli.BalanceOf(t.pltUser, "latest") },
+	); err != nil {
+		_, stopped := track.report(cc.EthereumSideChainID, hash)
+		return fmt.Errorf("round %d lock tx %s not arrived on palette, stopped at %s, err: %v", round, hash.Hex(), stopped, err)
+	}
+	return t.check(fmt.Sprintf("round %d plt ethereum->palette", round), hash)
+}
+
+// nftToEthereum 先发送全部token的safeTransferFrom, 再逐个等待token在以太上到账
+func (t *roundTrip) nftToEthereum(round int) error {
+	cc := config.Conf.CrossChain
+	track := startCrossChainTrack(cc.EthereumSideChainID)
+	hashes := make([]common.Hash, 0, len(t.tokens))
+	for _, token := range t.tokens {
+		hash, err := t.pltCli.NFTSafeTransferFrom(t.pltNFT, t.pltUser, cc.PaletteNFTProxy, token, t.ethUser, cc.EthereumSideChainID)
+		if err != nil {
+			return fmt.Errorf("lock token %s on palette failed, err: %v", token, err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	for i, token := range t.tokens {
+		if err := waitNFTOwner(
+			fmt.Sprintf("ethereum token %s", token),
+			t.ethUser,
+			func() (common.Address, error) { return t.ethCli.NFTOwner(t.ethNFT, token) },
+		); err != nil {
+			_, stopped := track.report(cc.PaletteSideChainID, hashes[i])
+			return fmt.Errorf("round %d lock tx %s not arrived on ethereum, stopped at %s, err: %v", round, hashes[i].Hex(), stopped, err)
+		}
+	}
+	t.onRemote = true
+	return t.check(fmt.Sprintf("round %d nft palette->ethereum", round), hashes...)
+}
+
+// nftToPalette 以太用户将全部token通过NFT proxy跨链回palette用户
+func (t *roundTrip) nftToPalette(round int) error {
+	cc := config.Conf.CrossChain
+	track := startCrossChainTrack(cc.PaletteSideChainID)
+	hashes := make([]common.Hash, 0, len(t.tokens))
+	for _, token := range t.tokens {
+		hash, err := t.ethCli.NFTSafeTransferFrom(t.ethNFT, t.ethUser, cc.EthereumNFTProxy, token, t.pltUser, cc.PaletteSideChainID)
+		if err != nil {
+			return fmt.Errorf("lock token %s on ethereum failed, err: %v", token, err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	for i, token := range t.tokens {
+		if err := waitNFTOwner(
+			fmt.Sprintf("palette token %s", token),
+			t.pltUser,
+			func() (common.Address, error) { return t.pltCli.NFTTokenOwner(t.pltNFT, token, "latest") },
+		); err != nil {
+			_, stopped := track.report(cc.EthereumSideChainID, hashes[i])
+			return fmt.Errorf("round %d lock tx %s not arrived on palette, stopped at %s, err: %v", round, hashes[i].Hex(), stopped, err)
+		}
+	}
+	t.onRemote = false
+	return t.check(fmt.Sprintf("round %d nft ethereum->palette", round), hashes...)
+}
+
+// check 检查PLT及NFT守恒, 不一致时记录失败的断言, 只有查询失败时返回error
+func (t *roundTrip) check(step string, hashes ...common.Hash) error {
+	cc := config.Conf.CrossChain
+	txs := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		txs = append(txs, hash.Hex())
+	}
+	desc := strings.Join(txs, ", ")
+
+	locked, err := t.pltCli.BalanceOf(t.pltLocker, "latest")
+	if err != nil {
+		return err
+	}
+	supply, err := t.ethCli.PLTTotalSupply(t.ethAsset)
+	if err != nil {
+		return err
+	}
+	log.Infof("%s: palette locked %d PLT, ethereum total supply %d PLT", step, plt.PrintUPLT(locked), plt.PrintUPLT(supply))
+	t.res.Assert(fmt.Sprintf("%s plt conservation", step), locked.Cmp(supply) == 0,
+		"palette locked %s, ethereum total supply %s, txs [%s]", locked, supply, desc)
+
+	for _, token := range t.tokens {
+		pltOwner, err := t.pltCli.NFTTokenOwner(t.pltNFT, token, "latest")
+		if err != nil {
+			return err
+		}
+		// token不存在或者已被以太proxy回收都视为不属于以太用户
+		ethOwner, _ := t.ethCli.NFTOwner(t.ethNFT, token)
+		if t.onRemote {
+			t.res.Assert(fmt.Sprintf("%s token %s held by palette proxy", step, token), pltOwner == cc.PaletteNFTProxy,
+				"palette owner %s, txs [%s]", pltOwner.Hex(), desc)
+			t.res.Assert(fmt.Sprintf("%s token %s owned on ethereum", step, token), ethOwner == t.ethUser,
+				"ethereum owner %s, txs [%s]", ethOwner.Hex(), desc)
+		} else {
+			t.res.Assert(fmt.Sprintf("%s token %s owned on palette", step, token), pltOwner == t.pltUser,
+				"palette owner %s, txs [%s]", pltOwner.Hex(), desc)
+			t.res.Assert(fmt.Sprintf("%s token %s released on ethereum", step, token), ethOwner != t.ethUser,
+				"ethereum owner %s, txs [%s]", ethOwner.Hex(), desc)
+		}
+	}
+	return nil
+}
+
+// waitNFTOwner 等待token的owner变为expect, token尚未mint时查询报错, 继续等待
+func waitNFTOwner(desc string, expect common.Address, ownerOf func() (common.Address, error)) error {
+	return poll.Until(desc, config.Conf.CrossChainPollOptions(), func() (bool, error) {
+		cur, err := ownerOf()
+		if err != nil {
+			log.Infof("%s: owner unknown, err: %v", desc, err)
+			return false, nil
+		}
+		log.Infof("%s: current owner %s, expect %s", desc, cur.Hex(), expect.Hex())
+		return cur == expect, nil
+	})
+}
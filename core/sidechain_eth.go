@@ -21,7 +21,7 @@ import (
 ///////////////////////////////////////////////////////
 func ETHDeployECCD() (succeed bool) {
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	eccd, err := ethOwner.DeployECCDContract()
+	eccd, hash, err := ethOwner.DeployECCDContract()
 	if err != nil {
 		log.Errorf("deploy eccd on ethereum failed, err: %s", err.Error())
 		return
@@ -29,8 +29,8 @@ func ETHDeployECCD() (succeed bool) {
 		log.Infof("deploy eccd %s on ethereum success", eccd.Hex())
 	}
 
-	if err := config.Conf.CrossChain.StoreEthereumECCD(eccd); err != nil {
-		log.Error("store ethereum eccd failed")
+	if err := recordEthereumDeployment(ethOwner, config.ContractECCD, eccd, hash, nil); err != nil {
+		log.Error(err)
 		return
 	}
 
@@ -47,7 +47,7 @@ func ETHDeployECCM() (succeed bool) {
 	}
 	curPkBytes := config.Conf.CrossChain.LoadCurrentBookKeeperBytes()
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	eccm, err := ethOwner.DeployECCMContract(eccd, sideChainID, whiteList, curPkBytes)
+	eccm, hash, err := ethOwner.DeployECCMContract(eccd, sideChainID, whiteList, curPkBytes)
 	if err != nil {
 		log.Errorf("deploy eccm on ethereum failed, err: %s", err.Error())
 		return
@@ -55,8 +55,9 @@ func ETHDeployECCM() (succeed bool) {
 		log.Infof("deploy eccm %s on ethereum success, eecd %s", eccm.Hex(), eccd.Hex())
 	}
 
-	if err := config.Conf.CrossChain.StoreEthereumECCM(eccm); err != nil {
-		log.Error("store ethereum eccm failed")
+	bindings := map[string]common.Address{config.ContractECCD: eccd}
+	if err := recordEthereumDeployment(ethOwner, config.ContractECCM, eccm, hash, bindings); err != nil {
+		log.Error(err)
 		return
 	}
 
@@ -66,7 +67,7 @@ func ETHDeployECCM() (succeed bool) {
 func ETHDeployCCMP() (succeed bool) {
 	eccm := config.Conf.CrossChain.EthereumECCM
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	ccmp, err := ethOwner.DeployCCMPContract(eccm)
+	ccmp, hash, err := ethOwner.DeployCCMPContract(eccm)
 	if err != nil {
 		log.Errorf("deploy ccmp on ethereum failed, err: %s", err.Error())
 		return
@@ -74,8 +75,9 @@ func ETHDeployCCMP() (succeed bool) {
 		log.Infof("deploy ccmp %s on ethereum success, eccm %s", ccmp.Hex(), eccm.Hex())
 	}
 
-	if err := config.Conf.CrossChain.StoreEthereumCCMP(ccmp); err != nil {
-		log.Error("store ethereum ccmp failed")
+	bindings := map[string]common.Address{config.ContractECCM: eccm}
+	if err := recordEthereumDeployment(ethOwner, config.ContractCCMP, ccmp, hash, bindings); err != nil {
+		log.Error(err)
 		return
 	}
 
@@ -110,6 +112,7 @@ func ETHTransferECCDOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer eccd %s ownership to eccm %s on ethereum success, tx %s", eccd.Hex(), eccm.Hex(), hash.Hex())
+	recordOwner(config.ChainEthereum, config.ContractECCD, eccm)
 	return true
 }
 
@@ -141,6 +144,7 @@ func ETHTransferECCMOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer eccm %s ownership to ccmp %s on ethereum success, tx %s", eccm.Hex(), ccmp.Hex(), hash.Hex())
+	recordOwner(config.ChainEthereum, config.ContractECCM, ccmp)
 	return true
 }
 
@@ -172,6 +176,7 @@ func ETHTransferCCMPOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer ccmp %s ownership to new owner %s on ethereum success, tx %s", ccmp.Hex(), newOwner.Hex(), hash.Hex())
+	recordOwner(config.ChainEthereum, config.ContractCCMP, newOwner)
 	return true
 }
 
@@ -236,7 +241,7 @@ func ETHApproveRegisterSideChain() (succeed bool) {
 
 func ETHDeployPLTAsset() (succeed bool) {
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	pltAsset, err := ethOwner.DeployPLTAsset()
+	pltAsset, hash, err := ethOwner.DeployPLTAsset()
 	if err != nil {
 		log.Errorf("deploy PLT asset on ethereum failed, err: %s", err)
 		return
@@ -244,8 +249,8 @@ func ETHDeployPLTAsset() (succeed bool) {
 
 	log.Infof("deploy PLT asset %s on ethereum success!", pltAsset.Hex())
 
-	if err := config.Conf.CrossChain.StoreEthereumPLTAsset(pltAsset); err != nil {
-		log.Error("store ethereum plt asset failed")
+	if err := recordEthereumDeployment(ethOwner, config.ContractPLTAsset, pltAsset, hash, nil); err != nil {
+		log.Error(err)
 		return
 	}
 	return true
@@ -253,7 +258,7 @@ func ETHDeployPLTAsset() (succeed bool) {
 
 func ETHDeployPLTProxy() (succeed bool) {
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	proxy, err := ethOwner.DeployPLTLockProxy()
+	proxy, hash, err := ethOwner.DeployPLTLockProxy()
 	if err != nil {
 		log.Errorf("deploy PLT proxy on ethereum failed, err: %s", err)
 		return
@@ -261,8 +266,8 @@ func ETHDeployPLTProxy() (succeed bool) {
 		log.Infof("deploy PLT proxy %s on ethereum success!", proxy.Hex())
 	}
 
-	if err := config.Conf.CrossChain.StoreEthereumPLTProxy(proxy); err != nil {
-		log.Error("store ethereum plt proxy failed")
+	if err := recordEthereumDeployment(ethOwner, config.ContractPLTProxy, proxy, hash, nil); err != nil {
+		log.Error(err)
 		return
 	}
 
@@ -298,6 +303,7 @@ func ETHBindPLTProxy() (succeed bool) {
 	}
 
 	log.Infof("bind PLT proxy %s to %s on ethereum success, hash %s", localLockProxy.Hex(), targetLockProxy.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractPLTProxy, proxyBindingKey(targetSideChainID), targetLockProxy)
 	return true
 }
 
@@ -331,6 +337,7 @@ func ETHBindPLTAsset() (succeed bool) {
 	}
 
 	log.Infof("bind PLT asset %s to %s on ethereum success, hash %s", fromAsset.Hex(), toAsset.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractPLTProxy, assetBindingKey(fromAsset, toChainId), toAsset)
 	return true
 }
 
@@ -362,6 +369,7 @@ func ETHSetPLTCCMP() (succeed bool) {
 	}
 
 	log.Infof("register PLT proxy %s to ccmp %s on ethereum success, tx %s", proxy.Hex(), ccmp.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractPLTProxy, config.ContractCCMP, ccmp)
 	return true
 }
 
@@ -397,7 +405,7 @@ func ETHDeployNFTAsset() (succeed bool) {
 
 func ETHDeployNFTProxy() (succeed bool) {
 	ethOwner := getEthereumCli(ethCTypeOwner)
-	proxy, err := ethOwner.DeployNFTLockProxy()
+	proxy, hash, err := ethOwner.DeployNFTLockProxy()
 	if err != nil {
 		log.Errorf("deploy nft lock proxy on ethereum failed, err: %s", err.Error())
 		return
@@ -405,8 +413,8 @@ func ETHDeployNFTProxy() (succeed bool) {
 		log.Infof("deploy NFT lock proxy %s on ethereum success!", proxy.Hex())
 	}
 
-	if err := config.Conf.CrossChain.StoreEthereumNFTProxy(proxy); err != nil {
-		log.Error("save ethereum nft proxy failed")
+	if err := recordEthereumDeployment(ethOwner, config.ContractNFTProxy, proxy, hash, nil); err != nil {
+		log.Error(err)
 		return
 	}
 	return true
@@ -440,6 +448,7 @@ func ETHSetNFTCCMP() (succeed bool) {
 	}
 
 	log.Infof("register NFT proxy %s to ccmp %s on ethereum success, tx %s", proxy.Hex(), ccmp.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractNFTProxy, config.ContractCCMP, ccmp)
 	return true
 }
 
@@ -472,6 +481,7 @@ func ETHBindNFTProxy() (succeed bool) {
 	}
 
 	log.Infof("bind NFT proxy %s to %s on ethereum success, tx %s", localLockProxy.Hex(), targetLockProxy.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractNFTProxy, proxyBindingKey(targetSideChainID), targetLockProxy)
 	return true
 }
 
@@ -519,6 +529,7 @@ func ETHBindNFTAsset() (succeed bool) {
 	}
 
 	log.Infof("bind NFT asset %s to %s on ethereum success, hash %s", fromAsset.Hex(), toAsset.Hex(), hash.Hex())
+	recordBinding(config.ChainEthereum, config.ContractNFTProxy, assetBindingKey(fromAsset, chainID), toAsset)
 	return true
 }
 
@@ -539,7 +550,11 @@ func ETHTransferPLTAssetOwnership() (succeed bool) {
 		return
 	}
 
-	newEthOwner := customEthereumCli(newOwner)
+	newEthOwner, err := customEthereumCli(newOwner)
+	if err != nil {
+		log.Error(err)
+		return
+	}
 	hash2, err := newEthOwner.AcceptOwnership(asset)
 	if err != nil {
 		log.Errorf("accept plt asset ownership to eccm on ethereum failed, err: %s", err.Error())
@@ -557,6 +572,7 @@ func ETHTransferPLTAssetOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer plt asset ownership to eccm on ethereum success, transfer ownership tx %s, accept ownership tx %s", hash1.Hex(), hash2.Hex())
+	recordOwner(config.ChainEthereum, config.ContractPLTAsset, newOwner)
 	return true
 }
 
@@ -588,6 +604,7 @@ func ETHTransferPLTProxyOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer plt proxy ownership to eccm on ethereum success, tx %s", hash.Hex())
+	recordOwner(config.ChainEthereum, config.ContractPLTProxy, newOwner)
 	return true
 }
 
@@ -619,6 +636,7 @@ func ETHTransferNFTProxyOwnership() (succeed bool) {
 	}
 
 	log.Infof("transfer nft proxy ownership to eccm on ethereum success, tx %s", hash.Hex())
+	recordOwner(config.ChainEthereum, config.ContractNFTProxy, newOwner)
 	return true
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
 	"github.com/palettechain/onRobot/pkg/poly"
 	"github.com/palettechain/onRobot/pkg/sdk"
@@ -28,25 +29,24 @@ import (
 // 2. palette native PLT unlock 取出ccmp地址，并进入该合约查询eccm地址，比较从relayer过来的eccm地址与该地址是否匹配
 // 3. 进入unlock资金逻辑
 
-func PLTDeployECCD() (succeed bool) {
+func PLTDeployECCD(res *frame.Result) error {
 	ccAdmCli := getPaletteCli(pltCTypeCrossChainAdmin)
-	eccd, err := ccAdmCli.DeployECCD()
+	eccd, hash, err := ccAdmCli.DeployECCD()
 	if err != nil {
-		log.Errorf("deploy eccd on palette failed, err: %s", err.Error())
-		return
+		return fmt.Errorf("deploy eccd on palette failed, err: %s", err.Error())
 	}
 
 	log.Infof("deploy eccd %s on palette success!", eccd.Hex())
+	res.Output("address", eccd.Hex())
 
-	if err := config.Conf.CrossChain.StorePaletteECCD(eccd); err != nil {
-		log.Error("store palette eccd failed")
-		return
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractECCD, eccd, hash, nil); err != nil {
+		return err
 	}
 
-	return true
+	return nil
 }
 
-func PLTDeployECCM() (succeed bool) {
+func PLTDeployECCM(res *frame.Result) error {
 	eccd := config.Conf.CrossChain.PaletteECCD
 	sideChainID := config.Conf.CrossChain.PaletteSideChainID
 	ccAdmCli := getPaletteCli(pltCTypeCrossChainAdmin)
@@ -56,39 +56,39 @@ func PLTDeployECCM() (succeed bool) {
 		config.Conf.CrossChain.PaletteNFTProxy,
 	}
 	curPkBytes := config.Conf.CrossChain.LoadCurrentBookKeeperBytes()
-	eccm, err := ccAdmCli.DeployECCM(eccd, sideChainID, whiteList, curPkBytes)
+	eccm, hash, err := ccAdmCli.DeployECCM(eccd, sideChainID, whiteList, curPkBytes)
 	if err != nil {
-		log.Errorf("deploy eccm on palette failed, err: %s", err.Error())
-		return
+		return fmt.Errorf("deploy eccm on palette failed, err: %s", err.Error())
 	}
 
 	log.Infof("deploy eccm %s on palette success!", eccm.Hex())
+	res.Output("address", eccm.Hex())
 
-	if err := config.Conf.CrossChain.StorePaletteECCM(eccm); err != nil {
-		log.Error("store palette eccm failed")
-		return
+	bindings := map[string]common.Address{config.ContractECCD: eccd}
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractECCM, eccm, hash, bindings); err != nil {
+		return err
 	}
 
-	return true
+	return nil
 }
 
-func PLTDeployCCMP() (succeed bool) {
+func PLTDeployCCMP(res *frame.Result) error {
 	eccm := config.Conf.CrossChain.PaletteECCM
 	ccAdmCli := getPaletteCli(pltCTypeCrossChainAdmin)
-	ccmp, err := ccAdmCli.DeployCCMP(eccm)
+	ccmp, hash, err := ccAdmCli.DeployCCMP(eccm)
 	if err != nil {
-		log.Errorf("deploy ccmp on palette failed, err: %s", err.Error())
-		return
+		return fmt.Errorf("deploy ccmp on palette failed, err: %s", err.Error())
 	}
 
 	log.Infof("deploy ccmp %s on palette success!", ccmp.Hex())
+	res.Output("address", ccmp.Hex())
 
-	if err := config.Conf.CrossChain.StorePaletteCCMP(ccmp); err != nil {
-		log.Error("store palette ccmp failed")
-		return
+	bindings := map[string]common.Address{config.ContractECCM: eccm}
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractCCMP, ccmp, hash, bindings); err != nil {
+		return err
 	}
 
-	return true
+	return nil
 }
 
 func PLTTransferECCDOwnerShip() (succeed bool) {
@@ -117,6 +117,7 @@ func PLTTransferECCDOwnerShip() (succeed bool) {
 		return
 	}
 	log.Infof("transfer eccd %s to eccm %s success! hash %s", eccd.Hex(), eccm.Hex(), hash.Hex())
+	recordOwner(config.ChainPalette, config.ContractECCD, eccm)
 
 	return true
 }
@@ -147,6 +148,7 @@ func PLTTransferECCMOwnerShip() (succeed bool) {
 		return
 	}
 	log.Infof("transfer eccm %s to ccmp %s success! hash %s", eccm.Hex(), ccmp.Hex(), hash.Hex())
+	recordOwner(config.ChainPalette, config.ContractECCM, ccmp)
 
 	return true
 }
@@ -177,6 +179,7 @@ func PLTTransferCCMPOwnerShip() (succeed bool) {
 		return
 	}
 	log.Infof("transfer ccmp %s to new owner %s success! hash %s", ccmp.Hex(), actual.Hex(), hash.Hex())
+	recordOwner(config.ChainPalette, config.ContractCCMP, actual)
 
 	return true
 }
@@ -207,6 +210,7 @@ func PLTTransferNFTProxyOwnership() (succeed bool) {
 		return
 	}
 	log.Infof("transfer nft proxy %s to new owner %s success! hash %s", proxy.Hex(), newOwner.Hex(), hash.Hex())
+	recordOwner(config.ChainPalette, config.ContractNFTProxy, newOwner)
 	return true
 }
 
@@ -386,7 +390,7 @@ func PLTApproveRegisterSideChain() (succeed bool) {
 
 func PLTDeployNFTProxy() (succeed bool) {
 	ccAdmCli := getPaletteCli(pltCTypeCrossChainAdmin)
-	proxy, err := ccAdmCli.DeployNFTProxy()
+	proxy, hash, err := ccAdmCli.DeployNFTProxy()
 	if err != nil {
 		log.Errorf("deploy NFT proxy on palette failed, err: %s", err.Error())
 		return
@@ -394,8 +398,8 @@ func PLTDeployNFTProxy() (succeed bool) {
 
 	log.Infof("deploy NFT proxy %s on palette success!", proxy.Hex())
 
-	if err := config.Conf.CrossChain.StorePaletteNFTProxy(proxy); err != nil {
-		log.Error("store palette nft proxy failed")
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractNFTProxy, proxy, hash, nil); err != nil {
+		log.Error(err)
 		return
 	}
 
@@ -431,6 +435,7 @@ func PLTBindNFTProxy() (succeed bool) {
 	}
 
 	log.Infof("bind NFT proxy %s to %s on palette success! hash %s", localLockproxy.Hex(), targetLockProxy.Hex(), hash.Hex())
+	recordBinding(config.ChainPalette, config.ContractNFTProxy, proxyBindingKey(targetSideChainID), targetLockProxy)
 	return true
 }
 
@@ -461,6 +466,7 @@ func PLTSetNFTCCMP() (succeed bool) {
 		return
 	}
 	log.Infof("set NFT proxy manager %s for nft proxy %s on palette success! hash %s", actual.Hex(), proxy.Hex(), hash.Hex())
+	recordBinding(config.ChainPalette, config.ContractNFTProxy, config.ContractCCMP, ccmp)
 	return true
 }
 
@@ -599,6 +605,7 @@ func PLTBindNFTAsset() (succeed bool) {
 	}
 
 	log.Infof("bind NFT asset %s to %s on palette success, hash %s", fromAsset.Hex(), toAsset.Hex(), hash.Hex())
+	recordBinding(config.ChainPalette, config.ContractNFTProxy, assetBindingKey(fromAsset, targetSideChainID), toAsset)
 	return true
 }
 
@@ -626,6 +633,11 @@ func PLTUpgradeECCM() (succeed bool) {
 		return
 	}
 	log.Infof("new eccm contract %s", eccmAddr.Hex())
+	bindings := map[string]common.Address{config.ContractECCD: eccdAddr}
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractECCM, eccmAddr, common.Hash{}, bindings); err != nil {
+		log.Error(err)
+		return
+	}
 
 	// eccd contract transfer ownership
 	{
@@ -684,10 +696,12 @@ func PLTUpgradeECCM() (succeed bool) {
 		log.Infof("unpause tx %s", hash.Hex())
 	}
 
-	// record contracts address
+	// record contracts relationship
 	{
+		recordOwner(config.ChainPalette, config.ContractECCD, eccmAddr)
+		recordOwner(config.ChainPalette, config.ContractECCM, ccmpAddr)
+		recordBinding(config.ChainPalette, config.ContractCCMP, config.ContractECCM, eccmAddr)
 		log.Infof(" {\n\teccd: %s\n\teccm: %s\n\tccmp: %s\n}", eccdAddr.Hex(), eccmAddr.Hex(), ccmpAddr.Hex())
-		log.Info("record these address in config.json NOW!")
 	}
 
 	return true
@@ -942,9 +956,9 @@ func PLTChangeBookKeepers() (succeed bool) {
 			log.Error(err)
 			return
 		}
-		PLTLock()
+		PLTLock(frame.NewResult())
 		wait(1)
-		PLTLock()
+		PLTLock(frame.NewResult())
 		if err := checkValidators(false); err != nil {
 			log.Error(err)
 			return
@@ -959,13 +973,13 @@ func PLTChangeBookKeepers() (succeed bool) {
 			log.Error(err)
 			return
 		}
-		PLTLock()
+		PLTLock(frame.NewResult())
 		wait(config.Conf.RewardEffectivePeriod + 2)
 		if err := checkValidators(true); err != nil {
 			log.Error(err)
 			return
 		}
-		PLTLock()
+		PLTLock(frame.NewResult())
 	}
 
 	// 5.revoke stake
@@ -1034,7 +1048,7 @@ func PolyChangeBookKeepers() (succeed bool) {
 	wait(5)
 
 	// 3. lock
-	PLTLock()
+	PLTLock(frame.NewResult())
 
 	// 4. quit node
 	if err := cli.QuitNode(node); err != nil {
@@ -1046,7 +1060,7 @@ func PolyChangeBookKeepers() (succeed bool) {
 	wait(5)
 
 	// 5. lock
-	PLTLock()
+	PLTLock(frame.NewResult())
 
 	return true
 }
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/signer"
+)
+
+// SignerStub 启动本地clef风格签名服务, 用于在测试网验证remote signer配置.
+// 1.从palette及ethereum keystore目录加载账户私钥
+// 2.监听`Listen`地址并提供`account_list`, `account_signTransaction`接口, 交易不经确认直接签名
+// 3.收到SIGINT/SIGTERM后退出
+func SignerStub() (succeed bool) {
+	var params struct {
+		Listen           string // 监听地址, e.g: 127.0.0.1:8550
+		ChainID          uint64 // 大于0时使用eip155签名, 否则使用homestead签名
+		PaletteAccounts  []common.Address
+		EthereumAccounts []common.Address
+	}
+
+	if err := config.LoadParams("SignerStub.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	signers := make([]signer.Signer, 0, len(params.PaletteAccounts)+len(params.EthereumAccounts))
+	for _, addr := range params.PaletteAccounts {
+		key, err := config.LoadPaletteAccount(addr)
+		if err != nil {
+			log.Errorf("load palette account %s failed, err: %v", addr.Hex(), err)
+			return
+		}
+		s, err := signer.NewKeySigner(key)
+		if err != nil {
+			log.Errorf("load palette account %s failed, err: %v", addr.Hex(), err)
+			return
+		}
+		signers = append(signers, s)
+	}
+	for _, addr := range params.EthereumAccounts {
+		key, err := config.Conf.CrossChain.CustomLoadEthAccount(addr, "")
+		if err != nil {
+			log.Errorf("load ethereum account %s failed, err: %v", addr.Hex(), err)
+			return
+		}
+		s, err := signer.NewKeySigner(key)
+		if err != nil {
+			log.Errorf("load ethereum account %s failed, err: %v", addr.Hex(), err)
+			return
+		}
+		signers = append(signers, s)
+	}
+
+	var chainID *big.Int
+	if params.ChainID > 0 {
+		chainID = new(big.Int).SetUint64(params.ChainID)
+	}
+	stub, err := signer.NewStub(chainID, signers...)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	defer stub.Stop()
+
+	srv := &http.Server{Addr: params.Listen, Handler: stub}
+	logger := log.Current()
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("signer stub stopped, err: %v", err)
+		}
+	}()
+
+	log.Infof("signer stub listening on %s with %d accounts, press ctrl+c to stop", params.Listen, len(signers))
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-ch
+	log.Infof("signer stub received signal %s, stopping...", sig)
+	if err := srv.Close(); err != nil {
+		log.Error(err)
+		return
+	}
+	return true
+}
@@ -22,7 +22,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native/plt"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/palettechain/onRobot/config"
 	"github.com/palettechain/onRobot/pkg/log"
 	"github.com/palettechain/onRobot/pkg/sdk"
@@ -30,8 +29,9 @@ import (
 
 func Stable() (succeed bool) {
 	var params struct {
-		Number int
+		Number    int
 		NodeIndex int
+		Role      string // 设置后使用该角色的派生账户, 否则每次生成新账户
 	}
 
 	if err := config.LoadParams("Stable.json", &params); err != nil {
@@ -44,26 +44,22 @@ func Stable() (succeed bool) {
 	}
 
 	num := params.Number
+	accounts, err := roleOrFreshAccounts(params.Role, num)
+	if err != nil {
+		log.Error(err)
+		return
+	}
 	fans := make([]*Fan, num)
 	url := config.Conf.Nodes[0].RPCAddr()
-	for i := 0; i < num; i++ {
-		key, err := crypto.GenerateKey()
-		if err != nil {
-			continue
-		}
-		cli := sdk.NewSender(url, key)
-		addr := crypto.PubkeyToAddress(key.PublicKey)
-		fans[i] = &Fan{Address: addr, Cli: cli}
+	for i, acc := range accounts {
+		fans[i] = &Fan{Address: acc.Address, Cli: sdk.NewSender(url, acc.Key)}
 	}
 
 	amount := plt.MultiPLT(10)
-	admcli := getPaletteCli(pltCTypeAdmin)
-	for i := 0; i < num; i++ {
-		if _, err := admcli.PLTTransferWithoutWaiting(fans[i].Address, amount); err != nil {
-			log.Error("admin transfer to fans %s failed, err: %v", fans[i].Address.Hex(), err)
-		}
+	if err := fundAccounts(accounts, amount, 0); err != nil {
+		log.Errorf("admin transfer to fans failed, err: %v", err)
+		return
 	}
-	wait(10)
 
 	// fans delegate
 	nodeIndex := params.NodeIndex
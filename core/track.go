@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/tracker"
+)
+
+// newCrossChainTracker 连接palette, poly及ethereum, 跟踪palette<->ethereum双向的跨链交易
+func newCrossChainTracker() (*tracker.Tracker, error) {
+	cc := config.Conf.CrossChain
+	palette, err := eth.NewTrackChain(groupPalette, cc.PaletteSideChainID, config.Conf.Nodes[0].RPCAddr(), cc.PaletteECCM)
+	if err != nil {
+		return nil, err
+	}
+	ethereum, err := eth.NewTrackChain(groupEthereum, cc.EthereumSideChainID, cc.EthereumRPCUrl, cc.EthereumECCM)
+	if err != nil {
+		return nil, err
+	}
+	polyCli, err := poly.NewPolyClient(cc.PolyRPCAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect poly %s failed, err: %v", cc.PolyRPCAddress, err)
+	}
+	return tracker.New(poly.NewTrackRelay(polyCli), palette, ethereum), nil
+}
+
+// crossChainTrack 在lock之前记录poly及目标链的高度, lock之后用于跟踪交易. tracker不可用时只打印日志,
+// 不影响lock用例本身
+type crossChainTrack struct {
+	tk    *tracker.Tracker
+	start tracker.Start
+}
+
+func startCrossChainTrack(toChainID uint64) *crossChainTrack {
+	tk, err := newCrossChainTracker()
+	if err != nil {
+		log.Warnf("cross chain tracker unavailable, err: %v", err)
+		return nil
+	}
+	start, err := tk.Start(toChainID)
+	if err != nil {
+		log.Warnf("cross chain tracker unavailable, err: %v", err)
+		return nil
+	}
+	return &crossChainTrack{tk: tk, start: start}
+}
+
+// report 跟踪一次源链交易并打印每一跳, 返回跨链交易停止的位置, 交易已到达目标链时返回空字符串
+func (t *crossChainTrack) report(fromChainID uint64, hash common.Hash) (trace *tracker.Trace, stopped string) {
+	if t == nil {
+		return nil, "unknown"
+	}
+	trace, err := t.tk.Track(fromChainID, hash.Hex(), t.start)
+	if trace == nil {
+		log.Warnf("track cross chain tx %s failed, err: %v", hash.Hex(), err)
+		return nil, "unknown"
+	}
+	log.Infof("track %s", trace)
+	if hop := trace.StoppedAt(); hop != nil {
+		return trace, fmt.Sprintf("%s on %s", hop.Name, hop.Chain)
+	}
+	return trace, ""
+}
+
+// recordTraceMetrics 记录源链到poly以及poly到目标链的耗时
+func recordTraceMetrics(res *frame.Result, trace *tracker.Trace) {
+	if trace == nil || !trace.Completed() {
+		return
+	}
+	source, proof, target := trace.Hop(tracker.HopSource), trace.Hop(tracker.HopPoly), trace.Hop(tracker.HopTarget)
+	res.Metric("source-to-poly", proof.Time.Sub(source.Time).Seconds(), "s")
+	res.Metric("poly-to-target", target.Time.Sub(proof.Time).Seconds(), "s")
+}
+
+// TrackTx 从源链交易hash开始跟踪跨链交易
+// 1. 从源链eccm的CrossChainEvent中解析跨链交易id及目标链, 源链为palette时同时解析PLT proxy的lock事件
+// 2. 在poly上扫描cross chain manager的makeProof事件, 找到对应的poly交易
+// 3. 在目标链上扫描eccm的VerifyHeaderAndExecuteTxEvent, 找到执行该跨链交易的目标链交易
+// 4. 打印每一跳的交易hash, 区块高度及时间, 交易未到达目标链时用例失败并指出停止的位置
+// 未指定起始高度时从最新区块往前扫描Window个区块, Wait为true时持续跟踪直到到达目标链或超时
+func TrackTx(res *frame.Result) error {
+	var params struct {
+		Chain       string // 源链, palette或ethereum
+		Hash        common.Hash
+		PolyStart   uint64
+		TargetStart uint64
+		Window      uint64
+		Wait        bool
+	}
+	if err := config.LoadParams("TrackTx.json", &params); err != nil {
+		return err
+	}
+
+	cc := config.Conf.CrossChain
+	var fromChainID uint64
+	switch strings.ToLower(params.Chain) {
+	case groupPalette:
+		fromChainID = cc.PaletteSideChainID
+		if fromAsset, _, toAsset, toAddress, chainID, amount, err := getPaletteCli(pltCTypeCustomer).GetPaletteLockEvent(params.Hash); err == nil {
+			log.Infof("plt lock %s => chain %d asset %s, to %s, amount %s", fromAsset.Hex(), chainID, toAsset.Hex(), toAddress.Hex(), amount)
+		}
+	case groupEthereum:
+		fromChainID = cc.EthereumSideChainID
+	default:
+		return fmt.Errorf("invalid source chain %s, it should be palette or ethereum", params.Chain)
+	}
+
+	tk, err := newCrossChainTracker()
+	if err != nil {
+		return err
+	}
+	if params.Window > 0 {
+		tk.Window = params.Window
+	}
+	start := tracker.Start{Poly: params.PolyStart, Target: params.TargetStart}
+
+	var trace *tracker.Trace
+	if params.Wait {
+		trace, err = tk.Wait(fromChainID, params.Hash.Hex(), start, config.Conf.CrossChainPollOptions())
+	} else {
+		trace, err = tk.Track(fromChainID, params.Hash.Hex(), start)
+	}
+	if trace == nil {
+		return err
+	}
+	log.Infof("track %s", trace)
+
+	for _, hop := range trace.Hops {
+		res.Assert(fmt.Sprintf("%s %s", hop.Name, hop.Chain), hop.Found, "not found, err: %v", hop.Err)
+		if hop.Found {
+			res.Output(hop.Name, hop.TxHash)
+		}
+	}
+	if trace.Completed() {
+		res.Metric("cross-chain-duration", trace.Duration().Seconds(), "s")
+		recordTraceMetrics(res, trace)
+	}
+	return nil
+}
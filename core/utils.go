@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -15,9 +16,12 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/palettechain/onRobot/config"
 	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poll"
 	"github.com/palettechain/onRobot/pkg/sdk"
 	"github.com/palettechain/onRobot/pkg/shell"
+	"github.com/palettechain/onRobot/pkg/signer"
 )
 
 type cliType uint8
@@ -36,18 +40,18 @@ func getPaletteCli(typ cliType) (cli *sdk.Client) {
 	switch typ {
 	case pltCTypeCustomer:
 		addr := config.Conf.Accounts[0]
-		key, err := config.LoadPaletteAccount(addr)
+		s, err := config.LoadPaletteSigner(addr)
 		if err != nil {
 			panic(fmt.Sprintf("load palette account err: %s", err.Error()))
 		}
-		cli = sdk.NewSender(url, key)
+		cli = sdk.NewSignerSender(url, s)
 	case pltCTypeInvoker:
 		node := config.Conf.ValidatorNodes()[0]
 		cli = sdk.NewSender(url, node.PrivateKey())
 	case pltCTypeAdmin:
-		cli = sdk.NewSender(url, config.AdminKey)
+		cli = sdk.NewSignerSender(url, config.AdminSigner)
 	case pltCTypeCrossChainAdmin:
-		cli = sdk.NewSender(url, config.CrossChainAdminKey)
+		cli = sdk.NewSignerSender(url, config.CrossChainAdminSigner)
 	}
 	return
 }
@@ -57,14 +61,14 @@ func getEthereumCli(typ cliType) (cli *eth.EthInvoker) {
 	url := config.Conf.CrossChain.EthereumRPCUrl
 	switch typ {
 	case ethCTypeInvoker:
-		if priv, err := config.Conf.CrossChain.LoadETHAccount(); err == nil {
-			cli = eth.NewEInvoker(chainID, url, priv)
+		if s, err := config.Conf.CrossChain.LoadETHSigner(); err == nil {
+			cli = eth.NewSignerInvoker(chainID, url, s)
 		} else {
 			panic(fmt.Sprintf("load eth account err: %s", err.Error()))
 		}
 	case ethCTypeOwner:
-		if priv, err := config.Conf.CrossChain.LoadETHOwner(); err == nil {
-			cli = eth.NewEInvoker(chainID, url, priv)
+		if s, err := config.Conf.CrossChain.LoadETHOwnerSigner(); err == nil {
+			cli = eth.NewSignerInvoker(chainID, url, s)
 		} else {
 			panic(fmt.Sprintf("load eth owner err: %s", err.Error()))
 		}
@@ -72,15 +76,14 @@ func getEthereumCli(typ cliType) (cli *eth.EthInvoker) {
 	return
 }
 
-func customEthereumCli(account common.Address) (cli *eth.EthInvoker) {
-	chainID := config.Conf.CrossChain.EthereumSideChainID
-	url := config.Conf.CrossChain.EthereumRPCUrl
-	if priv, err := config.Conf.CrossChain.CustomLoadEthAccount(account, ""); err == nil {
-		cli = eth.NewEInvoker(chainID, url, priv)
-	} else {
-		panic(fmt.Sprintf("load eth account err: %s", err.Error()))
+// customEthereumCli returns the ethereum invoker signed by account, which is loaded from `Signers` or
+// the ethereum keystore dir.
+func customEthereumCli(account common.Address) (*eth.EthInvoker, error) {
+	s, err := config.Conf.CrossChain.CustomLoadEthSigner(account, "")
+	if err != nil {
+		return nil, fmt.Errorf("load eth account %s err: %v", account.Hex(), err)
 	}
-	return
+	return eth.NewSignerInvoker(config.Conf.CrossChain.EthereumSideChainID, config.Conf.CrossChain.EthereumRPCUrl, s), nil
 }
 
 func gc() {
@@ -88,8 +91,33 @@ func gc() {
 	//config.Conf = config.BakConf.DeepCopy()
 }
 
+// wait for n new blocks, and fallback to sleep if the node is unreachable.
 func wait(nBlock int) {
-	time.Sleep(time.Duration(config.Conf.BlockPeriod) * time.Duration(nBlock))
+	if nBlock <= 0 {
+		return
+	}
+	cli := sdk.NewReader(config.Conf.Rpc)
+	if err := cli.WaitBlocks(uint64(nBlock), config.Conf.BlocksPollOptions(nBlock)); err != nil {
+		log.Warnf("wait %d blocks failed, err: %v", nBlock, err)
+		if !poll.IsTimeout(err) {
+			time.Sleep(time.Duration(config.Conf.BlockPeriod) * time.Duration(nBlock))
+		}
+	}
+}
+
+// waitBalanceDelta wait until `balanceOf() - before` equals to delta, it's used to check balances on both
+// of palette and ethereum chain after cross chain transfer.
+func waitBalanceDelta(desc string, before, delta *big.Int, balanceOf func() (*big.Int, error)) error {
+	expect := new(big.Int).Add(before, delta)
+	return poll.Until(desc, config.Conf.CrossChainPollOptions(), func() (bool, error) {
+		cur, err := balanceOf()
+		if err != nil {
+			return false, err
+		}
+		log.Infof("%s: balance before [%d], current [%d], expect [%d]",
+			desc, plt.PrintUPLT(before), plt.PrintUPLT(cur), plt.PrintUPLT(expect))
+		return cur.Cmp(expect) == 0, nil
+	})
 }
 
 func BlockNumber2Hex(data uint64) string {
@@ -247,7 +275,38 @@ func customLoadAccount(addr common.Address) *ecdsa.PrivateKey {
 		}
 	}
 
-	return nil
+	return loadRoleKey(addr)
+}
+
+// customLoadSigner prefer the signer configured in `Signers`, e.g: remote signer for mainnet owners.
+func customLoadSigner(addr common.Address) (signer.Signer, error) {
+	if s, err := config.LoadPaletteSigner(addr); err == nil {
+		return s, nil
+	}
+	s, err := signer.NewKeySigner(customLoadAccount(addr))
+	if err != nil {
+		return nil, fmt.Errorf("load palette account %s err: %v", addr.Hex(), err)
+	}
+	return s, nil
+}
+
+// customPaletteCli returns the palette client signed by account, see `customLoadSigner`.
+func customPaletteCli(addr common.Address) (*sdk.Client, error) {
+	s, err := customLoadSigner(addr)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.NewSignerSender(config.Conf.Nodes[0].RPCAddr(), s), nil
+}
+
+// assertReverted check that the tx is rejected by contract and the decoded revert reason contains `substr`,
+// failures such as insufficient funds, nonce or network error are not counted as rejection.
+func assertReverted(res *frame.Result, name string, err error, substr string) bool {
+	reason, ok := sdk.RevertReason(err)
+	if ok {
+		log.Infof("%s, revert reason: %s", name, reason)
+	}
+	return res.Assert(name, ok && strings.Contains(reason, substr), "expect revert reason contains %q, err: %v", substr, err)
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////
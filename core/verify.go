@@ -0,0 +1,219 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/native"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/eth"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/poly"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// BridgeCheck is one cell of the bridge verification matrix.
+type BridgeCheck struct {
+	Chain  string // palette or ethereum, poly checks belong to the registered side chain
+	Name   string
+	Expect string
+	Actual string
+	Err    error
+}
+
+func (c *BridgeCheck) Passed() bool {
+	return c.Err == nil && strings.EqualFold(c.Expect, c.Actual)
+}
+
+func (c *BridgeCheck) String() string {
+	result := "PASS"
+	if !c.Passed() {
+		result = "FAIL"
+	}
+	s := fmt.Sprintf("%s %-8s %s: expect %s, actual %s", result, c.Chain, c.Name, c.Expect, c.Actual)
+	if c.Err != nil {
+		s += fmt.Sprintf(", err: %v", c.Err)
+	}
+	return s
+}
+
+type bridgeChecker struct {
+	checks []*BridgeCheck
+}
+
+func (v *bridgeChecker) address(chain, name string, expect common.Address, getter func() (common.Address, error)) {
+	check := &BridgeCheck{Chain: chain, Name: name, Expect: expect.Hex()}
+	if expect == (common.Address{}) {
+		check.Err = fmt.Errorf("expect address not configured")
+	} else if actual, err := getter(); err != nil {
+		check.Err = err
+	} else {
+		check.Actual = actual.Hex()
+	}
+	v.checks = append(v.checks, check)
+}
+
+var errFinalOwnerNotConfigured = fmt.Errorf("final owner not configured")
+
+// finalOwner check owner against the final owner of chain, `FinalOwner` is optional in config.
+func (v *bridgeChecker) finalOwner(chain, name string, getter func() (common.Address, error)) {
+	fo := config.Conf.FinalOwner
+	if fo == nil {
+		v.checks = append(v.checks, &BridgeCheck{Chain: chain, Name: name, Expect: common.Address{}.Hex(), Err: errFinalOwnerNotConfigured})
+		return
+	}
+	expect := fo.PaletteFinalOwner
+	if chain == groupEthereum {
+		expect = fo.EthereumFinalOwner
+	}
+	v.address(chain, name, expect, getter)
+}
+
+// VerifyBridge 检查palette<->ethereum跨链配置
+// 1. 两条链上eccd的owner为eccm, eccm的owner为ccmp, ccmp的owner为FinalOwner
+// 2. PLT/NFT proxy的ccmp为本链ccmp, proxy及资产的绑定关系对称, 即palette绑定ethereum的proxy, ethereum也绑定palette的proxy
+// 3. PLT资产, proxy及cross chain admin的owner为FinalOwner
+// 4. poly上侧链注册信息中的eccd与配置一致
+// 查询失败同样视为检查失败, 所有检查都会执行. 只使用只读客户端, 不需要加载任何账户
+func VerifyBridge() []*BridgeCheck {
+	cc := config.Conf.CrossChain
+	plt := sdk.NewReader(config.Conf.Rpc)
+	ethCli := eth.NewReader(cc.EthereumSideChainID, cc.EthereumRPCUrl)
+	pltNative := common.HexToAddress(native.PLTContractAddress)
+	v := new(bridgeChecker)
+
+	// ownership chain
+	v.address(groupPalette, "eccd owner is eccm", cc.PaletteECCM, func() (common.Address, error) { return plt.ECCDOwnership(cc.PaletteECCD) })
+	v.address(groupEthereum, "eccd owner is eccm", cc.EthereumECCM, func() (common.Address, error) { return ethCli.ECCDOwnership(cc.EthereumECCD) })
+	v.address(groupPalette, "eccm owner is ccmp", cc.PaletteCCMP, func() (common.Address, error) { return plt.ECCMOwnership(cc.PaletteECCM) })
+	v.address(groupEthereum, "eccm owner is ccmp", cc.EthereumCCMP, func() (common.Address, error) { return ethCli.ECCMOwnership(cc.EthereumECCM) })
+	v.finalOwner(groupPalette, "ccmp owner is final owner", func() (common.Address, error) { return plt.CCMPOwnership(cc.PaletteCCMP) })
+	v.finalOwner(groupEthereum, "ccmp owner is final owner", func() (common.Address, error) { return ethCli.CCMPOwnership(cc.EthereumCCMP) })
+
+	// proxies managed by ccmp
+	v.address(groupPalette, "plt proxy ccmp", cc.PaletteCCMP, func() (common.Address, error) { return plt.GetPLTCCMP("latest") })
+	v.address(groupEthereum, "plt proxy ccmp", cc.EthereumCCMP, func() (common.Address, error) { return ethCli.GetPLTCCMP(cc.EthereumPLTProxy) })
+	v.address(groupPalette, "nft proxy ccmp", cc.PaletteCCMP, func() (common.Address, error) { return plt.GetNFTCCMP(cc.PaletteNFTProxy) })
+	v.address(groupEthereum, "nft proxy ccmp", cc.EthereumCCMP, func() (common.Address, error) { return ethCli.GetNFTCCMP(cc.EthereumNFTProxy) })
+
+	// bindings of both sides
+	v.address(groupPalette, "plt proxy bound to peer", cc.EthereumPLTProxy, func() (common.Address, error) {
+		return plt.GetBindPLTProxy(cc.EthereumSideChainID, "latest")
+	})
+	v.address(groupEthereum, "plt proxy bound to peer", pltNative, func() (common.Address, error) {
+		return ethCli.GetBoundPLTProxy(cc.EthereumPLTProxy, cc.PaletteSideChainID)
+	})
+	v.address(groupPalette, "plt asset bound to peer", cc.EthereumPLTAsset, func() (common.Address, error) {
+		return plt.GetBindPLTAsset(cc.EthereumSideChainID, "latest")
+	})
+	v.address(groupEthereum, "plt asset bound to peer", pltNative, func() (common.Address, error) {
+		return ethCli.GetBoundPLTAsset(cc.EthereumPLTProxy, cc.EthereumPLTAsset, cc.PaletteSideChainID)
+	})
+	v.address(groupPalette, "nft proxy bound to peer", cc.EthereumNFTProxy, func() (common.Address, error) {
+		return plt.GetBoundNFTProxy(cc.PaletteNFTProxy, cc.EthereumSideChainID)
+	})
+	v.address(groupEthereum, "nft proxy bound to peer", cc.PaletteNFTProxy, func() (common.Address, error) {
+		return ethCli.GetBoundNFTProxy(cc.EthereumNFTProxy, cc.PaletteSideChainID)
+	})
+
+	// final owners of assets and proxies
+	v.finalOwner(groupEthereum, "plt asset owner is final owner", func() (common.Address, error) {
+		return ethCli.PLTAssetOwnership(cc.EthereumPLTAsset)
+	})
+	v.finalOwner(groupEthereum, "plt proxy owner is final owner", func() (common.Address, error) {
+		return ethCli.PLTProxyOwnership(cc.EthereumPLTProxy)
+	})
+	v.finalOwner(groupPalette, "nft proxy owner is final owner", func() (common.Address, error) {
+		return plt.NFTProxyOwnership(cc.PaletteNFTProxy)
+	})
+	v.finalOwner(groupEthereum, "nft proxy owner is final owner", func() (common.Address, error) {
+		return ethCli.NFTProxyOwnership(cc.EthereumNFTProxy)
+	})
+	v.finalOwner(groupPalette, "cross chain admin is final owner", func() (common.Address, error) {
+		return plt.CrossChainAdminOwnership("latest")
+	})
+
+	// side chain registration on poly
+	polyCli, polyErr := poly.NewPolyClient(cc.PolyRPCAddress, nil)
+	sideChainECCD := func(chainID uint64) (common.Address, error) {
+		if polyErr != nil {
+			return common.Address{}, polyErr
+		}
+		sideChain, err := polyCli.GetSideChain(chainID)
+		if err != nil {
+			return common.Address{}, err
+		}
+		if sideChain == nil {
+			return common.Address{}, fmt.Errorf("side chain %d not registered", chainID)
+		}
+		return common.BytesToAddress(sideChain.CCMCAddress), nil
+	}
+	v.address(groupPalette, "poly side chain eccd", cc.PaletteECCD, func() (common.Address, error) { return sideChainECCD(cc.PaletteSideChainID) })
+	v.address(groupEthereum, "poly side chain eccd", cc.EthereumECCD, func() (common.Address, error) { return sideChainECCD(cc.EthereumSideChainID) })
+
+	return v.checks
+}
+
+// BridgeMatrix format checks as a pass/fail matrix, rows are checks and columns are chains, the
+// details of failed checks are listed below the matrix.
+func BridgeMatrix(checks []*BridgeCheck) (matrix string, failed int) {
+	chains := []string{groupPalette, groupEthereum}
+	names := make([]string, 0)
+	cells := make(map[string]map[string]string)
+	width := len("check")
+	for _, check := range checks {
+		if _, ok := cells[check.Name]; !ok {
+			names = append(names, check.Name)
+			cells[check.Name] = make(map[string]string)
+			if len(check.Name) > width {
+				width = len(check.Name)
+			}
+		}
+		cells[check.Name][check.Chain] = "PASS"
+		if !check.Passed() {
+			cells[check.Name][check.Chain] = "FAIL"
+			failed++
+		}
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%-*s", width, "check")
+	for _, chain := range chains {
+		fmt.Fprintf(b, "  %-8s", chain)
+	}
+	b.WriteString("\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "%-*s", width, name)
+		for _, chain := range chains {
+			cell, ok := cells[name][chain]
+			if !ok {
+				cell = "-"
+			}
+			fmt.Fprintf(b, "  %-8s", cell)
+		}
+		b.WriteString("\n")
+	}
+	for _, check := range checks {
+		if !check.Passed() {
+			b.WriteString(check.String())
+			b.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(b, "%d checks, %d failed", len(checks), failed)
+	return b.String(), failed
+}
+
+// VerifyBridgeCase 在用例中执行跨链配置检查, 每项检查作为一个断言记录在报告中
+func VerifyBridgeCase(res *frame.Result) error {
+	checks := VerifyBridge()
+	for _, check := range checks {
+		res.Assert(fmt.Sprintf("%s %s", check.Chain, check.Name), check.Passed(),
+			"expect %s, actual %s, err: %v", check.Expect, check.Actual, check.Err)
+	}
+	matrix, failed := BridgeMatrix(checks)
+	log.Infof("verify bridge:\n%s", matrix)
+	res.Metric("failed", float64(failed), "checks")
+	return nil
+}
@@ -20,18 +20,19 @@ func PLTDeployPLTWrap() (succeed bool) {
 	ccAdmCli := getPaletteCli(pltCTypeInvoker)
 	owner := ccAdmCli.Address()
 
-	contractAddr, err := ccAdmCli.DeployPLTWrapper(owner, lockProxy, chainId)
+	contractAddr, hash, err := ccAdmCli.DeployPLTWrapper(owner, lockProxy, chainId)
 	if err != nil {
 		log.Errorf("deploy wrap on palette failed, err: %s", err.Error())
 		return
 	}
 
-	//if err := config.Conf.CrossChain.StorePalettePLTWrapper(contractAddr); err != nil {
-	//	log.Error("store palette wrapper failed")
-	//	return
-	//}
-
 	log.Infof("deploy wrap %s on palette success!", contractAddr.Hex())
+
+	bindings := map[string]common.Address{"lock-proxy": lockProxy}
+	if err := recordPaletteDeployment(ccAdmCli, config.ContractPLTWrapper, contractAddr, hash, bindings); err != nil {
+		log.Error(err)
+		return
+	}
 	return true
 }
 
@@ -48,13 +49,17 @@ func PLTWrapperLock() (succeed bool) {
 	}
 
 	baseUrl := config.Conf.Nodes[0].RPCAddr()
-	privKey := customLoadAccount(params.From)
-	cli := sdk.NewSender(baseUrl, privKey)
+	s, err := customLoadSigner(params.From)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	cli := sdk.NewSignerSender(baseUrl, s)
 	from := cli.Address()
-	invoker := eth.NewEInvoker(
+	invoker := eth.NewSignerInvoker(
 		config.Conf.CrossChain.EthereumSideChainID,
 		config.Conf.CrossChain.EthereumRPCUrl,
-		customLoadAccount(from),
+		s,
 	)
 
 	wrapAddr := config.Conf.CrossChain.PalettePLTWrapper
@@ -176,8 +181,12 @@ func NFTWrapLock() (succeed bool) {
 
 	// generate new sender
 	baseUrl := config.Conf.Nodes[0].RPCAddr()
-	privKey := customLoadAccount(from)
-	cli := sdk.NewSender(baseUrl, privKey)
+	s, err := customLoadSigner(from)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	cli := sdk.NewSignerSender(baseUrl, s)
 	ethInvoker := getEthereumCli(ethCTypeInvoker)
 
 	// mint or transfer ownership
@@ -0,0 +1,537 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/native"
+	"github.com/ethereum/go-ethereum/contracts/native/utils"
+	"github.com/palettechain/onRobot/config"
+	"github.com/palettechain/onRobot/pkg/frame"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/polywrap"
+	"github.com/palettechain/onRobot/pkg/sdk"
+)
+
+// wrapperAdmin is implemented by both of `sdk.Client` and `eth.EthInvoker`.
+type wrapperAdmin interface {
+	Address() common.Address
+	WrapperSetFeeCollector(kind string, wrapAddr, collector common.Address) (common.Hash, error)
+	WrapperSetLockProxy(kind string, wrapAddr, lockProxy common.Address) (common.Hash, error)
+	WrapperPause(kind string, wrapAddr common.Address) (common.Hash, error)
+	WrapperUnpause(kind string, wrapAddr common.Address) (common.Hash, error)
+	WrapperExtractFee(kind string, wrapAddr, token common.Address) (common.Hash, error)
+	WrapperSpeedUp(kind string, wrapAddr, token common.Address, lockTx common.Hash, fee *big.Int) (common.Hash, error)
+	WrapperFeeCollector(kind string, wrapAddr common.Address) (common.Address, error)
+	WrapperLockProxy(kind string, wrapAddr common.Address) (common.Address, error)
+	WrapperPaused(kind string, wrapAddr common.Address) (bool, error)
+	WrapperLockEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.LockEvent, error)
+	WrapperSpeedUpEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.SpeedUpEvent, error)
+	FindWrapperLock(kind string, wrapAddr common.Address, start uint64, id *big.Int) (*polywrap.LockEvent, error)
+}
+
+func wrapperAddress(chain, kind string) common.Address {
+	cc := config.Conf.CrossChain
+	switch {
+	case chain == config.ChainPalette && kind == polywrap.KindPLT:
+		return cc.PalettePLTWrapper
+	case chain == config.ChainPalette && kind == polywrap.KindNFT:
+		return cc.PaletteNFTWrapper
+	case chain == config.ChainEthereum && kind == polywrap.KindPLT:
+		return cc.EthereumPLTWrapper
+	case chain == config.ChainEthereum && kind == polywrap.KindNFT:
+		return cc.EthereumNFTWrapper
+	}
+	return utils.EmptyAddress
+}
+
+// wrapperClient returns the palette client or ethereum invoker of account, the wrapper owner is used if
+// account not specified.
+func wrapperClient(chain string, account common.Address) (wrapperAdmin, error) {
+	switch chain {
+	case config.ChainPalette:
+		if account == utils.EmptyAddress {
+			return getPaletteCli(pltCTypeInvoker), nil
+		}
+		s, err := customLoadSigner(account)
+		if err != nil {
+			return nil, err
+		}
+		return sdk.NewSignerSender(config.Conf.Rpc, s), nil
+	case config.ChainEthereum:
+		if account == utils.EmptyAddress {
+			return getEthereumCli(ethCTypeOwner), nil
+		}
+		return customEthereumCli(account)
+	}
+	return nil, fmt.Errorf("unknown chain %s", chain)
+}
+
+// WrapperAdmin 管理palette或ethereum上的PLT/NFT wrapper
+// 1. Chain为palette或ethereum, Kind为plt或nft, 对应配置中的wrapper地址
+// 2. Action包括status, set-fee-collector, set-lock-proxy, pause, unpause, extract-fee, speed-up, 默认由wrapper owner
+// 发送交易, 指定From时使用该账户, 其中extract-fee只能由fee collector调用
+// 3. speed-up为lock交易追加手续费, 未指定Hash时, 从StartHeight开始查找Id对应的lock交易
+func WrapperAdmin() (succeed bool) {
+	var params struct {
+		Chain       string
+		Kind        string
+		Action      string
+		From        common.Address
+		Address     common.Address
+		Token       common.Address
+		Hash        common.Hash
+		Id          *big.Int
+		StartHeight uint64
+		Fee         *big.Int
+	}
+	if err := config.LoadParams("WrapperAdmin.json", &params); err != nil {
+		log.Error(err)
+		return
+	}
+
+	kind, wrap := params.Kind, wrapperAddress(params.Chain, params.Kind)
+	if wrap == utils.EmptyAddress {
+		log.Errorf("%s %s wrapper not configured", params.Chain, kind)
+		return
+	}
+	cli, err := wrapperClient(params.Chain, params.From)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	var hash common.Hash
+	switch params.Action {
+	case "status":
+		collector, err := cli.WrapperFeeCollector(kind, wrap)
+		if err != nil {
+			log.Errorf("get fee collector failed, err: %v", err)
+			return
+		}
+		proxy, err := cli.WrapperLockProxy(kind, wrap)
+		if err != nil {
+			log.Errorf("get lock proxy failed, err: %v", err)
+			return
+		}
+		paused, err := cli.WrapperPaused(kind, wrap)
+		if err != nil {
+			log.Errorf("get paused failed, err: %v", err)
+			return
+		}
+		log.Infof("%s %s wrapper %s: fee collector %s, lock proxy %s, paused %v",
+			params.Chain, kind, wrap.Hex(), collector.Hex(), proxy.Hex(), paused)
+		return true
+	case "set-fee-collector":
+		hash, err = cli.WrapperSetFeeCollector(kind, wrap, params.Address)
+	case "set-lock-proxy":
+		hash, err = cli.WrapperSetLockProxy(kind, wrap, params.Address)
+	case "pause":
+		hash, err = cli.WrapperPause(kind, wrap)
+	case "unpause":
+		hash, err = cli.WrapperUnpause(kind, wrap)
+	case "extract-fee":
+		hash, err = cli.WrapperExtractFee(kind, wrap, params.Token)
+	case "speed-up":
+		lockTx, token := params.Hash, params.Token
+		if lockTx == (common.Hash{}) {
+			if params.Id == nil {
+				log.Errorf("speed up need lock tx hash or id")
+				return
+			}
+			evt, err := cli.FindWrapperLock(kind, wrap, params.StartHeight, params.Id)
+			if err != nil {
+				log.Error(err)
+				return
+			}
+			log.Infof("lock id %s in tx %s, fee %s", params.Id, evt.TxHash.Hex(), evt.Fee)
+			lockTx, token = evt.TxHash, evt.FeeToken
+		}
+		hash, err = cli.WrapperSpeedUp(kind, wrap, token, lockTx, params.Fee)
+	default:
+		log.Errorf("unknown wrapper action %s", params.Action)
+		return
+	}
+	if err != nil {
+		log.Errorf("%s %s wrapper %s failed, err: %v", params.Chain, kind, params.Action, err)
+		return
+	}
+	log.Infof("%s %s wrapper %s success, tx %s", params.Chain, kind, params.Action, hash.Hex())
+	return true
+}
+
+// wrapperFeeSuite run the fee checks of one wrapper with one fee token, the lock and balance functions
+// are chain specific and the admin operations are shared.
+type wrapperFeeSuite struct {
+	res       *frame.Result
+	chain     string
+	kind      string
+	wrapper   common.Address
+	proxy     common.Address
+	owner     wrapperAdmin
+	user      wrapperAdmin
+	collector wrapperAdmin
+	gasToken  common.Address // balance of tx sender in gas token is not checked
+	spend     *big.Int       // amount of fee token paid by every lock
+	fee       *big.Int
+	id        *big.Int
+	// duplicateReason is expected in the revert reason of lock with used id, any reason is accepted if empty
+	duplicateReason string
+
+	balanceOf func(token, owner common.Address) (*big.Int, error)
+	approve   func(token common.Address, amount *big.Int) error
+	lock      func(token common.Address, fee, id *big.Int) (common.Hash, error)
+}
+
+func (s *wrapperFeeSuite) name(token common.Address, check string) string {
+	return fmt.Sprintf("%s %s wrapper fee token %s %s", s.chain, s.kind, token.Hex(), check)
+}
+
+func (s *wrapperFeeSuite) nextID() *big.Int {
+	s.id = new(big.Int).Add(s.id, common.Big1)
+	return new(big.Int).Set(s.id)
+}
+
+// delta returns `balanceOf(owner) - before`.
+func (s *wrapperFeeSuite) delta(token, owner common.Address, before *big.Int) (*big.Int, error) {
+	cur, err := s.balanceOf(token, owner)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(cur, before), nil
+}
+
+// prepare make sure the wrapper collects fee to collector.
+func (s *wrapperFeeSuite) prepare() error {
+	expect := s.collector.Address()
+	cur, err := s.owner.WrapperFeeCollector(s.kind, s.wrapper)
+	if err != nil {
+		return fmt.Errorf("get %s %s wrapper fee collector failed, err: %v", s.chain, s.kind, err)
+	}
+	if cur != expect {
+		if _, err := s.owner.WrapperSetFeeCollector(s.kind, s.wrapper, expect); err != nil {
+			return fmt.Errorf("set %s %s wrapper fee collector failed, err: %v", s.chain, s.kind, err)
+		}
+		if cur, err = s.owner.WrapperFeeCollector(s.kind, s.wrapper); err != nil {
+			return err
+		}
+	}
+	s.res.Equal(fmt.Sprintf("%s %s wrapper fee collector", s.chain, s.kind), expect.Hex(), cur.Hex())
+
+	if s.proxy, err = s.owner.WrapperLockProxy(s.kind, s.wrapper); err != nil {
+		return fmt.Errorf("get %s %s wrapper lock proxy failed, err: %v", s.chain, s.kind, err)
+	}
+	log.Infof("%s %s wrapper %s, lock proxy %s, fee collector %s", s.chain, s.kind, s.wrapper.Hex(), s.proxy.Hex(), expect.Hex())
+	return nil
+}
+
+// run 检查一种手续费token
+// 1. lock后wrapper余额增加fee, PLT wrapper的lock proxy增加amount-fee, lock事件中fee及id与参数一致
+// 2. 重复的id被拒绝
+// 3. speed up后wrapper余额增加追加的fee, 并记录对应lock交易的speed up事件
+// 4. pause之后lock失败, unpause之后lock成功
+// 5. fee collector提取手续费后wrapper余额清零, collector收到全部手续费
+func (s *wrapperFeeSuite) run(token common.Address) error {
+	extra := new(big.Int).Div(s.fee, big.NewInt(2))
+	if token != utils.EmptyAddress {
+		allowance := new(big.Int).Add(new(big.Int).Mul(s.spend, big.NewInt(3)), extra)
+		if err := s.approve(token, allowance); err != nil {
+			return fmt.Errorf("approve %s to wrapper failed, err: %v", token.Hex(), err)
+		}
+	}
+
+	// fee accounting
+	logsplit()
+	id := s.nextID()
+	userBefore, err := s.balanceOf(token, s.user.Address())
+	if err != nil {
+		return err
+	}
+	wrapBefore, err := s.balanceOf(token, s.wrapper)
+	if err != nil {
+		return err
+	}
+	proxyBefore, err := s.balanceOf(token, s.proxy)
+	if err != nil {
+		return err
+	}
+	hash, err := s.lock(token, s.fee, id)
+	if !s.res.Assert(s.name(token, "lock"), err == nil, "err: %v", err) {
+		return nil
+	}
+	log.Infof("%s %s wrapper lock id %s, tx %s", s.chain, s.kind, id, hash.Hex())
+
+	wrapDelta, err := s.delta(token, s.wrapper, wrapBefore)
+	if err != nil {
+		return err
+	}
+	s.res.Equal(s.name(token, "wrapper fee"), s.fee.String(), wrapDelta.String())
+	if s.kind == polywrap.KindPLT {
+		net, err := polywrap.Net(s.spend, s.fee)
+		if err != nil {
+			return err
+		}
+		proxyDelta, err := s.delta(token, s.proxy, proxyBefore)
+		if err != nil {
+			return err
+		}
+		s.res.Equal(s.name(token, "lock proxy net amount"), net.String(), proxyDelta.String())
+	}
+	if token != s.gasToken {
+		userDelta, err := s.delta(token, s.user.Address(), userBefore)
+		if err != nil {
+			return err
+		}
+		s.res.Equal(s.name(token, "user spent"), s.spend.String(), new(big.Int).Neg(userDelta).String())
+	}
+	events, err := s.user.WrapperLockEvents(s.kind, s.wrapper, hash)
+	if err != nil {
+		return err
+	}
+	if s.res.Assert(s.name(token, "lock event"), len(events) == 1, "expect 1 event, got %d", len(events)) {
+		s.res.Equal(s.name(token, "lock event id"), id.String(), events[0].ID.String())
+		s.res.Equal(s.name(token, "lock event fee"), s.fee.String(), events[0].Fee.String())
+		s.res.Equal(s.name(token, "lock event fee token"), token.Hex(), events[0].FeeToken.Hex())
+	}
+
+	// duplicate id
+	logsplit()
+	_, err = s.lock(token, s.fee, id)
+	assertReverted(s.res, s.name(token, "duplicate id rejected"), err, s.duplicateReason)
+
+	// speed up
+	logsplit()
+	if wrapBefore, err = s.balanceOf(token, s.wrapper); err != nil {
+		return err
+	}
+	speedUp, err := s.user.WrapperSpeedUp(s.kind, s.wrapper, token, hash, extra)
+	if s.res.Assert(s.name(token, "speed up"), err == nil, "err: %v", err) {
+		if wrapDelta, err = s.delta(token, s.wrapper, wrapBefore); err != nil {
+			return err
+		}
+		s.res.Equal(s.name(token, "speed up fee"), extra.String(), wrapDelta.String())
+		list, err := s.user.WrapperSpeedUpEvents(s.kind, s.wrapper, speedUp)
+		if err != nil {
+			return err
+		}
+		s.res.Assert(s.name(token, "speed up event"), len(list) == 1 && list[0].Of(hash) && list[0].Fee.Cmp(extra) == 0,
+			"speed up event of lock tx %s with fee %s not found", hash.Hex(), extra)
+	}
+
+	// paused
+	logsplit()
+	if err := s.paused(token); err != nil {
+		return err
+	}
+
+	// extract fee
+	logsplit()
+	kept, err := s.balanceOf(token, s.wrapper)
+	if err != nil {
+		return err
+	}
+	collectorBefore, err := s.balanceOf(token, s.collector.Address())
+	if err != nil {
+		return err
+	}
+	_, err = s.collector.WrapperExtractFee(s.kind, s.wrapper, token)
+	if !s.res.Assert(s.name(token, "extract fee"), err == nil, "err: %v", err) {
+		return nil
+	}
+	rest, err := s.balanceOf(token, s.wrapper)
+	if err != nil {
+		return err
+	}
+	s.res.Equal(s.name(token, "wrapper fee after extract"), "0", rest.String())
+	collectorDelta, err := s.delta(token, s.collector.Address(), collectorBefore)
+	if err != nil {
+		return err
+	}
+	if token != s.gasToken {
+		s.res.Equal(s.name(token, "collector received"), kept.String(), collectorDelta.String())
+	} else {
+		s.res.Assert(s.name(token, "collector received"), collectorDelta.Sign() > 0,
+			"collector balance delta %s, wrapper kept %s", collectorDelta, kept)
+	}
+	log.Infof("%s %s wrapper extract fee %s of %s", s.chain, s.kind, kept, token.Hex())
+	return nil
+}
+
+// paused check that lock is rejected while paused, and the wrapper is always unpaused at last.
+func (s *wrapperFeeSuite) paused(token common.Address) error {
+	_, err := s.owner.WrapperPause(s.kind, s.wrapper)
+	if !s.res.Assert(s.name(token, "pause"), err == nil, "err: %v", err) {
+		return nil
+	}
+	paused, err := s.owner.WrapperPaused(s.kind, s.wrapper)
+	if err != nil {
+		return err
+	}
+	s.res.Assert(s.name(token, "paused"), paused, "wrapper not paused")
+	_, err = s.lock(token, s.fee, s.nextID())
+	s.res.Assert(s.name(token, "lock rejected while paused"), err != nil, "lock accepted while paused")
+
+	_, err = s.owner.WrapperUnpause(s.kind, s.wrapper)
+	if !s.res.Assert(s.name(token, "unpause"), err == nil, "err: %v", err) {
+		return fmt.Errorf("%s %s wrapper %s is left paused", s.chain, s.kind, s.wrapper.Hex())
+	}
+	if paused, err = s.owner.WrapperPaused(s.kind, s.wrapper); err != nil {
+		return err
+	}
+	s.res.Assert(s.name(token, "unpaused"), !paused, "wrapper still paused")
+	_, err = s.lock(token, s.fee, s.nextID())
+	s.res.Assert(s.name(token, "lock after unpause"), err == nil, "err: %v", err)
+	return nil
+}
+
+// WrapperFee 测试palette及ethereum上wrapper的手续费, 每个wrapper及手续费token分别检查
+// 1. palette PLT wrapper锁定native PLT并以PLT支付手续费, NFT wrapper锁定TokenIDs中的nft, 手续费token默认为native PLT
+// 2. ethereum PLT wrapper的手续费token即锁定的资产, 默认为erc20 PLT, 零地址表示ether
+// 3. 测试之前由wrapper owner将fee collector设置为Collector, 每一项检查见`wrapperFeeSuite.run`
+// 4. 重复id的lock必须被合约拒绝, 配置DuplicateReason时revert reason须包含该内容
+// 5. 未配置的wrapper跳过
+func WrapperFee(res *frame.Result) error {
+	var params struct {
+		User              common.Address
+		Collector         common.Address
+		Amount            *big.Int
+		Fee               *big.Int
+		PaletteNFTAsset   common.Address
+		PaletteTokenIDs   []uint64
+		PaletteFeeTokens  []common.Address
+		EthereumFeeTokens []common.Address
+		DuplicateReason   string
+	}
+	if err := config.LoadParams("WrapperFee.json", &params); err != nil {
+		return err
+	}
+	if params.Amount == nil || params.Fee == nil || params.Fee.Cmp(params.Amount) > 0 {
+		return fmt.Errorf("invalid amount %s and fee %s", params.Amount, params.Fee)
+	}
+	plt := common.HexToAddress(native.PLTContractAddress)
+	if len(params.PaletteFeeTokens) == 0 {
+		params.PaletteFeeTokens = []common.Address{plt}
+	}
+	if len(params.EthereumFeeTokens) == 0 {
+		params.EthereumFeeTokens = []common.Address{config.Conf.CrossChain.EthereumPLTAsset}
+	}
+
+	cc := config.Conf.CrossChain
+	id := big.NewInt(time.Now().UnixNano())
+	userSigner, err := customLoadSigner(params.User)
+	if err != nil {
+		return err
+	}
+	collectorSigner, err := customLoadSigner(params.Collector)
+	if err != nil {
+		return err
+	}
+	pltUser := sdk.NewSignerSender(config.Conf.Rpc, userSigner)
+	pltCollector := sdk.NewSignerSender(config.Conf.Rpc, collectorSigner)
+	pltBalanceOf := func(token, owner common.Address) (*big.Int, error) {
+		if token != plt {
+			return nil, fmt.Errorf("palette fee token %s is not native PLT", token.Hex())
+		}
+		return pltUser.BalanceOf(owner, "latest")
+	}
+	pltApprove := func(wrap common.Address) func(token common.Address, amount *big.Int) error {
+		return func(token common.Address, amount *big.Int) error {
+			if token != plt {
+				return fmt.Errorf("palette fee token %s is not native PLT", token.Hex())
+			}
+			_, err := pltUser.PLTApprove(wrap, amount)
+			return err
+		}
+	}
+
+	suites := make([]*wrapperFeeSuite, 0)
+	tokens := make([][]common.Address, 0)
+	if cc.PalettePLTWrapper != utils.EmptyAddress {
+		s := &wrapperFeeSuite{
+			res: res, chain: config.ChainPalette, kind: polywrap.KindPLT, wrapper: cc.PalettePLTWrapper,
+			owner: getPaletteCli(pltCTypeInvoker), user: pltUser, collector: pltCollector,
+			gasToken: plt, spend: params.Amount, fee: params.Fee, id: id, duplicateReason: params.DuplicateReason,
+			balanceOf: pltBalanceOf, approve: pltApprove(cc.PalettePLTWrapper),
+		}
+		s.lock = func(token common.Address, fee, id *big.Int) (common.Hash, error) {
+			return pltUser.PLTWrapLock(s.wrapper, token, params.User, cc.EthereumSideChainID, s.spend, fee, id)
+		}
+		suites, tokens = append(suites, s), append(tokens, []common.Address{plt})
+	}
+	if cc.PaletteNFTWrapper != utils.EmptyAddress {
+		s := &wrapperFeeSuite{
+			res: res, chain: config.ChainPalette, kind: polywrap.KindNFT, wrapper: cc.PaletteNFTWrapper,
+			owner: getPaletteCli(pltCTypeInvoker), user: pltUser, collector: pltCollector,
+			gasToken: plt, spend: params.Fee, fee: params.Fee, id: id, duplicateReason: params.DuplicateReason,
+			balanceOf: pltBalanceOf, approve: pltApprove(cc.PaletteNFTWrapper),
+		}
+		tokenIDs := params.PaletteTokenIDs
+		s.lock = func(token common.Address, fee, id *big.Int) (common.Hash, error) {
+			if len(tokenIDs) == 0 {
+				return utils.EmptyHash, fmt.Errorf("no more nft token id to lock")
+			}
+			tokenID := new(big.Int).SetUint64(tokenIDs[0])
+			if _, err := pltUser.NFTTokenApprove(params.PaletteNFTAsset, s.wrapper, tokenID); err != nil {
+				return utils.EmptyHash, err
+			}
+			hash, err := pltUser.NFTWrapLock(s.wrapper, params.PaletteNFTAsset, params.User, token, cc.EthereumSideChainID, tokenID, fee, id)
+			if err != nil {
+				return utils.EmptyHash, err
+			}
+			tokenIDs = tokenIDs[1:]
+			owner, err := pltUser.NFTTokenOwner(params.PaletteNFTAsset, tokenID, "latest")
+			if err != nil {
+				return hash, err
+			}
+			s.res.Equal(s.name(token, fmt.Sprintf("nft %d owner", tokenID)), s.proxy.Hex(), owner.Hex())
+			return hash, nil
+		}
+		suites, tokens = append(suites, s), append(tokens, params.PaletteFeeTokens)
+	}
+	if cc.EthereumPLTWrapper != utils.EmptyAddress {
+		ethUser, err := customEthereumCli(params.User)
+		if err != nil {
+			return err
+		}
+		ethCollector, err := customEthereumCli(params.Collector)
+		if err != nil {
+			return err
+		}
+		s := &wrapperFeeSuite{
+			res: res, chain: config.ChainEthereum, kind: polywrap.KindPLT, wrapper: cc.EthereumPLTWrapper,
+			owner: getEthereumCli(ethCTypeOwner), user: ethUser, collector: ethCollector,
+			gasToken: utils.EmptyAddress, spend: params.Amount, fee: params.Fee, id: id, duplicateReason: params.DuplicateReason,
+		}
+		s.balanceOf = func(token, owner common.Address) (*big.Int, error) {
+			if token == utils.EmptyAddress {
+				return ethUser.ETHBalance(owner)
+			}
+			return ethUser.PLTBalanceOf(token, owner)
+		}
+		s.approve = func(token common.Address, amount *big.Int) error {
+			_, err := ethUser.PLTApprove(token, s.wrapper, amount)
+			return err
+		}
+		s.lock = func(token common.Address, fee, id *big.Int) (common.Hash, error) {
+			return ethUser.WrapLock(s.wrapper, token, params.User, cc.PaletteSideChainID, s.spend, fee, id)
+		}
+		suites, tokens = append(suites, s), append(tokens, params.EthereumFeeTokens)
+	}
+	if len(suites) == 0 {
+		return fmt.Errorf("no wrapper configured")
+	}
+
+	for i, s := range suites {
+		if err := s.prepare(); err != nil {
+			return err
+		}
+		for _, token := range tokens[i] {
+			if err := s.run(token); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -16,9 +16,10 @@ require (
 	github.com/polynetwork/wrapper v0.0.0-20210708030702-eb192531f509
 	github.com/stretchr/testify v1.7.0
 	github.com/tyler-smith/go-bip39 v1.0.2
+	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 )
 
 replace (
-	github.com/ethereum/go-ethereum v1.9.15 => /Users/dylen/workspace/gohome/src/github.com/palettechain/palette
+	github.com/ethereum/go-ethereum v1.9.15 => ../palette
 	github.com/polynetwork/eth-contracts v1.0.0 => github.com/KSlashh/eth-contracts v0.0.0-20210813100032-17cd52477f06
 )
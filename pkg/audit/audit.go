@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Auditor collects named values observed on every node at every height, e.g: block hash, state root,
+// and compares them between nodes. it's safe for concurrent use.
+type Auditor struct {
+	mu     sync.Mutex
+	nodes  []string
+	values map[uint64]map[string]map[string]string // height -> field -> node -> value
+	errors []string
+}
+
+func New(nodes []string) *Auditor {
+	return &Auditor{
+		nodes:  nodes,
+		values: make(map[uint64]map[string]map[string]string),
+		errors: make([]string, 0),
+	}
+}
+
+func (a *Auditor) Observe(node string, height uint64, field, value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fields, ok := a.values[height]
+	if !ok {
+		fields = make(map[string]map[string]string)
+		a.values[height] = fields
+	}
+	values, ok := fields[field]
+	if !ok {
+		values = make(map[string]string)
+		fields[field] = values
+	}
+	values[node] = value
+}
+
+// Fail record the error of fetching, the field of node at that height is skipped while comparing.
+func (a *Auditor) Fail(node string, height uint64, field string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.errors = append(a.errors, fmt.Sprintf("%s height %d %s: %v", node, height, field, err))
+}
+
+// Diff is a field which has different values at the same height.
+type Diff struct {
+	Height uint64            `json:"height"`
+	Field  string            `json:"field"`
+	Values map[string]string `json:"values"`
+}
+
+// Divergence is the first height at which two nodes disagree.
+type Divergence struct {
+	NodeA  string   `json:"nodeA"`
+	NodeB  string   `json:"nodeB"`
+	Height uint64   `json:"height"`
+	Fields []string `json:"fields"`
+}
+
+type Report struct {
+	Nodes       []string      `json:"nodes"`
+	From        uint64        `json:"from"`
+	To          uint64        `json:"to"`
+	Consistent  bool          `json:"consistent"`
+	Divergences []*Divergence `json:"divergences"`
+	Diffs       []*Diff       `json:"diffs"`
+	Errors      []string      `json:"errors"`
+}
+
+func (a *Auditor) Report() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r := &Report{
+		Nodes:       append([]string{}, a.nodes...),
+		Divergences: make([]*Divergence, 0),
+		Diffs:       make([]*Diff, 0),
+		Errors:      append([]string{}, a.errors...),
+	}
+
+	heights := make([]uint64, 0, len(a.values))
+	for h := range a.values {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	if len(heights) > 0 {
+		r.From, r.To = heights[0], heights[len(heights)-1]
+	}
+
+	firsts := make(map[[2]int]*Divergence)
+	for _, h := range heights {
+		fields := a.values[h]
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			values := fields[name]
+			differ := false
+			for i := 0; i < len(a.nodes); i++ {
+				for j := i + 1; j < len(a.nodes); j++ {
+					vi, ok1 := values[a.nodes[i]]
+					vj, ok2 := values[a.nodes[j]]
+					if !ok1 || !ok2 || vi == vj {
+						continue
+					}
+					differ = true
+					pair := [2]int{i, j}
+					d, ok := firsts[pair]
+					if !ok {
+						d = &Divergence{NodeA: a.nodes[i], NodeB: a.nodes[j], Height: h}
+						firsts[pair] = d
+						r.Divergences = append(r.Divergences, d)
+					}
+					if d.Height == h {
+						d.Fields = append(d.Fields, name)
+					}
+				}
+			}
+			if differ {
+				cp := make(map[string]string, len(values))
+				for node, v := range values {
+					cp[node] = v
+				}
+				r.Diffs = append(r.Diffs, &Diff{Height: h, Field: name, Values: cp})
+			}
+		}
+	}
+	r.Consistent = len(r.Diffs) == 0
+	return r
+}
+
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "\t")
+}
+
+// Text format the report as human readable lines.
+func (r *Report) Text() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "audit %d nodes from height %d to %d, consistent %v\n", len(r.Nodes), r.From, r.To, r.Consistent)
+	for _, d := range r.Divergences {
+		fmt.Fprintf(buf, "%s and %s diverge at height %d, fields: %s\n", d.NodeA, d.NodeB, d.Height, strings.Join(d.Fields, ","))
+	}
+	for _, d := range r.Diffs {
+		nodes := make([]string, 0, len(d.Values))
+		for node := range d.Values {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+		fmt.Fprintf(buf, "height %d %s:\n", d.Height, d.Field)
+		for _, node := range nodes {
+			fmt.Fprintf(buf, "\t%s %s\n", node, d.Values[node])
+		}
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(buf, "error %s\n", e)
+	}
+	return buf.String()
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	nodes := []string{"node0", "node1", "node2"}
+	a := New(nodes)
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			for h := uint64(1); h <= 5; h++ {
+				hash := fmt.Sprintf("hash%d", h)
+				if node == "node2" && h >= 3 {
+					hash = fmt.Sprintf("fork%d", h)
+				}
+				a.Observe(node, h, "hash", hash)
+
+				root := fmt.Sprintf("root%d", h)
+				if node == "node1" && h == 4 {
+					root = "bad"
+				}
+				a.Observe(node, h, "stateRoot", root)
+			}
+		}(node)
+	}
+	wg.Wait()
+	a.Fail("node1", 5, "hash", fmt.Errorf("connection refused"))
+
+	r := a.Report()
+	assert.False(t, r.Consistent)
+	assert.Equal(t, uint64(1), r.From)
+	assert.Equal(t, uint64(5), r.To)
+	assert.Equal(t, []*Divergence{
+		{NodeA: "node0", NodeB: "node2", Height: 3, Fields: []string{"hash"}},
+		{NodeA: "node1", NodeB: "node2", Height: 3, Fields: []string{"hash"}},
+		{NodeA: "node0", NodeB: "node1", Height: 4, Fields: []string{"stateRoot"}},
+	}, r.Divergences)
+	// hash at 3,4,5 and state root at 4
+	assert.Equal(t, 4, len(r.Diffs))
+	assert.Equal(t, 1, len(r.Errors))
+	assert.Contains(t, r.Text(), "node0 and node2 diverge at height 3, fields: hash")
+
+	_, err := r.JSON()
+	assert.NoError(t, err)
+}
+
+func TestConsistent(t *testing.T) {
+	a := New([]string{"node0", "node1"})
+	a.Observe("node0", 10, "validators", "a,b")
+	a.Observe("node1", 10, "validators", "a,b")
+	// missing value is not divergence
+	a.Observe("node0", 11, "validators", "a,b")
+
+	r := a.Report()
+	assert.True(t, r.Consistent)
+	assert.Empty(t, r.Divergences)
+}
@@ -0,0 +1,126 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options controls the open loop load generator.
+type Options struct {
+	// TPS is the target submitting rate.
+	TPS float64
+	// Duration is how long the load lasts.
+	Duration time.Duration
+	// Workers is the max number of in-flight submits, a tick is dropped if all workers are busy.
+	Workers int
+}
+
+func (o Options) Validate() error {
+	if o.TPS <= 0 {
+		return fmt.Errorf("invalid tps %v", o.TPS)
+	}
+	if o.Duration <= 0 {
+		return fmt.Errorf("invalid duration %v", o.Duration)
+	}
+	if o.Workers <= 0 {
+		return fmt.Errorf("invalid workers %d", o.Workers)
+	}
+	return nil
+}
+
+// Run call `fn` with increasing sequence at the target rate until duration passed, and wait for all
+// of the calls finished. it returns the number of ticks dropped since all workers were busy.
+func Run(opt Options, fn func(seq int)) (dropped int, err error) {
+	if err := opt.Validate(); err != nil {
+		return 0, err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, opt.Workers)
+		interval = time.Duration(float64(time.Second) / opt.TPS)
+		start    = time.Now()
+		deadline = start.Add(opt.Duration)
+		total    = int(opt.TPS * opt.Duration.Seconds())
+		skipped  int32
+	)
+
+	for seq := 0; seq < total; seq++ {
+		// schedule by absolute time so that slow ticks don't lower the rate
+		next := start.Add(time.Duration(seq) * interval)
+		if next.After(deadline) {
+			break
+		}
+		time.Sleep(time.Until(next))
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			atomic.AddInt32(&skipped, 1)
+			continue
+		}
+		wg.Add(1)
+		go func(seq int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(seq)
+		}(seq)
+	}
+	wg.Wait()
+	return int(atomic.LoadInt32(&skipped)), nil
+}
+
+// Mix picks transaction kind by weight.
+type Mix struct {
+	kinds   []string
+	weights []int
+	total   int
+}
+
+func NewMix(weights map[string]int) (*Mix, error) {
+	m := &Mix{}
+	for kind := range weights {
+		m.kinds = append(m.kinds, kind)
+	}
+	sort.Strings(m.kinds)
+	for _, kind := range m.kinds {
+		w := weights[kind]
+		if w < 0 {
+			return nil, fmt.Errorf("invalid weight %d of %s", w, kind)
+		}
+		m.weights = append(m.weights, w)
+		m.total += w
+	}
+	if m.total == 0 {
+		return nil, fmt.Errorf("transaction mix is empty")
+	}
+	return m, nil
+}
+
+func (m *Mix) Pick(r *rand.Rand) string {
+	n := r.Intn(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.kinds[i]
+		}
+		n -= w
+	}
+	return m.kinds[len(m.kinds)-1]
+}
+
+// Kinds returns transaction kinds with positive weight.
+func (m *Mix) Kinds() []string {
+	list := make([]string, 0, len(m.kinds))
+	for i, kind := range m.kinds {
+		if m.weights[i] > 0 {
+			list = append(list, kind)
+		}
+	}
+	return list
+}
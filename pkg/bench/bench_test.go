@@ -0,0 +1,99 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var count int32
+	start := time.Now()
+	dropped, err := Run(Options{TPS: 100, Duration: 200 * time.Millisecond, Workers: 4}, func(seq int) {
+		atomic.AddInt32(&count, 1)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, int32(20), count)
+	assert.True(t, time.Since(start) >= 190*time.Millisecond)
+
+	// slow workers cause dropping
+	dropped, err = Run(Options{TPS: 100, Duration: 100 * time.Millisecond, Workers: 1}, func(seq int) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	assert.NoError(t, err)
+	assert.True(t, dropped > 0)
+
+	_, err = Run(Options{TPS: 0, Duration: time.Second, Workers: 1}, func(seq int) {})
+	assert.Error(t, err)
+}
+
+func TestMix(t *testing.T) {
+	_, err := NewMix(map[string]int{"transfer": 0})
+	assert.Error(t, err)
+
+	m, err := NewMix(map[string]int{"transfer": 3, "stake": 1, "call": 0})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stake", "transfer"}, m.Kinds())
+
+	r := rand.New(rand.NewSource(1))
+	counter := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		counter[m.Pick(r)] += 1
+	}
+	assert.Equal(t, 0, counter["call"])
+	assert.InDelta(t, 3000, counter["transfer"], 200)
+	assert.InDelta(t, 1000, counter["stake"], 200)
+}
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	base := time.Now()
+	for i := 0; i < 10; i++ {
+		s.Submitted("transfer", fmt.Sprintf("0x%d", i), base)
+	}
+	s.Failed("stake", Categorize(fmt.Errorf("nonce too low")))
+	s.Failed("stake", Categorize(fmt.Errorf("insufficient funds for gas * price + value")))
+
+	hashes := []string{"0xother"}
+	for i := 0; i < 9; i++ {
+		hashes = append(hashes, fmt.Sprintf("0x%d", i))
+	}
+	s.Block(1, hashes[:5], base.Add(time.Second))
+	s.Block(2, hashes[5:], base.Add(2*time.Second))
+	s.Block(3, nil, base.Add(3*time.Second))
+	assert.Equal(t, 1, s.Pending())
+
+	sum := s.Summary()
+	assert.Equal(t, 10, sum.Submitted)
+	assert.Equal(t, 9, sum.Included)
+	assert.Equal(t, 2, sum.Failed)
+	assert.Equal(t, 1, sum.Pending)
+	assert.Equal(t, map[string]int{ErrNonce: 1, ErrFunds: 1, ErrNotIncluded: 1}, sum.Errors)
+	assert.Equal(t, &KindSummary{Submitted: 10, Included: 9}, sum.Kinds["transfer"])
+	assert.Equal(t, &KindSummary{Failed: 2}, sum.Kinds["stake"])
+	assert.Equal(t, []*BlockSummary{{1, 5, 4}, {2, 5, 5}, {3, 0, 0}}, sum.Blocks)
+	assert.Equal(t, time.Second, sum.Latency.Min)
+	assert.Equal(t, 2*time.Second, sum.Latency.P50)
+	assert.Equal(t, 2*time.Second, sum.Latency.P90)
+	assert.Equal(t, 2*time.Second, sum.Latency.Max)
+	assert.InDelta(t, 9.0, sum.IncludeTPS, 0.01)
+}
+
+func TestPercentiles(t *testing.T) {
+	list := make([]time.Duration, 100)
+	for i := range list {
+		list[i] = time.Duration(100-i) * time.Millisecond
+	}
+	l := percentiles(list)
+	assert.Equal(t, time.Millisecond, l.Min)
+	assert.Equal(t, 50*time.Millisecond, l.P50)
+	assert.Equal(t, 90*time.Millisecond, l.P90)
+	assert.Equal(t, 99*time.Millisecond, l.P99)
+	assert.Equal(t, 100*time.Millisecond, l.Max)
+	assert.Equal(t, Latency{}, percentiles(nil))
+}
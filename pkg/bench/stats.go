@@ -0,0 +1,231 @@
+package bench
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// error categories
+const (
+	ErrNonce       = "nonce"
+	ErrUnderpriced = "underpriced"
+	ErrFunds       = "insufficient-funds"
+	ErrPoolFull    = "txpool-full"
+	ErrRevert      = "revert"
+	ErrTimeout     = "timeout"
+	ErrConnection  = "connection"
+	ErrNotIncluded = "not-included"
+	ErrOther       = "other"
+)
+
+// Categorize classify the error returned by node with its message.
+func Categorize(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "nonce"):
+		return ErrNonce
+	case strings.Contains(msg, "underpriced"):
+		return ErrUnderpriced
+	case strings.Contains(msg, "insufficient funds"):
+		return ErrFunds
+	case strings.Contains(msg, "txpool is full"), strings.Contains(msg, "queue is full"):
+		return ErrPoolFull
+	case strings.Contains(msg, "revert"):
+		return ErrRevert
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"):
+		return ErrTimeout
+	case strings.Contains(msg, "connection"), strings.Contains(msg, "eof"):
+		return ErrConnection
+	default:
+		return ErrOther
+	}
+}
+
+type pendingTx struct {
+	kind   string
+	submit time.Time
+}
+
+// Stats collects submitting and inclusion of transactions, it's safe for concurrent use.
+type Stats struct {
+	mu        sync.Mutex
+	start     time.Time
+	pending   map[string]*pendingTx
+	kinds     map[string]*KindSummary
+	latencies []time.Duration
+	blocks    []*BlockSummary
+	errors    map[string]int
+	firstIncl time.Time
+	lastIncl  time.Time
+}
+
+type KindSummary struct {
+	Submitted int `json:"submitted"`
+	Included  int `json:"included"`
+	Failed    int `json:"failed"`
+}
+
+type BlockSummary struct {
+	Number uint64 `json:"number"`
+	// Txs is the number of all transactions in the block, and Ours is the number of them sent by benchmark.
+	Txs  int `json:"txs"`
+	Ours int `json:"ours"`
+}
+
+type Latency struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+type Summary struct {
+	Duration   time.Duration           `json:"duration"`
+	Submitted  int                     `json:"submitted"`
+	Included   int                     `json:"included"`
+	Failed     int                     `json:"failed"`
+	Pending    int                     `json:"pending"`
+	SubmitTPS  float64                 `json:"submitTps"`
+	IncludeTPS float64                 `json:"includeTps"`
+	Latency    Latency                 `json:"latency"`
+	Kinds      map[string]*KindSummary `json:"kinds"`
+	Blocks     []*BlockSummary         `json:"blocks"`
+	Errors     map[string]int          `json:"errors"`
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		start:   time.Now(),
+		pending: make(map[string]*pendingTx),
+		kinds:   make(map[string]*KindSummary),
+		blocks:  make([]*BlockSummary, 0),
+		errors:  make(map[string]int),
+	}
+}
+
+func (s *Stats) kind(kind string) *KindSummary {
+	ks, ok := s.kinds[kind]
+	if !ok {
+		ks = new(KindSummary)
+		s.kinds[kind] = ks
+	}
+	return ks
+}
+
+// Submitted record a transaction accepted by node at time `at`.
+func (s *Stats) Submitted(kind, hash string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.kind(kind).Submitted += 1
+	s.pending[hash] = &pendingTx{kind: kind, submit: at}
+}
+
+// Failed record a transaction which failed to submit.
+func (s *Stats) Failed(kind, category string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.kind(kind).Failed += 1
+	s.errors[category] += 1
+}
+
+// Block record all transactions of a new block observed at time `at`.
+func (s *Stats) Block(number uint64, hashes []string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blk := &BlockSummary{Number: number, Txs: len(hashes)}
+	for _, hash := range hashes {
+		tx, ok := s.pending[hash]
+		if !ok {
+			continue
+		}
+		delete(s.pending, hash)
+		blk.Ours += 1
+		s.kind(tx.kind).Included += 1
+		s.latencies = append(s.latencies, at.Sub(tx.submit))
+		if s.firstIncl.IsZero() {
+			s.firstIncl = at
+		}
+		s.lastIncl = at
+	}
+	s.blocks = append(s.blocks, blk)
+}
+
+// Pending returns the number of submitted transactions which are not included yet.
+func (s *Stats) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending)
+}
+
+// Summary calculate the result, pending transactions are counted as `not-included` errors.
+func (s *Stats) Summary() *Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := &Summary{
+		Duration: time.Since(s.start),
+		Pending:  len(s.pending),
+		Kinds:    make(map[string]*KindSummary),
+		Blocks:   append([]*BlockSummary{}, s.blocks...),
+		Errors:   make(map[string]int),
+	}
+	for kind, ks := range s.kinds {
+		cp := *ks
+		sum.Kinds[kind] = &cp
+		sum.Submitted += ks.Submitted
+		sum.Included += ks.Included
+		sum.Failed += ks.Failed
+	}
+	for category, n := range s.errors {
+		sum.Errors[category] = n
+	}
+	if sum.Pending > 0 {
+		sum.Errors[ErrNotIncluded] += sum.Pending
+	}
+	if secs := sum.Duration.Seconds(); secs > 0 {
+		sum.SubmitTPS = float64(sum.Submitted+sum.Failed) / secs
+	}
+	if d := s.lastIncl.Sub(s.firstIncl).Seconds(); d > 0 {
+		sum.IncludeTPS = float64(sum.Included) / d
+	}
+	sum.Latency = percentiles(s.latencies)
+	return sum
+}
+
+func percentiles(list []time.Duration) Latency {
+	if len(list) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration{}, list...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	// nearest rank method
+	rank := func(p float64) time.Duration {
+		idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx]
+	}
+	return Latency{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  rank(50),
+		P90:  rank(90),
+		P99:  rank(99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,281 @@
+// Package bootstrap runs an ordered list of idempotent steps. every step detects whether it is done
+// before running, so the flow can be run again after a failure and resumes from the failed step. the
+// progress, e.g: which steps completed and which one failed, is saved in a json file.
+package bootstrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Step is one action of the flow.
+type Step struct {
+	Name  string // step name, e.g: plt-deploy-eccd
+	Group string // e.g: ethereum, palette, poly
+	// Done detect whether the step is done already, e.g: contract deployed, proxy bound. it should
+	// return false instead of error if the step depends on pending steps. nil means the step can't
+	// be detected and the progress file decides.
+	Done func() (bool, error)
+	Run  func() error
+}
+
+type Status string
+
+const (
+	StatusDone    Status = "done"
+	StatusPending Status = "pending"
+	StatusSkipped Status = "skipped"
+	StatusUnknown Status = "unknown"
+)
+
+type Item struct {
+	Step   *Step
+	Status Status
+	Reason string
+}
+
+// Plan is the status of steps in order.
+type Plan []*Item
+
+// Pending returns the number of steps to run.
+func (p Plan) Pending() int {
+	n := 0
+	for _, item := range p {
+		if item.Status == StatusPending || item.Status == StatusUnknown {
+			n++
+		}
+	}
+	return n
+}
+
+func (p Plan) String() string {
+	b := new(strings.Builder)
+	for i, item := range p {
+		fmt.Fprintf(b, "%3d. %-9s %-9s %s", i+1, item.Status, item.Step.Group, item.Step.Name)
+		if item.Reason != "" {
+			fmt.Fprintf(b, " (%s)", item.Reason)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "%d steps, %d to run", len(p), p.Pending())
+	return b.String()
+}
+
+// Options limit the steps to run.
+type Options struct {
+	From string   // steps before `From` are skipped
+	Skip []string // steps never run
+}
+
+// Event is the result of one executed step.
+type Event struct {
+	Step  string
+	Error string `json:",omitempty"`
+	Cost  string
+	Time  time.Time
+}
+
+// Progress is saved after every step, so that the steps done and the failed one are known even
+// if the process is killed.
+type Progress struct {
+	Completed map[string]time.Time // step name => completed time
+	Failed    string               // the last failed step, empty if the last run succeed
+	Error     string
+	Events    []*Event
+	Updated   time.Time
+
+	file string
+}
+
+// LoadProgress read the progress file, an empty progress is returned if the file not exist.
+func LoadProgress(file string) (*Progress, error) {
+	p := &Progress{Completed: make(map[string]time.Time), Events: make([]*Event, 0), file: file}
+	bz, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bz, p); err != nil {
+		return nil, fmt.Errorf("progress file %s: %v", file, err)
+	}
+	if p.Completed == nil {
+		p.Completed = make(map[string]time.Time)
+	}
+	return p, nil
+}
+
+func (p *Progress) save() error {
+	p.Updated = time.Now()
+	if p.file == "" {
+		return nil
+	}
+	if err := os.MkdirAll(path.Dir(p.file), 0755); err != nil {
+		return err
+	}
+	bz, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := p.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.file)
+}
+
+func (p *Progress) complete(name string) {
+	if _, ok := p.Completed[name]; !ok {
+		p.Completed[name] = time.Now()
+	}
+}
+
+type Runner struct {
+	steps    []*Step
+	opts     Options
+	progress *Progress
+}
+
+// New check that step names are unique and options reference the existing steps.
+func New(steps []*Step, opts Options, progress *Progress) (*Runner, error) {
+	names := make(map[string]struct{})
+	for _, step := range steps {
+		if _, ok := names[step.Name]; ok {
+			return nil, fmt.Errorf("duplicate step %s", step.Name)
+		}
+		if step.Run == nil {
+			return nil, fmt.Errorf("step %s has no action", step.Name)
+		}
+		names[step.Name] = struct{}{}
+	}
+	for _, name := range append([]string{opts.From}, opts.Skip...) {
+		if _, ok := names[name]; name != "" && !ok {
+			return nil, fmt.Errorf("step %s not found", name)
+		}
+	}
+	if progress == nil {
+		progress, _ = LoadProgress("")
+	}
+	return &Runner{steps: steps, opts: opts, progress: progress}, nil
+}
+
+func (r *Runner) Progress() *Progress {
+	return r.progress
+}
+
+func (r *Runner) skipped(index int) bool {
+	step := r.steps[index]
+	for _, name := range r.opts.Skip {
+		if name == step.Name {
+			return true
+		}
+	}
+	if r.opts.From == "" {
+		return false
+	}
+	for _, s := range r.steps[:index] {
+		if s.Name == r.opts.From {
+			return false
+		}
+	}
+	return step.Name != r.opts.From
+}
+
+func (r *Runner) status(index int) (Status, string) {
+	step := r.steps[index]
+	if r.skipped(index) {
+		return StatusSkipped, ""
+	}
+	if step.Done == nil {
+		if t, ok := r.progress.Completed[step.Name]; ok {
+			return StatusDone, fmt.Sprintf("completed at %s", t.Format(time.RFC3339))
+		}
+		return StatusPending, "undetectable"
+	}
+	done, err := step.Done()
+	if err != nil {
+		return StatusUnknown, err.Error()
+	}
+	if done {
+		return StatusDone, ""
+	}
+	if step.Name == r.progress.Failed {
+		return StatusPending, fmt.Sprintf("failed last time: %s", r.progress.Error)
+	}
+	return StatusPending, ""
+}
+
+// Plan detect all of the steps without running, it's the dry run of flow. note that steps depend on
+// pending steps are always pending.
+func (r *Runner) Plan() Plan {
+	plan := make(Plan, 0, len(r.steps))
+	for i, step := range r.steps {
+		status, reason := r.status(i)
+		plan = append(plan, &Item{Step: step, Status: status, Reason: reason})
+	}
+	return plan
+}
+
+// Run execute the pending steps in order, every step is detected again just before running because
+// the previous steps may change its status. the flow stops at the first failed step, and the step is
+// verified by `Done` after running. `fn` is called after every step if it's not nil.
+func (r *Runner) Run(fn func(item *Item, err error)) (executed int, err error) {
+	if fn == nil {
+		fn = func(item *Item, err error) {}
+	}
+
+	for i, step := range r.steps {
+		status, reason := r.status(i)
+		item := &Item{Step: step, Status: status, Reason: reason}
+		switch status {
+		case StatusSkipped:
+			fn(item, nil)
+			continue
+		case StatusDone:
+			r.progress.complete(step.Name)
+			fn(item, nil)
+			continue
+		case StatusUnknown:
+			err = fmt.Errorf("detect step %s failed, err: %s", step.Name, reason)
+		default:
+			start := time.Now()
+			err = step.Run()
+			if err == nil && step.Done != nil {
+				if done, derr := step.Done(); derr != nil || !done {
+					err = fmt.Errorf("step %s finished but not detected as done, err: %v", step.Name, derr)
+				}
+			}
+			event := &Event{Step: step.Name, Cost: time.Since(start).Round(time.Millisecond).String(), Time: time.Now()}
+			if err != nil {
+				event.Error = err.Error()
+			}
+			r.progress.Events = append(r.progress.Events, event)
+			executed++
+		}
+
+		if err != nil {
+			item.Status, item.Reason = StatusPending, err.Error()
+			r.progress.Failed, r.progress.Error = step.Name, err.Error()
+			if serr := r.progress.save(); serr != nil {
+				err = fmt.Errorf("%v, save progress err: %v", err, serr)
+			}
+			fn(item, err)
+			return executed, err
+		}
+
+		item.Status, item.Reason = StatusDone, ""
+		r.progress.complete(step.Name)
+		if err := r.progress.save(); err != nil {
+			return executed, fmt.Errorf("save progress err: %v", err)
+		}
+		fn(item, nil)
+	}
+
+	r.progress.Failed, r.progress.Error = "", ""
+	return executed, r.progress.save()
+}
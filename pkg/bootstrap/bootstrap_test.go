@@ -0,0 +1,139 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChain struct {
+	state map[string]bool
+	fail  map[string]bool
+	runs  []string
+}
+
+func (c *fakeChain) step(name string, deps ...string) *Step {
+	return &Step{
+		Name:  name,
+		Group: "test",
+		Done: func() (bool, error) {
+			return c.state[name], nil
+		},
+		Run: func() error {
+			c.runs = append(c.runs, name)
+			for _, dep := range deps {
+				if !c.state[dep] {
+					return fmt.Errorf("%s not done", dep)
+				}
+			}
+			if c.fail[name] {
+				return fmt.Errorf("%s failed", name)
+			}
+			c.state[name] = true
+			return nil
+		},
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onrobot-bootstrap")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "local", "bootstrap.json")
+
+	chain := &fakeChain{state: map[string]bool{"deploy-eccd": true}, fail: map[string]bool{"deploy-ccmp": true}}
+	steps := []*Step{
+		chain.step("deploy-eccd"),
+		chain.step("deploy-eccm", "deploy-eccd"),
+		chain.step("deploy-ccmp", "deploy-eccm"),
+		chain.step("eccm-ownership", "deploy-ccmp"),
+	}
+
+	progress, err := LoadProgress(file)
+	assert.NoError(t, err)
+	r, err := New(steps, Options{}, progress)
+	assert.NoError(t, err)
+
+	plan := r.Plan()
+	assert.Equal(t, 3, plan.Pending())
+	assert.Equal(t, StatusDone, plan[0].Status)
+	t.Log(plan.String())
+
+	executed, err := r.Run(nil)
+	assert.Error(t, err)
+	assert.Equal(t, 2, executed)
+	assert.Equal(t, []string{"deploy-eccm", "deploy-ccmp"}, chain.runs)
+
+	// progress saved on failure
+	progress, err = LoadProgress(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy-ccmp", progress.Failed)
+	assert.Equal(t, 2, len(progress.Completed))
+	assert.Equal(t, 2, len(progress.Events))
+
+	// resume from the failed step
+	chain.fail = nil
+	chain.runs = nil
+	r, err = New(steps, Options{}, progress)
+	assert.NoError(t, err)
+	assert.Contains(t, r.Plan()[2].Reason, "failed last time")
+	executed, err = r.Run(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, executed)
+	assert.Equal(t, []string{"deploy-ccmp", "eccm-ownership"}, chain.runs)
+	assert.Equal(t, "", r.Progress().Failed)
+
+	// nothing to do
+	chain.runs = nil
+	executed, err = r.Run(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, executed)
+	assert.Equal(t, 0, r.Plan().Pending())
+}
+
+func TestOptions(t *testing.T) {
+	chain := &fakeChain{state: map[string]bool{}}
+	steps := []*Step{chain.step("a"), chain.step("b"), chain.step("c"), chain.step("d")}
+
+	_, err := New(steps, Options{From: "x"}, nil)
+	assert.Error(t, err)
+	_, err = New(append(steps, chain.step("a")), Options{}, nil)
+	assert.Error(t, err)
+
+	r, err := New(steps, Options{From: "b", Skip: []string{"c"}}, nil)
+	assert.NoError(t, err)
+	plan := r.Plan()
+	assert.Equal(t, StatusSkipped, plan[0].Status)
+	assert.Equal(t, StatusPending, plan[1].Status)
+	assert.Equal(t, StatusSkipped, plan[2].Status)
+	assert.Equal(t, 2, plan.Pending())
+
+	_, err = r.Run(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "d"}, chain.runs)
+}
+
+func TestUndetectable(t *testing.T) {
+	runs := 0
+	step := &Step{Name: "mint", Run: func() error { runs++; return nil }}
+	verify := &Step{Name: "verify", Done: func() (bool, error) { return false, nil }, Run: func() error { return nil }}
+
+	r, err := New([]*Step{step, verify}, Options{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "undetectable", r.Plan()[0].Reason)
+
+	// step not detected as done after running
+	_, err = r.Run(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "verify", r.Progress().Failed)
+
+	// undetectable step is decided by progress
+	_, err = r.Run(nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, StatusDone, r.Plan()[0].Status)
+}
@@ -0,0 +1,259 @@
+// Package codec encodes and decodes the data carried by cross chain txs: the poly `MakeTxParam`
+// emitted as rawdata of eccm `CrossChainEvent`, the args of PLT and NFT proxy `unlock` carried in it,
+// the args of wrapper `lock` and the callData of NFT `safeTransferFrom` to lock proxy.
+package codec
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// MakeTxParam is the cross chain param serialized by source eccm and proved to poly.
+type MakeTxParam struct {
+	TxHash              []byte // cross chain tx id
+	CrossChainID        []byte
+	FromContractAddress []byte
+	ToChainID           uint64
+	ToContractAddress   []byte
+	Method              string
+	Args                []byte
+}
+
+func (p *MakeTxParam) Serialization() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(p.TxHash)
+	sink.WriteVarBytes(p.CrossChainID)
+	sink.WriteVarBytes(p.FromContractAddress)
+	sink.WriteUint64(p.ToChainID)
+	sink.WriteVarBytes(p.ToContractAddress)
+	sink.WriteVarBytes([]byte(p.Method))
+	sink.WriteVarBytes(p.Args)
+	return sink.Bytes()
+}
+
+func (p *MakeTxParam) Deserialization(raw []byte) error {
+	source := common.NewZeroCopySource(raw)
+	if err := p.deserialize(source); err != nil {
+		return err
+	}
+	return finished(source, "MakeTxParam")
+}
+
+func (p *MakeTxParam) deserialize(source *common.ZeroCopySource) error {
+	var eof bool
+	if p.TxHash, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("MakeTxParam", "TxHash")
+	}
+	if p.CrossChainID, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("MakeTxParam", "CrossChainID")
+	}
+	if p.FromContractAddress, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("MakeTxParam", "FromContractAddress")
+	}
+	if p.ToChainID, eof = source.NextUint64(); eof {
+		return unexpectedEOF("MakeTxParam", "ToChainID")
+	}
+	if p.ToContractAddress, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("MakeTxParam", "ToContractAddress")
+	}
+	method, eof := source.NextVarBytes()
+	if eof {
+		return unexpectedEOF("MakeTxParam", "Method")
+	}
+	p.Method = string(method)
+	if p.Args, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("MakeTxParam", "Args")
+	}
+	return nil
+}
+
+// TxArgs is the args of PLT lock proxy `unlock`, amount is serialized as 32 bytes little endian.
+type TxArgs struct {
+	ToAssetHash []byte
+	ToAddress   []byte
+	Amount      *big.Int
+}
+
+func (args *TxArgs) Serialization() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(args.ToAssetHash)
+	sink.WriteVarBytes(args.ToAddress)
+	raw, _ := PadFixedBytes(args.Amount, 32)
+	sink.WriteBytes(raw)
+	return sink.Bytes()
+}
+
+func (args *TxArgs) Deserialization(raw []byte) error {
+	source := common.NewZeroCopySource(raw)
+	if err := args.deserialize(source); err != nil {
+		return err
+	}
+	return finished(source, "TxArgs")
+}
+
+func (args *TxArgs) deserialize(source *common.ZeroCopySource) (err error) {
+	var eof bool
+	if args.ToAssetHash, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("TxArgs", "ToAssetHash")
+	}
+	if args.ToAddress, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("TxArgs", "ToAddress")
+	}
+	if args.Amount, err = nextUint255(source); err != nil {
+		return fmt.Errorf("TxArgs.Deserialization Amount error: %v", err)
+	}
+	return nil
+}
+
+// NFTArgs is the args of NFT lock proxy `unlock`, the token uri is minted together with the token on
+// target chain.
+type NFTArgs struct {
+	ToAssetHash []byte
+	ToAddress   []byte
+	TokenID     *big.Int
+	TokenURI    string
+}
+
+func (args *NFTArgs) Serialization() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(args.ToAssetHash)
+	sink.WriteVarBytes(args.ToAddress)
+	raw, _ := PadFixedBytes(args.TokenID, 32)
+	sink.WriteBytes(raw)
+	sink.WriteVarBytes([]byte(args.TokenURI))
+	return sink.Bytes()
+}
+
+func (args *NFTArgs) Deserialization(raw []byte) error {
+	source := common.NewZeroCopySource(raw)
+	if err := args.deserialize(source); err != nil {
+		return err
+	}
+	return finished(source, "NFTArgs")
+}
+
+func (args *NFTArgs) deserialize(source *common.ZeroCopySource) (err error) {
+	var eof bool
+	if args.ToAssetHash, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("NFTArgs", "ToAssetHash")
+	}
+	if args.ToAddress, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("NFTArgs", "ToAddress")
+	}
+	if args.TokenID, err = nextUint255(source); err != nil {
+		return fmt.Errorf("NFTArgs.Deserialization TokenID error: %v", err)
+	}
+	uri, eof := source.NextVarBytes()
+	if eof {
+		return unexpectedEOF("NFTArgs", "TokenURI")
+	}
+	args.TokenURI = string(uri)
+	return nil
+}
+
+// SafeTransferData is the callData of NFT `safeTransferFrom` to lock proxy, which tells the proxy the
+// receiver and target chain.
+type SafeTransferData struct {
+	ToAddress []byte
+	ToChainID uint64
+}
+
+func (d *SafeTransferData) Serialization() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(d.ToAddress)
+	sink.WriteUint64(d.ToChainID)
+	return sink.Bytes()
+}
+
+func (d *SafeTransferData) Deserialization(raw []byte) error {
+	source := common.NewZeroCopySource(raw)
+	var eof bool
+	if d.ToAddress, eof = source.NextVarBytes(); eof {
+		return unexpectedEOF("SafeTransferData", "ToAddress")
+	}
+	if d.ToChainID, eof = source.NextUint64(); eof {
+		return unexpectedEOF("SafeTransferData", "ToChainID")
+	}
+	return finished(source, "SafeTransferData")
+}
+
+// DecodeArgs decode the proxy args carried in MakeTxParam. PLT and NFT proxy share the method name
+// `unlock`, the args are distinguished by layout: NFT args carry the token uri after token id.
+func DecodeArgs(raw []byte) (interface{}, error) {
+	nft := new(NFTArgs)
+	if err := nft.Deserialization(raw); err == nil {
+		return nft, nil
+	}
+	plt := new(TxArgs)
+	if err := plt.Deserialization(raw); err != nil {
+		return nil, fmt.Errorf("args neither PLT nor NFT unlock args, err: %v", err)
+	}
+	return plt, nil
+}
+
+func nextUint255(source *common.ZeroCopySource) (*big.Int, error) {
+	value, eof := source.NextBytes(32)
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return UnpadFixedBytes(value, 32)
+}
+
+func unexpectedEOF(typ, field string) error {
+	return fmt.Errorf("%s.Deserialization %s error: %s", typ, field, io.ErrUnexpectedEOF)
+}
+
+func finished(source *common.ZeroCopySource, typ string) error {
+	if n := source.Len(); n > 0 {
+		return fmt.Errorf("%s.Deserialization %d bytes left", typ, n)
+	}
+	return nil
+}
+
+// UnpadFixedBytes decode little endian fixed bytes, only nonnegative int256 supported.
+func UnpadFixedBytes(paddedBs []byte, intBsLen int) (*big.Int, error) {
+	if len(paddedBs) != intBsLen {
+		return nil, fmt.Errorf("UnpadFixedBytes only support 32 bytes value, but got:%s", hex.EncodeToString(paddedBs))
+	}
+	nonZeroPos := intBsLen - 1
+	for i := nonZeroPos; i >= 0; i-- {
+		p := paddedBs[i]
+		if p != 0x0 {
+			nonZeroPos = i
+			break
+		}
+	}
+	if nonZeroPos == intBsLen-1 && paddedBs[intBsLen-1]>>7 == 1 {
+		return nil, fmt.Errorf("UnpadFixedBytes only support 32 bytes nonnegative value, but got:%s", hex.EncodeToString(paddedBs))
+	}
+
+	return big.NewInt(0).SetBytes(ToArrayReverse(paddedBs[:nonZeroPos+1])), nil
+}
+
+func ToArrayReverse(arr []byte) []byte {
+	l := len(arr)
+	x := make([]byte, 0)
+	for i := l - 1; i >= 0; i-- {
+		x = append(x, arr[i])
+	}
+	return x
+}
+
+// PadFixedBytes encode nonnegative int as little endian fixed bytes.
+func PadFixedBytes(bigint *big.Int, intBsLen int) ([]byte, error) {
+	ret := make([]byte, intBsLen)
+	if bigint.Cmp(big.NewInt(0)) < 0 {
+		return nil, fmt.Errorf("PadFixedBytes doesnot support negative big.Int, but got:%s", bigint.String())
+	}
+	bigBs := bigint.Bytes()
+	if len(bigBs) > intBsLen || (len(bigBs) == intBsLen && bigBs[0]>>7 == 1) {
+		return nil, fmt.Errorf("PadFixedBytes only support maximum 2**255-1 big.Int, but got:%s", bigint.String())
+	}
+	copy(ret[:len(bigBs)], make([]byte, len(bigBs)))
+	copy(ret[intBsLen-len(bigBs):], bigBs)
+	return ToArrayReverse(ret), nil
+}
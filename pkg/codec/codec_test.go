@@ -0,0 +1,125 @@
+package codec
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	polycm "github.com/polynetwork/poly/common"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// rawParam is the make tx param of a PLT unlock from palette to ethereum, extracted from the proof of
+// a `verifyHeaderAndExecuteTx` tx.
+const rawParam = "0x010001001434d4a23a1fc0c694f0d74ddaf9d8d564cfe2d430020000000000000014250e76987d838a75310c34bf422ea9f1ac4cc90606756e6c6f636b4a14956f47f50a910163d8bf957cf5846d573e7f87ca14c8a65fadf0e0ddaf421f28feab69bf6e2e589963f69f3cac80b974e7758200000000000000000000000000000000000000000000"
+
+func TestMakeTxParam(t *testing.T) {
+	raw := hexutil.MustDecode(rawParam)
+	p := new(MakeTxParam)
+	assert.NoError(t, p.Deserialization(raw))
+	assert.Equal(t, uint64(2), p.ToChainID)
+	assert.Equal(t, "unlock", p.Method)
+	assert.Equal(t, common.HexToAddress("0x250e76987d838a75310c34bf422ea9f1ac4cc906").Bytes(), p.ToContractAddress)
+	assert.Equal(t, raw, p.Serialization())
+
+	// same layout with poly cross chain manager
+	expect := new(ccmcom.MakeTxParam)
+	assert.NoError(t, expect.Deserialization(polycm.NewZeroCopySource(raw)))
+	assert.Equal(t, expect.TxHash, p.TxHash)
+	assert.Equal(t, expect.FromContractAddress, p.FromContractAddress)
+	assert.Equal(t, expect.Args, p.Args)
+
+	args, err := DecodeArgs(p.Args)
+	assert.NoError(t, err)
+	plt, ok := args.(*TxArgs)
+	assert.True(t, ok)
+	amount, _ := new(big.Int).SetString("616082589988960251715574", 10)
+	assert.Equal(t, amount, plt.Amount)
+	assert.Equal(t, common.HexToAddress("0xc8a65fadf0e0ddaf421f28feab69bf6e2e589963").Bytes(), plt.ToAddress)
+
+	assert.Error(t, p.Deserialization(raw[:len(raw)-1]))
+	assert.Error(t, p.Deserialization(append(raw, 0)))
+	t.Log(p)
+}
+
+func TestArgsRoundTrip(t *testing.T) {
+	asset := common.HexToAddress("0x0000000000000000000000000000000000000103").Bytes()
+	to := common.HexToAddress("0x5cD3143f91a13Fe971043E1e4605C1c23b46bF44").Bytes()
+
+	plt := &TxArgs{ToAssetHash: asset, ToAddress: to, Amount: big.NewInt(1000)}
+	decoded := new(TxArgs)
+	assert.NoError(t, decoded.Deserialization(plt.Serialization()))
+	assert.Equal(t, plt, decoded)
+
+	nft := &NFTArgs{ToAssetHash: asset, ToAddress: to, TokenID: big.NewInt(7), TokenURI: "https://nft.io/7"}
+	decodedNFT := new(NFTArgs)
+	assert.NoError(t, decodedNFT.Deserialization(nft.Serialization()))
+	assert.Equal(t, nft, decodedNFT)
+
+	// args distinguished by layout
+	args, err := DecodeArgs(nft.Serialization())
+	assert.NoError(t, err)
+	assert.Equal(t, nft, args)
+	args, err = DecodeArgs(plt.Serialization())
+	assert.NoError(t, err)
+	assert.Equal(t, plt, args)
+	_, err = DecodeArgs([]byte{1, 2, 3})
+	assert.Error(t, err)
+
+	data := &SafeTransferData{ToAddress: to, ToChainID: 2}
+	decodedData := new(SafeTransferData)
+	assert.NoError(t, decodedData.Deserialization(data.Serialization()))
+	assert.Equal(t, data, decodedData)
+}
+
+func TestWrapperRoundTrip(t *testing.T) {
+	lock := &WrapperLockArgs{
+		FromAsset: common.HexToAddress("0x0000000000000000000000000000000000000103"),
+		ToChainID: 2,
+		ToAddress: common.HexToAddress("0x5cD3143f91a13Fe971043E1e4605C1c23b46bF44").Bytes(),
+		Amount:    big.NewInt(1000),
+		Fee:       big.NewInt(10),
+		ID:        big.NewInt(1),
+	}
+	input, err := lock.Pack()
+	assert.NoError(t, err)
+	decoded := new(WrapperLockArgs)
+	assert.NoError(t, decoded.Unpack(input))
+	assert.Equal(t, lock, decoded)
+
+	nftLock := &NFTWrapperLockArgs{
+		FromAsset: common.HexToAddress("0x0000000000000000000000000000000000001002"),
+		ToChainID: 2,
+		ToAddress: common.HexToAddress("0x5cD3143f91a13Fe971043E1e4605C1c23b46bF44"),
+		TokenID:   big.NewInt(7),
+		FeeToken:  common.HexToAddress("0x0000000000000000000000000000000000000103"),
+		Fee:       big.NewInt(10),
+		ID:        big.NewInt(2),
+	}
+	nftInput, err := nftLock.Pack()
+	assert.NoError(t, err)
+	decodedNFT := new(NFTWrapperLockArgs)
+	assert.NoError(t, decodedNFT.Unpack(nftInput))
+	assert.Equal(t, nftLock, decodedNFT)
+
+	// method id mismatch
+	assert.Error(t, decoded.Unpack(nftInput))
+}
+
+func TestDecode(t *testing.T) {
+	v, err := Decode(TypeParam, hexutil.MustDecode(rawParam))
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(v.String(), "plt unlock"))
+	assert.True(t, strings.Contains(v.String(), "616082589988960251715574"))
+
+	nft := &NFTArgs{ToAssetHash: []byte{1}, ToAddress: []byte{2}, TokenID: big.NewInt(7), TokenURI: "uri"}
+	v, err = Decode(TypeNFTArgs, nft.Serialization())
+	assert.NoError(t, err)
+	assert.Equal(t, `nft unlock, to asset 0x01, to address 0x02, token id 7, token uri "uri"`, v.String())
+
+	_, err = Decode("unknown", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package codec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// payload types accepted by `Decode`.
+const (
+	TypeParam        = "param"
+	TypePLTArgs      = "plt"
+	TypeNFTArgs      = "nft"
+	TypeSafeTransfer = "safe-transfer"
+	TypeWrapper      = "wrapper"
+	TypeNFTWrapper   = "nft-wrapper"
+)
+
+// Decode decode the raw payload of type, and returns the value which prints in readable form.
+func Decode(typ string, raw []byte) (fmt.Stringer, error) {
+	switch strings.ToLower(typ) {
+	case TypeParam, "":
+		p := new(MakeTxParam)
+		return p, p.Deserialization(raw)
+	case TypePLTArgs:
+		args := new(TxArgs)
+		return args, args.Deserialization(raw)
+	case TypeNFTArgs:
+		args := new(NFTArgs)
+		return args, args.Deserialization(raw)
+	case TypeSafeTransfer:
+		d := new(SafeTransferData)
+		return d, d.Deserialization(raw)
+	case TypeWrapper:
+		args := new(WrapperLockArgs)
+		return args, args.Unpack(raw)
+	case TypeNFTWrapper:
+		args := new(NFTWrapperLockArgs)
+		return args, args.Unpack(raw)
+	default:
+		return nil, fmt.Errorf("unknown payload type %s", typ)
+	}
+}
+
+func (p *MakeTxParam) String() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "make tx param:\n")
+	fmt.Fprintf(b, "  tx id:          %s\n", hexutil.Encode(p.TxHash))
+	fmt.Fprintf(b, "  cross chain id: %s\n", hexutil.Encode(p.CrossChainID))
+	fmt.Fprintf(b, "  from contract:  %s\n", formatBytes(p.FromContractAddress))
+	fmt.Fprintf(b, "  to chain id:    %d\n", p.ToChainID)
+	fmt.Fprintf(b, "  to contract:    %s\n", formatBytes(p.ToContractAddress))
+	fmt.Fprintf(b, "  method:         %s\n", p.Method)
+	if args, err := DecodeArgs(p.Args); err == nil {
+		fmt.Fprintf(b, "  args:           %s", args)
+	} else {
+		fmt.Fprintf(b, "  args:           %s (%v)", hexutil.Encode(p.Args), err)
+	}
+	return b.String()
+}
+
+func (args *TxArgs) String() string {
+	return fmt.Sprintf("plt unlock, to asset %s, to address %s, amount %s",
+		formatBytes(args.ToAssetHash), formatBytes(args.ToAddress), args.Amount)
+}
+
+func (args *NFTArgs) String() string {
+	return fmt.Sprintf("nft unlock, to asset %s, to address %s, token id %s, token uri %q",
+		formatBytes(args.ToAssetHash), formatBytes(args.ToAddress), args.TokenID, args.TokenURI)
+}
+
+func (d *SafeTransferData) String() string {
+	return fmt.Sprintf("safe transfer to lock proxy, to address %s, to chain id %d", formatBytes(d.ToAddress), d.ToChainID)
+}
+
+func (args *WrapperLockArgs) String() string {
+	return fmt.Sprintf("wrapper lock, from asset %s, to chain id %d, to address %s, amount %s, fee %s, id %s",
+		args.FromAsset.Hex(), args.ToChainID, formatBytes(args.ToAddress), args.Amount, args.Fee, args.ID)
+}
+
+func (args *NFTWrapperLockArgs) String() string {
+	return fmt.Sprintf("nft wrapper lock, from asset %s, to chain id %d, to address %s, token id %s, fee token %s, fee %s, id %s",
+		args.FromAsset.Hex(), args.ToChainID, args.ToAddress.Hex(), args.TokenID, args.FeeToken.Hex(), args.Fee, args.ID)
+}
+
+// formatBytes prints 20 bytes as checksum address, and others as hex.
+func formatBytes(bz []byte) string {
+	if len(bz) == common.AddressLength {
+		return common.BytesToAddress(bz).Hex()
+	}
+	return hexutil.Encode(bz)
+}
@@ -0,0 +1,102 @@
+package codec
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const wrapperAbiJsonStr = `[
+	{"inputs":[{"internalType":"address","name":"fromAsset","type":"address"},{"internalType":"uint64","name":"toChainId","type":"uint64"},{"internalType":"bytes","name":"toAddress","type":"bytes"},{"internalType":"uint256","name":"amount","type":"uint256"},{"internalType":"uint256","name":"fee","type":"uint256"},{"internalType":"uint256","name":"id","type":"uint256"}],"name":"lock","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+const nftWrapperAbiJsonStr = `[
+	{"inputs":[{"internalType":"address","name":"fromAsset","type":"address"},{"internalType":"uint64","name":"toChainId","type":"uint64"},{"internalType":"address","name":"toAddress","type":"address"},{"internalType":"uint256","name":"tokenId","type":"uint256"},{"internalType":"address","name":"feeToken","type":"address"},{"internalType":"uint256","name":"fee","type":"uint256"},{"internalType":"uint256","name":"id","type":"uint256"}],"name":"lock","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+var (
+	wrapperABI    abi.ABI
+	nftWrapperABI abi.ABI
+)
+
+func init() {
+	var err error
+	if wrapperABI, err = abi.JSON(strings.NewReader(wrapperAbiJsonStr)); err != nil {
+		panic(err)
+	}
+	if nftWrapperABI, err = abi.JSON(strings.NewReader(nftWrapperAbiJsonStr)); err != nil {
+		panic(err)
+	}
+}
+
+// WrapperLockArgs is the input of PLT wrapper `lock`, the wrapper keeps `fee` and locks the rest
+// amount to lock proxy, `id` is used by relayer to identify the fee payment.
+type WrapperLockArgs struct {
+	FromAsset common.Address
+	ToChainID uint64
+	ToAddress []byte
+	Amount    *big.Int
+	Fee       *big.Int
+	ID        *big.Int
+}
+
+// Pack returns the tx input, including method id.
+func (args *WrapperLockArgs) Pack() ([]byte, error) {
+	return wrapperABI.Pack("lock", args.FromAsset, args.ToChainID, args.ToAddress, args.Amount, args.Fee, args.ID)
+}
+
+func (args *WrapperLockArgs) Unpack(input []byte) error {
+	values, err := unpackInput(wrapperABI, input)
+	if err != nil {
+		return err
+	}
+	args.FromAsset = values[0].(common.Address)
+	args.ToChainID = values[1].(uint64)
+	args.ToAddress = values[2].([]byte)
+	args.Amount = values[3].(*big.Int)
+	args.Fee = values[4].(*big.Int)
+	args.ID = values[5].(*big.Int)
+	return nil
+}
+
+// NFTWrapperLockArgs is the input of NFT wrapper `lock`, the fee is paid in `FeeToken`.
+type NFTWrapperLockArgs struct {
+	FromAsset common.Address
+	ToChainID uint64
+	ToAddress common.Address
+	TokenID   *big.Int
+	FeeToken  common.Address
+	Fee       *big.Int
+	ID        *big.Int
+}
+
+// Pack returns the tx input, including method id.
+func (args *NFTWrapperLockArgs) Pack() ([]byte, error) {
+	return nftWrapperABI.Pack("lock", args.FromAsset, args.ToChainID, args.ToAddress, args.TokenID, args.FeeToken, args.Fee, args.ID)
+}
+
+func (args *NFTWrapperLockArgs) Unpack(input []byte) error {
+	values, err := unpackInput(nftWrapperABI, input)
+	if err != nil {
+		return err
+	}
+	args.FromAsset = values[0].(common.Address)
+	args.ToChainID = values[1].(uint64)
+	args.ToAddress = values[2].(common.Address)
+	args.TokenID = values[3].(*big.Int)
+	args.FeeToken = values[4].(common.Address)
+	args.Fee = values[5].(*big.Int)
+	args.ID = values[6].(*big.Int)
+	return nil
+}
+
+func unpackInput(ab abi.ABI, input []byte) ([]interface{}, error) {
+	method, err := ab.MethodById(input)
+	if err != nil {
+		return nil, fmt.Errorf("input is not wrapper lock, err: %v", err)
+	}
+	return method.Inputs.UnpackValues(input[4:])
+}
@@ -0,0 +1,238 @@
+// Package collection indexes the tokens of an ERC721 asset from `Transfer` logs, and snapshots the owner,
+// uri and approval of every token, so that a collection could be compared before and after cross chain
+// round trip.
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransferEventID is the topic of `Transfer(address indexed from, address indexed to, uint256 indexed tokenId)`.
+var TransferEventID = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+const enumerableAbiJsonStr = `[
+	{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"index","type":"uint256"}],"name":"tokenOfOwnerByIndex","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"uint256","name":"index","type":"uint256"}],"name":"tokenByIndex","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// EnumerableABI is the optional enumeration extension of ERC721.
+var EnumerableABI abi.ABI
+
+func init() {
+	ab, err := abi.JSON(strings.NewReader(enumerableAbiJsonStr))
+	if err != nil {
+		panic(err)
+	}
+	EnumerableABI = ab
+}
+
+// UnpackTokenID decode the uint256 output of `tokenOfOwnerByIndex` and `tokenByIndex`.
+func UnpackTokenID(enc []byte) (*big.Int, error) {
+	if len(enc) != common.HashLength {
+		return nil, fmt.Errorf("invalid token id output length %d", len(enc))
+	}
+	return new(big.Int).SetBytes(enc), nil
+}
+
+type Transfer struct {
+	From     common.Address
+	To       common.Address
+	TokenID  *big.Int
+	Height   uint64
+	TxIndex  uint
+	LogIndex uint
+}
+
+// ParseTransfer decode ERC721 `Transfer` log, the token id is indexed in standard ERC721 and some assets
+// put it in data.
+func ParseTransfer(l *types.Log) (*Transfer, error) {
+	if len(l.Topics) < 3 || l.Topics[0] != TransferEventID {
+		return nil, fmt.Errorf("log is not transfer event")
+	}
+	t := &Transfer{
+		From:     common.BytesToAddress(l.Topics[1].Bytes()),
+		To:       common.BytesToAddress(l.Topics[2].Bytes()),
+		Height:   l.BlockNumber,
+		TxIndex:  l.TxIndex,
+		LogIndex: l.Index,
+	}
+	switch {
+	case len(l.Topics) == 4 && len(l.Data) == 0:
+		t.TokenID = l.Topics[3].Big()
+	case len(l.Topics) == 3 && len(l.Data) == common.HashLength:
+		t.TokenID = new(big.Int).SetBytes(l.Data)
+	default:
+		return nil, fmt.Errorf("transfer event with %d topics and %d bytes data is not ERC721", len(l.Topics), len(l.Data))
+	}
+	return t, nil
+}
+
+// Index is the owner of every existing token, it's built by replaying transfers from genesis or the
+// deployment of asset.
+type Index struct {
+	owners map[string]common.Address
+	ids    map[string]*big.Int
+}
+
+func NewIndex() *Index {
+	return &Index{owners: make(map[string]common.Address), ids: make(map[string]*big.Int)}
+}
+
+// Replay sort transfers in chain order and apply them to a new index.
+func Replay(list []*Transfer) *Index {
+	sorted := make([]*Transfer, len(list))
+	copy(sorted, list)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		if a.TxIndex != b.TxIndex {
+			return a.TxIndex < b.TxIndex
+		}
+		return a.LogIndex < b.LogIndex
+	})
+
+	idx := NewIndex()
+	for _, t := range sorted {
+		idx.Apply(t)
+	}
+	return idx
+}
+
+// Apply move the token to receiver, the token is burned if receiver is zero address.
+func (idx *Index) Apply(t *Transfer) {
+	key := t.TokenID.String()
+	if t.To == (common.Address{}) {
+		delete(idx.owners, key)
+		delete(idx.ids, key)
+		return
+	}
+	idx.owners[key] = t.To
+	idx.ids[key] = t.TokenID
+}
+
+func (idx *Index) Len() int {
+	return len(idx.owners)
+}
+
+func (idx *Index) Owner(tokenID *big.Int) (common.Address, bool) {
+	owner, ok := idx.owners[tokenID.String()]
+	return owner, ok
+}
+
+// Tokens returns all existing token ids in ascending order.
+func (idx *Index) Tokens() []*big.Int {
+	return idx.filter(func(common.Address) bool { return true })
+}
+
+// TokensOf returns token ids of owner in ascending order.
+func (idx *Index) TokensOf(owner common.Address) []*big.Int {
+	return idx.filter(func(cur common.Address) bool { return cur == owner })
+}
+
+func (idx *Index) filter(match func(owner common.Address) bool) []*big.Int {
+	list := make([]*big.Int, 0)
+	for key, owner := range idx.owners {
+		if match(owner) {
+			list = append(list, idx.ids[key])
+		}
+	}
+	SortIDs(list)
+	return list
+}
+
+func SortIDs(list []*big.Int) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Cmp(list[j]) < 0 })
+}
+
+type Token struct {
+	ID       *big.Int       `json:"id"`
+	Owner    common.Address `json:"owner"`
+	URI      string         `json:"uri"`
+	Approved common.Address `json:"approved"`
+}
+
+// Snapshot is the state of every token of asset at height.
+type Snapshot struct {
+	Asset  common.Address `json:"asset"`
+	Height uint64         `json:"height"`
+	Tokens []*Token       `json:"tokens"`
+}
+
+func (s *Snapshot) Token(tokenID *big.Int) *Token {
+	for _, t := range s.Tokens {
+		if t.ID.Cmp(tokenID) == 0 {
+			return t
+		}
+	}
+	return nil
+}
+
+// Owners returns the number of tokens of every owner.
+func (s *Snapshot) Owners() map[common.Address]int {
+	m := make(map[common.Address]int)
+	for _, t := range s.Tokens {
+		m[t.Owner]++
+	}
+	return m
+}
+
+func (s *Snapshot) Save(path string) error {
+	enc, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, enc, 0644)
+}
+
+func Load(path string) (*Snapshot, error) {
+	enc, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Snapshot)
+	if err := json.Unmarshal(enc, s); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s failed, err: %v", path, err)
+	}
+	return s, nil
+}
+
+// Diff returns the differences of tokens, asset and height are not compared because the collection may
+// be mapped to another asset on the other chain. approvals are cleared by transfer, so that it's only
+// compared if `approval` is true.
+func Diff(expect, actual *Snapshot, approval bool) []string {
+	list := make([]string, 0)
+	for _, e := range expect.Tokens {
+		a := actual.Token(e.ID)
+		if a == nil {
+			list = append(list, fmt.Sprintf("token %s missing", e.ID))
+			continue
+		}
+		if e.Owner != a.Owner {
+			list = append(list, fmt.Sprintf("token %s owner expect %s, actual %s", e.ID, e.Owner.Hex(), a.Owner.Hex()))
+		}
+		if e.URI != a.URI {
+			list = append(list, fmt.Sprintf("token %s uri expect %s, actual %s", e.ID, e.URI, a.URI))
+		}
+		if approval && e.Approved != a.Approved {
+			list = append(list, fmt.Sprintf("token %s approved expect %s, actual %s", e.ID, e.Approved.Hex(), a.Approved.Hex()))
+		}
+	}
+	for _, a := range actual.Tokens {
+		if expect.Token(a.ID) == nil {
+			list = append(list, fmt.Sprintf("token %s unexpected", a.ID))
+		}
+	}
+	return list
+}
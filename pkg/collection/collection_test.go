@@ -0,0 +1,135 @@
+package collection
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	alice = common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	bob   = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	zero  = common.Address{}
+)
+
+func transfer(from, to common.Address, id int64, height uint64, logIndex uint) *Transfer {
+	return &Transfer{From: from, To: to, TokenID: big.NewInt(id), Height: height, LogIndex: logIndex}
+}
+
+func TestParseTransfer(t *testing.T) {
+	l := &types.Log{
+		Topics:      []common.Hash{TransferEventID, zero.Hash(), alice.Hash(), common.BigToHash(big.NewInt(7))},
+		BlockNumber: 10,
+		TxIndex:     2,
+		Index:       3,
+	}
+	tr, err := ParseTransfer(l)
+	assert.NoError(t, err)
+	assert.Equal(t, zero, tr.From)
+	assert.Equal(t, alice, tr.To)
+	assert.Equal(t, int64(7), tr.TokenID.Int64())
+	assert.Equal(t, uint64(10), tr.Height)
+	assert.Equal(t, uint(2), tr.TxIndex)
+	assert.Equal(t, uint(3), tr.LogIndex)
+
+	// token id in data
+	l = &types.Log{Topics: []common.Hash{TransferEventID, alice.Hash(), bob.Hash()}, Data: common.BigToHash(big.NewInt(8)).Bytes()}
+	tr, err = ParseTransfer(l)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), tr.TokenID.Int64())
+
+	// other event
+	l = &types.Log{Topics: []common.Hash{common.HexToHash("0x01"), alice.Hash(), bob.Hash()}, Data: common.BigToHash(big.NewInt(8)).Bytes()}
+	_, err = ParseTransfer(l)
+	assert.Error(t, err)
+
+	// ERC20 transfer with amount in data is not distinguishable, but extra data is rejected
+	l = &types.Log{Topics: []common.Hash{TransferEventID, alice.Hash(), bob.Hash(), common.BigToHash(big.NewInt(8))}, Data: []byte{1}}
+	_, err = ParseTransfer(l)
+	assert.Error(t, err)
+}
+
+func TestReplay(t *testing.T) {
+	list := []*Transfer{
+		transfer(alice, bob, 1, 12, 0),
+		transfer(zero, alice, 1, 10, 0),
+		transfer(zero, alice, 2, 10, 1),
+		transfer(zero, alice, 3, 11, 0),
+		transfer(alice, zero, 3, 13, 0),
+		transfer(zero, bob, 10, 13, 1),
+	}
+	idx := Replay(list)
+	assert.Equal(t, 3, idx.Len())
+
+	owner, ok := idx.Owner(big.NewInt(1))
+	assert.True(t, ok)
+	assert.Equal(t, bob, owner)
+	_, ok = idx.Owner(big.NewInt(3))
+	assert.False(t, ok)
+
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(10)}, idx.Tokens())
+	assert.Equal(t, []*big.Int{big.NewInt(2)}, idx.TokensOf(alice))
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(10)}, idx.TokensOf(bob))
+
+	// the input is not reordered
+	assert.Equal(t, int64(1), list[0].TokenID.Int64())
+	assert.Equal(t, uint64(12), list[0].Height)
+}
+
+func TestSnapshot(t *testing.T) {
+	expect := &Snapshot{
+		Asset:  alice,
+		Height: 100,
+		Tokens: []*Token{
+			{ID: big.NewInt(1), Owner: alice, URI: "cat1.jpg", Approved: bob},
+			{ID: big.NewInt(2), Owner: bob, URI: "cat2.jpg"},
+		},
+	}
+	assert.Equal(t, map[common.Address]int{alice: 1, bob: 1}, expect.Owners())
+
+	dir, err := ioutil.TempDir("", "collection")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+	assert.NoError(t, expect.Save(path))
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expect.Height, loaded.Height)
+	assert.Empty(t, Diff(expect, loaded, true))
+
+	// approval is cleared after round trip
+	actual := &Snapshot{
+		Asset:  bob,
+		Height: 200,
+		Tokens: []*Token{
+			{ID: big.NewInt(2), Owner: bob, URI: "cat2.jpg"},
+			{ID: big.NewInt(1), Owner: alice, URI: "cat1.jpg"},
+		},
+	}
+	assert.Empty(t, Diff(expect, actual, false))
+	assert.Len(t, Diff(expect, actual, true), 1)
+
+	actual.Tokens[0].Owner = alice
+	actual.Tokens[1].URI = "dog1.jpg"
+	actual.Tokens = append(actual.Tokens, &Token{ID: big.NewInt(3), Owner: alice})
+	assert.Len(t, Diff(expect, actual, false), 3)
+	assert.Len(t, Diff(actual, expect, false), 3)
+}
+
+func TestUnpackTokenID(t *testing.T) {
+	enc, err := EnumerableABI.Pack("tokenOfOwnerByIndex", alice, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Len(t, enc, 4+2*common.HashLength)
+
+	id, err := UnpackTokenID(common.BigToHash(big.NewInt(9)).Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), id.Int64())
+	_, err = UnpackTokenID(nil)
+	assert.Error(t, err)
+}
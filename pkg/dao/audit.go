@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/goleveldb/leveldb/util"
+)
+
+const (
+	ActionSave   = "save"
+	ActionGet    = "get"
+	ActionRevoke = "revoke"
+	ActionExpire = "expire"
+)
+
+// AuditRecord tells which method used which secret, the secret itself is never recorded.
+type AuditRecord struct {
+	Time    time.Time
+	Action  string
+	Type    byte
+	Account string
+	User    string
+	Error   string `json:",omitempty"`
+}
+
+func (r *AuditRecord) String() string {
+	s := fmt.Sprintf("%s %-6s type %d account %s by %s", r.Time.Format(time.RFC3339), r.Action, r.Type, r.Account, r.User)
+	if r.Error != "" {
+		s += ", err: " + r.Error
+	}
+	return s
+}
+
+func writeAudit(action string, typ byte, account []byte, user string, err error) {
+	if user == "" {
+		user = "unknown"
+	}
+	rc := &AuditRecord{
+		Time:    time.Now(),
+		Action:  action,
+		Type:    typ,
+		Account: "0x" + hex.EncodeToString(account),
+		User:    user,
+	}
+	if err != nil {
+		rc.Error = err.Error()
+	}
+	bz, _ := json.Marshal(rc)
+	seq := atomic.AddUint32(&instance.seq, 1)
+	_ = instance.db.Put(auditKey(rc.Time, seq), bz, nil)
+}
+
+// Audit returns the latest `limit` audit records in time order, limit 0 means all of them.
+func Audit(limit int) ([]*AuditRecord, error) {
+	list := make([]*AuditRecord, 0)
+	iter := instance.db.NewIterator(util.BytesPrefix(prefixAudit), nil)
+	defer iter.Release()
+	for iter.Next() {
+		rc := new(AuditRecord)
+		if err := json.Unmarshal(iter.Value(), rc); err != nil {
+			return nil, err
+		}
+		list = append(list, rc)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(list) > limit {
+		list = list[len(list)-limit:]
+	}
+	return list, nil
+}
@@ -2,8 +2,11 @@ package dao
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/btcsuite/goleveldb/leveldb"
+	"github.com/btcsuite/goleveldb/leveldb/util"
 )
 
 var instance *DaoImpl
@@ -11,6 +14,11 @@ var instance *DaoImpl
 type DaoImpl struct {
 	db   *leveldb.DB
 	name string
+
+	mu     sync.RWMutex
+	master []byte // aes key derived from master passphrase, nil means secret store disabled
+	ttl    time.Duration
+	seq    uint32 // audit key sequence, avoid conflict in the same nanosecond
 }
 
 func NewDao(dir string) {
@@ -22,26 +30,44 @@ func NewDao(dir string) {
 	}
 	fmt.Printf("open leveldb %s\r\n", dir)
 	d.db = db
+	d.ttl = DefaultTTL
 	instance = d
+
+	if n, err := purgeLegacy(); err != nil {
+		fmt.Printf("purge legacy passwords err: %v\r\n", err)
+	} else if n > 0 {
+		fmt.Printf("purge %d legacy plaintext passwords\r\n", n)
+	}
 }
 
 func (d *DaoImpl) Name() string {
 	return d.name
 }
 
-func SavePwd(typ byte, k, v []byte) error {
-	key := formatKey(typ, k)
-	return instance.db.Put(key, v, nil)
-}
-
-func GetPwd(typ byte, k []byte) ([]byte, error) {
-	key := formatKey(typ, k)
-	return instance.db.Get(key, nil)
+// Close release the leveldb, it's used by sub-commands and tests which open the db temporarily.
+func Close() error {
+	if instance == nil {
+		return nil
+	}
+	return instance.db.Close()
 }
 
-func formatKey(typ byte, k []byte) []byte {
-	key := make([]byte, 0)
-	key = append(key, typ)
-	key = append(key, k...)
-	return key
+// purgeLegacy delete the passwords saved in plaintext by older versions, the key is formatted
+// as `type + address` and the type is in range [1, 3].
+func purgeLegacy() (int, error) {
+	batch := new(leveldb.Batch)
+	iter := instance.db.NewIterator(&util.Range{Start: []byte{1}, Limit: []byte{4}}, nil)
+	for iter.Next() {
+		if key := iter.Key(); len(key) == 21 {
+			batch.Delete(append([]byte{}, key...))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+	if batch.Len() == 0 {
+		return 0, nil
+	}
+	return batch.Len(), instance.db.Write(batch, nil)
 }
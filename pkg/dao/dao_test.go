@@ -1,20 +1,106 @@
 package dao
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
-func TestInsertStat(t *testing.T) {
-	cfg := &Config{
-		Ip:   "172.168.3.219",
-		Port: 3306,
-		User: "root",
-		Pwd:  "123456",
-		Db:   "txstat",
+func openTestDao(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "onrobot-dao")
+	assert.NoError(t, err)
+	NewDao(dir)
+	return func() {
+		_ = Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestSecretStore(t *testing.T) {
+	defer openTestDao(t)()
+
+	acc := []byte{0x01, 0x02}
+	assert.Equal(t, ErrNoMasterKey, SaveSecret(1, acc, []byte("pwd"), "test"))
+
+	assert.NoError(t, SetMasterKey("master"))
+	assert.True(t, Enabled())
+	assert.NoError(t, SaveSecret(1, acc, []byte("pwd"), "deploy"))
+
+	secret, err := GetSecret(1, acc, "bind")
+	assert.NoError(t, err)
+	assert.Equal(t, "pwd", string(secret))
+
+	_, err = GetSecret(2, acc, "bind")
+	assert.Equal(t, ErrNotFound, err)
+
+	// plaintext never saved
+	iter := instance.db.NewIterator(nil, nil)
+	for iter.Next() {
+		assert.NotContains(t, string(iter.Value()), "pwd")
 	}
-	NewDao(cfg)
-	err := InsertStat("127.0.0.1", 30001, "0xjflaksdjfoi23rnlasdf", 10, 10)
-	if err != nil {
-		t.Fatal(err)
+	iter.Release()
+
+	assert.Equal(t, ErrWrongMasterKey, SetMasterKey("another"))
+	assert.NoError(t, SetMasterKey("master"))
+
+	list, err := ListSecrets()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, byte(1), list[0].Type)
+	assert.Equal(t, acc, list[0].Account)
+
+	assert.NoError(t, RevokeSecret(1, acc, "cli"))
+	assert.Equal(t, ErrNotFound, RevokeSecret(1, acc, "cli"))
+
+	records, err := Audit(0)
+	assert.NoError(t, err)
+	actions := make([]string, 0)
+	for _, r := range records {
+		actions = append(actions, r.Action+":"+r.User)
 	}
+	assert.Equal(t, []string{"save:test", "save:deploy", "get:bind", "revoke:cli"}, actions)
+
+	records, err = Audit(1)
+	assert.NoError(t, err)
+	assert.Equal(t, ActionRevoke, records[0].Action)
+}
+
+func TestSecretExpire(t *testing.T) {
+	defer openTestDao(t)()
+
+	assert.NoError(t, SetMasterKey("master"))
+	SetTTL(time.Millisecond)
+	assert.NoError(t, SaveSecret(1, []byte{0x01}, []byte("pwd"), "test"))
+	assert.NoError(t, SaveSecret(1, []byte{0x02}, []byte("pwd"), "test"))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := GetSecret(1, []byte{0x01}, "test")
+	assert.Equal(t, ErrExpired, err)
+
+	n, err := PurgeExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	list, err := ListSecrets()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(list))
+}
+
+func TestPurgeLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onrobot-dao")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	NewDao(dir)
+	legacy := append([]byte{2}, make([]byte, 20)...)
+	assert.NoError(t, instance.db.Put(legacy, []byte("pwd"), nil))
+	assert.NoError(t, Close())
+
+	NewDao(dir)
+	defer Close()
+	_, err = instance.db.Get(legacy, nil)
+	assert.Error(t, err)
 }
@@ -0,0 +1,314 @@
+package dao
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/btcsuite/goleveldb/leveldb"
+	"github.com/btcsuite/goleveldb/leveldb/util"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// MasterKeyEnv is used if the master passphrase is not set in config.
+	MasterKeyEnv = "ONROBOT_MASTER_KEY"
+
+	DefaultTTL = 12 * time.Hour
+
+	kdfIterations = 100000
+	checkPlain    = "onrobot secret store"
+)
+
+var (
+	ErrNoMasterKey    = errors.New("secret store disabled, master key not set")
+	ErrWrongMasterKey = errors.New("master key mismatch")
+	ErrNotFound       = errors.New("secret not found")
+	ErrExpired        = errors.New("secret expired")
+)
+
+var (
+	prefixMeta   = []byte("secret/meta")
+	prefixSecret = []byte("secret/e/")
+	prefixAudit  = []byte("secret/a/")
+)
+
+// Entry describes a saved secret without the plaintext.
+type Entry struct {
+	Type    byte
+	Account []byte
+	Created time.Time
+	Expire  time.Time
+}
+
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.Expire.IsZero() && now.After(e.Expire)
+}
+
+type record struct {
+	Created time.Time
+	Expire  time.Time
+	Nonce   []byte
+	Cipher  []byte
+}
+
+type meta struct {
+	Salt  []byte
+	Nonce []byte
+	Check []byte
+}
+
+// SetMasterKey derive the aes key from passphrase, the env `ONROBOT_MASTER_KEY` is used if it's empty.
+// the secret store is disabled if both of them are empty, and `SaveSecret` always fails.
+// the first passphrase is bound with the store, `ErrWrongMasterKey` is returned for any other one.
+func SetMasterKey(passphrase string) error {
+	if passphrase == "" {
+		passphrase = os.Getenv(MasterKeyEnv)
+	}
+
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	if passphrase == "" {
+		instance.master = nil
+		return ErrNoMasterKey
+	}
+
+	m := new(meta)
+	bz, err := instance.db.Get(prefixMeta, nil)
+	switch err {
+	case nil:
+		if err := json.Unmarshal(bz, m); err != nil {
+			return err
+		}
+		key := deriveKey([]byte(passphrase), m.Salt)
+		plain, err := decrypt(key, m.Nonce, m.Check, prefixMeta)
+		if err != nil || string(plain) != checkPlain {
+			return ErrWrongMasterKey
+		}
+		instance.master = key
+		return nil
+
+	case leveldb.ErrNotFound:
+		m.Salt = make([]byte, 32)
+		if _, err := rand.Read(m.Salt); err != nil {
+			return err
+		}
+		key := deriveKey([]byte(passphrase), m.Salt)
+		if m.Nonce, m.Check, err = encrypt(key, []byte(checkPlain), prefixMeta); err != nil {
+			return err
+		}
+		if bz, err = json.Marshal(m); err != nil {
+			return err
+		}
+		if err := instance.db.Put(prefixMeta, bz, nil); err != nil {
+			return err
+		}
+		instance.master = key
+		return nil
+
+	default:
+		return err
+	}
+}
+
+// SetTTL set the lifetime of secrets saved later, 0 means never expire.
+func SetTTL(ttl time.Duration) {
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+	instance.ttl = ttl
+}
+
+// Enabled returns true if the master key is set.
+func Enabled() bool {
+	instance.mu.RLock()
+	defer instance.mu.RUnlock()
+	return instance.master != nil
+}
+
+// SaveSecret encrypt and save the secret of account, user is the name of method which is saving it.
+func SaveSecret(typ byte, account, secret []byte, user string) (err error) {
+	defer func() { writeAudit(ActionSave, typ, account, user, err) }()
+
+	instance.mu.RLock()
+	master, ttl := instance.master, instance.ttl
+	instance.mu.RUnlock()
+	if master == nil {
+		return ErrNoMasterKey
+	}
+
+	key := secretKey(typ, account)
+	rc := &record{Created: time.Now()}
+	if ttl > 0 {
+		rc.Expire = rc.Created.Add(ttl)
+	}
+	if rc.Nonce, rc.Cipher, err = encrypt(master, secret, key); err != nil {
+		return err
+	}
+	bz, err := json.Marshal(rc)
+	if err != nil {
+		return err
+	}
+	return instance.db.Put(key, bz, nil)
+}
+
+// GetSecret decrypt the secret of account, expired secret is deleted and `ErrExpired` returned.
+func GetSecret(typ byte, account []byte, user string) (secret []byte, err error) {
+	instance.mu.RLock()
+	master := instance.master
+	instance.mu.RUnlock()
+	if master == nil {
+		return nil, ErrNoMasterKey
+	}
+
+	key := secretKey(typ, account)
+	bz, err := instance.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() { writeAudit(ActionGet, typ, account, user, err) }()
+
+	rc := new(record)
+	if err := json.Unmarshal(bz, rc); err != nil {
+		return nil, err
+	}
+	if !rc.Expire.IsZero() && time.Now().After(rc.Expire) {
+		_ = instance.db.Delete(key, nil)
+		return nil, ErrExpired
+	}
+	return decrypt(master, rc.Nonce, rc.Cipher, key)
+}
+
+// RevokeSecret delete the secret of account.
+func RevokeSecret(typ byte, account []byte, user string) (err error) {
+	key := secretKey(typ, account)
+	if _, err := instance.db.Get(key, nil); err == leveldb.ErrNotFound {
+		return ErrNotFound
+	}
+	defer func() { writeAudit(ActionRevoke, typ, account, user, err) }()
+	return instance.db.Delete(key, nil)
+}
+
+// RevokeAll delete all of the secrets and returns the number of them.
+func RevokeAll(user string) (int, error) {
+	list, err := ListSecrets()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range list {
+		if err := RevokeSecret(e.Type, e.Account, user); err != nil {
+			return 0, err
+		}
+	}
+	return len(list), nil
+}
+
+// PurgeExpired delete expired secrets and returns the number of them.
+func PurgeExpired() (int, error) {
+	list, err := ListSecrets()
+	if err != nil {
+		return 0, err
+	}
+	n, now := 0, time.Now()
+	for _, e := range list {
+		if !e.Expired(now) {
+			continue
+		}
+		if err := instance.db.Delete(secretKey(e.Type, e.Account), nil); err != nil {
+			return n, err
+		}
+		writeAudit(ActionExpire, e.Type, e.Account, "", nil)
+		n++
+	}
+	return n, nil
+}
+
+// ListSecrets returns all secrets including the expired ones, the master key is not required.
+func ListSecrets() ([]*Entry, error) {
+	list := make([]*Entry, 0)
+	iter := instance.db.NewIterator(util.BytesPrefix(prefixSecret), nil)
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()[len(prefixSecret):]
+		if len(key) == 0 {
+			continue
+		}
+		rc := new(record)
+		if err := json.Unmarshal(iter.Value(), rc); err != nil {
+			return nil, err
+		}
+		list = append(list, &Entry{
+			Type:    key[0],
+			Account: append([]byte{}, key[1:]...),
+			Created: rc.Created,
+			Expire:  rc.Expire,
+		})
+	}
+	return list, iter.Error()
+}
+
+func secretKey(typ byte, account []byte) []byte {
+	key := make([]byte, 0, len(prefixSecret)+1+len(account))
+	key = append(key, prefixSecret...)
+	key = append(key, typ)
+	key = append(key, account...)
+	return key
+}
+
+// deriveKey is pbkdf2 with hmac-sha256, the key length is 32 for aes-256.
+func deriveKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, kdfIterations, 32, sha256.New)
+}
+
+// encrypt seal plaintext with aes-gcm, the db key is used as additional data so that
+// the cipher text can't be moved to another account.
+func encrypt(key, plain, ad []byte) (nonce, sealed []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plain, ad), nil
+}
+
+func decrypt(key, nonce, sealed, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, ErrWrongMasterKey
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, ad)
+	if err != nil {
+		return nil, ErrWrongMasterKey
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func auditKey(t time.Time, seq uint32) []byte {
+	key := make([]byte, len(prefixAudit)+12)
+	copy(key, prefixAudit)
+	binary.BigEndian.PutUint64(key[len(prefixAudit):], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint32(key[len(prefixAudit)+8:], seq)
+	return key
+}
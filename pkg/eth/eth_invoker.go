@@ -19,6 +19,7 @@ package eth
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -26,9 +27,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/palettechain/onRobot/pkg/encode"
+	"github.com/palettechain/onRobot/pkg/codec"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/polywrap"
+	"github.com/palettechain/onRobot/pkg/signer"
 	pltabi "github.com/palettechain/palette_token/go_abi/plt"
 	"github.com/polynetwork/eth-contracts/go_abi/eccd_abi"
 	"github.com/polynetwork/eth-contracts/go_abi/eccm_abi"
@@ -36,7 +38,6 @@ import (
 	"github.com/polynetwork/eth-contracts/go_abi/lock_proxy_abi"
 	nftlp "github.com/polynetwork/nft-contracts/go_abi/nft_lock_proxy_abi"
 	nftmapping "github.com/polynetwork/nft-contracts/go_abi/nft_mapping_abi"
-	polycm "github.com/polynetwork/poly/common"
 	wrapabi "github.com/polynetwork/wrapper/abi/eth"
 )
 
@@ -45,18 +46,34 @@ import (
 // 而对应的proxy和NFT的proxy一样，来自项目github.com/polynetwork/eth-contracts.git
 
 type EthInvoker struct {
-	PrivateKey *ecdsa.PrivateKey
-	ChainID    uint64
-	Tools      *ETHTools
-	NM         *NonceManager
-	TestSigner *EthSigner
+	Signer  signer.Signer
+	ChainID uint64
+	Tools   *ETHTools
+	NM      *NonceManager
 }
 
 var (
 	DefaultGasLimit = 7000000
 )
 
-func NewEInvoker(chainID uint64, url string, privateKey *ecdsa.PrivateKey) *EthInvoker {
+// ErrReadOnly is returned while sending tx with invoker created by `NewReader`.
+var ErrReadOnly = errors.New("read-only invoker can not sign tx")
+
+func NewEInvoker(chainID uint64, url string, privateKey *ecdsa.PrivateKey) (*EthInvoker, error) {
+	s, err := signer.NewKeySigner(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerInvoker(chainID, url, s), nil
+}
+
+// NewReader create invoker without signer, it only needs rpc access and is used to query contracts.
+func NewReader(chainID uint64, url string) *EthInvoker {
+	return NewSignerInvoker(chainID, url, nil)
+}
+
+// NewSignerInvoker create invoker with any kind of signer, e.g: remote signer which holds the key in another process.
+func NewSignerInvoker(chainID uint64, url string, s signer.Signer) *EthInvoker {
 	instance := &EthInvoker{}
 	instance.ChainID = chainID
 	instance.Tools = NewEthTools(url)
@@ -64,22 +81,16 @@ func NewEInvoker(chainID uint64, url string, privateKey *ecdsa.PrivateKey) *EthI
 		log.Errorf("dail eth failed")
 	}
 	instance.NM = NewNonceManager(instance.Tools.GetEthClient())
-	instance.PrivateKey = privateKey
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
-	instance.TestSigner = &EthSigner{
-		PrivateKey: privateKey,
-		Address:    address,
-	}
+	instance.Signer = s
 	return instance
 }
 
+// Address returns the signer address, or empty address for read-only invoker.
 func (i *EthInvoker) Address() common.Address {
-	publicKey := i.PrivateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		panic("ecdsa public key convert failed")
+	if i.Signer == nil {
+		return common.Address{}
 	}
-	return crypto.PubkeyToAddress(*publicKeyECDSA)
+	return i.Signer.Address()
 }
 
 func (i *EthInvoker) TransferETH(to common.Address, amount *big.Int) (common.Hash, error) {
@@ -89,10 +100,11 @@ func (i *EthInvoker) TransferETH(to common.Address, amount *big.Int) (common.Has
 	}
 	auth.Value = amount
 	tx := types.NewTransaction(auth.Nonce.Uint64(), to, amount, auth.GasLimit, auth.GasPrice, []byte{})
-	if tx, err = types.SignTx(tx, types.HomesteadSigner{}, i.PrivateKey); err != nil {
+	if tx, err = i.Signer.SignTx(types.HomesteadSigner{}, tx); err != nil {
+		i.NM.DecreaseAddressNonce(auth.From, auth.Nonce.Uint64())
 		return utils.EmptyHash, err
 	}
-	if err := i.Tools.ethclient.SendTransaction(context.Background(), tx, bind.PrivateTxArgs{}); err != nil {
+	if err := i.backend().SendTransaction(context.Background(), tx, bind.PrivateTxArgs{}); err != nil {
 		return utils.EmptyHash, err
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
@@ -105,34 +117,34 @@ func (i *EthInvoker) ETHBalance(owner common.Address) (*big.Int, error) {
 	return i.Tools.ethclient.BalanceAt(context.Background(), owner, nil)
 }
 
-func (i *EthInvoker) DeployPLTLockProxy() (common.Address, error) {
+func (i *EthInvoker) DeployPLTLockProxy() (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	contractAddr, tx, _, err := lock_proxy_abi.DeployLockProxy(auth, i.backend())
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddr, nil
+	return contractAddr, tx.Hash(), nil
 }
 
-func (i *EthInvoker) DeployNFTLockProxy() (common.Address, error) {
+func (i *EthInvoker) DeployNFTLockProxy() (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	contractAddr, tx, _, err := nftlp.DeployPolyNFTLockProxy(auth, i.backend())
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddr, nil
+	return contractAddr, tx.Hash(), nil
 }
 
 func (i *EthInvoker) SetPLTCCMP(proxyAddr, ccmpAddr common.Address) (common.Hash, error) {
@@ -191,19 +203,19 @@ func (i *EthInvoker) GetNFTCCMP(proxyAddr common.Address) (common.Address, error
 	return proxy.ManagerProxyContract(nil)
 }
 
-func (i *EthInvoker) DeployPLTAsset() (common.Address, error) {
+func (i *EthInvoker) DeployPLTAsset() (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	contractAddr, tx, _, err := pltabi.DeployPaletteToken(auth, i.backend())
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddr, nil
+	return contractAddr, tx.Hash(), nil
 }
 
 func (i *EthInvoker) DeployNFT(lockProxy common.Address, name, symbol string) (common.Address, error) {
@@ -228,49 +240,49 @@ func (i *EthInvoker) DeployNFT(lockProxy common.Address, name, symbol string) (c
 	return address, nil
 }
 
-func (i *EthInvoker) DeployECCDContract() (common.Address, error) {
+func (i *EthInvoker) DeployECCDContract() (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, fmt.Errorf("DeployECCDContract, err: %v", err)
+		return utils.EmptyAddress, utils.EmptyHash, fmt.Errorf("DeployECCDContract, err: %v", err)
 	}
 	contractAddress, tx, _, err := eccd_abi.DeployEthCrossChainData(auth, i.backend())
 	if err != nil {
-		return common.Address{}, fmt.Errorf("DeployECCDContract, err: %v", err)
+		return common.Address{}, utils.EmptyHash, fmt.Errorf("DeployECCDContract, err: %v", err)
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddress, nil
+	return contractAddress, tx.Hash(), nil
 }
 
-func (i *EthInvoker) DeployECCMContract(eccd common.Address, sideChainID uint64, whiteList []common.Address, curBookeepers []byte) (common.Address, error) {
+func (i *EthInvoker) DeployECCMContract(eccd common.Address, sideChainID uint64, whiteList []common.Address, curBookeepers []byte) (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, fmt.Errorf("DeployECCMContract, err: %v", err)
+		return utils.EmptyAddress, utils.EmptyHash, fmt.Errorf("DeployECCMContract, err: %v", err)
 	}
 	contractAddress, tx, _, err := eccm_abi.DeployEthCrossChainManager(auth, i.backend(), eccd, sideChainID, whiteList, curBookeepers)
 	if err != nil {
-		return common.Address{}, fmt.Errorf("DeployECCMContract, err: %v", err)
+		return common.Address{}, utils.EmptyHash, fmt.Errorf("DeployECCMContract, err: %v", err)
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddress, nil
+	return contractAddress, tx.Hash(), nil
 }
 
-func (i *EthInvoker) DeployCCMPContract(eccmAddress common.Address) (common.Address, error) {
+func (i *EthInvoker) DeployCCMPContract(eccmAddress common.Address) (common.Address, common.Hash, error) {
 	auth, err := i.makeAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	contractAddress, tx, _, err := eccmp_abi.DeployEthCrossChainManagerProxy(auth, i.backend(), eccmAddress)
 	if err != nil {
-		return utils.EmptyAddress, fmt.Errorf("DeployCCMPContract, err: %v", err)
+		return utils.EmptyAddress, utils.EmptyHash, fmt.Errorf("DeployCCMPContract, err: %v", err)
 	}
 	if err := i.waitTxConfirm(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return contractAddress, nil
+	return contractAddress, tx.Hash(), nil
 }
 
 func (i *EthInvoker) BindPLTAsset(
@@ -473,6 +485,27 @@ func (i *EthInvoker) ECCDOwnership(eccdAddr common.Address) (common.Address, err
 	return eccd.Owner(nil)
 }
 
+// CheckIfFromChainTxExist check that the poly tx has been executed by eccm.
+func (i *EthInvoker) CheckIfFromChainTxExist(eccdAddr common.Address, fromChainID uint64, polyTxHash []byte) (bool, error) {
+	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, i.backend())
+	if err != nil {
+		return false, err
+	}
+	var tx [32]byte
+	copy(tx[:], polyTxHash)
+	return eccd.CheckIfFromChainTxExist(nil, fromChainID, tx)
+}
+
+// GetCurEpochStartHeight returns the poly height since which the book keepers in eccd are valid.
+func (i *EthInvoker) GetCurEpochStartHeight(eccdAddr common.Address) (uint64, error) {
+	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, i.backend())
+	if err != nil {
+		return 0, err
+	}
+	height, err := eccd.GetCurEpochStartHeight(nil)
+	return uint64(height), err
+}
+
 func (i *EthInvoker) TransferECCMOwnership(eccm, ccmp common.Address) (common.Hash, error) {
 	eccmContract, err := eccm_abi.NewEthCrossChainManager(eccm, i.backend())
 	if err != nil {
@@ -778,7 +811,7 @@ func (i *EthInvoker) PLTUnlock(
 	if err != nil {
 		return utils.EmptyHash, err
 	}
-	args := encode.TxArgs{
+	args := codec.TxArgs{
 		ToAssetHash: toAsset.Bytes(),
 		ToAddress:   toAddress.Bytes(),
 		Amount:      amount,
@@ -806,6 +839,10 @@ func (i *EthInvoker) WrapLock(wrapAddr, fromAsset, toUser common.Address, dstCha
 	if err != nil {
 		return utils.EmptyHash, err
 	}
+	// ether is locked with zero address and paid by `msg.value`
+	if fromAsset == utils.EmptyAddress {
+		auth.Value = amount
+	}
 	tx, err := wrap.Lock(auth, fromAsset, dstChainId, toUser.Bytes(), amount, fee, id)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -817,6 +854,137 @@ func (i *EthInvoker) WrapLock(wrapAddr, fromAsset, toUser common.Address, dstCha
 	return tx.Hash(), nil
 }
 
+// WrapperSetFeeCollector set the collector who can extract fee from wrapper, `kind` is `polywrap.KindPLT`
+// or `polywrap.KindNFT`, only wrapper owner can call it. the other admin operations are the same.
+func (i *EthInvoker) WrapperSetFeeCollector(kind string, wrapAddr, collector common.Address) (common.Hash, error) {
+	return i.sendWrapperTx(kind, wrapAddr, nil, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SetFeeCollector(auth, collector)
+	})
+}
+
+func (i *EthInvoker) WrapperSetLockProxy(kind string, wrapAddr, lockProxy common.Address) (common.Hash, error) {
+	return i.sendWrapperTx(kind, wrapAddr, nil, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SetLockProxy(auth, lockProxy)
+	})
+}
+
+func (i *EthInvoker) WrapperPause(kind string, wrapAddr common.Address) (common.Hash, error) {
+	return i.sendWrapperTx(kind, wrapAddr, nil, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Pause(auth)
+	})
+}
+
+func (i *EthInvoker) WrapperUnpause(kind string, wrapAddr common.Address) (common.Hash, error) {
+	return i.sendWrapperTx(kind, wrapAddr, nil, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Unpause(auth)
+	})
+}
+
+// WrapperExtractFee transfer the fee of token kept by wrapper to fee collector, the invoker should be collector.
+func (i *EthInvoker) WrapperExtractFee(kind string, wrapAddr, token common.Address) (common.Hash, error) {
+	return i.sendWrapperTx(kind, wrapAddr, nil, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.ExtractFee(auth, token)
+	})
+}
+
+// WrapperSpeedUp pay more fee for the lock tx, ether fee is paid by `msg.value` and erc20 fee should be
+// approved to wrapper before.
+func (i *EthInvoker) WrapperSpeedUp(kind string, wrapAddr, token common.Address, lockTx common.Hash, fee *big.Int) (common.Hash, error) {
+	var value *big.Int
+	if token == utils.EmptyAddress {
+		value = fee
+	}
+	return i.sendWrapperTx(kind, wrapAddr, value, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SpeedUp(auth, token, lockTx, fee)
+	})
+}
+
+func (i *EthInvoker) WrapperFeeCollector(kind string, wrapAddr common.Address) (common.Address, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return utils.EmptyAddress, err
+	}
+	return w.FeeCollector(nil)
+}
+
+func (i *EthInvoker) WrapperLockProxy(kind string, wrapAddr common.Address) (common.Address, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return utils.EmptyAddress, err
+	}
+	return w.LockProxy(nil)
+}
+
+func (i *EthInvoker) WrapperPaused(kind string, wrapAddr common.Address) (bool, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return false, err
+	}
+	return w.Paused(nil)
+}
+
+// WrapperLockEvents returns the `PolyWrapperLock` events in tx.
+func (i *EthInvoker) WrapperLockEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.LockEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := i.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return w.LockEvents(receipt)
+}
+
+// WrapperSpeedUpEvents returns the `PolyWrapperSpeedUp` events in tx.
+func (i *EthInvoker) WrapperSpeedUpEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.SpeedUpEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := i.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return w.SpeedUpEvents(receipt)
+}
+
+// FindWrapperLock search the lock event with `id` from block `start` to the latest block.
+func (i *EthInvoker) FindWrapperLock(kind string, wrapAddr common.Address, start uint64, id *big.Int) (*polywrap.LockEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return nil, err
+	}
+	return w.FindLock(context.Background(), start, 0, id)
+}
+
+func (i *EthInvoker) sendWrapperTx(
+	kind string,
+	wrapAddr common.Address,
+	value *big.Int,
+	send func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error),
+) (common.Hash, error) {
+	w, err := polywrap.New(kind, wrapAddr, i.backend())
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	auth, err := i.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	if value != nil {
+		auth.Value = value
+	}
+	tx, err := send(w, auth)
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	if err := i.waitTxConfirm(tx.Hash()); err != nil {
+		return utils.EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
 func (i *EthInvoker) NFTApprove(asset, to common.Address, token *big.Int) (common.Hash, error) {
 	cm, err := nftmapping.NewCrossChainNFTMapping(asset, i.backend())
 	if err != nil {
@@ -886,7 +1054,7 @@ func (i *EthInvoker) NFTSafeTransferFrom(
 	if err != nil {
 		return utils.EmptyHash, err
 	}
-	data := assembleSafeTransferCallData(to, toChainID)
+	data := (&codec.SafeTransferData{ToAddress: to.Bytes(), ToChainID: toChainID}).Serialization()
 	tx, err := cm.SafeTransferFrom0(auth, from, proxy, tokenID, data)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -961,18 +1129,22 @@ func (i *EthInvoker) SuggestGasPrice() (*big.Int, error) {
 }
 
 func (i *EthInvoker) makeAuth() (*bind.TransactOpts, error) {
+	if i.Signer == nil {
+		return nil, ErrReadOnly
+	}
 	fromAddress := i.Address()
-	nonce, err := i.backend().PendingNonceAt(context.Background(), fromAddress)
+	nonce, err := i.NM.GetAddressNonce(fromAddress)
 	if err != nil {
 		return nil, fmt.Errorf("makeAuth, addr %s, err %v", fromAddress.Hex(), err)
 	}
 
 	gasPrice, err := i.backend().SuggestGasPrice(context.Background())
 	if err != nil {
+		i.NM.DecreaseAddressNonce(fromAddress, nonce)
 		return nil, fmt.Errorf("makeAuth, %v", err)
 	}
 
-	auth := bind.NewKeyedTransactor(i.PrivateKey)
+	auth := signer.NewTransactor(i.Signer)
 	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = big.NewInt(int64(0))       // in wei
 	auth.GasLimit = uint64(DefaultGasLimit) // in units
@@ -990,12 +1162,5 @@ func (i *EthInvoker) waitTxConfirm(hash common.Hash) error {
 }
 
 func (i *EthInvoker) backend() bind.ContractBackend {
-	return i.Tools.GetEthClient()
-}
-
-func assembleSafeTransferCallData(toAddress common.Address, chainID uint64) []byte {
-	sink := polycm.NewZeroCopySink(nil)
-	sink.WriteVarBytes(toAddress.Bytes())
-	sink.WriteUint64(chainID)
-	return sink.Bytes()
+	return &nonceBackend{ContractBackend: i.Tools.GetEthClient(), nm: i.NM, address: i.Address()}
 }
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/palettechain/onRobot/pkg/log"
+	pltabi "github.com/palettechain/palette_token/go_abi/plt"
 	"github.com/polynetwork/eth-contracts/go_abi/eccm_abi"
 )
 
@@ -89,6 +90,15 @@ type BlockRep struct {
 	Id      uint          `json:"id"`
 }
 
+type proofRep struct {
+	JsonRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Id uint `json:"id"`
+}
+
 func NewEthTools(url string) *ETHTools {
 	ethclient, err := ethclient.Dial(url)
 	if err != nil {
@@ -108,6 +118,15 @@ func (s *ETHTools) GetEthClient() *ethclient.Client {
 	return s.ethclient
 }
 
+// PLTBalanceOf query PLT asset balance without private key.
+func (s *ETHTools) PLTBalanceOf(asset, user common.Address) (*big.Int, error) {
+	instance, err := pltabi.NewPaletteToken(asset, s.ethclient)
+	if err != nil {
+		return nil, err
+	}
+	return instance.BalanceOf(nil, user)
+}
+
 func (s *ETHTools) GetNodeHeight() (uint64, error) {
 	req := &heightReq{
 		JsonRpc: "2.0",
@@ -161,11 +180,43 @@ func (s *ETHTools) GetBlockHeader(height uint64) (*types.Header, error) {
 	return rsp.Result, nil
 }
 
+// GetProof returns the raw result of `eth_getProof`, which is the proof format that poly verifies.
+func (s *ETHTools) GetProof(contractAddr common.Address, key string, height uint64) ([]byte, error) {
+	params := []interface{}{contractAddr.Hex(), []string{key}, fmt.Sprintf("0x%x", height)}
+	req := &BlockReq{
+		JsonRpc: "2.0",
+		Method:  "eth_getProof",
+		Params:  params,
+		Id:      1,
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("GetProof: marshal req err: %s", err)
+	}
+	resp, err := s.restclient.SendRestRequest(data)
+	if err != nil {
+		return nil, fmt.Errorf("GetProof err: %s", err)
+	}
+	rsp := &proofRep{}
+	if err := json.Unmarshal(resp, rsp); err != nil {
+		return nil, fmt.Errorf("GetProof, unmarshal resp err: %s", err)
+	}
+	if rsp.Error != nil {
+		return nil, fmt.Errorf("GetProof err: %s", rsp.Error.Message)
+	}
+	return rsp.Result, nil
+}
+
 func (s *ETHTools) GetChainID() (*big.Int, error) {
 	return s.ethclient.ChainID(context.Background())
 }
 
 func (s *ETHTools) GetSmartContractEventByBlock(contractAddr string, height uint64) ([]*LockEvent, []*UnlockEvent, error) {
+	return s.GetSmartContractEventByRange(contractAddr, height, height)
+}
+
+// GetSmartContractEventByRange filter eccm events in blocks [start, end].
+func (s *ETHTools) GetSmartContractEventByRange(contractAddr string, start, end uint64) ([]*LockEvent, []*UnlockEvent, error) {
 	eccmAddr := common.HexToAddress(contractAddr)
 	instance, err := eccm_abi.NewEthCrossChainManager(eccmAddr, s.ethclient)
 	if err != nil {
@@ -173,8 +224,8 @@ func (s *ETHTools) GetSmartContractEventByBlock(contractAddr string, height uint
 	}
 
 	opt := &bind.FilterOpts{
-		Start:   height,
-		End:     &height,
+		Start:   start,
+		End:     &end,
 		Context: context.Background(),
 	}
 
@@ -198,7 +249,7 @@ func (s *ETHTools) GetSmartContractEventByBlock(contractAddr string, height uint
 				Saddress: evt.Sender.String(),
 				Tchain:   uint32(evt.ToChainId),
 				Value:    evt.Rawdata,
-				Height:   height,
+				Height:   evt.Raw.BlockNumber,
 			})
 		}
 	}
@@ -222,7 +273,7 @@ func (s *ETHTools) GetSmartContractEventByBlock(contractAddr string, height uint
 				RTxid:    hex.EncodeToString(evt.CrossChainTxHash),
 				FromTxId: hex.EncodeToString(evt.FromChainTxHash),
 				Token:    hex.EncodeToString(evt.ToContract),
-				Height:   height,
+				Height:   evt.Raw.BlockNumber,
 			})
 		}
 	}
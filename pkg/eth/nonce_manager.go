@@ -18,72 +18,65 @@ package eth
 
 import (
 	"context"
-	"sync"
-	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/nonce"
 )
 
-const ClearNonceInterval = 10 * time.Minute
-
 type NonceManager struct {
-	addressNonce map[common.Address]uint64
-	ethClient    *ethclient.Client
-	lock         sync.RWMutex
+	*nonce.Manager
+	ethClient *ethclient.Client
 }
 
 func NewNonceManager(ethClient *ethclient.Client) *NonceManager {
-	nonceManager := &NonceManager{
-		addressNonce: make(map[common.Address]uint64),
-		ethClient:    ethClient,
+	return &NonceManager{
+		Manager:   nonce.NewManager(),
+		ethClient: ethClient,
 	}
-	go nonceManager.clearNonce()
-	return nonceManager
 }
 
-// return account nonce, and than nonce++
-func (this *NonceManager) GetAddressNonce(address common.Address) uint64 {
-	this.lock.Lock()
-	defer this.lock.Unlock()
-
-	// return a new point
-	nonce, ok := this.addressNonce[address]
-	if !ok {
-		// get nonce from eth network
-		uintNonce, err := this.ethClient.PendingNonceAt(context.Background(), address)
-		if err != nil {
-			log.Infof("GetAddressNonce: cannot get account %s nonce, err: %s, set it to nil!",
-				address, err)
-		}
-		this.addressNonce[address] = uintNonce
-		nonce = uintNonce
+func (this *NonceManager) fetcher(address common.Address) nonce.Fetcher {
+	return func() (uint64, error) {
+		return this.ethClient.PendingNonceAt(context.Background(), address)
 	}
-	// increase record
-	this.addressNonce[address]++
-	return nonce
 }
 
-func (this *NonceManager) DecreaseAddressNonce(address common.Address) {
-	this.lock.Lock()
-	defer this.lock.Unlock()
+// return account nonce, and than nonce++
+func (this *NonceManager) GetAddressNonce(address common.Address) (uint64, error) {
+	return this.Acquire(address, this.fetcher(address))
+}
+
+// DecreaseAddressNonce give back the nonce of a tx which failed to send.
+func (this *NonceManager) DecreaseAddressNonce(address common.Address, n uint64) {
+	this.Release(address, n)
+}
 
-	nonce, ok := this.addressNonce[address]
-	if ok && nonce > 0 {
-		this.addressNonce[address]--
+func (this *NonceManager) onSendFailed(address common.Address, n uint64, err error) {
+	if !nonce.IsNonceTooLow(err) {
+		this.Release(address, n)
+		return
+	}
+	log.Warnf("%s nonce %d too low, resync from tx pool", address.Hex(), n)
+	if err := this.Resync(address, this.fetcher(address)); err != nil {
+		log.Errorf("failed to resync %s nonce, err: %v", address.Hex(), err)
 	}
 }
 
-// clear nonce per
-func (this *NonceManager) clearNonce() {
-	for {
-		<-time.After(ClearNonceInterval)
-		this.lock.Lock()
-		for addr := range this.addressNonce {
-			delete(this.addressNonce, addr)
-		}
-		this.lock.Unlock()
-		//log.Infof("clearNonce: clear all cache nonce")
+// nonceBackend release the nonce allocated by `makeAuth` if the tx failed to send.
+type nonceBackend struct {
+	bind.ContractBackend
+	nm      *NonceManager
+	address common.Address
+}
+
+func (b *nonceBackend) SendTransaction(ctx context.Context, tx *types.Transaction, args bind.PrivateTxArgs) error {
+	err := b.ContractBackend.SendTransaction(ctx, tx, args)
+	if err != nil {
+		b.nm.onSendFailed(b.address, tx.Nonce(), err)
 	}
+	return err
 }
@@ -0,0 +1,98 @@
+package eth
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/tracker"
+)
+
+// trackScanRange limit the blocks of one filter request, some rpc providers reject large ranges.
+const trackScanRange uint64 = 2000
+
+// TrackChain implement `tracker.Chain` with the eccm events, both palette and ethereum are supported
+// because palette is compatible with ethereum rpc.
+type TrackChain struct {
+	name        string
+	sideChainID uint64
+	eccm        common.Address
+	tools       *ETHTools
+}
+
+func NewTrackChain(name string, sideChainID uint64, url string, eccm common.Address) (*TrackChain, error) {
+	tools := NewEthTools(url)
+	if tools == nil {
+		return nil, fmt.Errorf("dial %s %s failed", name, url)
+	}
+	return &TrackChain{name: name, sideChainID: sideChainID, eccm: eccm, tools: tools}, nil
+}
+
+func (c *TrackChain) Name() string {
+	return c.name
+}
+
+func (c *TrackChain) SideChainID() uint64 {
+	return c.sideChainID
+}
+
+func (c *TrackChain) Height() (uint64, error) {
+	return c.tools.GetNodeHeight()
+}
+
+func (c *TrackChain) BlockTime(height uint64) (time.Time, error) {
+	header, err := c.tools.GetBlockHeader(height)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if header == nil {
+		return time.Time{}, fmt.Errorf("block %d not found", height)
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+func (c *TrackChain) CrossChainEvent(hash string) (*tracker.CrossChainEvent, error) {
+	receipt, err := c.tools.GetEthClient().TransactionReceipt(context.Background(), common.HexToHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("get receipt of %s failed, err: %v", hash, err)
+	}
+	height := receipt.BlockNumber.Uint64()
+	lockEvents, _, err := c.tools.GetSmartContractEventByBlock(c.eccm.Hex(), height)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range lockEvents {
+		if strings.EqualFold(event.TxHash, hash) {
+			return &tracker.CrossChainEvent{
+				TxHash:    event.TxHash,
+				TxID:      event.Txid,
+				ToChainID: uint64(event.Tchain),
+				Height:    height,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("CrossChainEvent of eccm %s not found in tx %s", c.eccm.Hex(), hash)
+}
+
+func (c *TrackChain) FindExecution(txID []byte, start, end uint64) (*tracker.Record, error) {
+	fromTxID := hex.EncodeToString(txID)
+	for from := start; from <= end; from += trackScanRange {
+		to := from + trackScanRange - 1
+		if to > end {
+			to = end
+		}
+		_, unlockEvents, err := c.tools.GetSmartContractEventByRange(c.eccm.Hex(), from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range unlockEvents {
+			if event.FromTxId == fromTxID {
+				return &tracker.Record{TxHash: event.Txid, Height: event.Height}, nil
+			}
+		}
+	}
+	return nil, nil
+}
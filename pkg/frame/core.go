@@ -1,6 +1,8 @@
 package frame
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/palettechain/onRobot/pkg/log"
@@ -8,7 +10,7 @@ import (
 
 var (
 	Tool      = NewPaletteTool()
-	startTime = time.Now().Unix()
+	startTime = time.Now()
 )
 
 type Method func() bool
@@ -16,28 +18,50 @@ type GcFunc func()
 
 type PaletteTool struct {
 	//Map name to method
-	methodsMap map[string]Method
-	//Map method result
-	methodsRes map[string]bool
+	methodsMap map[string]Case
+	//Method records in invocation order
+	records []*Record
+	//Protect records while methods running concurrently
+	mu sync.Mutex
+	//Report file path, empty means no report
+	reportPath string
 	//gc func
 	gc GcFunc
+	//Map goroutine id to the method which is running on it
+	bindings map[uint64]*binding
+}
+
+// binding is the step name and params of method which is running on a goroutine.
+type binding struct {
+	name   string
+	params map[string]map[string]interface{}
 }
 
 func NewPaletteTool() *PaletteTool {
 	return &PaletteTool{
-		methodsMap: make(map[string]Method, 0),
-		methodsRes: make(map[string]bool, 0),
+		methodsMap: make(map[string]Case, 0),
+		records:    make([]*Record, 0),
+		bindings:   make(map[uint64]*binding),
 	}
 }
 
 func (pt *PaletteTool) RegMethod(name string, method Method) {
-	pt.methodsMap[name] = method
+	pt.methodsMap[name] = AdaptMethod(method)
+}
+
+func (pt *PaletteTool) RegCase(name string, c Case) {
+	pt.methodsMap[name] = c
 }
 
 func (pt *PaletteTool) RegGCFunc(fn GcFunc) {
 	pt.gc = fn
 }
 
+// SetReportPath set the file path which used to dump json and junit xml report after all methods finished.
+func (pt *PaletteTool) SetReportPath(path string) {
+	pt.reportPath = path
+}
+
 //Start run
 func (pt *PaletteTool) Start(methodsList []string) {
 	if len(methodsList) > 0 {
@@ -66,14 +90,128 @@ func (pt *PaletteTool) runMethodList(methodsList []string) {
 }
 
 func (pt *PaletteTool) runMethod(index int, methodName string) {
-	pt.onBeforeMethodStart(index, methodName)
+	pt.invoke(index, methodName, methodName, nil, 0, false)
+}
+
+// invoke run method with its own step params and record the result, timeout 0 means no limit.
+// note that the method is not able to be cancelled, it will keep running in background after
+// timeout with the same params. logs of the method will be prefixed with its name if `prefixed` is true.
+func (pt *PaletteTool) invoke(index int, name, methodName string, params map[string]map[string]interface{},
+	timeout time.Duration, prefixed bool) (*Record, *Result) {
+	if prefixed {
+		log.SetPrefix("[" + name + "]")
+		defer log.ClearPrefix()
+	}
+
+	pt.onBeforeMethodStart(index, name)
+	record := &Record{
+		Index:  index,
+		Name:   name,
+		Start:  time.Now(),
+		Result: ResultSkipped,
+		Error:  "method interrupted",
+	}
+	pt.addRecord(record)
+
+	res := NewResult()
 	method := pt.getMethodByName(methodName)
-	if method != nil {
-		ok := method()
-		pt.onAfterMethodFinish(index, methodName, ok)
-		pt.methodsRes[methodName] = ok
+	if method == nil {
+		pt.mu.Lock()
+		record.End = time.Now()
+		record.Error = fmt.Sprintf("method %s not registered", methodName)
+		pt.mu.Unlock()
+		return record, res
+	}
+
+	run := func(res *Result) error {
+		pt.bind(&binding{name: name, params: params})
+		defer pt.bind(nil)
+		return method(res)
+	}
+	if timeout <= 0 {
+		res.Err = run(res)
+	} else {
+		done := make(chan error, 1)
+		tmp := NewResult()
+		go func() {
+			if prefixed {
+				log.SetPrefix("[" + name + "]")
+				defer log.ClearPrefix()
+			}
+			done <- run(tmp)
+		}()
+		select {
+		case err := <-done:
+			res, res.Err = tmp, err
+		case <-time.After(timeout):
+			res.Err = fmt.Errorf("method %s timeout after %s", methodName, timeout)
+		}
+	}
+
+	pt.mu.Lock()
+	record.End = time.Now()
+	record.Duration = record.End.Sub(record.Start)
+	record.Assertions = res.Assertions
+	record.Metrics = res.Metrics
+	if err := res.Error(); err == nil {
+		record.Result = ResultSuccess
+		record.Error = ""
+	} else {
+		record.Result = ResultFailed
+		record.Error = err.Error()
+	}
+	pt.mu.Unlock()
+	pt.onAfterMethodFinish(index, name, res)
+	if pt.gc != nil {
 		pt.gc()
 	}
+	return record, res
+}
+
+func (pt *PaletteTool) addRecord(record *Record) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.records = append(pt.records, record)
+}
+
+// bind the method to current goroutine, nil means unbind.
+func (pt *PaletteTool) bind(b *binding) {
+	gid := log.GetGID()
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if b == nil {
+		delete(pt.bindings, gid)
+	} else {
+		pt.bindings[gid] = b
+	}
+}
+
+// Params returns the fields which override cases file `fileName` in params of the step which is
+// running on current goroutine. each step reads its own params, so that steps running concurrently
+// or timed out but still running in background never read params of others.
+func (pt *PaletteTool) Params(fileName string) (map[string]interface{}, bool) {
+	gid := log.GetGID()
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	b, ok := pt.bindings[gid]
+	if !ok {
+		return nil, false
+	}
+	fields, ok := b.params[fileName]
+	return fields, ok
+}
+
+// Current returns the name of method which is running on current goroutine, e.g: used to audit who
+// is using a secret. it returns empty string outside of methods, note that goroutines spawned by a
+// method are not bound to it.
+func (pt *PaletteTool) Current() string {
+	gid := log.GetGID()
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if b, ok := pt.bindings[gid]; ok {
+		return b.name
+	}
+	return ""
 }
 
 func (pt *PaletteTool) onStart() {
@@ -84,35 +222,51 @@ func (pt *PaletteTool) onStart() {
 }
 
 func (pt *PaletteTool) onFinish(methodsList []string) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
 	failedList := make([]string, 0)
 	successList := make([]string, 0)
-	for methodName, ok := range pt.methodsRes {
-		if ok {
-			successList = append(successList, methodName)
-		} else {
-			failedList = append(failedList, methodName)
+	skipList := make([]string, 0)
+	for _, record := range pt.records {
+		switch record.Result {
+		case ResultSuccess:
+			successList = append(successList, record.Name)
+		case ResultFailed:
+			failedList = append(failedList, fmt.Sprintf("%s: %s", record.Name, record.Error))
+		default:
+			skipList = append(skipList, record.Name)
 		}
 	}
-
-	skipList := make([]string, 0)
-	for _, method := range methodsList {
-		_, ok := pt.methodsRes[method]
-		if !ok {
-			skipList = append(skipList, method)
+	// methods never started(e.g: panic or scenario stopped before) should be skipped too.
+	started := make(map[int]struct{})
+	for _, record := range pt.records {
+		started[record.Index] = struct{}{}
+	}
+	for i := 0; i < len(methodsList); i++ {
+		if _, ok := started[i+1]; ok {
+			continue
 		}
+		skipList = append(skipList, methodsList[i])
+		pt.records = append(pt.records, &Record{
+			Index:  i + 1,
+			Name:   methodsList[i],
+			Result: ResultSkipped,
+			Error:  "method not started",
+		})
 	}
 
 	succCount := len(successList)
 	failedCount := len(failedList)
-	endTime := time.Now().Unix()
+	endTime := time.Now()
 
 	log.Info("===============================================================")
 	log.Infof("Palette Tool Finish Total:%v Success:%v Failed:%v Skip:%v, SpendTime:%d sec",
-		len(methodsList),
+		len(pt.records),
 		succCount,
 		failedCount,
-		len(methodsList)-succCount-failedCount,
-		endTime-startTime,
+		len(skipList),
+		int64(endTime.Sub(startTime).Seconds()),
 	)
 
 	if succCount > 0 {
@@ -137,6 +291,14 @@ func (pt *PaletteTool) onFinish(methodsList []string) {
 		}
 	}
 	log.Info("===============================================================")
+
+	if pt.reportPath != "" {
+		if err := NewReport(startTime, endTime, pt.records).WriteFiles(pt.reportPath); err != nil {
+			log.Errorf("failed to write report to %s, err: %v", pt.reportPath, err)
+		} else {
+			log.Infof("report saved to %s", pt.reportPath)
+		}
+	}
 }
 
 func (pt *PaletteTool) onBeforeMethodStart(index int, methodName string) {
@@ -145,16 +307,19 @@ func (pt *PaletteTool) onBeforeMethodStart(index int, methodName string) {
 	log.Info("---------------------------------------------------------------")
 }
 
-func (pt *PaletteTool) onAfterMethodFinish(index int, methodName string, res bool) {
-	if res {
+func (pt *PaletteTool) onAfterMethodFinish(index int, methodName string, res *Result) {
+	for _, m := range res.Metrics {
+		log.Infof("Method:%s metric %s: %v %s", methodName, m.Name, m.Value, m.Unit)
+	}
+	if err := res.Error(); err == nil {
 		log.Infof("Run Method:%s success.", methodName)
 	} else {
-		log.Infof("Run Method:%s failed.", methodName)
+		log.Infof("Run Method:%s failed, err: %v", methodName, err)
 	}
 	log.Info("---------------------------------------------------------------")
 	log.Info("")
 }
 
-func (pt *PaletteTool) getMethodByName(name string) Method {
+func (pt *PaletteTool) getMethodByName(name string) Case {
 	return pt.methodsMap[name]
 }
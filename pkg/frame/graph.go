@@ -0,0 +1,267 @@
+package frame
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// NewMethodsScenario build a scenario from methods list, the methods are independent of each other
+// and a method appears more than once will be named as `method#n`.
+func NewMethodsScenario(methodsList []string, parallel int) *Scenario {
+	s := &Scenario{Name: "methods", Parallel: parallel, Steps: make([]*Step, 0, len(methodsList))}
+	counter := make(map[string]int)
+	for _, method := range methodsList {
+		counter[method] += 1
+		name := method
+		if n := counter[method]; n > 1 {
+			name = fmt.Sprintf("%s#%d", method, n)
+		}
+		s.Steps = append(s.Steps, &Step{Name: name, Method: method, ContinueOnFailure: true})
+	}
+	return s
+}
+
+// StartParallel run independent methods with at most `parallel` workers.
+func (pt *PaletteTool) StartParallel(methodsList []string, parallel int) {
+	if len(methodsList) == 0 {
+		log.Info("No method to run")
+		return
+	}
+	s := NewMethodsScenario(methodsList, parallel)
+	if err := s.Validate(pt); err != nil {
+		log.Errorf("invalid methods list, err: %v", err)
+		return
+	}
+	pt.RunScenario(s)
+}
+
+// references collect variable names used in step params.
+func (step *Step) references() []string {
+	list := make([]string, 0)
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			for _, m := range variableRegexp.FindAllStringSubmatch(v, -1) {
+				list = append(list, m[1])
+			}
+		case map[string]interface{}:
+			for _, sub := range v {
+				walk(sub)
+			}
+		case []interface{}:
+			for _, sub := range v {
+				walk(sub)
+			}
+		}
+	}
+	for _, fields := range step.Params {
+		walk(map[string]interface{}(fields))
+	}
+	return list
+}
+
+// buildGraph resolve step dependencies and make sure that there is no cycle.
+func (s *Scenario) buildGraph() error {
+	names := make(map[string]int)
+	aliases := make(map[string]int)
+	for i, step := range s.Steps {
+		names[step.Name] = i
+		for _, alias := range step.Outputs {
+			if j, exist := aliases[alias]; exist && j != i {
+				return fmt.Errorf("output variable %s is set by both step %s and %s", alias, s.Steps[j].Name, step.Name)
+			}
+			aliases[alias] = i
+		}
+	}
+
+	for i, step := range s.Steps {
+		deps := make(map[int]struct{})
+		for _, name := range step.DependsOn {
+			j, ok := names[name]
+			if !ok {
+				return fmt.Errorf("step %s depends on unknown step %s", step.Name, name)
+			}
+			deps[j] = struct{}{}
+		}
+		for _, ref := range step.references() {
+			if j, ok := aliases[ref]; ok {
+				deps[j] = struct{}{}
+				continue
+			}
+			// choose the longest step name which is the prefix of reference
+			matched := -1
+			for name, j := range names {
+				if strings.HasPrefix(ref, name+".") && (matched < 0 || len(name) > len(s.Steps[matched].Name)) {
+					matched = j
+				}
+			}
+			if matched >= 0 {
+				deps[matched] = struct{}{}
+			}
+		}
+		if _, ok := deps[i]; ok {
+			return fmt.Errorf("step %s depends on itself", step.Name)
+		}
+
+		step.deps = make([]int, 0, len(deps))
+		for j := range deps {
+			step.deps = append(step.deps, j)
+		}
+		sort.Ints(step.deps)
+	}
+
+	// kahn's algorithm
+	indegree := make([]int, len(s.Steps))
+	children := make([][]int, len(s.Steps))
+	for i, step := range s.Steps {
+		indegree[i] = len(step.deps)
+		for _, j := range step.deps {
+			children[j] = append(children[j], i)
+		}
+	}
+	queue := make([]int, 0)
+	for i, n := range indegree {
+		if n == 0 {
+			queue = append(queue, i)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		visited += 1
+		for _, j := range children[i] {
+			if indegree[j] -= 1; indegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	if visited != len(s.Steps) {
+		cycle := make([]string, 0)
+		for i, n := range indegree {
+			if n > 0 {
+				cycle = append(cycle, s.Steps[i].Name)
+			}
+		}
+		return fmt.Errorf("dependency cycle between steps %s", strings.Join(cycle, ","))
+	}
+	return nil
+}
+
+type stepState int
+
+const (
+	stepPending stepState = iota
+	stepRunning
+	stepSucceed
+	stepFailed
+	stepSkipped
+)
+
+type stepDone struct {
+	index int
+	res   *Result
+	ok    bool
+}
+
+// runGraph run steps concurrently with at most `Parallel` workers. a step starts after all of its
+// dependencies succeed, and it is skipped if any of them failed. new steps stop starting after a failure
+// without `ContinueOnFailure`.
+func (pt *PaletteTool) runGraph(s *Scenario) {
+	var (
+		states  = make([]stepState, len(s.Steps))
+		running = make(map[int]struct{})
+		vars    = make(map[string]interface{})
+		done    = make(chan *stepDone)
+		abort   = false
+	)
+
+	fail := func(i int) {
+		states[i] = stepFailed
+		if !s.Steps[i].ContinueOnFailure && !abort {
+			abort = true
+			log.Errorf("scenario %s stop starting new steps since step %s failed", s.Name, s.Steps[i].Name)
+		}
+	}
+
+	for {
+		failed := false
+
+		// skip steps whose dependencies will never succeed
+		for changed := true; changed; {
+			changed = false
+			for i, step := range s.Steps {
+				if states[i] != stepPending {
+					continue
+				}
+				for _, j := range step.deps {
+					if states[j] == stepFailed || states[j] == stepSkipped {
+						states[i] = stepSkipped
+						changed = true
+						pt.addRecord(&Record{
+							Index:  i + 1,
+							Name:   step.Name,
+							Result: ResultSkipped,
+							Error:  fmt.Sprintf("dependency %s not succeed", s.Steps[j].Name),
+						})
+						break
+					}
+				}
+			}
+		}
+
+		for i, step := range s.Steps {
+			if abort || len(running) >= s.Parallel {
+				break
+			}
+			if states[i] != stepPending {
+				continue
+			}
+			ready := true
+			for _, j := range step.deps {
+				if states[j] != stepSucceed {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			params, err := step.resolveParams(vars)
+			if err != nil {
+				pt.failStep(i+1, step, err)
+				fail(i)
+				failed = true
+				continue
+			}
+
+			states[i] = stepRunning
+			running[i] = struct{}{}
+			go func(i int, step *Step, params map[string]map[string]interface{}) {
+				res, ok := pt.tryStep(i+1, step, params, true)
+				done <- &stepDone{index: i, res: res, ok: ok}
+			}(i, step, params)
+		}
+
+		if len(running) == 0 {
+			// dependents of the failed step should be skipped in next round
+			if failed && !abort {
+				continue
+			}
+			break
+		}
+
+		result := <-done
+		delete(running, result.index)
+		if result.ok {
+			states[result.index] = stepSucceed
+			s.Steps[result.index].saveOutputs(result.res, vars)
+		} else {
+			fail(result.index)
+		}
+	}
+}
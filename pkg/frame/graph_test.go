@@ -0,0 +1,137 @@
+package frame
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildGraph(t *testing.T) {
+	pt := NewPaletteTool()
+	pt.RegMethod("m", func() bool { return true })
+
+	s := &Scenario{Steps: []*Step{
+		{Name: "a", Method: "m", Outputs: map[string]string{"address": "eccd"}},
+		{Name: "b", Method: "m", Params: map[string]map[string]interface{}{"B.json": {"Addr": "${eccd}"}}},
+		{Name: "c", Method: "m", Params: map[string]map[string]interface{}{"C.json": {"ID": "${b.id}"}}},
+		{Name: "d", Method: "m", DependsOn: []string{"a", "c"}},
+	}}
+	assert.NoError(t, s.Validate(pt))
+	assert.Equal(t, []int{}, s.Steps[0].deps)
+	assert.Equal(t, []int{0}, s.Steps[1].deps)
+	assert.Equal(t, []int{1}, s.Steps[2].deps)
+	assert.Equal(t, []int{0, 2}, s.Steps[3].deps)
+
+	s.Steps[0].DependsOn = []string{"d"}
+	assert.Error(t, s.Validate(pt))
+
+	s.Steps[0].DependsOn = []string{"x"}
+	assert.Error(t, s.Validate(pt))
+}
+
+func TestRunGraph(t *testing.T) {
+	var (
+		pt              = NewPaletteTool()
+		current, maxRun int32
+		mu              sync.Mutex
+		callers         = make(map[string]struct{})
+	)
+
+	slow := func(name string, ok bool) Case {
+		return func(res *Result) error {
+			n := atomic.AddInt32(&current, 1)
+			defer atomic.AddInt32(&current, -1)
+			for {
+				m := atomic.LoadInt32(&maxRun)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRun, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			callers[pt.Current()] = struct{}{}
+			mu.Unlock()
+			res.Output("value", name)
+			if !ok {
+				return fmt.Errorf("%s failed", name)
+			}
+			return nil
+		}
+	}
+	pt.RegCase("deploy", slow("deploy", true))
+	pt.RegCase("check", slow("check", true))
+	pt.RegCase("fail", slow("fail", false))
+
+	s := &Scenario{Parallel: 2, Steps: []*Step{
+		{Name: "deploy", Method: "deploy"},
+		{Name: "check-1", Method: "check"},
+		{Name: "check-2", Method: "check"},
+		{Name: "use", Method: "check", Params: map[string]map[string]interface{}{"A.json": {"V": "${deploy.value}"}}},
+		{Name: "fail", Method: "fail", ContinueOnFailure: true},
+		{Name: "after-fail", Method: "check", DependsOn: []string{"fail"}},
+	}}
+	assert.NoError(t, s.Validate(pt))
+	pt.RunScenario(s)
+
+	assert.Equal(t, int32(2), maxRun)
+	results := make(map[string]string)
+	for _, record := range pt.records {
+		results[record.Name] = record.Result
+	}
+	assert.Equal(t, map[string]string{
+		"deploy":     ResultSuccess,
+		"check-1":    ResultSuccess,
+		"check-2":    ResultSuccess,
+		"use":        ResultSuccess,
+		"fail":       ResultFailed,
+		"after-fail": ResultSkipped,
+	}, results)
+	// concurrent steps are attributed to themselves only
+	assert.Equal(t, map[string]struct{}{
+		"deploy": {}, "check-1": {}, "check-2": {}, "use": {}, "fail": {},
+	}, callers)
+}
+
+func TestTimeoutStepParams(t *testing.T) {
+	var (
+		pt       = NewPaletteTool()
+		received = make(chan interface{}, 2)
+		release  = make(chan struct{})
+	)
+	pt.RegMethod("read", func() bool {
+		if fields, ok := pt.Params("A.json"); ok && fields["V"] == "slow" {
+			<-release
+		}
+		fields, _ := pt.Params("A.json")
+		received <- fields["V"]
+		return true
+	})
+
+	s := &Scenario{Parallel: 2, Steps: []*Step{
+		{Name: "slow", Method: "read", Timeout: "10ms", ContinueOnFailure: true,
+			Params: map[string]map[string]interface{}{"A.json": {"V": "slow"}}},
+		{Name: "next", Method: "read", DependsOn: []string{"slow"},
+			Params: map[string]map[string]interface{}{"A.json": {"V": "next"}}},
+		{Name: "after", Method: "read", ContinueOnFailure: true,
+			Params: map[string]map[string]interface{}{"A.json": {"V": "after"}}},
+	}}
+	assert.NoError(t, s.Validate(pt))
+	pt.RunScenario(s)
+	assert.Equal(t, "after", <-received)
+
+	// the timed-out method still reads its own params after scenario finished
+	close(release)
+	assert.Equal(t, "slow", <-received)
+	_, ok := pt.Params("A.json")
+	assert.False(t, ok)
+}
+
+func TestNewMethodsScenario(t *testing.T) {
+	s := NewMethodsScenario([]string{"balanceOf", "consistency", "balanceOf"}, 3)
+	assert.Equal(t, "balanceOf#2", s.Steps[2].Name)
+	assert.Equal(t, 3, s.Parallel)
+}
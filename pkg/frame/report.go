@@ -0,0 +1,163 @@
+package frame
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+	ResultSkipped = "skipped"
+)
+
+const reportSuiteName = "onRobot"
+
+// Record describes one invocation of a method, the same method invoked twice in a run
+// will be recorded twice with different index.
+type Record struct {
+	Index      int           `json:"index"`
+	Name       string        `json:"name"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Duration   time.Duration `json:"duration"`
+	Result     string        `json:"result"`
+	Error      string        `json:"error,omitempty"`
+	Assertions []*Assertion  `json:"assertions,omitempty"`
+	Metrics    []*Metric     `json:"metrics,omitempty"`
+}
+
+func (r *Record) Succeed() bool {
+	return r.Result == ResultSuccess
+}
+
+// Report is the machine-readable summary of a run.
+type Report struct {
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Total    int           `json:"total"`
+	Success  int           `json:"success"`
+	Failed   int           `json:"failed"`
+	Skipped  int           `json:"skipped"`
+	Records  []*Record     `json:"records"`
+}
+
+func NewReport(start, end time.Time, records []*Record) *Report {
+	r := &Report{
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start),
+		Total:    len(records),
+		Records:  records,
+	}
+	for _, v := range records {
+		switch v.Result {
+		case ResultSuccess:
+			r.Success += 1
+		case ResultFailed:
+			r.Failed += 1
+		default:
+			r.Skipped += 1
+		}
+	}
+	return r
+}
+
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "\t")
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitFailure `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func (r *Report) JUnit() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      reportSuiteName,
+		Tests:     r.Total,
+		Failures:  r.Failed,
+		Skipped:   r.Skipped,
+		Time:      junitSeconds(r.Duration),
+		Timestamp: r.Start.Format(time.RFC3339),
+		Cases:     make([]junitTestCase, 0, len(r.Records)),
+	}
+	for _, v := range r.Records {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%d.%s", v.Index, v.Name),
+			ClassName: reportSuiteName,
+			Time:      junitSeconds(v.Duration),
+		}
+		switch v.Result {
+		case ResultSuccess:
+		case ResultFailed:
+			tc.Failure = &junitFailure{Message: v.Error, Content: v.Error}
+		default:
+			tc.Skipped = &junitFailure{Message: v.Error}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	enc, err := xml.MarshalIndent(&junitTestSuites{Suites: []junitTestSuite{suite}}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), enc...), nil
+}
+
+// WriteFiles dump report as `prefix.json` and `prefix.xml`, the file extension of `path` will be ignored.
+func (r *Report) WriteFiles(path string) error {
+	prefix := strings.TrimSuffix(path, filepath.Ext(path))
+	if dir := filepath.Dir(prefix); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	enc, err := r.JSON()
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(prefix+".json", enc, 0644); err != nil {
+		return err
+	}
+
+	if enc, err = r.JUnit(); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(prefix+".xml", enc, 0644)
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
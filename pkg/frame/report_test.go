@@ -0,0 +1,67 @@
+package frame
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunMethodRecords(t *testing.T) {
+	pt := NewPaletteTool()
+	pt.RegGCFunc(func() {})
+	pt.RegMethod("ok", func() bool { return true })
+	pt.RegMethod("fail", func() bool { return false })
+
+	pt.runMethod(1, "ok")
+	pt.runMethod(2, "fail")
+	pt.runMethod(3, "ok")
+	pt.runMethod(4, "missing")
+
+	assert.Equal(t, 4, len(pt.records))
+	assert.Equal(t, ResultSuccess, pt.records[0].Result)
+	assert.Equal(t, ResultFailed, pt.records[1].Result)
+	assert.NotEmpty(t, pt.records[1].Error)
+	assert.Equal(t, "ok", pt.records[2].Name)
+	assert.Equal(t, 3, pt.records[2].Index)
+	assert.Equal(t, ResultSkipped, pt.records[3].Result)
+}
+
+func TestReportWriteFiles(t *testing.T) {
+	start := time.Now()
+	records := []*Record{
+		{Index: 1, Name: "ok", Start: start, End: start.Add(time.Second), Duration: time.Second, Result: ResultSuccess},
+		{Index: 2, Name: "fail", Start: start, End: start.Add(time.Second), Duration: time.Second, Result: ResultFailed, Error: "boom"},
+		{Index: 3, Name: "skip", Result: ResultSkipped},
+	}
+	report := NewReport(start, start.Add(2*time.Second), records)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Success)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+
+	dir, err := ioutil.TempDir("", "report")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, report.WriteFiles(filepath.Join(dir, "robot.json")))
+
+	enc, err := ioutil.ReadFile(filepath.Join(dir, "robot.json"))
+	assert.NoError(t, err)
+	got := new(Report)
+	assert.NoError(t, json.Unmarshal(enc, got))
+	assert.Equal(t, "boom", got.Records[1].Error)
+
+	enc, err = ioutil.ReadFile(filepath.Join(dir, "robot.xml"))
+	assert.NoError(t, err)
+	suites := new(junitTestSuites)
+	assert.NoError(t, xml.Unmarshal(enc, suites))
+	assert.Equal(t, 1, suites.Suites[0].Failures)
+	assert.Equal(t, "boom", suites.Suites[0].Cases[1].Failure.Message)
+	assert.NotNil(t, suites.Suites[0].Cases[2].Skipped)
+}
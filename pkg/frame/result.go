@@ -0,0 +1,106 @@
+package frame
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Case is the richer method contract. method record assertions and metrics in the `Result`,
+// and the returned error explains why the method failed.
+type Case func(res *Result) error
+
+// AdaptMethod wrap the legacy bool method as `Case`.
+func AdaptMethod(method Method) Case {
+	return func(res *Result) error {
+		if !method() {
+			return fmt.Errorf("method returned false")
+		}
+		return nil
+	}
+}
+
+type Assertion struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// Result collect the assertions and metrics of one method invocation.
+type Result struct {
+	Err        error
+	Assertions []*Assertion
+	Metrics    []*Metric
+	Outputs    map[string]interface{}
+}
+
+func NewResult() *Result {
+	return &Result{
+		Assertions: make([]*Assertion, 0),
+		Metrics:    make([]*Metric, 0),
+		Outputs:    make(map[string]interface{}),
+	}
+}
+
+// Assert record an assertion and return the condition, message is only used while the condition is false.
+func (r *Result) Assert(name string, cond bool, format string, args ...interface{}) bool {
+	a := &Assertion{Name: name, Passed: cond}
+	if !cond {
+		a.Message = fmt.Sprintf(format, args...)
+	}
+	r.Assertions = append(r.Assertions, a)
+	return cond
+}
+
+// Equal assert that expect and actual are deep equal, big.Int and other `Stringer` are compared by string.
+func (r *Result) Equal(name string, expect, actual interface{}) bool {
+	return r.Assert(name, equal(expect, actual), "expect %v, actual %v", expect, actual)
+}
+
+// Metric record a named measure, e.g: `lock-duration` 12.3 `s`.
+func (r *Result) Metric(name string, value float64, unit string) {
+	r.Metrics = append(r.Metrics, &Metric{Name: name, Value: value, Unit: unit})
+}
+
+// Output publish a named value which could be referenced by later scenario steps.
+func (r *Result) Output(key string, value interface{}) {
+	r.Outputs[key] = value
+}
+
+// Failed return true if error happened or any assertion failed.
+func (r *Result) Failed() bool {
+	return r.Error() != nil
+}
+
+// Error return the method error, or an error composed of all failed assertions.
+func (r *Result) Error() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	list := make([]string, 0)
+	for _, v := range r.Assertions {
+		if !v.Passed {
+			list = append(list, fmt.Sprintf("assert %s failed: %s", v.Name, v.Message))
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(list, "; "))
+}
+
+func equal(expect, actual interface{}) bool {
+	if s1, ok := expect.(fmt.Stringer); ok {
+		if s2, ok := actual.(fmt.Stringer); ok {
+			return s1.String() == s2.String()
+		}
+	}
+	return reflect.DeepEqual(expect, actual)
+}
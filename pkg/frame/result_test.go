@@ -0,0 +1,43 @@
+package frame
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultError(t *testing.T) {
+	res := NewResult()
+	assert.True(t, res.Equal("bigint", big.NewInt(10), new(big.Int).SetUint64(10)))
+	assert.False(t, res.Failed())
+
+	assert.False(t, res.Assert("balance", false, "expect %d, actual %d", 1, 2))
+	assert.True(t, res.Failed())
+	assert.Equal(t, "assert balance failed: expect 1, actual 2", res.Error().Error())
+
+	res.Err = fmt.Errorf("rpc error")
+	assert.Equal(t, "rpc error", res.Error().Error())
+}
+
+func TestCaseRecord(t *testing.T) {
+	pt := NewPaletteTool()
+	pt.RegCase("case", func(res *Result) error {
+		res.Metric("tps", 100, "tx/s")
+		res.Equal("value", 1, 2)
+		return nil
+	})
+	pt.RegCase("err", func(res *Result) error {
+		assert.Equal(t, "err", pt.Current())
+		return fmt.Errorf("proposal should be passed")
+	})
+	pt.runMethod(1, "case")
+	pt.runMethod(2, "err")
+
+	assert.Equal(t, ResultFailed, pt.records[0].Result)
+	assert.Equal(t, 1, len(pt.records[0].Metrics))
+	assert.Equal(t, "assert value failed: expect 1, actual 2", pt.records[0].Error)
+	assert.Equal(t, "proposal should be passed", pt.records[1].Error)
+	assert.Equal(t, "", pt.Current())
+}
@@ -0,0 +1,251 @@
+package frame
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// Scenario is a declarative list of steps, e.g:
+//
+//	{
+//		"Name": "plt-base",
+//		"Steps": [
+//			{"Method": "plt-deploy-eccd", "Outputs": {"address": "eccd"}},
+//			{"Method": "proposal", "Params": {"Proposal.json": {"ProposalValue": 2000}}, "Retry": 1},
+//			{"Name": "balance", "Method": "balanceOf", "Params": {"PLT-Balance.json": {"Address": "${eccd}"}}}
+//		]
+//	}
+//
+// method outputs are saved as variable `stepName.key`, and also renamed by `Outputs`. the variable
+// referenced as `${name}` in step params will be replaced before the step start.
+type Scenario struct {
+	Name string
+	// Parallel is the max number of steps running at the same time, steps are executed in order if it's
+	// less than 2. in parallel mode steps only wait for the steps they depend on.
+	Parallel int
+	Steps    []*Step
+}
+
+type Step struct {
+	// Name is the step identity and the prefix of outputs, default is the method name.
+	Name   string
+	Method string
+	// Params maps cases file name to fields which override the content of that file, only the step
+	// method itself reads them, see `PaletteTool.Params`.
+	Params map[string]map[string]interface{}
+	// Outputs maps method output key to scenario variable name.
+	Outputs map[string]string
+	// Timeout is a duration string like `10m`, empty means no limit.
+	Timeout string
+	// Retry is the number of extra attempts after a failure.
+	Retry int
+	// ContinueOnFailure continue the next steps even if this one failed.
+	ContinueOnFailure bool
+	// DependsOn is the list of step names which must succeed before this step start in parallel mode,
+	// steps referenced by `${step.key}` or output alias in params are added automatically.
+	DependsOn []string
+
+	timeout time.Duration
+	deps    []int
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Scenario)
+	if err := json.Unmarshal(bz, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scenario %s, err: %v", path, err)
+	}
+	if s.Name == "" {
+		s.Name = path
+	}
+	return s, nil
+}
+
+// Validate fill default step name, parse timeout and check that names are unique.
+func (s *Scenario) Validate(pt *PaletteTool) error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("scenario %s has no step", s.Name)
+	}
+	names := make(map[string]struct{})
+	for i, step := range s.Steps {
+		if step.Method == "" {
+			return fmt.Errorf("step %d method is empty", i+1)
+		}
+		if pt.getMethodByName(step.Method) == nil {
+			return fmt.Errorf("step %d method %s not registered", i+1, step.Method)
+		}
+		if step.Name == "" {
+			step.Name = step.Method
+		}
+		if _, exist := names[step.Name]; exist {
+			return fmt.Errorf("step %d name %s duplicated, set `Name` to distinguish steps", i+1, step.Name)
+		}
+		names[step.Name] = struct{}{}
+		if step.Timeout != "" {
+			d, err := time.ParseDuration(step.Timeout)
+			if err != nil {
+				return fmt.Errorf("step %s invalid timeout %s, err: %v", step.Name, step.Timeout, err)
+			}
+			step.timeout = d
+		}
+		if step.Retry < 0 {
+			return fmt.Errorf("step %s invalid retry %d", step.Name, step.Retry)
+		}
+	}
+	return s.buildGraph()
+}
+
+var variableRegexp = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// resolve replace `${name}` in params with scenario variables. a string equal to exactly one
+// reference keeps the type of variable, otherwise variables are formatted into the string.
+func resolve(value interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if m := variableRegexp.FindStringSubmatch(v); m != nil && m[0] == v {
+			data, ok := vars[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("variable %s not found", m[1])
+			}
+			return data, nil
+		}
+		var err error
+		s := variableRegexp.ReplaceAllStringFunc(v, func(ref string) string {
+			name := strings.TrimSuffix(strings.TrimPrefix(ref, "${"), "}")
+			data, ok := vars[name]
+			if !ok {
+				err = fmt.Errorf("variable %s not found", name)
+				return ref
+			}
+			return fmt.Sprintf("%v", data)
+		})
+		return s, err
+
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, sub := range v {
+			data, err := resolve(sub, vars)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = data
+		}
+		return m, nil
+
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, sub := range v {
+			data, err := resolve(sub, vars)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = data
+		}
+		return list, nil
+
+	default:
+		return value, nil
+	}
+}
+
+func (step *Step) resolveParams(vars map[string]interface{}) (map[string]map[string]interface{}, error) {
+	params := make(map[string]map[string]interface{}, len(step.Params))
+	for file, fields := range step.Params {
+		data, err := resolve(map[string]interface{}(fields), vars)
+		if err != nil {
+			return nil, fmt.Errorf("step %s params %s: %v", step.Name, file, err)
+		}
+		params[file] = data.(map[string]interface{})
+	}
+	return params, nil
+}
+
+// RunScenario execute steps in order or concurrently, each attempt of a step is recorded in the report.
+func (pt *PaletteTool) RunScenario(s *Scenario) {
+	names := make([]string, len(s.Steps))
+	for i, step := range s.Steps {
+		names[i] = step.Name
+	}
+
+	pt.onStart()
+	defer pt.onFinish(names)
+
+	if s.Parallel > 1 {
+		log.Infof("run scenario %s with %d steps and %d workers", s.Name, len(s.Steps), s.Parallel)
+		pt.runGraph(s)
+		return
+	}
+
+	log.Infof("run scenario %s with %d steps", s.Name, len(s.Steps))
+	vars := make(map[string]interface{})
+	for i, step := range s.Steps {
+		if !pt.runStep(i+1, step, vars) && !step.ContinueOnFailure {
+			log.Errorf("scenario %s stopped at step %s", s.Name, step.Name)
+			return
+		}
+	}
+}
+
+func (pt *PaletteTool) runStep(index int, step *Step, vars map[string]interface{}) bool {
+	params, err := step.resolveParams(vars)
+	if err != nil {
+		pt.failStep(index, step, err)
+		return false
+	}
+
+	res, ok := pt.tryStep(index, step, params, false)
+	if ok {
+		step.saveOutputs(res, vars)
+	}
+	return ok
+}
+
+// tryStep invoke the step method at most `Retry + 1` times until it succeed.
+func (pt *PaletteTool) tryStep(index int, step *Step, params map[string]map[string]interface{}, prefixed bool) (*Result, bool) {
+	for attempt := 0; attempt <= step.Retry; attempt++ {
+		if attempt > 0 {
+			log.Infof("retry step %s, attempt %d", step.Name, attempt)
+		}
+		record, res := pt.invoke(index, step.Name, step.Method, params, step.timeout, prefixed)
+		if record.Result == ResultSuccess {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+func (pt *PaletteTool) failStep(index int, step *Step, err error) {
+	now := time.Now()
+	pt.addRecord(&Record{
+		Index:  index,
+		Name:   step.Name,
+		Start:  now,
+		End:    now,
+		Result: ResultFailed,
+		Error:  err.Error(),
+	})
+	log.Error(err)
+}
+
+func (step *Step) saveOutputs(res *Result, vars map[string]interface{}) {
+	for key, value := range res.Outputs {
+		vars[step.Name+"."+key] = value
+		if alias, ok := step.Outputs[key]; ok {
+			vars[alias] = value
+		}
+	}
+	for key, alias := range step.Outputs {
+		if _, ok := res.Outputs[key]; !ok {
+			log.Warnf("step %s output %s not found, variable %s not set", step.Name, key, alias)
+		}
+	}
+}
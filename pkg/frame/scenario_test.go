@@ -0,0 +1,98 @@
+package frame
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	vars := map[string]interface{}{
+		"eccd":           "0x01",
+		"proposal.value": 10,
+	}
+	params := map[string]interface{}{
+		"Address": "${eccd}",
+		"Value":   "${proposal.value}",
+		"Memo":    "eccd ${eccd} value ${proposal.value}",
+		"List":    []interface{}{"${eccd}", 1},
+	}
+	data, err := resolve(params, vars)
+	assert.NoError(t, err)
+
+	got := data.(map[string]interface{})
+	assert.Equal(t, "0x01", got["Address"])
+	assert.Equal(t, 10, got["Value"])
+	assert.Equal(t, "eccd 0x01 value 10", got["Memo"])
+	assert.Equal(t, []interface{}{"0x01", 1}, got["List"])
+
+	_, err = resolve("${missing}", vars)
+	assert.Error(t, err)
+	_, err = resolve("prefix ${missing}", vars)
+	assert.Error(t, err)
+}
+
+func TestRunScenario(t *testing.T) {
+	var (
+		pt       = NewPaletteTool()
+		received = make([]interface{}, 0)
+		flaky    = 0
+	)
+
+	pt.RegCase("deploy", func(res *Result) error {
+		res.Output("address", "0xabc")
+		return nil
+	})
+	pt.RegCase("flaky", func(res *Result) error {
+		if flaky++; flaky < 2 {
+			return fmt.Errorf("flaky failed")
+		}
+		return nil
+	})
+	pt.RegMethod("fail", func() bool { return false })
+	pt.RegMethod("check", func() bool {
+		if fields, ok := pt.Params("Check.json"); ok {
+			received = append(received, fields["Address"])
+		}
+		return true
+	})
+
+	s := &Scenario{
+		Name: "test",
+		Steps: []*Step{
+			{Method: "deploy", Outputs: map[string]string{"address": "eccd"}},
+			{Method: "flaky", Retry: 1},
+			{Method: "fail", ContinueOnFailure: true},
+			{Name: "check-1", Method: "check", Params: map[string]map[string]interface{}{
+				"Check.json": {"Address": "${eccd}"},
+			}},
+			{Name: "check-2", Method: "check", Params: map[string]map[string]interface{}{
+				"Check.json": {"Address": "${deploy.address}"},
+			}},
+			{Method: "fail"},
+			{Name: "never", Method: "check"},
+		},
+	}
+	assert.Error(t, s.Validate(pt))
+	s.Steps[5].Name = "fail-again"
+	assert.NoError(t, s.Validate(pt))
+
+	pt.RunScenario(s)
+	assert.Equal(t, []interface{}{"0xabc", "0xabc"}, received)
+
+	results := make([]string, 0)
+	for _, record := range pt.records {
+		results = append(results, record.Name+":"+record.Result)
+	}
+	assert.Equal(t, []string{
+		"deploy:success",
+		"flaky:failed",
+		"flaky:success",
+		"fail:failed",
+		"check-1:success",
+		"check-2:success",
+		"fail-again:failed",
+		"never:skipped",
+	}, results)
+}
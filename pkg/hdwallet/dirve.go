@@ -1,28 +1,144 @@
 package hdwallet
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-const mnemonic = "tag volcano eight thank tide danger coast health above argue embrace heavy"
+const (
+	defaultMnemonic = "tag volcano eight thank tide danger coast health above argue embrace heavy"
 
-var wallet *Wallet
+	// MnemonicEnv is used if no mnemonic set in config.
+	MnemonicEnv = "ONROBOT_MNEMONIC"
+)
+
+// ErrNoMnemonic is returned by deriving if neither mnemonic nor env is set and the built-in mnemonic is not allowed.
+var ErrNoMnemonic = errors.New("mnemonic not configured, the built-in mnemonic is public and only allowed in local profile")
+
+var (
+	wallet *Wallet
+	mu     sync.RWMutex
+)
 
 func init() {
+	if _, err := Init("", true); err != nil {
+		panic(err)
+	}
+}
+
+// Init reset the wallet with mnemonic, the env `ONROBOT_MNEMONIC` is used if it's empty. the built-in
+// mnemonic is public and everyone can spend the accounts derived from it, so it's used only if `allowDefault`
+// is true, otherwise the wallet is disabled and deriving fails with `ErrNoMnemonic`.
+// it returns true if the built-in mnemonic is used.
+func Init(mnemonic string, allowDefault bool) (bool, error) {
+	if mnemonic == "" {
+		mnemonic = os.Getenv(MnemonicEnv)
+	}
+	builtin := false
+	if mnemonic == "" {
+		if !allowDefault {
+			mu.Lock()
+			wallet = nil
+			mu.Unlock()
+			return false, nil
+		}
+		mnemonic, builtin = defaultMnemonic, true
+	}
 	w, err := NewFromMnemonic(mnemonic)
 	if err != nil {
-		panic(err)
+		return false, err
 	}
+
+	mu.Lock()
 	wallet = w
+	mu.Unlock()
+	return builtin, nil
 }
 
 func Drive(hdIndex int) (accounts.Account, error) {
 	path := derivationPath(hdIndex)
+	mu.RLock()
+	defer mu.RUnlock()
+	if wallet == nil {
+		return accounts.Account{}, ErrNoMnemonic
+	}
 	return wallet.Derive(path, false)
 }
 
+// RoleAccount is a labelled test account, e.g: the 3rd of fans.
+type RoleAccount struct {
+	Role    string
+	Index   int
+	Path    string
+	Address common.Address
+	Key     *ecdsa.PrivateKey
+}
+
+// DeriveRole derive `n` accounts of role from path `m/44'/60'/account'/0/index`. account 0 is reserved
+// for `Drive`, so that accounts of different roles never overlap.
+func DeriveRole(role string, account uint32, n int) ([]*RoleAccount, error) {
+	if account == 0 {
+		return nil, fmt.Errorf("account 0 is reserved, role %s should use another one", role)
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if wallet == nil {
+		return nil, ErrNoMnemonic
+	}
+
+	list := make([]*RoleAccount, n)
+	for i := 0; i < n; i++ {
+		path := rolePath(account, i)
+		acc, err := wallet.Derive(path, false)
+		if err != nil {
+			return nil, err
+		}
+		key, err := wallet.derivePrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = &RoleAccount{
+			Role:    role,
+			Index:   i,
+			Path:    path.String(),
+			Address: acc.Address,
+			Key:     key,
+		}
+	}
+	return list, nil
+}
+
+// DerivePath derive the account of an arbitrary path, e.g: m/44'/60'/0'/0/1.
+func DerivePath(path string) (common.Address, *ecdsa.PrivateKey, error) {
+	dp, err := ParseDerivationPath(path)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if wallet == nil {
+		return common.Address{}, nil, ErrNoMnemonic
+	}
+
+	acc, err := wallet.Derive(dp, false)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	key, err := wallet.derivePrivateKey(dp)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return acc.Address, key, nil
+}
+
 // DerivationPath represents the computer friendly version of a hierarchical
 // deterministic wallet account derivaion path.
 //
@@ -44,3 +160,8 @@ func derivationPath(idx int) accounts.DerivationPath {
 	format := fmt.Sprintf("m/44'/60'/0'/0/%d", idx)
 	return MustParseDerivationPath(format)
 }
+
+func rolePath(account uint32, idx int) accounts.DerivationPath {
+	format := fmt.Sprintf("m/44'/60'/%d'/0/%d", account, idx)
+	return MustParseDerivationPath(format)
+}
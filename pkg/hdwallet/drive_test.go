@@ -2,7 +2,9 @@ package hdwallet
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
+	"os"
 	"testing"
 )
 
@@ -18,3 +20,61 @@ func TestDrive(t *testing.T) {
 
 	assert.Equal(t, N, len(data))
 }
+
+func TestDeriveRole(t *testing.T) {
+	_, err := DeriveRole("fans", 0, 1)
+	assert.Error(t, err)
+
+	fans, err := DeriveRole("fans", 1, 5)
+	assert.NoError(t, err)
+	stakers, err := DeriveRole("stakers", 2, 5)
+	assert.NoError(t, err)
+
+	data := make(map[common.Address]struct{})
+	for _, list := range [][]*RoleAccount{fans, stakers} {
+		for _, acc := range list {
+			assert.Equal(t, acc.Address, crypto.PubkeyToAddress(acc.Key.PublicKey))
+			data[acc.Address] = struct{}{}
+		}
+	}
+	assert.Equal(t, 10, len(data))
+	assert.Equal(t, "m/44'/60'/1'/0/3", fans[3].Path)
+
+	// derivation is deterministic
+	again, err := DeriveRole("fans", 1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, fans[4].Address, again[4].Address)
+}
+
+func TestDerivePath(t *testing.T) {
+	acc, err := Drive(1)
+	assert.NoError(t, err)
+
+	addr, key, err := DerivePath("m/44'/60'/0'/0/1")
+	assert.NoError(t, err)
+	assert.Equal(t, acc.Address, addr)
+	assert.Equal(t, addr, crypto.PubkeyToAddress(key.PublicKey))
+
+	_, _, err = DerivePath("m/invalid")
+	assert.Error(t, err)
+}
+
+func TestInitWithoutMnemonic(t *testing.T) {
+	env := os.Getenv(MnemonicEnv)
+	os.Unsetenv(MnemonicEnv)
+	defer os.Setenv(MnemonicEnv, env)
+
+	builtin, err := Init("", false)
+	assert.NoError(t, err)
+	assert.False(t, builtin)
+	_, err = Drive(0)
+	assert.Equal(t, ErrNoMnemonic, err)
+	_, err = DeriveRole("fans", 1, 1)
+	assert.Equal(t, ErrNoMnemonic, err)
+
+	builtin, err = Init("", true)
+	assert.NoError(t, err)
+	assert.True(t, builtin)
+	_, err = Drive(0)
+	assert.NoError(t, err)
+}
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -69,6 +70,83 @@ func GetGID() uint64 {
 
 var Log *Logger
 
+// prefixes maps goroutine id to log prefix, it's used to distinguish logs of concurrent methods
+// which log with package level functions. goroutines spawned by a method don't inherit the prefix,
+// they should log with the `Entry` captured by `Current` instead.
+var prefixes sync.Map
+
+// SetPrefix set log prefix for current goroutine.
+func SetPrefix(prefix string) {
+	prefixes.Store(GetGID(), prefix)
+}
+
+// ClearPrefix remove log prefix of current goroutine.
+func ClearPrefix() {
+	prefixes.Delete(GetGID())
+}
+
+func goroutinePrefix(gid uint64) string {
+	if prefix, ok := prefixes.Load(gid); ok {
+		return prefix.(string)
+	}
+	return ""
+}
+
+// Entry is a logger value bound with a prefix, it's passed to where the prefix can not be found
+// by goroutine, e.g: goroutines spawned by a method.
+type Entry struct {
+	prefix string
+}
+
+// WithPrefix returns a logger value which prefix all logs with `prefix`.
+func WithPrefix(prefix string) *Entry {
+	return &Entry{prefix: prefix}
+}
+
+// Current returns a logger value bound with the prefix of current goroutine, e.g:
+//
+//	logger := log.Current()
+//	go func() { logger.Infof("...") }()
+func Current() *Entry {
+	return &Entry{prefix: goroutinePrefix(GetGID())}
+}
+
+func (e *Entry) Prefix() string {
+	return e.prefix
+}
+
+func (e *Entry) Debug(a ...interface{}) {
+	Log.output(DebugLog, e.prefix, a...)
+}
+
+func (e *Entry) Debugf(format string, a ...interface{}) {
+	Log.outputf(DebugLog, e.prefix, format, a...)
+}
+
+func (e *Entry) Info(a ...interface{}) {
+	Log.output(InfoLog, e.prefix, a...)
+}
+
+func (e *Entry) Infof(format string, a ...interface{}) {
+	Log.outputf(InfoLog, e.prefix, format, a...)
+}
+
+func (e *Entry) Warn(a ...interface{}) {
+	Log.output(WarnLog, e.prefix, a...)
+}
+
+func (e *Entry) Warnf(format string, a ...interface{}) {
+	Log.outputf(WarnLog, e.prefix, format, a...)
+}
+
+func (e *Entry) Error(a ...interface{}) {
+	Log.output(ErrorLog, e.prefix, a...)
+}
+
+func (e *Entry) Errorf(format string, a ...interface{}) {
+	Log.outputf(ErrorLog, e.prefix, format, a...)
+}
+
 func init() {
 	//Default print to console
 	InitLog(InfoLog, Stdout)
@@ -118,25 +196,37 @@ func (l *Logger) SetDebugLevel(level int) error {
 }
 
 func (l *Logger) Output(level int, a ...interface{}) error {
+	return l.output(level, goroutinePrefix(GetGID()), a...)
+}
+
+func (l *Logger) Outputf(level int, format string, v ...interface{}) error {
+	return l.outputf(level, goroutinePrefix(GetGID()), format, v...)
+}
+
+func (l *Logger) output(level int, prefix string, a ...interface{}) error {
 	if level >= l.level {
-		gid := GetGID()
-		gidStr := strconv.FormatUint(gid, 10)
+		gidStr := strconv.FormatUint(GetGID(), 10)
 
 		a = append([]interface{}{LevelName(level), "GID",
 			gidStr + ","}, a...)
+		if prefix != "" {
+			a = append(a[:3], append([]interface{}{prefix}, a[3:]...)...)
+		}
 
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintln(a...))
+		return l.logger.Output(CALL_DEPTH+1, fmt.Sprintln(a...))
 	}
 	return nil
 }
 
-func (l *Logger) Outputf(level int, format string, v ...interface{}) error {
+func (l *Logger) outputf(level int, prefix string, format string, v ...interface{}) error {
 	if level >= l.level {
-		gid := GetGID()
 		v = append([]interface{}{LevelName(level), "GID",
-			gid}, v...)
+			GetGID()}, v...)
+		if prefix != "" {
+			format = strings.Replace(prefix, "%", "%%", -1) + " " + format
+		}
 
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintf("%s %s %d, "+format+"\n", v...))
+		return l.logger.Output(CALL_DEPTH+1, fmt.Sprintf("%s %s %d, "+format+"\n", v...))
 	}
 	return nil
 }
@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry keeps gauges and counters and exposes them in prometheus text format.
+type Registry struct {
+	mu      sync.Mutex
+	help    map[string]string
+	types   map[string]string
+	samples map[string]map[string]float64 // name -> labels -> value
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		help:    make(map[string]string),
+		types:   make(map[string]string),
+		samples: make(map[string]map[string]float64),
+	}
+}
+
+// Gauge declare a metric whose value can go up and down.
+func (r *Registry) Gauge(name, help string) {
+	r.declare(name, help, "gauge")
+}
+
+// Counter declare a metric which only increases.
+func (r *Registry) Counter(name, help string) {
+	r.declare(name, help, "counter")
+}
+
+func (r *Registry) declare(name, help, typ string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.help[name] = help
+	r.types[name] = typ
+	if _, ok := r.samples[name]; !ok {
+		r.samples[name] = make(map[string]float64)
+	}
+}
+
+func (r *Registry) Set(name string, labels map[string]string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name)[formatLabels(labels)] = value
+}
+
+func (r *Registry) Add(name string, labels map[string]string, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.series(name)[formatLabels(labels)] += delta
+}
+
+func (r *Registry) Get(name string, labels map[string]string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.samples[name][formatLabels(labels)]
+	return v, ok
+}
+
+func (r *Registry) series(name string) map[string]float64 {
+	s, ok := r.samples[name]
+	if !ok {
+		s = make(map[string]float64)
+		r.samples[name] = s
+		r.types[name] = "untyped"
+	}
+	return s
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]string, len(keys))
+	for i, k := range keys {
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[k])
+		list[i] = fmt.Sprintf(`%s="%s"`, k, v)
+	}
+	return "{" + strings.Join(list, ",") + "}"
+}
+
+// WriteTo write all metrics in prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.samples))
+	for name := range r.samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		if help := r.help[name]; help != "" {
+			fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+		}
+		fmt.Fprintf(buf, "# TYPE %s %s\n", name, r.types[name])
+		series := r.samples[name]
+		labels := make([]string, 0, len(series))
+		for l := range series {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		for _, l := range labels {
+			fmt.Fprintf(buf, "%s%s %v\n", name, l, series[l])
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP implement the `/metrics` endpoint.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/timer"
+)
+
+// Probe is executed periodically, the returned error is counted in `onrobot_probe_errors_total`.
+type Probe func(m *Monitor) error
+
+// Monitor schedules probes with `timer.Timer`, a probe is scheduled again after the last execution
+// finished so that slow rpc never piles up.
+type Monitor struct {
+	Metrics *Registry
+
+	timer  *timer.Timer
+	sinks  []Sink
+	mu     sync.Mutex
+	firing map[string]*Alert // rule/target -> alert
+	// stopped is protected by mu, no probe is added to wg after it's set
+	stopped bool
+	wg      sync.WaitGroup
+	server  *http.Server
+}
+
+func New(sinks ...Sink) *Monitor {
+	m := &Monitor{
+		Metrics: NewRegistry(),
+		timer:   timer.NewTimer(16),
+		sinks:   sinks,
+		firing:  make(map[string]*Alert),
+	}
+	m.Metrics.Counter("onrobot_probe_errors_total", "number of failed probe executions")
+	m.Metrics.Counter("onrobot_alerts_total", "number of alerts sent")
+	m.Metrics.Gauge("onrobot_alerts_firing", "number of alerts firing")
+	return m
+}
+
+// AddProbe run probe right now and every `interval` after that.
+func (m *Monitor) AddProbe(name string, interval time.Duration, probe Probe) {
+	var (
+		td    *timer.TimerData
+		ready = make(chan struct{})
+	)
+	td = m.timer.Add(0, func() {
+		m.mu.Lock()
+		if m.stopped {
+			m.mu.Unlock()
+			return
+		}
+		m.wg.Add(1)
+		m.mu.Unlock()
+		go func() {
+			defer m.wg.Done()
+			<-ready
+			if err := probe(m); err != nil {
+				m.Metrics.Add("onrobot_probe_errors_total", map[string]string{"probe": name}, 1)
+				log.Warnf("probe %s failed, err: %v", name, err)
+			}
+			// the expired timer data is reused for the next round
+			m.timer.Set(td, interval)
+		}()
+	})
+	close(ready)
+}
+
+// Serve expose `/metrics` on addr, e.g: `:9100`.
+func (m *Monitor) Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Metrics)
+	m.server = &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Errorf("metrics server stopped, err: %v", err)
+		}
+	}()
+	log.Infof("metrics exposed on %s/metrics", addr)
+}
+
+// Stop prevent probes from running again and wait for the running ones.
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	m.stopped = true
+	m.mu.Unlock()
+	if m.server != nil {
+		m.server.Close()
+	}
+	m.wg.Wait()
+}
+
+// Fire send alert once until it's resolved.
+func (m *Monitor) Fire(level, rule, target, format string, args ...interface{}) {
+	key := rule + "/" + target
+	m.mu.Lock()
+	if _, ok := m.firing[key]; ok {
+		m.mu.Unlock()
+		return
+	}
+	alert := &Alert{Time: time.Now(), Level: level, Rule: rule, Target: target, Message: fmt.Sprintf(format, args...)}
+	m.firing[key] = alert
+	m.Metrics.Set("onrobot_alerts_firing", nil, float64(len(m.firing)))
+	m.mu.Unlock()
+
+	m.send(alert)
+}
+
+// Resolve send a resolved alert if the rule is firing on target.
+func (m *Monitor) Resolve(rule, target, format string, args ...interface{}) {
+	key := rule + "/" + target
+	m.mu.Lock()
+	if _, ok := m.firing[key]; !ok {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.firing, key)
+	m.Metrics.Set("onrobot_alerts_firing", nil, float64(len(m.firing)))
+	m.mu.Unlock()
+
+	m.send(&Alert{Time: time.Now(), Level: LevelResolved, Rule: rule, Target: target, Message: fmt.Sprintf(format, args...)})
+}
+
+// Notify send an event which has no resolved state, e.g: validators changed.
+func (m *Monitor) Notify(level, rule, target, format string, args ...interface{}) {
+	m.send(&Alert{Time: time.Now(), Level: level, Rule: rule, Target: target, Message: fmt.Sprintf(format, args...)})
+}
+
+func (m *Monitor) send(alert *Alert) {
+	m.Metrics.Add("onrobot_alerts_total", map[string]string{"rule": alert.Rule, "level": alert.Level}, 1)
+	for _, sink := range m.sinks {
+		if err := sink.Send(alert); err != nil {
+			log.Warnf("failed to send alert %s, err: %v", alert, err)
+		}
+	}
+}
+
+// HeightTracker detect stalled chains and nodes.
+type HeightTracker struct {
+	mu      sync.Mutex
+	heights map[string]uint64
+	changed map[string]time.Time
+}
+
+func NewHeightTracker() *HeightTracker {
+	return &HeightTracker{heights: make(map[string]uint64), changed: make(map[string]time.Time)}
+}
+
+// Update record the height of target and return how long the height has not increased.
+func (t *HeightTracker) Update(target string, height uint64, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last, ok := t.heights[target]
+	if !ok || height > last {
+		t.heights[target] = height
+		t.changed[target] = now
+		return 0
+	}
+	return now.Sub(t.changed[target])
+}
+
+// Max returns the highest height among the given targets, or among all targets if none given. heights
+// of different chains are not comparable, e.g: palette nodes and ethereum.
+func (t *HeightTracker) Max(targets ...string) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var max uint64
+	if len(targets) == 0 {
+		for _, h := range t.heights {
+			if h > max {
+				max = h
+			}
+		}
+		return max
+	}
+	for _, target := range targets {
+		if h := t.heights[target]; h > max {
+			max = h
+		}
+	}
+	return max
+}
+
+// ChangeTracker detect value changes of targets, e.g: validator set.
+type ChangeTracker struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func NewChangeTracker() *ChangeTracker {
+	return &ChangeTracker{values: make(map[string]string)}
+}
+
+// Update returns the previous value and true if the value changed, the first value is not a change.
+func (t *ChangeTracker) Update(target, value string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.values[target]
+	t.values[target] = value
+	return prev, ok && prev != value
+}
@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memSink struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (s *memSink) Send(alert *Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("palette_block_height", "current block height")
+	r.Set("palette_block_height", map[string]string{"node": "node1"}, 12)
+	r.Set("palette_block_height", map[string]string{"node": "node0"}, 10)
+	r.Counter("errors_total", "")
+	r.Add("errors_total", nil, 1)
+	r.Add("errors_total", nil, 2)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	bz, _ := ioutil.ReadAll(resp.Body)
+
+	expect := `# TYPE errors_total counter
+errors_total 3
+# HELP palette_block_height current block height
+# TYPE palette_block_height gauge
+palette_block_height{node="node0"} 10
+palette_block_height{node="node1"} 12
+`
+	assert.Equal(t, expect, string(bz))
+
+	v, ok := r.Get("palette_block_height", map[string]string{"node": "node1"})
+	assert.True(t, ok)
+	assert.Equal(t, float64(12), v)
+}
+
+func TestAlerts(t *testing.T) {
+	sink := new(memSink)
+	m := New(sink)
+
+	m.Fire(LevelCritical, "stall", "node0", "height %d not increased", 10)
+	m.Fire(LevelCritical, "stall", "node0", "height %d not increased", 10)
+	m.Resolve("stall", "node1", "recovered")
+	m.Resolve("stall", "node0", "recovered")
+	m.Notify(LevelInfo, "validators", "palette", "changed")
+
+	assert.Equal(t, 3, len(sink.alerts))
+	assert.Equal(t, LevelCritical, sink.alerts[0].Level)
+	assert.Equal(t, "height 10 not increased", sink.alerts[0].Message)
+	assert.Equal(t, LevelResolved, sink.alerts[1].Level)
+	assert.Equal(t, "validators", sink.alerts[2].Rule)
+
+	firing, _ := m.Metrics.Get("onrobot_alerts_firing", nil)
+	assert.Equal(t, float64(0), firing)
+}
+
+func TestProbe(t *testing.T) {
+	m := New()
+	var count int32
+	m.AddProbe("counter", 10*time.Millisecond, func(m *Monitor) error {
+		if atomic.AddInt32(&count, 1)%2 == 0 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+	time.Sleep(100 * time.Millisecond)
+	m.Stop()
+	n := atomic.LoadInt32(&count)
+	assert.True(t, n >= 3, "executed %d times", n)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&count))
+
+	errs, _ := m.Metrics.Get("onrobot_probe_errors_total", map[string]string{"probe": "counter"})
+	assert.Equal(t, float64(n/2), errs)
+
+	// probes added after stop never run
+	m.AddProbe("late", 10*time.Millisecond, func(m *Monitor) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	m.Stop()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&count))
+}
+
+func TestSinks(t *testing.T) {
+	alert := &Alert{Time: time.Now(), Level: LevelWarn, Rule: "lag", Target: "node3", Message: "lag 20 blocks"}
+
+	dir, err := ioutil.TempDir("", "monitor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "alerts.log")
+	fs := NewFileSink(path)
+	assert.NoError(t, fs.Send(alert))
+	assert.NoError(t, fs.Send(alert))
+	bz, _ := ioutil.ReadFile(path)
+	assert.Equal(t, 2, len(strings.Split(strings.TrimSpace(string(bz)), "\n")))
+
+	var received *Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = new(Alert)
+		json.NewDecoder(r.Body).Decode(received)
+	}))
+	defer srv.Close()
+	assert.NoError(t, NewWebhookSink(srv.URL, time.Second).Send(alert))
+	assert.Equal(t, "node3", received.Target)
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+	assert.Error(t, NewWebhookSink(bad.URL, time.Second).Send(alert))
+}
+
+func TestTrackers(t *testing.T) {
+	ht := NewHeightTracker()
+	now := time.Now()
+	assert.Equal(t, time.Duration(0), ht.Update("node0", 10, now))
+	assert.Equal(t, 5*time.Second, ht.Update("node0", 10, now.Add(5*time.Second)))
+	assert.Equal(t, time.Duration(0), ht.Update("node0", 11, now.Add(6*time.Second)))
+	ht.Update("node1", 20, now)
+	assert.Equal(t, uint64(20), ht.Max())
+	ht.Update("ethereum", 9000, now)
+	assert.Equal(t, uint64(20), ht.Max("node0", "node1"))
+	assert.Equal(t, uint64(11), ht.Max("node0", "node2"))
+
+	ct := NewChangeTracker()
+	_, changed := ct.Update("validators", "a,b")
+	assert.False(t, changed)
+	_, changed = ct.Update("validators", "a,b")
+	assert.False(t, changed)
+	prev, changed := ct.Update("validators", "a,b,c")
+	assert.True(t, changed)
+	assert.Equal(t, "a,b", prev)
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+const (
+	LevelInfo     = "info"
+	LevelWarn     = "warn"
+	LevelCritical = "critical"
+	LevelResolved = "resolved"
+)
+
+type Alert struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Rule    string    `json:"rule"`
+	Target  string    `json:"target"`
+	Message string    `json:"message"`
+}
+
+func (a *Alert) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", a.Level, a.Rule, a.Target, a.Message)
+}
+
+// Sink deliver alerts to somewhere, e.g: log, webhook, file.
+type Sink interface {
+	Send(alert *Alert) error
+}
+
+type LogSink struct{}
+
+func (s *LogSink) Send(alert *Alert) error {
+	switch alert.Level {
+	case LevelCritical:
+		log.Error(alert.String())
+	case LevelWarn:
+		log.Warn(alert.String())
+	default:
+		log.Info(alert.String())
+	}
+	return nil
+}
+
+// FileSink append alerts to file as json lines.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Send(alert *Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bz, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(bz, '\n'))
+	return err
+}
+
+// WebhookSink post alert as json to url.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Send(alert *Alert) error {
+	bz, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s response status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
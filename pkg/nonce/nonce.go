@@ -0,0 +1,142 @@
+package nonce
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// Fetcher returns the pending transaction count of account, e.g: `ethclient.PendingNonceAt`.
+type Fetcher func() (uint64, error)
+
+// Manager allocates nonces for accounts which may be shared by many goroutines and clients. every
+// acquiring compares the local nonce with the pending count of tx pool, so txs sent by other processes
+// are also taken into account. a nonce which is acquired but not sent should be released, it will be
+// reused first to avoid gap in the tx pool.
+type Manager struct {
+	mu       sync.Mutex
+	accounts map[common.Address]*account
+}
+
+type account struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released map[uint64]struct{}
+}
+
+func NewManager() *Manager {
+	return &Manager{accounts: make(map[common.Address]*account)}
+}
+
+func (m *Manager) account(addr common.Address) *account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &account{released: make(map[uint64]struct{})}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// Acquire returns the next usable nonce of account. the local nonce is used if fetching failed after
+// the account has been synced.
+func (m *Manager) Acquire(addr common.Address, fetch Fetcher) (uint64, error) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	pending, err := fetch()
+	if err != nil {
+		if !acc.synced {
+			return 0, err
+		}
+		log.Warnf("failed to fetch %s pending nonce, use local nonce %d, err: %v", addr.Hex(), acc.next, err)
+	} else if !acc.synced || pending > acc.next {
+		acc.reset(pending)
+	} else {
+		for n := range acc.released {
+			if n < pending {
+				delete(acc.released, n)
+			}
+		}
+	}
+
+	if gaps := acc.gaps(); len(gaps) > 0 {
+		log.Warnf("%s nonce gap %v detected, fill it with %d", addr.Hex(), gaps, gaps[0])
+		delete(acc.released, gaps[0])
+		return gaps[0], nil
+	}
+	nonce := acc.next
+	acc.next += 1
+	return nonce, nil
+}
+
+// Release give back the nonce of a tx which failed to send.
+func (m *Manager) Release(addr common.Address, nonce uint64) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	if !acc.synced || nonce >= acc.next {
+		return
+	}
+	acc.released[nonce] = struct{}{}
+	// shrink the tail so that the released nonces are always gaps
+	for acc.next > 0 {
+		if _, ok := acc.released[acc.next-1]; !ok {
+			break
+		}
+		delete(acc.released, acc.next-1)
+		acc.next -= 1
+	}
+}
+
+// Resync drop local state and reload the pending nonce, it should be called after `nonce too low`.
+func (m *Manager) Resync(addr common.Address, fetch Fetcher) error {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	pending, err := fetch()
+	if err != nil {
+		acc.synced = false
+		return err
+	}
+	acc.reset(pending)
+	return nil
+}
+
+// Gaps returns the released nonces which have not been reused yet.
+func (m *Manager) Gaps(addr common.Address) []uint64 {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	return acc.gaps()
+}
+
+func (acc *account) reset(pending uint64) {
+	acc.synced = true
+	acc.next = pending
+	acc.released = make(map[uint64]struct{})
+}
+
+func (acc *account) gaps() []uint64 {
+	list := make([]uint64, 0, len(acc.released))
+	for n := range acc.released {
+		list = append(list, n)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	return list
+}
+
+// IsNonceTooLow check the error returned by `eth_sendRawTransaction`.
+func IsNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
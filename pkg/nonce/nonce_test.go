@@ -0,0 +1,95 @@
+package nonce
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAddr = common.HexToAddress("0x0000000000000000000000000000000000000103")
+
+func fixed(n uint64) Fetcher {
+	return func() (uint64, error) { return n, nil }
+}
+
+func TestAcquireConcurrently(t *testing.T) {
+	m := NewManager()
+	num := 100
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[uint64]struct{})
+	)
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := m.Acquire(testAddr, fixed(5))
+			assert.NoError(t, err)
+			mu.Lock()
+			seen[n] = struct{}{}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, num, len(seen))
+	for n := uint64(5); n < uint64(5+num); n++ {
+		assert.Contains(t, seen, n)
+	}
+}
+
+func TestReleaseAndGap(t *testing.T) {
+	m := NewManager()
+	for i := 0; i < 4; i++ {
+		m.Acquire(testAddr, fixed(0))
+	}
+
+	// release the tail
+	m.Release(testAddr, 3)
+	assert.Empty(t, m.Gaps(testAddr))
+	n, _ := m.Acquire(testAddr, fixed(0))
+	assert.Equal(t, uint64(3), n)
+
+	// release the middle one and it will be reused first
+	m.Release(testAddr, 1)
+	assert.Equal(t, []uint64{1}, m.Gaps(testAddr))
+	n, _ = m.Acquire(testAddr, fixed(1))
+	assert.Equal(t, uint64(1), n)
+	n, _ = m.Acquire(testAddr, fixed(1))
+	assert.Equal(t, uint64(4), n)
+
+	// gap consumed by other process
+	m.Release(testAddr, 2)
+	n, _ = m.Acquire(testAddr, fixed(3))
+	assert.Equal(t, uint64(5), n)
+	assert.Empty(t, m.Gaps(testAddr))
+}
+
+func TestPendingAndResync(t *testing.T) {
+	m := NewManager()
+
+	_, err := m.Acquire(testAddr, func() (uint64, error) { return 0, fmt.Errorf("rpc unavailable") })
+	assert.Error(t, err)
+
+	n, _ := m.Acquire(testAddr, fixed(2))
+	assert.Equal(t, uint64(2), n)
+
+	// local nonce is used if rpc failed after synced
+	n, err = m.Acquire(testAddr, func() (uint64, error) { return 0, fmt.Errorf("rpc unavailable") })
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), n)
+
+	// pending count grows by txs of other process
+	n, _ = m.Acquire(testAddr, fixed(10))
+	assert.Equal(t, uint64(10), n)
+
+	assert.True(t, IsNonceTooLow(fmt.Errorf("nonce too low")))
+	assert.NoError(t, m.Resync(testAddr, fixed(7)))
+	n, _ = m.Acquire(testAddr, fixed(7))
+	assert.Equal(t, uint64(7), n)
+}
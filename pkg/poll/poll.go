@@ -0,0 +1,125 @@
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Options controls how a condition is polled. the interval grows by `Multiplier` after every
+// unsatisfied attempt until it reaches `MaxInterval`.
+type Options struct {
+	Timeout     time.Duration
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+}
+
+var Default = Options{
+	Timeout:     2 * time.Minute,
+	Interval:    500 * time.Millisecond,
+	MaxInterval: 5 * time.Second,
+	Multiplier:  1.5,
+}
+
+// WithTimeout copy options with another timeout.
+func (o Options) WithTimeout(timeout time.Duration) Options {
+	o.Timeout = timeout
+	return o
+}
+
+func (o Options) normalize() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = Default.Timeout
+	}
+	if o.Interval <= 0 {
+		o.Interval = Default.Interval
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1
+	}
+	return o
+}
+
+// Condition returns true if the expected state reached. errors are treated as transient
+// and retried until timeout, wrap it with `Permanent` to stop polling immediately.
+type Condition func() (bool, error)
+
+type TimeoutError struct {
+	Desc     string
+	Timeout  time.Duration
+	Attempts int
+	// Last is the last transient error returned by condition
+	Last error
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("wait %s timeout after %s, attempts %d", e.Desc, e.Timeout, e.Attempts)
+	if e.Last != nil {
+		msg = fmt.Sprintf("%s, last err: %v", msg, e.Last)
+	}
+	return msg
+}
+
+func IsTimeout(err error) bool {
+	var e *TimeoutError
+	return errors.As(err, &e)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent mark the error as not retryable.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Until poll condition until it returns true, a permanent error returned, or timeout.
+func Until(desc string, opt Options, cond Condition) error {
+	opt = opt.normalize()
+
+	var (
+		deadline = time.Now().Add(opt.Timeout)
+		interval = opt.Interval
+		attempts int
+		last     error
+	)
+
+	for {
+		attempts += 1
+		ok, err := cond()
+		if err != nil {
+			var pe *permanentError
+			if errors.As(err, &pe) {
+				return pe.err
+			}
+			last = err
+		} else if ok {
+			return nil
+		}
+
+		remain := time.Until(deadline)
+		if remain <= 0 {
+			return &TimeoutError{Desc: desc, Timeout: opt.Timeout, Attempts: attempts, Last: last}
+		}
+		if interval > remain {
+			interval = remain
+		}
+		time.Sleep(interval)
+
+		interval = time.Duration(float64(interval) * opt.Multiplier)
+		if interval > opt.MaxInterval {
+			interval = opt.MaxInterval
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package poll
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fast = Options{
+	Timeout:     200 * time.Millisecond,
+	Interval:    5 * time.Millisecond,
+	MaxInterval: 20 * time.Millisecond,
+	Multiplier:  2,
+}
+
+func TestUntilSucceed(t *testing.T) {
+	n := 0
+	err := Until("counter", fast, func() (bool, error) {
+		n += 1
+		if n == 2 {
+			return false, fmt.Errorf("transient")
+		}
+		return n >= 3, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestUntilTimeout(t *testing.T) {
+	start := time.Now()
+	err := Until("never", fast, func() (bool, error) {
+		return false, fmt.Errorf("rpc unavailable")
+	})
+	assert.True(t, IsTimeout(err))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Contains(t, err.Error(), "rpc unavailable")
+}
+
+func TestUntilPermanent(t *testing.T) {
+	n := 0
+	err := Until("permanent", fast, func() (bool, error) {
+		n += 1
+		return false, Permanent(fmt.Errorf("reverted"))
+	})
+	assert.Equal(t, "reverted", err.Error())
+	assert.False(t, IsTimeout(err))
+	assert.Equal(t, 1, n)
+}
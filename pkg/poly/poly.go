@@ -20,7 +20,11 @@ import (
 	polysdk "github.com/polynetwork/poly-go-sdk"
 	polycm "github.com/polynetwork/poly/common"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	polystates "github.com/polynetwork/poly/core/states"
 	polytype "github.com/polynetwork/poly/core/types"
+	scm "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	polyutils "github.com/polynetwork/poly/native/service/utils"
 )
 
 type PolyClient struct {
@@ -268,6 +272,58 @@ func (c *PolyClient) GetCurrentBlockHeight() (uint32, error) {
 	return c.sdk.GetCurrentBlockHeight()
 }
 
+// SideChainState query side chain manager storage, `applied` means the side chain registering is requested
+// but not approved yet, `approved` means the side chain is registered.
+func (c *PolyClient) SideChainState(chainID uint64) (applied, approved bool, err error) {
+	contract := polyutils.SideChainManagerContractAddress.ToHexString()
+	chainIDBytes := polyutils.GetUint64Bytes(chainID)
+
+	raw, err := c.sdk.GetStorage(contract, append([]byte(scm.SIDE_CHAIN), chainIDBytes...))
+	if err != nil {
+		return false, false, err
+	}
+	if len(raw) > 0 {
+		return false, true, nil
+	}
+	if raw, err = c.sdk.GetStorage(contract, append([]byte(scm.SIDE_CHAIN_APPLY), chainIDBytes...)); err != nil {
+		return false, false, err
+	}
+	return len(raw) > 0, false, nil
+}
+
+// GetSideChain returns the registered side chain, nil if the side chain is not registered or approved.
+func (c *PolyClient) GetSideChain(chainID uint64) (*scm.SideChain, error) {
+	contract := polyutils.SideChainManagerContractAddress.ToHexString()
+	raw, err := c.sdk.GetStorage(contract, append([]byte(scm.SIDE_CHAIN), polyutils.GetUint64Bytes(chainID)...))
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+	value, err := polystates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	sideChain := new(scm.SideChain)
+	if err := sideChain.Deserialization(polycm.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("deserialize side chain %d err: %v", chainID, err)
+	}
+	return sideChain, nil
+}
+
+// GenesisSynced check that the side chain genesis header is synced to poly, quorum side chains, e.g: palette,
+// store consensus peers, and ethereum side chains store the genesis header.
+func (c *PolyClient) GenesisSynced(chainID, router uint64) (bool, error) {
+	key := []byte(hscommon.GENESIS_HEADER)
+	if router == polyutils.QUORUM_ROUTER {
+		key = []byte(hscommon.CONSENSUS_PEER)
+	}
+	key = append(key, polyutils.GetUint64Bytes(chainID)...)
+	raw, err := c.sdk.GetStorage(polyutils.HeaderSyncContractAddress.ToHexString(), key)
+	if err != nil {
+		return false, err
+	}
+	return len(raw) > 0, nil
+}
+
 func GetBookeeper(block *polytype.Block) ([]keypair.PublicKey, error) {
 	info := new(vconfig.VbftBlockInfo) //&vconfig.VbftBlockInfo{}
 	info.NewChainConfig = new(vconfig.ChainConfig)
@@ -9,7 +9,7 @@ import (
 func TestPolyClient_GetCurrentBlockHeight(t *testing.T) {
 	rpc := "http://106.75.226.11:40436"
 	configpath := "/Users/dylen/software/onRobot/build/target/config.json"
-	config.Init(configpath)
+	config.Init(configpath, nil)
 	cli, err := NewPolyClient(rpc, nil)
 	assert.NoError(t, err)
 
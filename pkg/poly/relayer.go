@@ -0,0 +1,210 @@
+package poly
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/signature"
+	"github.com/palettechain/onRobot/pkg/relayer"
+	polysdk "github.com/polynetwork/poly-go-sdk"
+	polycm "github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	polystates "github.com/polynetwork/poly/core/states"
+	polytype "github.com/polynetwork/poly/core/types"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	polyutils "github.com/polynetwork/poly/native/service/utils"
+)
+
+// makeProofNotify is the `makeProof` notify of cross chain manager, the states are:
+// [makeProof, fromChainID, toChainID, txHash, height, key], `txHash` is the hex of cross chain tx id
+// emitted by source eccm, and `key` is the key of cross states proof.
+type makeProofNotify struct {
+	TxHash      string // poly tx hash
+	FromChainID uint64
+	ToChainID   uint64
+	TxID        string
+	Key         string
+}
+
+func (c *PolyClient) makeProofNotifies(height uint32) ([]*makeProofNotify, error) {
+	events, err := c.sdk.GetSmartContractEventByBlock(height)
+	if err != nil {
+		return nil, fmt.Errorf("get poly events of block %d failed, err: %v", height, err)
+	}
+	ccm := polyutils.CrossChainManagerContractAddress.ToHexString()
+	list := make([]*makeProofNotify, 0)
+	for _, event := range events {
+		for _, notify := range event.Notify {
+			if notify.ContractAddress != ccm {
+				continue
+			}
+			states, ok := notify.States.([]interface{})
+			if !ok || len(states) < 6 || states[0] != ccmcom.NOTIFY_MAKE_PROOF {
+				continue
+			}
+			fromChainID, _ := states[1].(float64)
+			toChainID, _ := states[2].(float64)
+			txID, _ := states[3].(string)
+			key, _ := states[5].(string)
+			list = append(list, &makeProofNotify{
+				TxHash:      event.TxHash,
+				FromChainID: uint64(fromChainID),
+				ToChainID:   uint64(toChainID),
+				TxID:        txID,
+				Key:         key,
+			})
+		}
+	}
+	return list, nil
+}
+
+// RelayPoly implement `relayer.Poly`, the first account of client signs poly txs.
+type RelayPoly struct {
+	cli    *PolyClient
+	signer *polysdk.Account
+}
+
+func NewRelayPoly(cli *PolyClient) (*RelayPoly, error) {
+	if len(cli.accArr) == 0 {
+		return nil, fmt.Errorf("poly client has no account to sign relay txs")
+	}
+	return &RelayPoly{cli: cli, signer: cli.accArr[0]}, nil
+}
+
+func (r *RelayPoly) Height() (uint64, error) {
+	height, err := r.cli.GetCurrentBlockHeight()
+	return uint64(height), err
+}
+
+func (r *RelayPoly) Events(start, end uint64) ([]*relayer.PolyEvent, error) {
+	list := make([]*relayer.PolyEvent, 0)
+	for height := start; height <= end; height++ {
+		notifies, err := r.cli.makeProofNotifies(uint32(height))
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notifies {
+			list = append(list, &relayer.PolyEvent{
+				TxHash:      n.TxHash,
+				Height:      height,
+				FromChainID: n.FromChainID,
+				ToChainID:   n.ToChainID,
+				TxID:        n.TxID,
+				Key:         n.Key,
+			})
+		}
+	}
+	return list, nil
+}
+
+func (r *RelayPoly) SyncedHeight(chainID uint64) (uint64, error) {
+	key := append([]byte(hscommon.CURRENT_HEADER_HEIGHT), polyutils.GetUint64Bytes(chainID)...)
+	raw, err := r.cli.sdk.GetStorage(polyutils.HeaderSyncContractAddress.ToHexString(), key)
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) == 0 {
+		return 0, fmt.Errorf("side chain %d headers not synced, sync genesis header first", chainID)
+	}
+	value, err := polystates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(value), nil
+}
+
+func (r *RelayPoly) SyncHeaders(chainID uint64, headers [][]byte) error {
+	hash, err := r.cli.sdk.Native.Hs.SyncBlockHeader(chainID, r.signer.Address, headers, r.signer)
+	if err != nil {
+		return err
+	}
+	return r.cli.WaitPolyTx(hash)
+}
+
+func (r *RelayPoly) Import(chainID uint64, rawdata []byte, height uint64, proof, header []byte) (string, error) {
+	hash, err := r.cli.sdk.Native.Ccm.ImportOuterTransfer(chainID, rawdata, uint32(height), proof, r.signer.Address[:], header, r.signer)
+	if err != nil {
+		return "", err
+	}
+	if err := r.cli.WaitPolyTx(hash); err != nil {
+		return "", err
+	}
+	return hash.ToHexString(), nil
+}
+
+// Delivery build the params of `verifyHeaderAndExecuteTx`: the cross states proof is verified with the
+// cross states root of header at `height+1`. if the header is older than the book keepers in target eccd,
+// a later header signed by current book keepers is used as anchor, and the header is proved by merkle
+// proof of the anchor's block root.
+func (r *RelayPoly) Delivery(event *relayer.PolyEvent, epochStart uint64) (*relayer.Delivery, error) {
+	proof, err := r.cli.sdk.GetCrossStatesProof(uint32(event.Height), event.Key)
+	if err != nil {
+		return nil, fmt.Errorf("get cross states proof failed, err: %v", err)
+	}
+	auditPath, err := hex.DecodeString(proof.AuditPath)
+	if err != nil {
+		return nil, err
+	}
+	value, eof := polycm.NewZeroCopySource(auditPath).NextVarBytes()
+	if eof {
+		return nil, fmt.Errorf("invalid audit path of poly tx %s", event.TxHash)
+	}
+	merkleValue := new(ccmcom.ToMerkleValue)
+	if err := merkleValue.Deserialization(polycm.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("deserialize merkle value failed, err: %v", err)
+	}
+
+	headerHeight := uint32(event.Height) + 1
+	header, err := r.cli.sdk.GetHeaderByHeight(headerHeight)
+	if err != nil {
+		return nil, err
+	}
+	d := &relayer.Delivery{
+		Proof:      auditPath,
+		RawHeader:  header.GetMessage(),
+		PolyTxHash: merkleValue.TxHash,
+	}
+
+	signed := header
+	if uint64(headerHeight) < epochStart {
+		anchorHeight := uint32(epochStart) + 1
+		anchor, err := r.cli.sdk.GetHeaderByHeight(anchorHeight)
+		if err != nil {
+			return nil, err
+		}
+		mp, err := r.cli.sdk.GetMerkleProof(headerHeight, anchorHeight)
+		if err != nil {
+			return nil, fmt.Errorf("get merkle proof of header %d in %d failed, err: %v", headerHeight, anchorHeight, err)
+		}
+		if d.HeaderProof, err = hex.DecodeString(mp.AuditPath); err != nil {
+			return nil, err
+		}
+		d.CurRawHeader = anchor.GetMessage()
+		signed = anchor
+	} else if keyHeader(header) {
+		return nil, fmt.Errorf("poly book keepers changed at height %d, sync the poly header to target chain first", headerHeight)
+	}
+
+	for _, sig := range signed.SigData {
+		temp := make([]byte, len(sig))
+		copy(temp, sig)
+		ethSig, err := signature.ConvertToEthCompatible(temp)
+		if err != nil {
+			return nil, fmt.Errorf("convert signature failed, err: %v", err)
+		}
+		d.HeaderSig = append(d.HeaderSig, ethSig...)
+	}
+	return d, nil
+}
+
+// keyHeader check that the header changes book keepers.
+func keyHeader(header *polytype.Header) bool {
+	info := new(vconfig.VbftBlockInfo)
+	if err := json.Unmarshal(header.ConsensusPayload, info); err != nil {
+		return false
+	}
+	return info.NewChainConfig != nil
+}
@@ -0,0 +1,47 @@
+package poly
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/tracker"
+)
+
+// TrackRelay implement `tracker.Relay` with the `makeProof` notify of cross chain manager.
+type TrackRelay struct {
+	cli *PolyClient
+}
+
+func NewTrackRelay(cli *PolyClient) *TrackRelay {
+	return &TrackRelay{cli: cli}
+}
+
+func (r *TrackRelay) Height() (uint64, error) {
+	height, err := r.cli.GetCurrentBlockHeight()
+	return uint64(height), err
+}
+
+func (r *TrackRelay) BlockTime(height uint64) (time.Time, error) {
+	block, err := r.cli.GetBlockByHeight(uint32(height))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(block.Header.Timestamp), 0), nil
+}
+
+func (r *TrackRelay) FindProof(fromChainID uint64, txID []byte, start, end uint64) (*tracker.Record, error) {
+	expect := hex.EncodeToString(txID)
+	for height := start; height <= end; height++ {
+		notifies, err := r.cli.makeProofNotifies(uint32(height))
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notifies {
+			if n.FromChainID == fromChainID && strings.EqualFold(n.TxID, expect) {
+				return &tracker.Record{TxHash: n.TxHash, Height: height}, nil
+			}
+		}
+	}
+	return nil, nil
+}
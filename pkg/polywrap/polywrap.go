@@ -0,0 +1,300 @@
+// Package polywrap binds the fee wrappers in front of PLT and NFT lock proxies. user lock assets through
+// the wrapper with a fee and an `id`, the wrapper keeps the fee for relayers and forwards the asset to the
+// lock proxy. the package only binds the admin interface and events shared by the palette native wrappers
+// and the ethereum wrapper, locks are sent by the generated bindings in `sdk` and `eth`.
+package polywrap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	KindPLT = "plt" // PolyWrapper, lock erc20 or native token and pay fee in the same token
+	KindNFT = "nft" // PolyNFTWrapper, lock nft and pay fee in `feeToken`
+)
+
+// admin interface shared by wrappers, the lock proxy getter is `lockproxy` in the early PLT wrapper.
+const adminAbiEntries = `
+	{"inputs":[],"name":"owner","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"feeCollector","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"lockProxy","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"lockproxy","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"paused","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"address","name":"collector","type":"address"}],"name":"setFeeCollector","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"internalType":"address","name":"_lockProxy","type":"address"}],"name":"setLockProxy","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[],"name":"pause","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[],"name":"unpause","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"internalType":"address","name":"token","type":"address"}],"name":"extractFee","outputs":[],"stateMutability":"nonpayable","type":"function"}`
+
+const pltAbiEntries = `
+	{"inputs":[{"internalType":"address","name":"fromAsset","type":"address"},{"internalType":"bytes","name":"txHash","type":"bytes"},{"internalType":"uint256","name":"fee","type":"uint256"}],"name":"speedUp","outputs":[],"stateMutability":"payable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"fromAsset","type":"address"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint64","name":"toChainId","type":"uint64"},{"indexed":false,"internalType":"bytes","name":"toAddress","type":"bytes"},{"indexed":false,"internalType":"uint256","name":"net","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"fee","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"id","type":"uint256"}],"name":"PolyWrapperLock","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"fromAsset","type":"address"},{"indexed":true,"internalType":"bytes","name":"txHash","type":"bytes"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint256","name":"efee","type":"uint256"}],"name":"PolyWrapperSpeedUp","type":"event"}`
+
+const nftAbiEntries = `
+	{"inputs":[{"internalType":"address","name":"feeToken","type":"address"},{"internalType":"bytes","name":"txHash","type":"bytes"},{"internalType":"uint256","name":"fee","type":"uint256"}],"name":"speedUp","outputs":[],"stateMutability":"payable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"fromAsset","type":"address"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint64","name":"toChainId","type":"uint64"},{"indexed":false,"internalType":"address","name":"toAddress","type":"address"},{"indexed":false,"internalType":"uint256","name":"tokenId","type":"uint256"},{"indexed":false,"internalType":"address","name":"feeToken","type":"address"},{"indexed":false,"internalType":"uint256","name":"fee","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"id","type":"uint256"}],"name":"PolyWrapperLock","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"feeToken","type":"address"},{"indexed":true,"internalType":"bytes","name":"txHash","type":"bytes"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint256","name":"efee","type":"uint256"}],"name":"PolyWrapperSpeedUp","type":"event"}`
+
+const (
+	eventLock    = "PolyWrapperLock"
+	eventSpeedUp = "PolyWrapperSpeedUp"
+)
+
+var abis = make(map[string]abi.ABI)
+
+func init() {
+	for kind, entries := range map[string]string{KindPLT: pltAbiEntries, KindNFT: nftAbiEntries} {
+		ab, err := abi.JSON(strings.NewReader("[" + adminAbiEntries + "," + entries + "]"))
+		if err != nil {
+			panic(err)
+		}
+		abis[kind] = ab
+	}
+}
+
+// LockEvent is the `PolyWrapperLock` event. the fee of PLT wrapper is paid in the locked asset, so that
+// `FeeToken` equals to `FromAsset`, and `TokenID` is only used by NFT wrapper.
+type LockEvent struct {
+	FromAsset common.Address
+	Sender    common.Address
+	ToChainID uint64
+	ToAddress []byte
+	Net       *big.Int // amount forwarded to lock proxy
+	TokenID   *big.Int
+	FeeToken  common.Address
+	Fee       *big.Int
+	ID        *big.Int
+	TxHash    common.Hash
+	Height    uint64
+}
+
+// SpeedUpEvent is the `PolyWrapperSpeedUp` event, the indexed `txHash` is hashed by evm.
+type SpeedUpEvent struct {
+	FeeToken common.Address
+	TxHash   common.Hash
+	Sender   common.Address
+	Fee      *big.Int
+}
+
+// Of returns true if the event speeds up the lock tx.
+func (e *SpeedUpEvent) Of(lockTx common.Hash) bool {
+	return e.TxHash == crypto.Keccak256Hash(lockTx.Bytes())
+}
+
+// Net returns the amount forwarded to lock proxy by PLT wrapper.
+func Net(amount, fee *big.Int) (*big.Int, error) {
+	if amount.Sign() < 0 || fee.Sign() < 0 {
+		return nil, fmt.Errorf("negative amount %s or fee %s", amount, fee)
+	}
+	if fee.Cmp(amount) > 0 {
+		return nil, fmt.Errorf("fee %s exceeds amount %s", fee, amount)
+	}
+	return new(big.Int).Sub(amount, fee), nil
+}
+
+type Wrapper struct {
+	Address  common.Address
+	Kind     string
+	abi      abi.ABI
+	contract *bind.BoundContract
+	filterer bind.ContractFilterer
+}
+
+// New binds the wrapper, backend could be nil if the wrapper is only used to parse events.
+func New(kind string, addr common.Address, backend bind.ContractBackend) (*Wrapper, error) {
+	ab, ok := abis[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown wrapper kind %s", kind)
+	}
+	return &Wrapper{
+		Address:  addr,
+		Kind:     kind,
+		abi:      ab,
+		contract: bind.NewBoundContract(addr, ab, backend, backend, backend),
+		filterer: backend,
+	}, nil
+}
+
+func (w *Wrapper) Owner(opts *bind.CallOpts) (common.Address, error) {
+	return w.address(opts, "owner")
+}
+
+func (w *Wrapper) FeeCollector(opts *bind.CallOpts) (common.Address, error) {
+	return w.address(opts, "feeCollector")
+}
+
+func (w *Wrapper) LockProxy(opts *bind.CallOpts) (common.Address, error) {
+	addr, err := w.address(opts, "lockProxy")
+	if err == nil {
+		return addr, nil
+	}
+	if addr, err2 := w.address(opts, "lockproxy"); err2 == nil {
+		return addr, nil
+	}
+	return common.Address{}, err
+}
+
+func (w *Wrapper) Paused(opts *bind.CallOpts) (bool, error) {
+	out := new(bool)
+	err := w.contract.Call(opts, out, "paused")
+	return *out, err
+}
+
+func (w *Wrapper) SetFeeCollector(opts *bind.TransactOpts, collector common.Address) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "setFeeCollector", collector)
+}
+
+func (w *Wrapper) SetLockProxy(opts *bind.TransactOpts, proxy common.Address) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "setLockProxy", proxy)
+}
+
+func (w *Wrapper) Pause(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "pause")
+}
+
+func (w *Wrapper) Unpause(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "unpause")
+}
+
+// ExtractFee transfer all fee of token kept by wrapper to fee collector, only fee collector can call it.
+func (w *Wrapper) ExtractFee(opts *bind.TransactOpts, token common.Address) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "extractFee", token)
+}
+
+// SpeedUp pay more fee for the lock tx, the native token fee should be set as `opts.Value`.
+func (w *Wrapper) SpeedUp(opts *bind.TransactOpts, token common.Address, lockTx common.Hash, fee *big.Int) (*types.Transaction, error) {
+	return w.contract.Transact(opts, "speedUp", token, lockTx.Bytes(), fee)
+}
+
+// LockEvents returns the lock events emitted by this wrapper in receipt.
+func (w *Wrapper) LockEvents(receipt *types.Receipt) ([]*LockEvent, error) {
+	list := make([]*LockEvent, 0)
+	for _, l := range w.logs(receipt.Logs, eventLock) {
+		evt, err := w.ParseLock(l)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, evt)
+	}
+	return list, nil
+}
+
+// SpeedUpEvents returns the speed up events emitted by this wrapper in receipt.
+func (w *Wrapper) SpeedUpEvents(receipt *types.Receipt) ([]*SpeedUpEvent, error) {
+	list := make([]*SpeedUpEvent, 0)
+	for _, l := range w.logs(receipt.Logs, eventSpeedUp) {
+		if len(l.Topics) != 4 {
+			return nil, fmt.Errorf("invalid speed up event topics %d", len(l.Topics))
+		}
+		values, err := w.abi.Events[eventSpeedUp].Inputs.UnpackValues(l.Data)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &SpeedUpEvent{
+			FeeToken: common.BytesToAddress(l.Topics[1].Bytes()),
+			TxHash:   l.Topics[2],
+			Sender:   common.BytesToAddress(l.Topics[3].Bytes()),
+			Fee:      values[0].(*big.Int),
+		})
+	}
+	return list, nil
+}
+
+// FindLock search the lock event with id in blocks [start, end], the `id` is not indexed, so that all
+// lock events of the wrapper in range are decoded. end 0 means the latest block.
+func (w *Wrapper) FindLock(ctx context.Context, start, end uint64, id *big.Int) (*LockEvent, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(start),
+		Addresses: []common.Address{w.Address},
+		Topics:    [][]common.Hash{{w.eventID(eventLock)}},
+	}
+	if end > 0 {
+		query.ToBlock = new(big.Int).SetUint64(end)
+	}
+	logs, err := w.filterer.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i := range logs {
+		evt, err := w.ParseLock(&logs[i])
+		if err != nil {
+			return nil, err
+		}
+		if evt.ID.Cmp(id) == 0 {
+			return evt, nil
+		}
+	}
+	return nil, fmt.Errorf("lock id %s not found in blocks [%d, %d]", id, start, end)
+}
+
+// ParseLock decode the `PolyWrapperLock` event of PLT or NFT wrapper.
+func (w *Wrapper) ParseLock(l *types.Log) (*LockEvent, error) {
+	if len(l.Topics) != 3 || l.Topics[0] != w.eventID(eventLock) {
+		return nil, fmt.Errorf("log is not wrapper lock event")
+	}
+	values, err := w.abi.Events[eventLock].Inputs.UnpackValues(l.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := &LockEvent{
+		FromAsset: common.BytesToAddress(l.Topics[1].Bytes()),
+		Sender:    common.BytesToAddress(l.Topics[2].Bytes()),
+		ToChainID: values[0].(uint64),
+		TxHash:    l.TxHash,
+		Height:    l.BlockNumber,
+	}
+	switch w.Kind {
+	case KindPLT:
+		evt.ToAddress = values[1].([]byte)
+		evt.Net = values[2].(*big.Int)
+		evt.FeeToken = evt.FromAsset
+		evt.Fee = values[3].(*big.Int)
+		evt.ID = values[4].(*big.Int)
+	case KindNFT:
+		evt.ToAddress = values[1].(common.Address).Bytes()
+		evt.TokenID = values[2].(*big.Int)
+		evt.FeeToken = values[3].(common.Address)
+		evt.Fee = values[4].(*big.Int)
+		evt.ID = values[5].(*big.Int)
+	}
+	return evt, nil
+}
+
+func (w *Wrapper) logs(logs []*types.Log, event string) []*types.Log {
+	id := w.eventID(event)
+	list := make([]*types.Log, 0)
+	for _, l := range logs {
+		if l.Address == w.Address && len(l.Topics) > 0 && l.Topics[0] == id {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+func (w *Wrapper) address(opts *bind.CallOpts, method string) (common.Address, error) {
+	out := new(common.Address)
+	err := w.contract.Call(opts, out, method)
+	return *out, err
+}
+
+// eventID returns the topic of event, `abi.Event.ID` is a method in palette and a field in later geth.
+func (w *Wrapper) eventID(event string) common.Hash {
+	ev := w.abi.Events[event]
+	args := make([]string, 0, len(ev.Inputs))
+	for _, input := range ev.Inputs {
+		args = append(args, input.Type.String())
+	}
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s(%s)", ev.Name, strings.Join(args, ","))))
+}
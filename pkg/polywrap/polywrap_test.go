@@ -0,0 +1,133 @@
+package polywrap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testWrapper = common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	testAsset   = common.HexToAddress("0x0000000000000000000000000000000000000103")
+	testSender  = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+)
+
+func newWrapper(t *testing.T, kind string) *Wrapper {
+	w, err := New(kind, testWrapper, nil)
+	assert.NoError(t, err)
+	return w
+}
+
+func mockLog(t *testing.T, w *Wrapper, event string, topics []common.Hash, args ...interface{}) *types.Log {
+	ev := w.abi.Events[event]
+	data, err := ev.Inputs.NonIndexed().Pack(args...)
+	assert.NoError(t, err)
+	return &types.Log{
+		Address:     w.Address,
+		Topics:      append([]common.Hash{w.eventID(event)}, topics...),
+		Data:        data,
+		TxHash:      common.HexToHash("0x01"),
+		BlockNumber: 12,
+	}
+}
+
+func TestABI(t *testing.T) {
+	_, err := New("erc20", testWrapper, nil)
+	assert.Error(t, err)
+
+	// the same as generated PolyWrapper binding
+	plt := newWrapper(t, KindPLT)
+	assert.Equal(t, "0x2b0591052cc6602e870d3994f0a1b173fdac98c215cb3b0baf84eaca5a0aa81e", plt.eventID(eventLock).Hex())
+	assert.Equal(t, "0xf6579aef3e0d086d986c5d6972659f8a0d8602ef7945b054be1b88e088773ef6", plt.eventID(eventSpeedUp).Hex())
+
+	for _, kind := range []string{KindPLT, KindNFT} {
+		w := newWrapper(t, kind)
+		pause, err := w.abi.Pack("pause")
+		assert.NoError(t, err)
+		assert.Equal(t, "0x8456cb59", hexutil.Encode(pause))
+		unpause, err := w.abi.Pack("unpause")
+		assert.NoError(t, err)
+		assert.Equal(t, "0x3f4ba83a", hexutil.Encode(unpause))
+	}
+}
+
+func TestParseLock(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000b02")
+	feeToken := common.HexToAddress("0x0000000000000000000000000000000000000c01")
+	topics := []common.Hash{testAsset.Hash(), testSender.Hash()}
+
+	plt := newWrapper(t, KindPLT)
+	l := mockLog(t, plt, eventLock, topics, uint64(2), to.Bytes(), big.NewInt(90), big.NewInt(10), big.NewInt(7))
+	evt, err := plt.ParseLock(l)
+	assert.NoError(t, err)
+	assert.Equal(t, testAsset, evt.FromAsset)
+	assert.Equal(t, testSender, evt.Sender)
+	assert.Equal(t, uint64(2), evt.ToChainID)
+	assert.Equal(t, to.Bytes(), evt.ToAddress)
+	assert.Equal(t, int64(90), evt.Net.Int64())
+	assert.Equal(t, testAsset, evt.FeeToken)
+	assert.Equal(t, int64(10), evt.Fee.Int64())
+	assert.Equal(t, int64(7), evt.ID.Int64())
+	assert.Equal(t, uint64(12), evt.Height)
+
+	nft := newWrapper(t, KindNFT)
+	l = mockLog(t, nft, eventLock, topics, uint64(2), to, big.NewInt(1001), feeToken, big.NewInt(10), big.NewInt(8))
+	evt, err = nft.ParseLock(l)
+	assert.NoError(t, err)
+	assert.Equal(t, to.Bytes(), evt.ToAddress)
+	assert.Equal(t, int64(1001), evt.TokenID.Int64())
+	assert.Equal(t, feeToken, evt.FeeToken)
+	assert.Equal(t, int64(10), evt.Fee.Int64())
+	assert.Equal(t, int64(8), evt.ID.Int64())
+
+	// plt event is not nft event
+	_, err = plt.ParseLock(l)
+	assert.Error(t, err)
+}
+
+func TestReceiptEvents(t *testing.T) {
+	w := newWrapper(t, KindPLT)
+	lockTx := common.HexToHash("0x1234")
+	lock := mockLog(t, w, eventLock, []common.Hash{testAsset.Hash(), testSender.Hash()},
+		uint64(2), testSender.Bytes(), big.NewInt(90), big.NewInt(10), big.NewInt(7))
+	speedUp := mockLog(t, w, eventSpeedUp, []common.Hash{testAsset.Hash(), crypto.Keccak256Hash(lockTx.Bytes()), testSender.Hash()},
+		big.NewInt(5))
+	other := mockLog(t, w, eventLock, []common.Hash{testAsset.Hash(), testSender.Hash()},
+		uint64(2), testSender.Bytes(), big.NewInt(90), big.NewInt(10), big.NewInt(8))
+	other.Address = testAsset
+	receipt := &types.Receipt{Logs: []*types.Log{other, lock, speedUp}}
+
+	locks, err := w.LockEvents(receipt)
+	assert.NoError(t, err)
+	assert.Len(t, locks, 1)
+	assert.Equal(t, int64(7), locks[0].ID.Int64())
+
+	speedUps, err := w.SpeedUpEvents(receipt)
+	assert.NoError(t, err)
+	assert.Len(t, speedUps, 1)
+	assert.Equal(t, testAsset, speedUps[0].FeeToken)
+	assert.Equal(t, testSender, speedUps[0].Sender)
+	assert.Equal(t, int64(5), speedUps[0].Fee.Int64())
+	assert.True(t, speedUps[0].Of(lockTx))
+	assert.False(t, speedUps[0].Of(common.HexToHash("0x01")))
+}
+
+func TestNet(t *testing.T) {
+	net, err := Net(big.NewInt(100), big.NewInt(10))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), net.Int64())
+
+	net, err = Net(big.NewInt(100), big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), net.Int64())
+
+	_, err = Net(big.NewInt(10), big.NewInt(11))
+	assert.Error(t, err)
+	_, err = Net(big.NewInt(10), big.NewInt(-1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package profile
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decode document into out which should be a pointer to struct, fields are decoded one by one and
+// all of the type mismatches and unknown fields are reported.
+func Decode(doc map[string]interface{}, out interface{}) Errors {
+	var errs Errors
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		errs.Add("decode target should be pointer to struct, got %s", v.Type())
+		return errs
+	}
+	decodeStruct(doc, v.Elem(), "", &errs)
+	return errs
+}
+
+func decodeValue(raw interface{}, v reflect.Value, path string, errs *Errors) {
+	if raw == nil {
+		return
+	}
+
+	t := v.Type()
+	if isLeaf(t) {
+		decodeLeaf(raw, v, path, errs)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			decodeLeaf(raw, v, path, errs)
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		decodeValue(raw, v.Elem(), path, errs)
+
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			errs.Add("%s: should be an object", path)
+			return
+		}
+		decodeStruct(m, v, path, errs)
+
+	case reflect.Slice:
+		list, ok := raw.([]interface{})
+		if !ok {
+			errs.Add("%s: should be an array", path)
+			return
+		}
+		s := reflect.MakeSlice(t, len(list), len(list))
+		for i, e := range list {
+			decodeValue(e, s.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		v.Set(s)
+
+	default:
+		decodeLeaf(raw, v, path, errs)
+	}
+}
+
+func decodeStruct(m map[string]interface{}, v reflect.Value, path string, errs *Errors) {
+	t := v.Type()
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields[strings.ToLower(name)] = i
+	}
+
+	for _, key := range names(m) {
+		sub := key
+		if path != "" {
+			sub = path + "." + key
+		}
+		i, ok := fields[strings.ToLower(key)]
+		if !ok {
+			errs.Add("%s: unknown field", sub)
+			continue
+		}
+		decodeValue(m[key], v.Field(i), sub, errs)
+	}
+}
+
+func decodeLeaf(raw interface{}, v reflect.Value, path string, errs *Errors) {
+	bz, err := json.Marshal(raw)
+	if err != nil {
+		errs.Add("%s: %v", path, err)
+		return
+	}
+	if err := json.Unmarshal(bz, v.Addr().Interface()); err != nil {
+		if te, ok := err.(*json.UnmarshalTypeError); ok {
+			errs.Add("%s: expect %s, got %s %s", path, te.Type, te.Value, string(bz))
+		} else {
+			errs.Add("%s: %v", path, err)
+		}
+	}
+}
+
+func isLeaf(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return t.Implements(jsonUnmarshaler) || pt.Implements(jsonUnmarshaler) ||
+		t.Implements(textUnmarshaler) || pt.Implements(textUnmarshaler)
+}
@@ -0,0 +1,266 @@
+// Package profile resolves json config documents with profiles, overrides and field-by-field decoding,
+// so that all of the mistakes in config file are reported at once.
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	KeyProfile  = "Profile"  // 默认使用的profile名称
+	KeyProfiles = "Profiles" // profile名称 => 覆盖字段
+	KeyExtends  = "Extends"  // profile继承的父profile名称
+)
+
+// Errors collects all errors instead of stopping at the first one.
+type Errors []error
+
+func (e Errors) Error() string {
+	list := make([]string, len(e))
+	for i, err := range e {
+		list[i] = "  " + err.Error()
+	}
+	return fmt.Sprintf("%d errors:\n%s", len(e), strings.Join(list, "\n"))
+}
+
+// Err returns nil if there is no error.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Errors) Add(format string, args ...interface{}) {
+	*e = append(*e, fmt.Errorf(format, args...))
+}
+
+// Parse decode json document with number kept as `json.Number`, syntax error is reported with line and column.
+func Parse(data []byte) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			line, col := position(data, se.Offset)
+			return nil, fmt.Errorf("json syntax error at line %d column %d: %v", line, col, err)
+		}
+		return nil, err
+	}
+	return doc, nil
+}
+
+func position(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return
+}
+
+// Resolve merge the selected profile and its ancestors into document, the fields `Profile` and
+// `Profiles` are removed. name is used if it's not empty, otherwise `Profile` field of document,
+// and no profile is applied if both of them are empty.
+func Resolve(doc map[string]interface{}, name string) (map[string]interface{}, string, error) {
+	profiles, _ := doc[KeyProfiles].(map[string]interface{})
+	if name == "" {
+		name, _ = doc[KeyProfile].(string)
+	}
+
+	base := make(map[string]interface{})
+	for k, v := range doc {
+		if k != KeyProfile && k != KeyProfiles {
+			base[k] = clone(v)
+		}
+	}
+	if name == "" {
+		return base, "", nil
+	}
+
+	chain := make([]map[string]interface{}, 0)
+	visited := make(map[string]bool)
+	for cur := name; cur != ""; {
+		if visited[cur] {
+			return nil, "", fmt.Errorf("profile %s inherits itself", cur)
+		}
+		visited[cur] = true
+		p, ok := profiles[cur].(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("profile %s not found, available: %s", cur, strings.Join(names(profiles), ","))
+		}
+		chain = append(chain, p)
+		cur, _ = p[KeyExtends].(string)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		overlay := make(map[string]interface{})
+		for k, v := range chain[i] {
+			if k != KeyExtends {
+				overlay[k] = v
+			}
+		}
+		Merge(base, overlay)
+	}
+	return base, name, nil
+}
+
+func names(m map[string]interface{}) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Merge src into dst recursively, objects are merged field by field, arrays and values are replaced.
+func Merge(dst, src map[string]interface{}) {
+	for k, sv := range src {
+		key := matchKey(dst, k)
+		sm, ok1 := sv.(map[string]interface{})
+		dm, ok2 := dst[key].(map[string]interface{})
+		if ok1 && ok2 {
+			Merge(dm, sm)
+			continue
+		}
+		dst[key] = clone(sv)
+	}
+}
+
+func clone(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[k] = clone(e)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(x))
+		for i, e := range x {
+			list[i] = clone(e)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// matchKey returns the existing key which is case-insensitive equal to k, json fields are matched
+// case-insensitively too.
+func matchKey(m map[string]interface{}, k string) string {
+	if _, ok := m[k]; ok {
+		return k
+	}
+	for key := range m {
+		if strings.EqualFold(key, k) {
+			return key
+		}
+	}
+	return k
+}
+
+// Set override the field at path, e.g: ["Nodes", "0", "Host"]. the value is kept as string if the
+// existing field is string, otherwise it's decoded as json if possible.
+func Set(doc map[string]interface{}, path []string, value string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("empty path")
+	}
+
+	var cur interface{} = doc
+	for i, seg := range path {
+		last := i == len(path)-1
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			key := matchKey(node, seg)
+			if last {
+				node[key] = typedValue(node[key], value)
+				return nil
+			}
+			if node[key] == nil {
+				node[key] = make(map[string]interface{})
+			}
+			cur = node[key]
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return fmt.Errorf("%s: invalid index %s", strings.Join(path[:i], "."), seg)
+			}
+			if last {
+				node[idx] = typedValue(node[idx], value)
+				return nil
+			}
+			cur = node[idx]
+		default:
+			return fmt.Errorf("%s is not an object or array", strings.Join(path[:i], "."))
+		}
+	}
+	return nil
+}
+
+func typedValue(old interface{}, value string) interface{} {
+	if _, ok := old.(string); ok {
+		return value
+	}
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return value
+	}
+	return v
+}
+
+// Override is a field path and its raw value.
+type Override struct {
+	Path  []string
+	Value string
+	From  string
+}
+
+// ParseSet parse cli override like `CrossChain.EthereumRPCUrl=http://127.0.0.1:8545`.
+func ParseSet(s string) (*Override, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid override %s, should be like Path.To.Field=value", s)
+	}
+	return &Override{Path: strings.Split(s[:i], "."), Value: s[i+1:], From: "cli " + s[:i]}, nil
+}
+
+// ParseEnv find overrides in environ, e.g: `ONROBOT_CFG_CrossChain__EthereumRPCUrl=http://127.0.0.1:8545`
+// with prefix `ONROBOT_CFG_`, path is separated by double underscore and matched case-insensitively.
+func ParseEnv(environ []string, prefix string) []*Override {
+	list := make([]*Override, 0)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i <= len(prefix) {
+			continue
+		}
+		name := kv[len(prefix):i]
+		list = append(list, &Override{Path: strings.Split(name, "__"), Value: kv[i+1:], From: "env " + kv[:i]})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].From < list[j].From })
+	return list
+}
+
+// Apply set all overrides into document.
+func Apply(doc map[string]interface{}, list []*Override) error {
+	var errs Errors
+	for _, o := range list {
+		if err := Set(doc, o.Path, o.Value); err != nil {
+			errs.Add("%s: %v", o.From, err)
+		}
+	}
+	return errs.Err()
+}
@@ -0,0 +1,127 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDoc = `{
+  "Rpc": "http://127.0.0.1:22000",
+  "GasLimit": 8000000,
+  "Wait": {"Timeout": "1m", "Multiplier": 2},
+  "Nodes": [{"Host": "127.0.0.1", "RPCPort": "22000"}],
+  "Profile": "test",
+  "Profiles": {
+    "test": {"Extends": "local", "Rpc": "http://10.0.0.1:22000", "wait": {"Timeout": "5m"}},
+    "local": {"GasLimit": 9000000, "Wait": {"Multiplier": 1.5}},
+    "loop": {"Extends": "loop"}
+  }
+}`
+
+type testWait struct {
+	Timeout    duration
+	Multiplier float64
+}
+
+type testNode struct {
+	Host    string
+	RPCPort string `json:"RPCPort"`
+	secret  string
+}
+
+type testConfig struct {
+	Rpc      string
+	GasLimit uint64
+	Wait     *testWait
+	Nodes    []*testNode
+}
+
+type duration time.Duration
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	*d = duration(v)
+	return err
+}
+
+func TestResolve(t *testing.T) {
+	doc, err := Parse([]byte(testDoc))
+	assert.NoError(t, err)
+
+	resolved, name, err := Resolve(doc, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "test", name)
+
+	c := new(testConfig)
+	assert.NoError(t, Decode(resolved, c).Err())
+	assert.Equal(t, "http://10.0.0.1:22000", c.Rpc)
+	assert.Equal(t, uint64(9000000), c.GasLimit)
+	assert.Equal(t, duration(5*time.Minute), c.Wait.Timeout)
+	assert.Equal(t, 1.5, c.Wait.Multiplier)
+
+	// document not modified
+	resolved, _, err = Resolve(doc, "local")
+	assert.NoError(t, err)
+	c = new(testConfig)
+	assert.NoError(t, Decode(resolved, c).Err())
+	assert.Equal(t, "http://127.0.0.1:22000", c.Rpc)
+	assert.Equal(t, duration(time.Minute), c.Wait.Timeout)
+
+	_, _, err = Resolve(doc, "loop")
+	assert.Error(t, err)
+	_, _, err = Resolve(doc, "prod")
+	assert.Error(t, err)
+}
+
+func TestOverride(t *testing.T) {
+	doc, err := Parse([]byte(testDoc))
+	assert.NoError(t, err)
+	doc, _, err = Resolve(doc, "")
+	assert.NoError(t, err)
+
+	env := ParseEnv([]string{
+		"ONROBOT_CFG_GASLIMIT=100",
+		"ONROBOT_CFG_Nodes__0__RPCPort=22001",
+		"PATH=/usr/bin",
+	}, "ONROBOT_CFG_")
+	assert.Equal(t, 2, len(env))
+	set, err := ParseSet("Wait.Timeout=3s")
+	assert.NoError(t, err)
+	_, err = ParseSet("Wait.Timeout")
+	assert.Error(t, err)
+
+	assert.NoError(t, Apply(doc, append(env, set)))
+	c := new(testConfig)
+	assert.NoError(t, Decode(doc, c).Err())
+	assert.Equal(t, uint64(100), c.GasLimit)
+	assert.Equal(t, "22001", c.Nodes[0].RPCPort)
+	assert.Equal(t, duration(3*time.Second), c.Wait.Timeout)
+
+	bad, _ := ParseSet("Nodes.3.Host=x")
+	assert.Error(t, Apply(doc, []*Override{bad}))
+}
+
+func TestDecodeErrors(t *testing.T) {
+	doc, err := Parse([]byte(`{
+  "Rpc": 1,
+  "GasLimit": "much",
+  "Wiat": {},
+  "Wait": {"Timeout": "forever"},
+  "Nodes": [{"Host": "127.0.0.1", "Port": 1}]
+}`))
+	assert.NoError(t, err)
+
+	errs := Decode(doc, new(testConfig))
+	assert.Equal(t, 5, len(errs))
+	msg := errs.Error()
+	for _, path := range []string{"Rpc", "GasLimit", "Wiat: unknown field", "Wait.Timeout", "Nodes[0].Port: unknown field"} {
+		assert.True(t, strings.Contains(msg, path), path)
+	}
+
+	_, err = Parse([]byte("{\n  \"Rpc\": ,\n}"))
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "line 2"))
+}
@@ -0,0 +1,164 @@
+// Package proof verifies the result of `eth_getProof`: the account proof against the state root of a
+// block header, and the storage proofs against the storage root of the account. it also computes the
+// eccd storage keys which relayers prove to poly.
+package proof
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// storage slots of eccd state variables, `slot 0` is the owner and paused flag.
+const (
+	ECCDTxHashMapSlot        = 1 // mapping(uint256 => bytes32) EthToPolyTxHashMap
+	ECCDTxHashIndexSlot      = 2 // uint256 EthToPolyTxHashIndex
+	ECCDFromChainTxExistSlot = 5 // mapping(uint64 => mapping(bytes32 => bool)) FromChainTxExist
+)
+
+// Slot returns the storage key of state variable declared at slot n.
+func Slot(n uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(n))
+}
+
+// MappingKey returns the storage key of `mapping[key]`, the mapping is declared at slot or nested in
+// another mapping whose value key is slot.
+func MappingKey(key []byte, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(key, common.HashLength), slot.Bytes())
+}
+
+// ECCDTxHashKey returns the key of cross chain tx param hash in eccd, tx id is the index emitted in
+// eccm `CrossChainEvent`.
+func ECCDTxHashKey(txID []byte) common.Hash {
+	return MappingKey(txID, Slot(ECCDTxHashMapSlot))
+}
+
+// ECCDFromChainTxKey returns the key of the flag that poly tx executed by eccm.
+func ECCDFromChainTxKey(fromChainID uint64, polyTxHash []byte) common.Hash {
+	inner := MappingKey(new(big.Int).SetUint64(fromChainID).Bytes(), Slot(ECCDFromChainTxExistSlot))
+	return MappingKey(polyTxHash, inner)
+}
+
+// Proof is the result of `eth_getProof`.
+type Proof struct {
+	Address      common.Address `json:"address"`
+	Balance      *hexutil.Big   `json:"balance"`
+	CodeHash     common.Hash    `json:"codeHash"`
+	Nonce        hexutil.Uint64 `json:"nonce"`
+	StorageHash  common.Hash    `json:"storageHash"`
+	AccountProof []string       `json:"accountProof"`
+	StorageProof []StorageProof `json:"storageProof"`
+}
+
+type StorageProof struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// account is the rlp encoded account in state trie.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// Storage returns the value of key in storage proofs.
+func (p *Proof) Storage(key common.Hash) (*big.Int, bool) {
+	for _, sp := range p.StorageProof {
+		if common.HexToHash(sp.Key) == key {
+			return sp.Value.ToInt(), true
+		}
+	}
+	return nil, false
+}
+
+// Verify check the account proof against state root and all storage proofs against the proved
+// storage root.
+func (p *Proof) Verify(stateRoot common.Hash) error {
+	if err := p.VerifyAccount(stateRoot); err != nil {
+		return err
+	}
+	return p.VerifyStorage()
+}
+
+// VerifyAccount check the account proof against state root, and that the account fields returned by
+// node equal to the proved account.
+func (p *Proof) VerifyAccount(stateRoot common.Hash) error {
+	value, err := verify(stateRoot, crypto.Keccak256(p.Address.Bytes()), p.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account %s proof invalid, err: %v", p.Address.Hex(), err)
+	}
+	if len(value) == 0 {
+		return fmt.Errorf("account %s not exist in state %s", p.Address.Hex(), stateRoot.Hex())
+	}
+	acc := new(account)
+	if err := rlp.DecodeBytes(value, acc); err != nil {
+		return fmt.Errorf("decode account %s failed, err: %v", p.Address.Hex(), err)
+	}
+
+	if uint64(p.Nonce) != acc.Nonce {
+		return fmt.Errorf("account %s nonce mismatch, expect %d, proved %d", p.Address.Hex(), p.Nonce, acc.Nonce)
+	}
+	if p.Balance == nil || p.Balance.ToInt().Cmp(acc.Balance) != 0 {
+		return fmt.Errorf("account %s balance mismatch, expect %s, proved %s", p.Address.Hex(), p.Balance, acc.Balance)
+	}
+	if p.StorageHash != acc.Root {
+		return fmt.Errorf("account %s storage hash mismatch, expect %s, proved %s", p.Address.Hex(), p.StorageHash.Hex(), acc.Root.Hex())
+	}
+	if !bytes.Equal(p.CodeHash.Bytes(), acc.CodeHash) {
+		return fmt.Errorf("account %s code hash mismatch, expect %s, proved %s", p.Address.Hex(), p.CodeHash.Hex(), hexutil.Encode(acc.CodeHash))
+	}
+	return nil
+}
+
+// VerifyStorage check every storage proof against the storage hash, the key absent in storage trie is
+// proved to be zero.
+func (p *Proof) VerifyStorage() error {
+	for _, sp := range p.StorageProof {
+		key := common.HexToHash(sp.Key)
+		value, err := verify(p.StorageHash, crypto.Keccak256(key.Bytes()), sp.Proof)
+		if err != nil {
+			return fmt.Errorf("storage %s proof invalid, err: %v", key.Hex(), err)
+		}
+		proved := new(big.Int)
+		if len(value) > 0 {
+			var content []byte
+			if err := rlp.DecodeBytes(value, &content); err != nil {
+				return fmt.Errorf("decode storage %s failed, err: %v", key.Hex(), err)
+			}
+			proved.SetBytes(content)
+		}
+		if sp.Value == nil || sp.Value.ToInt().Cmp(proved) != 0 {
+			return fmt.Errorf("storage %s value mismatch, expect %s, proved %s", key.Hex(), sp.Value, (*hexutil.Big)(proved))
+		}
+	}
+	return nil
+}
+
+// verify returns the value of key proved by nodes, nil value means the key not exist.
+func verify(root common.Hash, key []byte, nodes []string) ([]byte, error) {
+	if root == types.EmptyRootHash && len(nodes) == 0 {
+		return nil, nil
+	}
+	db := memorydb.New()
+	for _, node := range nodes {
+		bz, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, fmt.Errorf("decode proof node %s failed, err: %v", node, err)
+		}
+		if err := db.Put(crypto.Keccak256(bz), bz); err != nil {
+			return nil, err
+		}
+	}
+	return trie.VerifyProof(root, key, db)
+}
@@ -0,0 +1,134 @@
+package proof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+// nodes collects the proof nodes in order of `trie.Prove`.
+type nodes []string
+
+func (n *nodes) Put(key, value []byte) error {
+	*n = append(*n, hexutil.Encode(value))
+	return nil
+}
+
+func (n *nodes) Delete(key []byte) error { return nil }
+
+func newTrie(t *testing.T) *trie.Trie {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.NoError(t, err)
+	return tr
+}
+
+func prove(t *testing.T, tr *trie.Trie, key []byte) []string {
+	list := new(nodes)
+	assert.NoError(t, tr.Prove(key, 0, list))
+	return *list
+}
+
+// mockProof build a state with eccd and another account, and prove the tx hash stored in eccd and an
+// absent key.
+func mockProof(t *testing.T) (common.Hash, *Proof) {
+	eccd := common.HexToAddress("0x0000000000000000000000000000000000001001")
+	txHashKey := ECCDTxHashKey([]byte{1})
+	indexKey := Slot(ECCDTxHashIndexSlot)
+	absentKey := ECCDTxHashKey([]byte{2})
+	paramHash := crypto.Keccak256Hash([]byte("cross chain param"))
+
+	storage := newTrie(t)
+	for key, value := range map[common.Hash][]byte{
+		txHashKey: paramHash.Bytes(),
+		indexKey:  {2},
+		Slot(0):   eccd.Bytes(),
+	} {
+		enc, err := rlp.EncodeToBytes(common.TrimLeftZeroes(value))
+		assert.NoError(t, err)
+		storage.Update(crypto.Keccak256(key.Bytes()), enc)
+	}
+
+	acc := &account{Nonce: 1, Balance: big.NewInt(100), Root: storage.Hash(), CodeHash: crypto.Keccak256([]byte("eccd"))}
+	other := &account{Nonce: 3, Balance: big.NewInt(7), Root: storage.Hash(), CodeHash: crypto.Keccak256(nil)}
+	state := newTrie(t)
+	for addr, data := range map[common.Address]*account{eccd: acc, common.HexToAddress("0x01"): other} {
+		enc, err := rlp.EncodeToBytes(data)
+		assert.NoError(t, err)
+		state.Update(crypto.Keccak256(addr.Bytes()), enc)
+	}
+
+	p := &Proof{
+		Address:      eccd,
+		Balance:      (*hexutil.Big)(acc.Balance),
+		CodeHash:     common.BytesToHash(acc.CodeHash),
+		Nonce:        hexutil.Uint64(acc.Nonce),
+		StorageHash:  acc.Root,
+		AccountProof: prove(t, state, crypto.Keccak256(eccd.Bytes())),
+	}
+	for key, value := range map[common.Hash]*big.Int{
+		txHashKey: paramHash.Big(),
+		indexKey:  big.NewInt(2),
+		absentKey: new(big.Int),
+	} {
+		p.StorageProof = append(p.StorageProof, StorageProof{
+			Key:   key.Hex(),
+			Value: (*hexutil.Big)(value),
+			Proof: prove(t, storage, crypto.Keccak256(key.Bytes())),
+		})
+	}
+	return state.Hash(), p
+}
+
+func TestECCDKeys(t *testing.T) {
+	// keccak256(uint256(0) . uint256(1))
+	assert.Equal(t, "0xa6eef7e35abe7026729641147f7915573c7e97b47efa546f5f6e3230263bcb49", ECCDTxHashKey([]byte{0}).Hex())
+	assert.Equal(t, ECCDTxHashKey(nil), ECCDTxHashKey([]byte{0}))
+
+	inner := crypto.Keccak256Hash(common.LeftPadBytes([]byte{2}, 32), common.LeftPadBytes([]byte{ECCDFromChainTxExistSlot}, 32))
+	hash := crypto.Keccak256Hash([]byte("poly tx"))
+	assert.Equal(t, crypto.Keccak256Hash(hash.Bytes(), inner.Bytes()), ECCDFromChainTxKey(2, hash.Bytes()))
+}
+
+func TestVerify(t *testing.T) {
+	root, p := mockProof(t)
+	assert.NoError(t, p.Verify(root))
+
+	value, ok := p.Storage(Slot(ECCDTxHashIndexSlot))
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), value.Int64())
+	_, ok = p.Storage(Slot(9))
+	assert.False(t, ok)
+
+	// wrong state root
+	assert.Error(t, p.Verify(common.HexToHash("0x1234")))
+
+	// account fields not match the proved account
+	root, p = mockProof(t)
+	p.Balance = (*hexutil.Big)(big.NewInt(101))
+	assert.Error(t, p.VerifyAccount(root))
+
+	root, p = mockProof(t)
+	p.StorageHash = common.HexToHash("0x1234")
+	assert.Error(t, p.VerifyAccount(root))
+	assert.Error(t, p.VerifyStorage())
+
+	// storage value not match the proved value
+	root, p = mockProof(t)
+	for i := range p.StorageProof {
+		p.StorageProof[i].Value = (*hexutil.Big)(big.NewInt(1))
+	}
+	assert.NoError(t, p.VerifyAccount(root))
+	assert.Error(t, p.VerifyStorage())
+
+	// tampered proof node
+	root, p = mockProof(t)
+	p.AccountProof[0] = hexutil.Encode(append(hexutil.MustDecode(p.AccountProof[0]), 0))
+	assert.Error(t, p.VerifyAccount(root))
+}
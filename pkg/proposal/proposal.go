@@ -0,0 +1,177 @@
+// Package proposal parses governance proposal events, tallies the stake of voters against the 2/3
+// threshold and predicts when a passed proposal takes effect.
+package proposal
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// proposal types of governance contract, the values of mint price and gas fee are in PLT and the
+// reward period is in blocks.
+const (
+	TypeMintPrice    uint8 = 1
+	TypeGasFee       uint8 = 2
+	TypeRewardPeriod uint8 = 3
+)
+
+var typeNames = map[uint8]string{
+	TypeMintPrice:    "mint-price",
+	TypeGasFee:       "gas-fee",
+	TypeRewardPeriod: "reward-period",
+}
+
+// TypeName returns the readable name of proposal type, and false if the type is not supported.
+func TypeName(typ uint8) (string, bool) {
+	name, ok := typeNames[typ]
+	return name, ok
+}
+
+// ParseType accept both of type number and type name, e.g: `3` or `reward-period`.
+func ParseType(s string) (uint8, error) {
+	for typ, name := range typeNames {
+		if s == name || s == fmt.Sprintf("%d", typ) {
+			return typ, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown proposal type %s", s)
+}
+
+// InPLT returns true if the proposal value is an amount of PLT.
+func InPLT(typ uint8) bool {
+	return typ == TypeMintPrice || typ == TypeGasFee
+}
+
+const (
+	StatusActive  = "active"
+	StatusPassed  = "passed"
+	StatusExpired = "expired"
+)
+
+// Status returns the state of proposal at height, the proposal could be voted before and at its end block.
+func Status(passed bool, endBlock, height uint64) string {
+	switch {
+	case passed:
+		return StatusPassed
+	case height > endBlock:
+		return StatusExpired
+	default:
+		return StatusActive
+	}
+}
+
+// Event is the log emitted by `propose`, the topics are event id, proposer, proposal id, proposal type
+// and end block.
+type Event struct {
+	ID       common.Address
+	Proposer common.Address
+	Type     uint8
+	EndBlock uint64
+	Height   uint64
+	TxHash   common.Hash
+}
+
+const eventTopics = 5
+
+// ParseEvent decode the proposal event, other logs of governance contract are rejected.
+func ParseEvent(l *types.Log) (*Event, error) {
+	if len(l.Topics) != eventTopics {
+		return nil, fmt.Errorf("log with %d topics is not proposal event", len(l.Topics))
+	}
+	endBlock := l.Topics[4].Big()
+	if !endBlock.IsUint64() {
+		return nil, fmt.Errorf("invalid proposal end block %s", endBlock)
+	}
+	typ := l.Topics[3].Big()
+	if !typ.IsUint64() || typ.Uint64() > 255 {
+		return nil, fmt.Errorf("invalid proposal type %s", typ)
+	}
+	return &Event{
+		Proposer: common.BytesToAddress(l.Topics[1].Bytes()),
+		ID:       common.BytesToAddress(l.Topics[2].Bytes()),
+		Type:     uint8(typ.Uint64()),
+		EndBlock: endBlock.Uint64(),
+		Height:   l.BlockNumber,
+		TxHash:   l.TxHash,
+	}, nil
+}
+
+// ParseEvents pick proposal events out of logs in chain order.
+func ParseEvents(logs []types.Log) []*Event {
+	list := make([]*Event, 0)
+	for i := range logs {
+		if evt, err := ParseEvent(&logs[i]); err == nil {
+			list = append(list, evt)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Height < list[j].Height })
+	return list
+}
+
+// Tally is the stake of voters compared to the total stake of effective validators.
+type Tally struct {
+	Voted *big.Int
+	Total *big.Int
+	// Voters are distinct validators in votes, Outsiders are the others which are not effective validators.
+	Voters    []common.Address
+	Outsiders []common.Address
+}
+
+// NewTally sum the stake of distinct voters, stakes contains the total stake amount of every effective validator.
+func NewTally(stakes map[common.Address]*big.Int, votes []common.Address) *Tally {
+	t := &Tally{Voted: new(big.Int), Total: new(big.Int)}
+	for _, stake := range stakes {
+		t.Total.Add(t.Total, stake)
+	}
+	seen := make(map[common.Address]bool)
+	for _, voter := range votes {
+		if seen[voter] {
+			continue
+		}
+		seen[voter] = true
+		stake, ok := stakes[voter]
+		if !ok {
+			t.Outsiders = append(t.Outsiders, voter)
+			continue
+		}
+		t.Voters = append(t.Voters, voter)
+		t.Voted.Add(t.Voted, stake)
+	}
+	return t
+}
+
+// Met returns true if the voted stake reaches 2/3 of total stake.
+func (t *Tally) Met() bool {
+	if t.Total.Sign() == 0 {
+		return false
+	}
+	return new(big.Int).Mul(t.Voted, big.NewInt(3)).Cmp(new(big.Int).Mul(t.Total, big.NewInt(2))) >= 0
+}
+
+// Lack returns the stake still required to reach the threshold, zero if it's met.
+func (t *Tally) Lack() *big.Int {
+	need := new(big.Int).Mul(t.Total, big.NewInt(2))
+	need.Add(need, big.NewInt(2)).Div(need, big.NewInt(3))
+	lack := need.Sub(need, t.Voted)
+	if lack.Sign() < 0 {
+		return new(big.Int)
+	}
+	return lack
+}
+
+func (t *Tally) String() string {
+	return fmt.Sprintf("voted %s/%s, %d voters, threshold met %v", t.Voted, t.Total, len(t.Voters), t.Met())
+}
+
+// EffectiveHeight predicts the block from which a proposal passed at `passHeight` takes effect. passed
+// proposals are applied with the next reward, which happens every `period` blocks since `lastReward`.
+func EffectiveHeight(passHeight, lastReward, period uint64) uint64 {
+	if period == 0 || passHeight < lastReward {
+		return lastReward
+	}
+	return lastReward + ((passHeight-lastReward)/period+1)*period
+}
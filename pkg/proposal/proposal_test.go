@@ -0,0 +1,118 @@
+package proposal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	val1 = common.HexToAddress("0x0000000000000000000000000000000000000a01")
+	val2 = common.HexToAddress("0x0000000000000000000000000000000000000a02")
+	val3 = common.HexToAddress("0x0000000000000000000000000000000000000a03")
+	user = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	id   = common.HexToAddress("0x0000000000000000000000000000000000000c01")
+)
+
+func proposeLog(height uint64, typ uint8, endBlock int64) types.Log {
+	return types.Log{
+		Topics: []common.Hash{
+			common.HexToHash("0x01"),
+			val1.Hash(),
+			id.Hash(),
+			common.BigToHash(big.NewInt(int64(typ))),
+			common.BigToHash(big.NewInt(endBlock)),
+		},
+		BlockNumber: height,
+		TxHash:      common.HexToHash("0x1234"),
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	l := proposeLog(10, TypeGasFee, 110)
+	evt, err := ParseEvent(&l)
+	assert.NoError(t, err)
+	assert.Equal(t, id, evt.ID)
+	assert.Equal(t, val1, evt.Proposer)
+	assert.Equal(t, TypeGasFee, evt.Type)
+	assert.Equal(t, uint64(110), evt.EndBlock)
+	assert.Equal(t, uint64(10), evt.Height)
+	assert.Equal(t, common.HexToHash("0x1234"), evt.TxHash)
+
+	vote := types.Log{Topics: []common.Hash{common.HexToHash("0x02"), val2.Hash(), id.Hash()}}
+	_, err = ParseEvent(&vote)
+	assert.Error(t, err)
+
+	invalid := proposeLog(10, 0, 110)
+	invalid.Topics[3] = common.BigToHash(big.NewInt(256))
+	_, err = ParseEvent(&invalid)
+	assert.Error(t, err)
+
+	list := ParseEvents([]types.Log{proposeLog(20, TypeMintPrice, 120), vote, proposeLog(10, TypeGasFee, 110)})
+	assert.Len(t, list, 2)
+	assert.Equal(t, uint64(10), list[0].Height)
+	assert.Equal(t, uint64(20), list[1].Height)
+}
+
+func TestType(t *testing.T) {
+	typ, err := ParseType("reward-period")
+	assert.NoError(t, err)
+	assert.Equal(t, TypeRewardPeriod, typ)
+	typ, err = ParseType("1")
+	assert.NoError(t, err)
+	assert.Equal(t, TypeMintPrice, typ)
+	_, err = ParseType("4")
+	assert.Error(t, err)
+
+	name, ok := TypeName(TypeGasFee)
+	assert.True(t, ok)
+	assert.Equal(t, "gas-fee", name)
+	assert.True(t, InPLT(TypeGasFee))
+	assert.False(t, InPLT(TypeRewardPeriod))
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, StatusActive, Status(false, 100, 100))
+	assert.Equal(t, StatusExpired, Status(false, 100, 101))
+	assert.Equal(t, StatusPassed, Status(true, 100, 101))
+}
+
+func TestTally(t *testing.T) {
+	stakes := map[common.Address]*big.Int{
+		val1: big.NewInt(100),
+		val2: big.NewInt(100),
+		val3: big.NewInt(100),
+	}
+
+	tally := NewTally(stakes, []common.Address{val1, val1, user})
+	assert.Equal(t, int64(100), tally.Voted.Int64())
+	assert.Equal(t, int64(300), tally.Total.Int64())
+	assert.Equal(t, []common.Address{val1}, tally.Voters)
+	assert.Equal(t, []common.Address{user}, tally.Outsiders)
+	assert.False(t, tally.Met())
+	assert.Equal(t, int64(100), tally.Lack().Int64())
+
+	// exactly 2/3
+	tally = NewTally(stakes, []common.Address{val1, val2})
+	assert.True(t, tally.Met())
+	assert.Equal(t, int64(0), tally.Lack().Int64())
+
+	// just below 2/3
+	stakes[val3] = big.NewInt(101)
+	tally = NewTally(stakes, []common.Address{val1, val2})
+	assert.False(t, tally.Met())
+	assert.Equal(t, int64(1), tally.Lack().Int64())
+
+	assert.False(t, NewTally(nil, []common.Address{val1}).Met())
+}
+
+func TestEffectiveHeight(t *testing.T) {
+	assert.Equal(t, uint64(105), EffectiveHeight(100, 100, 5))
+	assert.Equal(t, uint64(105), EffectiveHeight(104, 100, 5))
+	assert.Equal(t, uint64(110), EffectiveHeight(105, 100, 5))
+	assert.Equal(t, uint64(100), EffectiveHeight(90, 100, 5))
+	assert.Equal(t, uint64(100), EffectiveHeight(103, 100, 0))
+}
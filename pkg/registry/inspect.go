@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/pkg/signer"
+)
+
+// Backend is the chain reader used to inspect deployments, `ethclient.Client` satisfies it.
+type Backend interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// selector of `owner()`
+var ownerSelector = crypto.Keccak256([]byte("owner()"))[:4]
+
+// Inspect build record of contract deployed by tx, the deployer, block, code hash and owner are read
+// from chain. tx hash can be empty if the contract is not deployed by robot, e.g: native contracts.
+func Inspect(ctx context.Context, backend Backend, name string, addr common.Address, hash common.Hash) (*Record, error) {
+	rec := &Record{Name: name, Address: addr, TxHash: hash}
+
+	if hash != (common.Hash{}) {
+		receipt, err := backend.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("get receipt of %s err: %v", hash.Hex(), err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return nil, fmt.Errorf("deploy tx %s failed", hash.Hex())
+		}
+		if receipt.ContractAddress != (common.Address{}) && receipt.ContractAddress != addr {
+			return nil, fmt.Errorf("tx %s deployed %s, not %s", hash.Hex(), receipt.ContractAddress.Hex(), addr.Hex())
+		}
+		rec.Block = receipt.BlockNumber.Uint64()
+
+		tx, _, err := backend.TransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("get tx %s err: %v", hash.Hex(), err)
+		}
+		if rec.Deployer, err = signer.Sender(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	code, err := backend.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get code of %s err: %v", addr.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no code at %s", addr.Hex())
+	}
+	rec.CodeHash = crypto.Keccak256Hash(code)
+	rec.Owner = Owner(ctx, backend, addr)
+	return rec, nil
+}
+
+// Owner call `owner()` of Ownable contract, empty address is returned if the contract is not Ownable.
+func Owner(ctx context.Context, backend Backend, addr common.Address) common.Address {
+	bz, err := backend.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: ownerSelector}, nil)
+	if err != nil || len(bz) != common.HashLength {
+		return common.Address{}
+	}
+	return common.BytesToAddress(bz)
+}
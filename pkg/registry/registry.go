@@ -0,0 +1,338 @@
+// Package registry records deployed contracts per environment and chain, every change of a contract,
+// e.g: redeploy, ownership transfer and binding, is appended as a new version so that the history is
+// never lost. records are saved as json files: <dir>/<env>/<chain>.json
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Schema is the version of registry file format.
+const Schema = 1
+
+// Record is one version of a contract.
+type Record struct {
+	Version  int               // 合约记录版本, 从1开始递增
+	Name     string            // 合约名称, e.g: eccd, eccm
+	Address  common.Address    // 合约地址
+	TxHash   common.Hash       // 部署交易hash
+	Block    uint64            // 部署交易所在区块
+	CodeHash common.Hash       // 链上合约代码hash
+	Deployer common.Address    // 部署账户
+	Owner    common.Address    // 合约owner, 非Ownable合约为空
+	Bindings map[string]string // 合约绑定关系, e.g: eccd => 0x.., proxy@2 => 0x..
+	Note     string            // 该版本的变更说明, e.g: deploy, transfer ownership
+	Method   string            // 产生该版本的机器人方法
+	Time     time.Time
+}
+
+func (r *Record) copy() *Record {
+	cp := *r
+	cp.Bindings = make(map[string]string, len(r.Bindings))
+	for k, v := range r.Bindings {
+		cp.Bindings[k] = v
+	}
+	return &cp
+}
+
+type chainFile struct {
+	Schema    int
+	Env       string
+	Chain     string
+	Contracts map[string][]*Record // 合约名称 => 历史版本, 最后一个为当前版本
+}
+
+// Registry is the deployment records of one environment.
+type Registry struct {
+	dir    string
+	env    string
+	mu     sync.RWMutex
+	chains map[string]*chainFile
+}
+
+// Open load all of the chain files of env in dir, the directory is created if not exist.
+func Open(dir, env string) (*Registry, error) {
+	if env == "" {
+		return nil, fmt.Errorf("registry env should not be empty")
+	}
+	r := &Registry{dir: path.Join(dir, env), env: env, chains: make(map[string]*chainFile)}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	fs, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fs {
+		if f.IsDir() || path.Ext(f.Name()) != ".json" {
+			continue
+		}
+		bz, err := ioutil.ReadFile(path.Join(r.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cf := new(chainFile)
+		if err := json.Unmarshal(bz, cf); err != nil {
+			return nil, fmt.Errorf("registry file %s: %v", f.Name(), err)
+		}
+		if cf.Schema > Schema {
+			return nil, fmt.Errorf("registry file %s schema %d is newer than %d", f.Name(), cf.Schema, Schema)
+		}
+		r.chains[strings.TrimSuffix(f.Name(), ".json")] = cf
+	}
+	return r, nil
+}
+
+func (r *Registry) Env() string {
+	return r.env
+}
+
+func (r *Registry) Dir() string {
+	return r.dir
+}
+
+// Chains returns the sorted chain names.
+func (r *Registry) Chains() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]string, 0, len(r.chains))
+	for chain := range r.chains {
+		list = append(list, chain)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Names returns the sorted contract names of chain.
+func (r *Registry) Names(chain string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]string, 0)
+	if cf, ok := r.chains[chain]; ok {
+		for name := range cf.Contracts {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Current returns the latest version of contract.
+func (r *Registry) Current(chain, name string) (*Record, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := r.history(chain, name)
+	if len(list) == 0 {
+		return nil, false
+	}
+	return list[len(list)-1].copy(), true
+}
+
+// History returns all versions of contract, the oldest one comes first.
+func (r *Registry) History(chain, name string) []*Record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*Record, 0)
+	for _, rec := range r.history(chain, name) {
+		list = append(list, rec.copy())
+	}
+	return list
+}
+
+func (r *Registry) history(chain, name string) []*Record {
+	if cf, ok := r.chains[chain]; ok {
+		return cf.Contracts[name]
+	}
+	return nil
+}
+
+// Snapshot returns the current version of all contracts on chain, or the versions at time `at` if it's not zero.
+func (r *Registry) Snapshot(chain string, at time.Time) map[string]*Record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := make(map[string]*Record)
+	cf, ok := r.chains[chain]
+	if !ok {
+		return snapshot
+	}
+	for name, list := range cf.Contracts {
+		for i := len(list) - 1; i >= 0; i-- {
+			if at.IsZero() || !list[i].Time.After(at) {
+				snapshot[name] = list[i].copy()
+				break
+			}
+		}
+	}
+	return snapshot
+}
+
+// Add append a new deployment of contract, version and time are filled by registry.
+func (r *Registry) Add(chain string, rec *Record) (*Record, error) {
+	if rec.Name == "" {
+		return nil, fmt.Errorf("contract name should not be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec = rec.copy()
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if rec.Note == "" {
+		rec.Note = "deploy"
+	}
+	return rec, r.append(chain, rec)
+}
+
+// Update copy the current version of contract and append it as a new version after modified by
+// `fn`, nothing is appended if the fields are not changed.
+func (r *Registry) Update(chain, name, note, method string, fn func(rec *Record)) (*Record, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := r.history(chain, name)
+	if len(list) == 0 {
+		return nil, fmt.Errorf("contract %s not found on %s of %s", name, chain, r.env)
+	}
+	cur := list[len(list)-1]
+	rec := cur.copy()
+	fn(rec)
+	if len(Fields(cur, rec)) == 0 {
+		return rec, nil
+	}
+	rec.Note, rec.Method, rec.Time = note, method, time.Now()
+	return rec, r.append(chain, rec)
+}
+
+func (r *Registry) append(chain string, rec *Record) error {
+	cf, ok := r.chains[chain]
+	if !ok {
+		cf = &chainFile{Schema: Schema, Env: r.env, Chain: chain, Contracts: make(map[string][]*Record)}
+	}
+	list := cf.Contracts[rec.Name]
+	rec.Version = len(list) + 1
+
+	cf.Contracts[rec.Name] = append(list, rec)
+	if err := r.save(chain, cf); err != nil {
+		cf.Contracts[rec.Name] = list
+		return err
+	}
+	r.chains[chain] = cf
+	return nil
+}
+
+// save write file atomically, the old file is kept if anything goes wrong.
+func (r *Registry) save(chain string, cf *chainFile) error {
+	bz, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := path.Join(r.dir, chain+".json")
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Change is the difference of a contract between two snapshots.
+type Change struct {
+	Name   string
+	Fields []string
+	A, B   *Record
+}
+
+func (c *Change) String() string {
+	switch {
+	case c.A == nil:
+		return fmt.Sprintf("+ %s %s", c.Name, c.B.Address.Hex())
+	case c.B == nil:
+		return fmt.Sprintf("- %s %s", c.Name, c.A.Address.Hex())
+	}
+	list := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		list = append(list, fmt.Sprintf("%s: %v => %v", field, fieldValue(c.A, field), fieldValue(c.B, field)))
+	}
+	return fmt.Sprintf("~ %s %s", c.Name, strings.Join(list, ", "))
+}
+
+// Diff compare two snapshots, only the fields describing the contract on chain are compared, e.g:
+// address, code hash, owner and bindings, but not the version, method and time. fields can be
+// limited by `only`, e.g: compare address only.
+func Diff(a, b map[string]*Record, only ...string) []*Change {
+	names := make(map[string]struct{})
+	for name := range a {
+		names[name] = struct{}{}
+	}
+	for name := range b {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	list := make([]*Change, 0)
+	for _, name := range sorted {
+		ra, rb := a[name], b[name]
+		if ra == nil || rb == nil {
+			list = append(list, &Change{Name: name, A: ra, B: rb})
+			continue
+		}
+		if fields := Fields(ra, rb, only...); len(fields) > 0 {
+			list = append(list, &Change{Name: name, Fields: fields, A: ra, B: rb})
+		}
+	}
+	return list
+}
+
+var diffFields = []string{"Address", "TxHash", "Block", "CodeHash", "Deployer", "Owner", "Bindings"}
+
+// Fields returns the names of different fields.
+func Fields(a, b *Record, only ...string) []string {
+	fields := diffFields
+	if len(only) > 0 {
+		fields = only
+	}
+	list := make([]string, 0)
+	for _, field := range fields {
+		fa, fb := fieldValue(a, field), fieldValue(b, field)
+		if field == "Bindings" && len(a.Bindings) == 0 && len(b.Bindings) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(fa, fb) {
+			list = append(list, field)
+		}
+	}
+	return list
+}
+
+func fieldValue(r *Record, field string) interface{} {
+	v := reflect.ValueOf(r).Elem().FieldByName(field).Interface()
+	switch x := v.(type) {
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	}
+	return v
+}
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onrobot-registry")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, err := Open(dir, "local")
+	assert.NoError(t, err)
+
+	eccd := common.HexToAddress("0x01")
+	rec, err := r.Add("palette", &Record{Name: "eccd", Address: eccd, Method: "PLTDeployECCD"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rec.Version)
+	assert.Equal(t, "deploy", rec.Note)
+
+	_, err = r.Add("palette", &Record{Name: "eccm", Address: common.HexToAddress("0x02"), Bindings: map[string]string{"eccd": eccd.Hex()}})
+	assert.NoError(t, err)
+	before := time.Now()
+
+	// no change, no version
+	rec, err = r.Update("palette", "eccd", "transfer ownership", "test", func(rec *Record) {})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rec.Version)
+
+	time.Sleep(time.Millisecond)
+	rec, err = r.Update("palette", "eccd", "transfer ownership", "test", func(rec *Record) {
+		rec.Owner = common.HexToAddress("0x02")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rec.Version)
+
+	_, err = r.Update("palette", "ccmp", "bind", "test", func(rec *Record) {})
+	assert.Error(t, err)
+
+	// reload from files
+	r, err = Open(dir, "local")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"palette"}, r.Chains())
+	assert.Equal(t, []string{"eccd", "eccm"}, r.Names("palette"))
+	assert.Equal(t, 2, len(r.History("palette", "eccd")))
+	cur, ok := r.Current("palette", "eccd")
+	assert.True(t, ok)
+	assert.Equal(t, common.HexToAddress("0x02"), cur.Owner)
+
+	old := r.Snapshot("palette", before)
+	changes := Diff(old, r.Snapshot("palette", time.Time{}))
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, []string{"Owner"}, changes[0].Fields)
+
+	// another env
+	prod, err := Open(dir, "prod")
+	assert.NoError(t, err)
+	_, err = prod.Add("palette", &Record{Name: "eccd", Address: common.HexToAddress("0x03")})
+	assert.NoError(t, err)
+	changes = Diff(r.Snapshot("palette", time.Time{}), prod.Snapshot("palette", time.Time{}), "Address")
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "eccd", changes[0].Name)
+	assert.Equal(t, []string{"Address"}, changes[0].Fields)
+	assert.Nil(t, changes[1].B)
+}
+
+type testBackend struct {
+	tx      *types.Transaction
+	receipt *types.Receipt
+	code    map[common.Address][]byte
+	owner   common.Address
+}
+
+func (b *testBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if hash != b.tx.Hash() {
+		return nil, false, ethereum.NotFound
+	}
+	return b.tx, false, nil
+}
+
+func (b *testBackend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if hash != b.tx.Hash() {
+		return nil, ethereum.NotFound
+	}
+	return b.receipt, nil
+}
+
+func (b *testBackend) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.code[account], nil
+}
+
+func (b *testBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if b.owner == (common.Address{}) {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return common.LeftPadBytes(b.owner.Bytes(), 32), nil
+}
+
+func TestInspect(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+	tx, err := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 1e6, big.NewInt(1), []byte{0x60}), types.HomesteadSigner{}, key)
+	assert.NoError(t, err)
+
+	addr := crypto.CreateAddress(deployer, 0)
+	backend := &testBackend{
+		tx:      tx,
+		receipt: &types.Receipt{Status: types.ReceiptStatusSuccessful, ContractAddress: addr, BlockNumber: big.NewInt(10)},
+		code:    map[common.Address][]byte{addr: {0x60, 0x80}},
+		owner:   deployer,
+	}
+
+	rec, err := Inspect(context.Background(), backend, "eccd", addr, tx.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), rec.Block)
+	assert.Equal(t, deployer, rec.Deployer)
+	assert.Equal(t, deployer, rec.Owner)
+	assert.Equal(t, crypto.Keccak256Hash([]byte{0x60, 0x80}), rec.CodeHash)
+
+	_, err = Inspect(context.Background(), backend, "eccd", common.HexToAddress("0x01"), tx.Hash())
+	assert.Error(t, err)
+
+	backend.owner = common.Address{}
+	rec, err = Inspect(context.Background(), backend, "eccd", addr, common.Hash{})
+	assert.NoError(t, err)
+	assert.Equal(t, common.Address{}, rec.Owner)
+	assert.Equal(t, common.Address{}, rec.Deployer)
+}
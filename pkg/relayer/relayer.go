@@ -0,0 +1,423 @@
+// Package relayer is a minimal poly relayer for local cross chain testing. it watches `CrossChainEvent`
+// of side chain eccm, imports the tx with storage proof of eccd to poly, and then delivers the poly
+// `makeProof` tx to the target eccm by `verifyHeaderAndExecuteTx`. failed txs are retried in the next
+// round, the relayer never skips a tx silently.
+package relayer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/proof"
+)
+
+// DefaultMaxAttempts is the times a tx is retried before dropped.
+const DefaultMaxAttempts = 10
+
+// ProofKey returns the eccd storage key of cross chain tx id, it's the key of `eth_getProof`. the value
+// at the key is the hash of cross chain tx param, which is proved to poly.
+func ProofKey(txID []byte) string {
+	return proof.ECCDTxHashKey(txID).Hex()
+}
+
+// Event is the cross chain request emitted by source eccm.
+type Event struct {
+	TxHash    string
+	TxID      []byte // index of eccd tx hash map
+	ToChainID uint64
+	Rawdata   []byte // serialized MakeTxParam
+	Height    uint64
+}
+
+// PolyEvent is the `makeProof` notify of poly cross chain manager.
+type PolyEvent struct {
+	TxHash      string
+	Height      uint64
+	FromChainID uint64
+	ToChainID   uint64
+	TxID        string // hex of source cross chain tx id
+	Key         string // key of cross states proof
+}
+
+// Delivery is the params of eccm `verifyHeaderAndExecuteTx`.
+type Delivery struct {
+	Proof        []byte
+	RawHeader    []byte
+	HeaderProof  []byte
+	CurRawHeader []byte
+	HeaderSig    []byte
+	// PolyTxHash is the `fromChainTx` recorded in target eccd after executed.
+	PolyTxHash []byte
+}
+
+// Side is the side chain with eccd and eccm, e.g: palette, ethereum.
+type Side interface {
+	Name() string
+	SideChainID() uint64
+	Height() (uint64, error)
+	Events(start, end uint64) ([]*Event, error)
+	// Proof returns the json encoded `eth_getProof` result of eccd storage key at height.
+	Proof(key string, height uint64) ([]byte, error)
+	// Header returns the json encoded block header.
+	Header(height uint64) ([]byte, error)
+	// Executed check that the poly tx is executed on this chain already.
+	Executed(fromChainID uint64, polyTxHash []byte) (bool, error)
+	// EpochStartHeight returns the poly height of book keepers stored in eccd.
+	EpochStartHeight() (uint64, error)
+	Execute(d *Delivery) (string, error)
+}
+
+// Poly is the relay chain.
+type Poly interface {
+	Height() (uint64, error)
+	Events(start, end uint64) ([]*PolyEvent, error)
+	// SyncedHeight returns the latest side chain header synced to poly.
+	SyncedHeight(chainID uint64) (uint64, error)
+	SyncHeaders(chainID uint64, headers [][]byte) error
+	// Import call cross chain manager `importOuterTransfer`, header is empty if the side chain headers
+	// are synced to poly.
+	Import(chainID uint64, rawdata []byte, height uint64, proof, header []byte) (string, error)
+	// Delivery build the params of target eccm, `epochStart` is the poly height of book keepers in target eccd.
+	Delivery(event *PolyEvent, epochStart uint64) (*Delivery, error)
+}
+
+// Chain is the side chain joined in relaying.
+type Chain struct {
+	Side
+	// SyncHeader is true for side chains whose headers are synced to poly, e.g: ethereum registered with
+	// eth router. quorum side chains, e.g: palette, carry the header in `importOuterTransfer`.
+	SyncHeader bool
+	// Confirmations is the blocks to wait of side chain registered on poly, only for SyncHeader.
+	Confirmations uint64
+	// Start is the height to scan from, zero means the latest block.
+	Start uint64
+}
+
+type Options struct {
+	PolyStart       uint64 // poly height to scan from, zero means the latest block
+	MaxAttempts     int
+	HeaderBatchSize int // max headers synced in one poly tx
+}
+
+// Stats counts the relayed txs.
+type Stats struct {
+	Imported  int // source txs imported to poly
+	Delivered int // poly txs executed on target chain
+	Skipped   int // poly txs executed by other relayers
+	Dropped   int // txs dropped after max attempts
+	Pending   int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("imported %d, delivered %d, skipped %d, dropped %d, pending %d",
+		s.Imported, s.Delivered, s.Skipped, s.Dropped, s.Pending)
+}
+
+type pendingEvent struct {
+	*Event
+	source   uint64
+	attempts int
+}
+
+type pendingDelivery struct {
+	*PolyEvent
+	attempts int
+}
+
+type Relayer struct {
+	poly   Poly
+	chains map[uint64]*Chain
+	order  []uint64
+	opts   Options
+
+	next       map[uint64]uint64 // side chain id => next height to scan
+	polyNext   uint64
+	imports    []*pendingEvent
+	deliveries []*pendingDelivery
+	stats      Stats
+
+	// Logf is used to report relaying progress, nil means silent.
+	Logf func(format string, args ...interface{})
+}
+
+func New(poly Poly, opts Options, chains ...*Chain) (*Relayer, error) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.HeaderBatchSize <= 0 {
+		opts.HeaderBatchSize = 50
+	}
+	r := &Relayer{
+		poly:     poly,
+		chains:   make(map[uint64]*Chain),
+		opts:     opts,
+		next:     make(map[uint64]uint64),
+		polyNext: opts.PolyStart,
+	}
+	for _, chain := range chains {
+		id := chain.SideChainID()
+		if _, ok := r.chains[id]; ok {
+			return nil, fmt.Errorf("duplicate side chain %d", id)
+		}
+		r.chains[id] = chain
+		r.order = append(r.order, id)
+		r.next[id] = chain.Start
+	}
+	return r, nil
+}
+
+func (r *Relayer) Stats() Stats {
+	s := r.stats
+	s.Pending = len(r.imports) + len(r.deliveries)
+	return s
+}
+
+func (r *Relayer) logf(format string, args ...interface{}) {
+	if r.Logf != nil {
+		r.Logf(format, args...)
+	}
+}
+
+// Round relays once: scan side chains, import pending txs to poly, scan poly and deliver pending txs to
+// target chains. errors of one chain don't stop the others, the first error is returned.
+func (r *Relayer) Round() error {
+	var first error
+	record := func(err error) {
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	for _, id := range r.order {
+		chain := r.chains[id]
+		record(r.scanSide(chain))
+		if chain.SyncHeader {
+			record(r.syncHeaders(chain))
+		}
+	}
+	record(r.importPending())
+	record(r.scanPoly())
+	record(r.deliverPending())
+	return first
+}
+
+// Run relay every interval until stop closed.
+func (r *Relayer) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Round(); err != nil {
+			r.logf("relayer round err: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relayer) scanSide(chain *Chain) error {
+	id := chain.SideChainID()
+	latest, err := chain.Height()
+	if err != nil {
+		return fmt.Errorf("%s height: %v", chain.Name(), err)
+	}
+	next := r.next[id]
+	if next == 0 {
+		next = latest
+	}
+	if next > latest {
+		return nil
+	}
+	events, err := chain.Events(next, latest)
+	if err != nil {
+		return fmt.Errorf("%s events in [%d, %d]: %v", chain.Name(), next, latest, err)
+	}
+	for _, event := range events {
+		if _, ok := r.chains[event.ToChainID]; !ok {
+			r.logf("%s tx %s target chain %d not relayed, ignore", chain.Name(), event.TxHash, event.ToChainID)
+			continue
+		}
+		r.logf("%s cross chain tx %s to chain %d at height %d", chain.Name(), event.TxHash, event.ToChainID, event.Height)
+		r.imports = append(r.imports, &pendingEvent{Event: event, source: id})
+	}
+	r.next[id] = latest + 1
+	return nil
+}
+
+// syncHeaders sync side chain headers to poly in batches, up to the latest block.
+func (r *Relayer) syncHeaders(chain *Chain) error {
+	id := chain.SideChainID()
+	synced, err := r.poly.SyncedHeight(id)
+	if err != nil {
+		return fmt.Errorf("%s synced height: %v", chain.Name(), err)
+	}
+	latest, err := chain.Height()
+	if err != nil {
+		return fmt.Errorf("%s height: %v", chain.Name(), err)
+	}
+	for from := synced + 1; from <= latest; {
+		headers := make([][]byte, 0, r.opts.HeaderBatchSize)
+		for h := from; h <= latest && len(headers) < r.opts.HeaderBatchSize; h++ {
+			header, err := chain.Header(h)
+			if err != nil {
+				return fmt.Errorf("%s header %d: %v", chain.Name(), h, err)
+			}
+			headers = append(headers, header)
+		}
+		if err := r.poly.SyncHeaders(id, headers); err != nil {
+			return fmt.Errorf("sync %s headers [%d, %d] to poly: %v", chain.Name(), from, from+uint64(len(headers))-1, err)
+		}
+		from += uint64(len(headers))
+	}
+	return nil
+}
+
+func (r *Relayer) importPending() error {
+	var first error
+	remain := make([]*pendingEvent, 0, len(r.imports))
+	for _, event := range r.imports {
+		chain := r.chains[event.source]
+		done, err := r.importEvent(chain, event.Event)
+		if err == nil && !done {
+			remain = append(remain, event)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		event.attempts++
+		if event.attempts >= r.opts.MaxAttempts {
+			r.stats.Dropped++
+			r.logf("drop %s tx %s after %d attempts, err: %v", chain.Name(), event.TxHash, event.attempts, err)
+		} else {
+			remain = append(remain, event)
+		}
+		if first == nil {
+			first = fmt.Errorf("import %s tx %s: %v", chain.Name(), event.TxHash, err)
+		}
+	}
+	r.imports = remain
+	return first
+}
+
+// importEvent returns false if the tx is not ready for importing, e.g: headers not confirmed on poly.
+func (r *Relayer) importEvent(chain *Chain, event *Event) (bool, error) {
+	var header []byte
+	if chain.SyncHeader {
+		synced, err := r.poly.SyncedHeight(chain.SideChainID())
+		if err != nil {
+			return false, err
+		}
+		if synced+1 < event.Height+chain.Confirmations {
+			return false, nil
+		}
+	} else {
+		hdr, err := chain.Header(event.Height)
+		if err != nil {
+			return false, err
+		}
+		header = hdr
+	}
+	proof, err := chain.Proof(ProofKey(event.TxID), event.Height)
+	if err != nil {
+		return false, err
+	}
+	hash, err := r.poly.Import(chain.SideChainID(), event.Rawdata, event.Height, proof, header)
+	if err != nil {
+		// imported by other relayer or previous attempt
+		if strings.Contains(err.Error(), "check done transaction") {
+			r.logf("%s tx %s already imported to poly", chain.Name(), event.TxHash)
+			return true, nil
+		}
+		return false, err
+	}
+	r.stats.Imported++
+	r.logf("import %s tx %s to poly, poly tx %s", chain.Name(), event.TxHash, hash)
+	return true, nil
+}
+
+// scanPoly scan poly blocks up to the latest but one, because the next header is needed for delivery.
+func (r *Relayer) scanPoly() error {
+	latest, err := r.poly.Height()
+	if err != nil {
+		return fmt.Errorf("poly height: %v", err)
+	}
+	if latest == 0 {
+		return nil
+	}
+	end := latest - 1
+	next := r.polyNext
+	if next == 0 {
+		next = end
+	}
+	if next > end {
+		return nil
+	}
+	events, err := r.poly.Events(next, end)
+	if err != nil {
+		return fmt.Errorf("poly events in [%d, %d]: %v", next, end, err)
+	}
+	for _, event := range events {
+		if _, ok := r.chains[event.ToChainID]; !ok {
+			continue
+		}
+		r.logf("poly tx %s from chain %d to chain %d at height %d", event.TxHash, event.FromChainID, event.ToChainID, event.Height)
+		r.deliveries = append(r.deliveries, &pendingDelivery{PolyEvent: event})
+	}
+	r.polyNext = end + 1
+	return nil
+}
+
+func (r *Relayer) deliverPending() error {
+	var first error
+	remain := make([]*pendingDelivery, 0, len(r.deliveries))
+	for _, event := range r.deliveries {
+		chain := r.chains[event.ToChainID]
+		err := r.deliver(chain, event.PolyEvent)
+		if err == nil {
+			continue
+		}
+		event.attempts++
+		if event.attempts >= r.opts.MaxAttempts {
+			r.stats.Dropped++
+			r.logf("drop poly tx %s after %d attempts, err: %v", event.TxHash, event.attempts, err)
+		} else {
+			remain = append(remain, event)
+		}
+		if first == nil {
+			first = fmt.Errorf("deliver poly tx %s to %s: %v", event.TxHash, chain.Name(), err)
+		}
+	}
+	r.deliveries = remain
+	return first
+}
+
+func (r *Relayer) deliver(chain *Chain, event *PolyEvent) error {
+	epoch, err := chain.EpochStartHeight()
+	if err != nil {
+		return err
+	}
+	d, err := r.poly.Delivery(event, epoch)
+	if err != nil {
+		return err
+	}
+	executed, err := chain.Executed(event.FromChainID, d.PolyTxHash)
+	if err != nil {
+		return err
+	}
+	if executed {
+		r.stats.Skipped++
+		r.logf("poly tx %s already executed on %s", event.TxHash, chain.Name())
+		return nil
+	}
+	hash, err := chain.Execute(d)
+	if err != nil {
+		return err
+	}
+	r.stats.Delivered++
+	r.logf("deliver poly tx %s to %s, tx %s", event.TxHash, chain.Name(), hash)
+	return nil
+}
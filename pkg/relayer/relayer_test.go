@@ -0,0 +1,203 @@
+package relayer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSide struct {
+	name     string
+	id       uint64
+	height   uint64
+	events   []*Event
+	executed map[string]bool
+	failExec int // fail the next n executions
+	scanned  [][2]uint64
+}
+
+func (s *fakeSide) Name() string            { return s.name }
+func (s *fakeSide) SideChainID() uint64     { return s.id }
+func (s *fakeSide) Height() (uint64, error) { return s.height, nil }
+
+func (s *fakeSide) Events(start, end uint64) ([]*Event, error) {
+	s.scanned = append(s.scanned, [2]uint64{start, end})
+	list := make([]*Event, 0)
+	for _, event := range s.events {
+		if event.Height >= start && event.Height <= end {
+			list = append(list, event)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeSide) Proof(key string, height uint64) ([]byte, error) {
+	return []byte(key), nil
+}
+
+func (s *fakeSide) Header(height uint64) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s-%d", s.name, height)), nil
+}
+
+func (s *fakeSide) Executed(fromChainID uint64, polyTxHash []byte) (bool, error) {
+	return s.executed[string(polyTxHash)], nil
+}
+
+func (s *fakeSide) EpochStartHeight() (uint64, error) { return 0, nil }
+
+func (s *fakeSide) Execute(d *Delivery) (string, error) {
+	if s.failExec > 0 {
+		s.failExec--
+		return "", fmt.Errorf("execution reverted")
+	}
+	s.executed[string(d.PolyTxHash)] = true
+	return "exec-" + string(d.PolyTxHash), nil
+}
+
+type fakeImport struct {
+	chainID uint64
+	height  uint64
+	header  []byte
+}
+
+// fakePoly makes proof for every imported tx in the next block.
+type fakePoly struct {
+	height  uint64
+	synced  map[uint64]uint64
+	imports []*fakeImport
+	events  []*PolyEvent
+	done    map[string]bool
+}
+
+func (p *fakePoly) Height() (uint64, error) { return p.height, nil }
+
+func (p *fakePoly) Events(start, end uint64) ([]*PolyEvent, error) {
+	list := make([]*PolyEvent, 0)
+	for _, event := range p.events {
+		if event.Height >= start && event.Height <= end {
+			list = append(list, event)
+		}
+	}
+	return list, nil
+}
+
+func (p *fakePoly) SyncedHeight(chainID uint64) (uint64, error) { return p.synced[chainID], nil }
+
+func (p *fakePoly) SyncHeaders(chainID uint64, headers [][]byte) error {
+	p.synced[chainID] += uint64(len(headers))
+	return nil
+}
+
+func (p *fakePoly) Import(chainID uint64, rawdata []byte, height uint64, proof, header []byte) (string, error) {
+	key := fmt.Sprintf("%d-%s", chainID, rawdata)
+	if p.done[key] {
+		return "", fmt.Errorf("Quorum MakeDepositProposal, check done transaction error: tx already done")
+	}
+	p.done[key] = true
+	p.imports = append(p.imports, &fakeImport{chainID: chainID, height: height, header: header})
+	hash := fmt.Sprintf("poly-%s", rawdata)
+	p.events = append(p.events, &PolyEvent{TxHash: hash, Height: p.height + 1, FromChainID: chainID, ToChainID: toChainOf(rawdata), TxID: string(rawdata)})
+	return hash, nil
+}
+
+func (p *fakePoly) Delivery(event *PolyEvent, epochStart uint64) (*Delivery, error) {
+	return &Delivery{PolyTxHash: []byte(event.TxHash)}, nil
+}
+
+func toChainOf(rawdata []byte) uint64 {
+	var id uint64
+	fmt.Sscanf(string(rawdata), "to%d", &id)
+	return id
+}
+
+func TestProofKey(t *testing.T) {
+	// keccak256(uint256(0) . uint256(1)), the key of EthToPolyTxHashMap[0]
+	assert.Equal(t, "0xa6eef7e35abe7026729641147f7915573c7e97b47efa546f5f6e3230263bcb49", ProofKey([]byte{0}))
+	assert.Equal(t, ProofKey([]byte{0}), ProofKey(nil))
+}
+
+func TestRelay(t *testing.T) {
+	palette := &fakeSide{name: "palette", id: 101, height: 10, executed: make(map[string]bool)}
+	ethereum := &fakeSide{name: "ethereum", id: 2, height: 100, executed: make(map[string]bool)}
+	poly := &fakePoly{height: 1000, synced: map[uint64]uint64{2: 90}, done: make(map[string]bool)}
+
+	r, err := New(poly, Options{MaxAttempts: 2, HeaderBatchSize: 4},
+		&Chain{Side: palette},
+		&Chain{Side: ethereum, SyncHeader: true, Confirmations: 3},
+	)
+	assert.NoError(t, err)
+	_, err = New(poly, Options{}, &Chain{Side: palette}, &Chain{Side: palette})
+	assert.Error(t, err)
+
+	// first round starts from the latest blocks and syncs ethereum headers
+	assert.NoError(t, r.Round())
+	assert.Equal(t, [2]uint64{10, 10}, palette.scanned[0])
+	assert.Equal(t, uint64(100), poly.synced[2])
+
+	// palette => ethereum, header carried in tx
+	palette.height, ethereum.height = 12, 101
+	palette.events = append(palette.events, &Event{TxHash: "0xp1", TxID: []byte{1}, ToChainID: 2, Rawdata: []byte("to2-p1"), Height: 12})
+	// ethereum => palette, waits for confirmations
+	ethereum.events = append(ethereum.events, &Event{TxHash: "0xe1", TxID: []byte{1}, ToChainID: 101, Rawdata: []byte("to101-e1"), Height: 101})
+	// untracked target chain is ignored
+	palette.events = append(palette.events, &Event{TxHash: "0xp2", TxID: []byte{2}, ToChainID: 7, Rawdata: []byte("to7-p2"), Height: 12})
+
+	assert.NoError(t, r.Round())
+	assert.Equal(t, [2]uint64{11, 12}, palette.scanned[1])
+	assert.Equal(t, 1, len(poly.imports))
+	assert.Equal(t, []byte("palette-12"), poly.imports[0].header)
+	assert.Equal(t, 1, r.Stats().Imported)
+	assert.Equal(t, 1, r.Stats().Pending)
+
+	// ethereum tx confirmed after 2 more blocks, and the palette tx is delivered once the next poly
+	// header produced
+	ethereum.height = 103
+	poly.height += 2
+	ethereum.failExec = 1
+	err = r.Round()
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(poly.imports))
+	assert.Nil(t, poly.imports[1].header)
+	assert.Equal(t, uint64(101), poly.imports[1].height)
+	assert.Equal(t, 0, r.Stats().Delivered)
+
+	// failed delivery retried
+	poly.height += 2
+	assert.NoError(t, r.Round())
+	assert.True(t, ethereum.executed["poly-to2-p1"])
+	assert.True(t, palette.executed["poly-to101-e1"])
+	stats := r.Stats()
+	assert.Equal(t, 2, stats.Imported)
+	assert.Equal(t, 2, stats.Delivered)
+	assert.Equal(t, 0, stats.Pending)
+	t.Log(stats)
+}
+
+func TestRelayRetry(t *testing.T) {
+	palette := &fakeSide{name: "palette", id: 101, height: 10, executed: make(map[string]bool)}
+	ethereum := &fakeSide{name: "ethereum", id: 2, height: 100, executed: make(map[string]bool)}
+	poly := &fakePoly{height: 1000, synced: map[uint64]uint64{}, done: make(map[string]bool)}
+
+	r, err := New(poly, Options{MaxAttempts: 2, PolyStart: 990}, &Chain{Side: palette, Start: 5}, &Chain{Side: ethereum})
+	assert.NoError(t, err)
+
+	// imported by others before
+	poly.done["101-to2-p1"] = true
+	palette.events = []*Event{{TxHash: "0xp1", TxID: []byte{1}, ToChainID: 2, Rawdata: []byte("to2-p1"), Height: 6}}
+	// executed by others before
+	poly.events = []*PolyEvent{{TxHash: "poly-x", Height: 995, FromChainID: 101, ToChainID: 2}}
+	ethereum.executed["poly-x"] = true
+	// failed too many times
+	poly.events = append(poly.events, &PolyEvent{TxHash: "poly-y", Height: 996, FromChainID: 101, ToChainID: 2})
+	ethereum.failExec = 2
+
+	assert.Error(t, r.Round())
+	assert.Error(t, r.Round())
+	stats := r.Stats()
+	assert.Equal(t, 0, stats.Imported)
+	assert.Equal(t, 1, stats.Skipped)
+	assert.Equal(t, 1, stats.Dropped)
+	assert.Equal(t, 0, stats.Pending)
+	assert.Equal(t, [2]uint64{5, 10}, palette.scanned[0])
+}
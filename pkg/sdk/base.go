@@ -23,6 +23,9 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/nonce"
+	"github.com/palettechain/onRobot/pkg/proof"
+	"github.com/palettechain/onRobot/pkg/signer"
 )
 
 var (
@@ -101,6 +104,11 @@ func (c *Client) GetNonce(address string) uint64 {
 	return bigNonce.Uint64()
 }
 
+// PendingNonce returns the nonce including txs in the tx pool.
+func (c *Client) PendingNonce(addr common.Address) (uint64, error) {
+	return c.backend.PendingNonceAt(context.Background(), addr)
+}
+
 func (c *Client) GetCurrentBlockHeader() (uint64, *types.Header, error) {
 	curr := c.GetBlockNumber()
 	block, err := c.GetBlockByNumber(curr)
@@ -115,30 +123,53 @@ func (c *Client) GetCurrentBlockHeader() (uint64, *types.Header, error) {
 	return curr, hdr, nil
 }
 
+// SendTransaction allocate nonce from the process-wide nonce manager, so clients which share the same
+// key can send txs concurrently. the nonce is released if the tx failed to send, and resynced from the
+// tx pool once if the node complains `nonce too low`.
 func (c *Client) SendTransaction(contractAddr common.Address, payload []byte) (common.Hash, error) {
+	if c.Signer == nil {
+		return utils.EmptyHash, ErrReadOnly
+	}
 	addr := c.Address()
+	fetch := func() (uint64, error) { return c.PendingNonce(addr) }
 
-	nonce := c.GetNonce(addr.Hex())
-	if c.currentNonce < nonce {
-		c.currentNonce = nonce
-	}
-	log.Debugf("%s current nonce %d, valid nonce %d", addr.Hex(), c.currentNonce, nonce)
-	tx := types.NewTransaction(
-		c.currentNonce,
-		contractAddr,
-		big.NewInt(0),
-		gasLimit,
-		big.NewInt(gasPrice),
-		payload,
-	)
-	hash := tx.Hash()
-
-	signedTx, err := c.SignTransaction(tx)
-	if err != nil {
-		return hash, err
+	for resynced := false; ; resynced = true {
+		n, err := nonces.Acquire(addr, fetch)
+		if err != nil {
+			return utils.EmptyHash, fmt.Errorf("failed to get %s nonce: [%v]", addr.Hex(), err)
+		}
+		log.Debugf("%s nonce %d", addr.Hex(), n)
+		tx := types.NewTransaction(
+			n,
+			contractAddr,
+			big.NewInt(0),
+			gasLimit,
+			big.NewInt(gasPrice),
+			payload,
+		)
+		hash := tx.Hash()
+
+		signedTx, err := c.SignTransaction(tx)
+		if err != nil {
+			nonces.Release(addr, n)
+			return hash, err
+		}
+		hash, err = c.SendRawTransaction(hash, signedTx)
+		if err == nil {
+			return hash, nil
+		}
+		if !nonce.IsNonceTooLow(err) {
+			nonces.Release(addr, n)
+			return hash, err
+		}
+		if resynced {
+			return hash, err
+		}
+		log.Warnf("%s nonce %d too low, resync from tx pool", addr.Hex(), n)
+		if err := nonces.Resync(addr, fetch); err != nil {
+			return hash, err
+		}
 	}
-	c.currentNonce += 1
-	return c.SendRawTransaction(hash, signedTx)
 }
 
 func (c *Client) SendTransactionAndDumpEvent(contract common.Address, payload []byte) error {
@@ -173,13 +204,10 @@ func (c *Client) RepeatSendTransactionAndDumpEvent(contract common.Address, payl
 }
 
 func (c *Client) SignTransaction(tx *types.Transaction) (string, error) {
-
-	signer := types.HomesteadSigner{}
-	signedTx, err := types.SignTx(
-		tx,
-		signer,
-		c.Key,
-	)
+	if c.Signer == nil {
+		return "", ErrReadOnly
+	}
+	signedTx, err := c.Signer.SignTx(types.HomesteadSigner{}, tx)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign tx: [%v]", err)
 	}
@@ -201,7 +229,10 @@ func (c *Client) SendRawTransaction(hash common.Hash, signedTx string) (common.H
 }
 
 func (c *Client) DeployContract(abiStr, binStr string, params ...interface{}) (common.Address, *bind.BoundContract, error) {
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyAddress, nil, err
+	}
 
 	parsedABI, err := abi.JSON(strings.NewReader(abiStr))
 	if err != nil {
@@ -209,9 +240,8 @@ func (c *Client) DeployContract(abiStr, binStr string, params ...interface{}) (c
 		return utils.EmptyAddress, nil, err
 	}
 	parsedBin := common.FromHex(binStr)
-	backend := ethclient.NewClient(c.Client)
 
-	address, tx, contract, err := bind.DeployContract(auth, parsedABI, parsedBin, backend, params...)
+	address, tx, contract, err := bind.DeployContract(auth, parsedABI, parsedBin, c.sendBackend(), params...)
 	if err != nil {
 		return utils.EmptyAddress, nil, err
 	}
@@ -224,19 +254,95 @@ func (c *Client) DeployContract(abiStr, binStr string, params ...interface{}) (c
 	return address, contract, nil
 }
 
-func (c *Client) makeDeployAuth() *bind.TransactOpts {
-	auth := bind.NewKeyedTransactor(c.Key)
+func (c *Client) makeDeployAuth() (*bind.TransactOpts, error) {
+	auth, err := c.newTransactor()
+	if err != nil {
+		return nil, err
+	}
 	auth.GasLimit = 1e7
-	auth.Nonce = new(big.Int).SetUint64(c.GetNonce(c.Address().Hex()))
-	return auth
+	return auth, nil
 }
 
-func (c *Client) makeAuth() *bind.TransactOpts {
-	auth := bind.NewKeyedTransactor(c.Key)
+func (c *Client) makeAuth() (*bind.TransactOpts, error) {
+	auth, err := c.newTransactor()
+	if err != nil {
+		return nil, err
+	}
 	auth.GasLimit = 2100000
-	auth.Nonce = new(big.Int).SetUint64(c.GetNonce(c.Address().Hex()))
 	auth.Value = big.NewInt(0)
-	return auth
+	return auth, nil
+}
+
+// newTransactor allocate nonce from the nonce manager shared with `SendTransaction`, so that txs sent by
+// contract bindings and `SendTransaction` with the same key don't collide. the nonce is acquired lazily
+// in the signer callback, so that nothing is reserved if packing the input or estimating gas failed. the
+// bindings should be created with `sendBackend`, which releases the nonce if the tx failed to send.
+func (c *Client) newTransactor() (*bind.TransactOpts, error) {
+	if c.Signer == nil {
+		return nil, ErrReadOnly
+	}
+	price, err := c.backend.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: [%v]", err)
+	}
+
+	addr := c.Address()
+	auth := signer.NewTransactor(c.Signer)
+	// placeholder, stops the binding from querying pending nonce. the tx is rebuilt with the allocated
+	// nonce before signing.
+	auth.Nonce = new(big.Int)
+	auth.GasPrice = price
+	sign := auth.Signer
+	auth.Signer = func(s types.Signer, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		n, err := nonces.Acquire(addr, func() (uint64, error) { return c.PendingNonce(addr) })
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s nonce: [%v]", addr.Hex(), err)
+		}
+		log.Debugf("%s nonce %d", addr.Hex(), n)
+		signed, err := sign(s, from, withNonce(tx, n))
+		if err != nil {
+			nonces.Release(addr, n)
+		}
+		return signed, err
+	}
+	return auth, nil
+}
+
+// withNonce returns a copy of tx with the nonce replaced.
+func withNonce(tx *types.Transaction, n uint64) *types.Transaction {
+	if tx.To() == nil {
+		return types.NewContractCreation(n, tx.Value(), tx.Gas(), tx.GasPrice(), tx.Data())
+	}
+	return types.NewTransaction(n, *tx.To(), tx.Value(), tx.Gas(), tx.GasPrice(), tx.Data())
+}
+
+// sendBackend is used by contract bindings which send txs with `makeAuth` or `makeDeployAuth`.
+func (c *Client) sendBackend() bind.ContractBackend {
+	return &nonceBackend{ContractBackend: c.backend, client: c}
+}
+
+// nonceBackend release the nonce allocated by `newTransactor` if the tx failed to send, and resync the
+// nonce from tx pool if the node complains `nonce too low`.
+type nonceBackend struct {
+	bind.ContractBackend
+	client *Client
+}
+
+func (b *nonceBackend) SendTransaction(ctx context.Context, tx *types.Transaction, args bind.PrivateTxArgs) error {
+	err := b.ContractBackend.SendTransaction(ctx, tx, args)
+	if err == nil {
+		return nil
+	}
+	addr := b.client.Address()
+	if !nonce.IsNonceTooLow(err) {
+		nonces.Release(addr, tx.Nonce())
+		return err
+	}
+	log.Warnf("%s nonce %d too low, resync from tx pool", addr.Hex(), tx.Nonce())
+	if syncErr := nonces.Resync(addr, func() (uint64, error) { return b.client.PendingNonce(addr) }); syncErr != nil {
+		log.Errorf("failed to resync %s nonce, err: %v", addr.Hex(), syncErr)
+	}
+	return err
 }
 
 func (c *Client) getCallOpts() *bind.CallOpts {
@@ -284,46 +390,24 @@ func (c *Client) GetReceipt(hash common.Hash) (*types.Receipt, error) {
 	return raw, nil
 }
 
-type ProofRsp struct {
-	JsonRPC string       `json:"jsonrpc"`
-	Result  PaletteProof `json:"result,omitempty"`
-	Error   *JsonError   `json:"error,omitempty"`
-	Id      uint         `json:"id"`
-}
+// PaletteProof is the result of `eth_getProof`, which can be verified against the state root of block.
+type PaletteProof = proof.Proof
 
-type PaletteProof struct {
-	Address       string         `json:"address"`
-	Balance       string         `json:"balance"`
-	CodeHash      string         `json:"codeHash"`
-	Nonce         string         `json:"nonce"`
-	StorageHash   string         `json:"storageHash"`
-	AccountProof  []string       `json:"accountProof"`
-	StorageProofs []StorageProof `json:"StorageProof"`
-}
+type StorageProof = proof.StorageProof
 
-type StorageProof struct {
-	Key   string   `json:"key"`
-	Value string   `json:"value"`
-	Proof []string `json:"proof"`
-}
-
-type JsonError struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+// GetProof returns the storage proof of contract at block, the rpc client has already unwrapped the
+// json rpc response and returned the error in it.
+func (c *Client) GetProof(contractAddr common.Address, key string, blockNum string) (*PaletteProof, error) {
+	return c.GetProofs(contractAddr, []string{key}, blockNum)
 }
 
-func (c *Client) GetProof(contractAddr common.Address, key string, blockNum string) (*PaletteProof, error) {
-	res := new(ProofRsp)
-	if err := c.Call(res, "eth_getProof", contractAddr, []string{key}, blockNum); err != nil {
+// GetProofs returns the account proof and storage proofs of keys in one request.
+func (c *Client) GetProofs(contractAddr common.Address, keys []string, blockNum string) (*PaletteProof, error) {
+	res := new(PaletteProof)
+	if err := c.Call(res, "eth_getProof", contractAddr, keys, blockNum); err != nil {
 		return nil, err
 	}
-
-	if res.Error != nil {
-		return nil, fmt.Errorf(res.Error.Message)
-	}
-
-	return &res.Result, nil
+	return res, nil
 }
 
 func (c *Client) CallContract(caller, contractAddr common.Address, payload []byte, blockNum string) ([]byte, error) {
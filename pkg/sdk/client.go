@@ -2,28 +2,52 @@ package sdk
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/palettechain/onRobot/pkg/nonce"
+	"github.com/palettechain/onRobot/pkg/signer"
 )
 
 type Client struct {
 	*rpc.Client
-	backend      *ethclient.Client
-	url          string
-	Key          *ecdsa.PrivateKey
-	currentNonce uint64
+	backend *ethclient.Client
+	url     string
+	Signer  signer.Signer
 }
 
+// ErrReadOnly is returned while sending tx with client created by `NewReader`.
+var ErrReadOnly = errors.New("read-only client can not sign tx")
+
+// nonces is shared by all clients, accounts may be used by many clients at the same time.
+var nonces = nonce.NewManager()
+
+// NewSender create client signed by key, nil key means a read-only client, see `NewReader`.
 func NewSender(url string, key *ecdsa.PrivateKey) *Client {
+	s, err := signer.NewKeySigner(key)
+	if err != nil {
+		return NewReader(url)
+	}
+	return NewSignerSender(url, s)
+}
+
+// NewReader create client without signer, it's used to query chain state, e.g: block number. sending
+// txs with it returns `ErrReadOnly`.
+func NewReader(url string) *Client {
+	return NewSignerSender(url, nil)
+}
+
+// NewSignerSender create client with any kind of signer, e.g: remote signer which holds the key in another process.
+func NewSignerSender(url string, s signer.Signer) *Client {
 	cli := dialNode(url)
 	return &Client{
 		url:     url,
 		Client:  cli,
-		Key:     key,
+		Signer:  s,
 		backend: ethclient.NewClient(cli),
 	}
 }
@@ -32,12 +56,20 @@ func (c *Client) Url() string {
 	return c.url
 }
 
+// Address returns the signer address, or empty address for read-only client.
 func (c *Client) Address() common.Address {
-	return PubKey2Address(c.Key.PublicKey)
+	if c.Signer == nil {
+		return common.Address{}
+	}
+	return c.Signer.Address()
 }
 
+// Reset replace the signer with key, nil key makes the client read-only.
 func (c *Client) Reset(key *ecdsa.PrivateKey) *Client {
-	c.Key = key
+	c.Signer = nil
+	if s, err := signer.NewKeySigner(key); err == nil {
+		c.Signer = s
+	}
 	return c
 }
 
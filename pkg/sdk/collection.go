@@ -0,0 +1,206 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/native/nft"
+	"github.com/ethereum/go-ethereum/contracts/native/utils"
+	"github.com/palettechain/onRobot/pkg/collection"
+	"github.com/palettechain/onRobot/pkg/log"
+)
+
+// logs of at most `filterLogsRange` blocks are fetched in one request.
+const filterLogsRange = 5000
+
+// NFTBatchMint mint tokens to their owners, at most `batch` txs are sent before waiting for them.
+func (c *Client) NFTBatchMint(asset common.Address, tokens []*collection.Token, batch int) ([]common.Hash, error) {
+	return c.pipeline(len(tokens), batch, func(i int) (common.Hash, error) {
+		t := tokens[i]
+		return c.NFTMintWithoutWaiting(asset, t.Owner, t.ID, t.URI)
+	})
+}
+
+// NFTBatchTransfer transfer tokens of client to `to`.
+func (c *Client) NFTBatchTransfer(asset, to common.Address, tokenIDs []*big.Int, batch int) ([]common.Hash, error) {
+	from := c.Address()
+	return c.pipeline(len(tokenIDs), batch, func(i int) (common.Hash, error) {
+		payload, err := c.packNFT(nft.MethodTransferFrom, from, to, tokenIDs[i])
+		if err != nil {
+			return utils.EmptyHash, err
+		}
+		return c.SendTransaction(asset, payload)
+	})
+}
+
+func (c *Client) NFTBatchBurn(asset common.Address, tokenIDs []*big.Int, batch int) ([]common.Hash, error) {
+	return c.pipeline(len(tokenIDs), batch, func(i int) (common.Hash, error) {
+		payload, err := c.packNFT(nft.MethodBurn, tokenIDs[i])
+		if err != nil {
+			return utils.EmptyHash, err
+		}
+		return c.SendTransaction(asset, payload)
+	})
+}
+
+// pipeline send n txs without waiting, and wait for every `batch` txs. the hashes of sent txs are
+// returned even if some of them failed, and if sending fails the txs already sent in the batch are
+// waited for before returning.
+func (c *Client) pipeline(n, batch int, send func(i int) (common.Hash, error)) ([]common.Hash, error) {
+	if batch <= 0 {
+		batch = n
+	}
+	hashes := make([]common.Hash, 0, n)
+	for start := 0; start < n; start += batch {
+		end := start + batch
+		if end > n {
+			end = n
+		}
+		for i := start; i < end; i++ {
+			hash, err := send(i)
+			if err != nil {
+				// txs sent in this batch still hold pending nonces, wait for them before return.
+				for _, sent := range hashes[start:] {
+					if waitErr := c.WaitTransaction(sent); waitErr != nil {
+						log.Warnf("wait tx %s failed, err: %v", sent.Hex(), waitErr)
+					}
+				}
+				return hashes, fmt.Errorf("send tx %d failed, err: %v", i, err)
+			}
+			hashes = append(hashes, hash)
+		}
+		for _, hash := range hashes[start:end] {
+			if err := c.WaitTransaction(hash); err != nil {
+				return hashes, err
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// NFTTokenOfOwnerByIndex call the optional ERC721 enumeration extension.
+func (c *Client) NFTTokenOfOwnerByIndex(asset, owner common.Address, index *big.Int, blockNum string) (*big.Int, error) {
+	payload, err := collection.EnumerableABI.Pack("tokenOfOwnerByIndex", owner, index)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.callNFT(asset, payload, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return collection.UnpackTokenID(data)
+}
+
+// NFTTransferLogs returns the `Transfer` events of asset in blocks [start, end].
+func (c *Client) NFTTransferLogs(asset common.Address, start, end uint64) ([]*collection.Transfer, error) {
+	list := make([]*collection.Transfer, 0)
+	for from := start; from <= end; from += filterLogsRange {
+		to := from + filterLogsRange - 1
+		if to > end {
+			to = end
+		}
+		logs, err := c.backend.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{asset},
+			Topics:    [][]common.Hash{{collection.TransferEventID}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("filter transfer logs in blocks [%d, %d] failed, err: %v", from, to, err)
+		}
+		for i := range logs {
+			t, err := collection.ParseTransfer(&logs[i])
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+// NFTIndex replay the transfers of asset from block `start`, which should be no later than asset
+// deployment, to `height`.
+func (c *Client) NFTIndex(asset common.Address, start, height uint64) (*collection.Index, error) {
+	list, err := c.NFTTransferLogs(asset, start, height)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Replay(list), nil
+}
+
+// NFTTokensOf list tokens of owner at `height`, `tokenOfOwnerByIndex` is used if the asset supports it,
+// otherwise the transfer logs from block `start` are replayed.
+func (c *Client) NFTTokensOf(asset, owner common.Address, start, height uint64) ([]*big.Int, error) {
+	blockNum := hexutil.EncodeUint64(height)
+	balance, err := c.NFTBalance(asset, owner, blockNum)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*big.Int, 0, balance.Uint64())
+	for i := uint64(0); i < balance.Uint64(); i++ {
+		tokenID, err := c.NFTTokenOfOwnerByIndex(asset, owner, new(big.Int).SetUint64(i), blockNum)
+		if err != nil {
+			list = nil
+			break
+		}
+		list = append(list, tokenID)
+	}
+	if list != nil {
+		collection.SortIDs(list)
+		return list, nil
+	}
+
+	idx, err := c.NFTIndex(asset, start, height)
+	if err != nil {
+		return nil, err
+	}
+	list = idx.TokensOf(owner)
+	if uint64(len(list)) != balance.Uint64() {
+		return nil, fmt.Errorf("%d tokens found in transfer logs from block %d, balance %d", len(list), start, balance.Uint64())
+	}
+	return list, nil
+}
+
+// NFTSnapshot dumps owner, uri and approval of every token of asset at `height`, the tokens are found in
+// transfer logs from block `start` and checked against `totalSupply`.
+func (c *Client) NFTSnapshot(asset common.Address, start, height uint64) (*collection.Snapshot, error) {
+	blockNum := hexutil.EncodeUint64(height)
+	idx, err := c.NFTIndex(asset, start, height)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := c.NFTTotalSupply(asset, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if supply.Cmp(big.NewInt(int64(idx.Len()))) != 0 {
+		return nil, fmt.Errorf("%d tokens found in transfer logs from block %d, total supply %s", idx.Len(), start, supply)
+	}
+
+	snapshot := &collection.Snapshot{Asset: asset, Height: height, Tokens: make([]*collection.Token, 0, idx.Len())}
+	for _, tokenID := range idx.Tokens() {
+		owner, err := c.NFTTokenOwner(asset, tokenID, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("get owner of token %s failed, err: %v", tokenID, err)
+		}
+		if expect, _ := idx.Owner(tokenID); owner != expect {
+			return nil, fmt.Errorf("token %s owner %s, transfer logs %s", tokenID, owner.Hex(), expect.Hex())
+		}
+		uri, err := c.NFTTokenURI(asset, tokenID, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("get uri of token %s failed, err: %v", tokenID, err)
+		}
+		approved, err := c.NFTGetApproved(asset, tokenID, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("get approved of token %s failed, err: %v", tokenID, err)
+		}
+		snapshot.Tokens = append(snapshot.Tokens, &collection.Token{ID: tokenID, Owner: owner, URI: uri, Approved: approved})
+	}
+	return snapshot, nil
+}
@@ -13,49 +13,61 @@ import (
 	nftlp "github.com/polynetwork/nft-contracts/go_abi/nft_lock_proxy_abi"
 )
 
-func (c *Client) DeployECCD() (common.Address, error) {
-	auth := c.makeDeployAuth()
-	addr, tx, _, err := eccd_abi.DeployEthCrossChainData(auth, c.backend)
+func (c *Client) DeployECCD() (common.Address, common.Hash, error) {
+	auth, err := c.makeDeployAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
+	}
+	addr, tx, _, err := eccd_abi.DeployEthCrossChainData(auth, c.sendBackend())
+	if err != nil {
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return addr, nil
+	return addr, tx.Hash(), nil
 }
 
-func (c *Client) DeployECCM(eccd common.Address, sideChainID uint64, whiteList []common.Address, curBookeeperBytes []byte) (common.Address, error) {
-	auth := c.makeDeployAuth()
-	addr, tx, _, err := eccm_abi.DeployEthCrossChainManager(auth, c.backend, eccd, sideChainID, whiteList, curBookeeperBytes)
+func (c *Client) DeployECCM(eccd common.Address, sideChainID uint64, whiteList []common.Address, curBookeeperBytes []byte) (common.Address, common.Hash, error) {
+	auth, err := c.makeDeployAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
+	}
+	addr, tx, _, err := eccm_abi.DeployEthCrossChainManager(auth, c.sendBackend(), eccd, sideChainID, whiteList, curBookeeperBytes)
+	if err != nil {
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return addr, nil
+	return addr, tx.Hash(), nil
 }
 
-func (c *Client) DeployCCMP(eccm common.Address) (common.Address, error) {
-	auth := c.makeDeployAuth()
-	addr, tx, _, err := eccmp_abi.DeployEthCrossChainManagerProxy(auth, c.backend, eccm)
+func (c *Client) DeployCCMP(eccm common.Address) (common.Address, common.Hash, error) {
+	auth, err := c.makeDeployAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
+	}
+	addr, tx, _, err := eccmp_abi.DeployEthCrossChainManagerProxy(auth, c.sendBackend(), eccm)
+	if err != nil {
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return addr, nil
+	return addr, tx.Hash(), nil
 }
 
 func (c *Client) PauseCCMP(ccmpAddr common.Address) (common.Hash, error) {
-	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.backend)
+	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManagerProxy err: %s", err)
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := ccmp.PauseEthCrossChainManager(auth)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call ccmp pause err: %s", err)
@@ -68,12 +80,15 @@ func (c *Client) PauseCCMP(ccmpAddr common.Address) (common.Hash, error) {
 }
 
 func (c *Client) UnPauseCCMP(ccmpAddr common.Address) (common.Hash, error) {
-	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.backend)
+	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManagerProxy err: %s", err)
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := ccmp.UnpauseEthCrossChainManager(auth)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call ccmp unpause err: %s", err)
@@ -86,12 +101,15 @@ func (c *Client) UnPauseCCMP(ccmpAddr common.Address) (common.Hash, error) {
 }
 
 func (c *Client) UpgradeECCM(newEccmAddr, ccmpAddr common.Address) (common.Hash, error) {
-	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.backend)
+	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManagerProxy err: %s", err)
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := ccmp.UpgradeEthCrossChainManager(auth, newEccmAddr)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call upgradeEthCrossChainManager err: %s", err)
@@ -104,12 +122,15 @@ func (c *Client) UpgradeECCM(newEccmAddr, ccmpAddr common.Address) (common.Hash,
 }
 
 func (c *Client) ECCDTransferOwnerShip(eccdAddr, eccmAddr common.Address) (common.Hash, error) {
-	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, c.backend)
+	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainData err: %s", err)
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := eccd.TransferOwnership(auth, eccmAddr)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call transferOwnerShip err: %s", err)
@@ -128,13 +149,37 @@ func (c *Client) ECCDOwnership(eccdAddr common.Address) (common.Address, error)
 	return eccd.Owner(nil)
 }
 
+// CheckIfFromChainTxExist check that the poly tx has been executed by eccm.
+func (c *Client) CheckIfFromChainTxExist(eccdAddr common.Address, fromChainID uint64, polyTxHash []byte) (bool, error) {
+	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, c.backend)
+	if err != nil {
+		return false, err
+	}
+	var tx [32]byte
+	copy(tx[:], polyTxHash)
+	return eccd.CheckIfFromChainTxExist(nil, fromChainID, tx)
+}
+
+// GetCurEpochStartHeight returns the poly height since which the book keepers in eccd are valid.
+func (c *Client) GetCurEpochStartHeight(eccdAddr common.Address) (uint64, error) {
+	eccd, err := eccd_abi.NewEthCrossChainData(eccdAddr, c.backend)
+	if err != nil {
+		return 0, err
+	}
+	height, err := eccd.GetCurEpochStartHeight(nil)
+	return uint64(height), err
+}
+
 func (c *Client) ECCMTransferOwnerShip(eccmAddr, ccmpAddr common.Address) (common.Hash, error) {
-	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, c.backend)
+	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManager err: %s", err)
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := eccm.TransferOwnership(auth, ccmpAddr)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call transferOwnerShip err: %s", err)
@@ -154,12 +199,15 @@ func (c *Client) ECCMOwnership(eccmAddr common.Address) (common.Address, error)
 }
 
 func (c *Client) CCMPTransferOwnerShip(ccmpAddr, newOwner common.Address) (common.Hash, error) {
-	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.backend)
+	ccmp, err := eccmp_abi.NewEthCrossChainManagerProxy(ccmpAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := ccmp.TransferOwnership(auth, newOwner)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call transferOwnerShip err: %s", err)
@@ -321,12 +369,15 @@ func (c *Client) LockPLT(targetChainID uint64, dstAddr common.Address, amount *b
 }
 
 func (c *Client) SetNFTCCMP(proxyAddr, ccmp common.Address) (common.Hash, error) {
-	proxy, err := nftlp.NewPolyNFTLockProxy(proxyAddr, c.backend)
+	proxy, err := nftlp.NewPolyNFTLockProxy(proxyAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := proxy.SetManagerProxy(auth, ccmp)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -346,16 +397,19 @@ func (c *Client) GetNFTCCMP(proxyAddr common.Address) (common.Address, error) {
 	return proxy.ManagerProxyContract(nil)
 }
 
-func (c *Client) DeployNFTProxy() (common.Address, error) {
-	auth := c.makeDeployAuth()
-	addr, tx, _, err := nftlp.DeployPolyNFTLockProxy(auth, c.backend)
+func (c *Client) DeployNFTProxy() (common.Address, common.Hash, error) {
+	auth, err := c.makeDeployAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
+	}
+	addr, tx, _, err := nftlp.DeployPolyNFTLockProxy(auth, c.sendBackend())
+	if err != nil {
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return addr, nil
+	return addr, tx.Hash(), nil
 }
 
 func (c *Client) BindNFTProxy(
@@ -364,12 +418,15 @@ func (c *Client) BindNFTProxy(
 	targetSideChainID uint64,
 ) (common.Hash, error) {
 
-	proxy, err := nftlp.NewPolyNFTLockProxy(localLockProxy, c.backend)
+	proxy, err := nftlp.NewPolyNFTLockProxy(localLockProxy, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := proxy.BindProxyHash(auth, targetSideChainID, targetLockProxy.Bytes())
 	if err != nil {
 		return utils.EmptyHash, err
@@ -399,12 +456,15 @@ func (c *Client) GetBoundNFTProxy(
 }
 
 func (c *Client) TransferNFTProxyOwnership(proxyAddr, newOwner common.Address) (common.Hash, error) {
-	proxy, err := nftlp.NewPolyNFTLockProxy(proxyAddr, c.backend)
+	proxy, err := nftlp.NewPolyNFTLockProxy(proxyAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := proxy.TransferOwnership(auth, newOwner)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -430,12 +490,15 @@ func (c *Client) BindNFTAsset(
 	targetSideChainID uint64,
 ) (common.Hash, error) {
 
-	proxy, err := nftlp.NewPolyNFTLockProxy(localLockProxy, c.backend)
+	proxy, err := nftlp.NewPolyNFTLockProxy(localLockProxy, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeAuth()
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := proxy.BindAssetHash(auth, fromAsset, targetSideChainID, toAsset.Bytes())
 	if err != nil {
 		return utils.EmptyHash, err
@@ -459,13 +522,38 @@ func (c *Client) GetBoundNFTAsset(lockProxy, fromAsset common.Address, toChainID
 	return common.BytesToAddress(bz), nil
 }
 
+// VerifyHeaderAndExecuteTx relay the poly tx to palette eccm.
+func (c *Client) VerifyHeaderAndExecuteTx(eccmAddr common.Address, proof, rawHeader, headerProof, curRawHeader, headerSig []byte) (common.Hash, error) {
+	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, c.sendBackend())
+	if err != nil {
+		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManager err: %s", err)
+	}
+
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	tx, err := eccm.VerifyHeaderAndExecuteTx(auth, proof, rawHeader, headerProof, curRawHeader, headerSig)
+	if err != nil {
+		return utils.EmptyHash, fmt.Errorf("call eccm VerifyHeaderAndExecuteTx err: %s", err)
+	}
+
+	if err := c.WaitTransaction(tx.Hash()); err != nil {
+		return utils.EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
 func (c *Client) InitGenesisBlock(eccmAddr common.Address, rawHdr, publickeys []byte) (common.Hash, error) {
-	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, c.backend)
+	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("new EthCrossChainManager err: %s", err)
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := eccm.InitGenesisBlock(auth, rawHdr, publickeys)
 	if err != nil {
 		return utils.EmptyHash, fmt.Errorf("call eccm InitGenesisBlock err: %s", err)
@@ -9,8 +9,8 @@ import (
 	"github.com/ethereum/go-ethereum/contracts/native/nft"
 	"github.com/ethereum/go-ethereum/contracts/native/nftmanager"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
+	"github.com/palettechain/onRobot/pkg/codec"
 	"github.com/palettechain/onRobot/pkg/log"
-	polycm "github.com/polynetwork/poly/common"
 )
 
 func (c *Client) NFTDeploy(name string, symbol string) (common.Hash, common.Address, error) {
@@ -210,6 +210,15 @@ func (c *Client) NFTMint(asset common.Address, mintTo common.Address, tokenID *b
 	return c.sendNFT(asset, payload)
 }
 
+func (c *Client) NFTMintWithoutWaiting(asset common.Address, mintTo common.Address, tokenID *big.Int, uri string) (common.Hash, error) {
+	payload, err := c.packNFT(nft.MethodMint, mintTo, tokenID, uri)
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+
+	return c.SendTransaction(asset, payload)
+}
+
 func (c *Client) NFTBurn(asset common.Address, tokenID *big.Int) (common.Hash, error) {
 	payload, err := c.packNFT(nft.MethodBurn, tokenID)
 	if err != nil {
@@ -262,7 +271,7 @@ func (c *Client) NFTSafeTransferFrom(
 	toChainID uint64,
 ) (common.Hash, error) {
 
-	data := assembleSafeTransferCallData(to, toChainID)
+	data := (&codec.SafeTransferData{ToAddress: to.Bytes(), ToChainID: toChainID}).Serialization()
 	log.Infof("asset %s, from %s, proxy %s, tokenID %d, data %s",
 		asset.Hex(), from.Hex(), proxy.Hex(), tokenID.Uint64(), hexutil.Encode(data))
 
@@ -306,13 +315,6 @@ func (c *Client) NFTGetApproved(asset common.Address, tokenID *big.Int, blockNum
 	return result.Spender, nil
 }
 
-func assembleSafeTransferCallData(toAddress common.Address, chainID uint64) []byte {
-	sink := polycm.NewZeroCopySink(nil)
-	sink.WriteVarBytes(toAddress.Bytes())
-	sink.WriteUint64(chainID)
-	return sink.Bytes()
-}
-
 // NFT
 func (c *Client) packNFT(method string, args ...interface{}) ([]byte, error) {
 	return utils.PackMethod(NFTABI, method, args...)
@@ -1,14 +1,11 @@
 package sdk
 
 import (
-	"context"
 	"math/big"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native/plt"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
-	"github.com/palettechain/onRobot/pkg/log"
 )
 
 func (c *Client) BalanceOf(owner common.Address, blockNum string) (*big.Int, error) {
@@ -135,24 +132,16 @@ func (c *Client) PLTAllowance(owner, spender common.Address, blockNum string) (*
 	return output.Value, nil
 }
 
+// WaitTransaction wait the receipt and returns `RevertError` if the tx failed, use `IsRevert` to check it.
 func (self *Client) WaitTransaction(hash common.Hash) error {
-	for {
-		time.Sleep(time.Second * 1)
-		_, ispending, err := self.backend.TransactionByHash(context.Background(), hash)
-		if err != nil {
-			log.Errorf("failed to call TransactionByHash: %v", err)
-			continue
-		}
-		if ispending == true {
-			continue
-		}
-
-		if err := self.DumpEventLog(hash); err != nil {
-			return err
-		}
-		break
-	}
-	return nil
+	receipt, err := self.WaitReceipt(hash, pollOptions)
+	if err != nil {
+		return err
+	}
+	if err := self.checkReceipt(receipt); err != nil {
+		return err
+	}
+	return self.DumpEventLog(hash)
 }
 
 func (c *Client) packPLT(method string, args ...interface{}) ([]byte, error) {
@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palettechain/onRobot/pkg/proposal"
+)
+
+// ProposalEvents returns the proposals proposed in blocks [start, end].
+func (c *Client) ProposalEvents(start, end uint64) ([]*proposal.Event, error) {
+	list := make([]*proposal.Event, 0)
+	for from := start; from <= end; from += filterLogsRange {
+		to := from + filterLogsRange - 1
+		if to > end {
+			to = end
+		}
+		logs, err := c.backend.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{GovernanceAddress},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("filter governance logs in blocks [%d, %d] failed, err: %v", from, to, err)
+		}
+		list = append(list, proposal.ParseEvents(logs)...)
+	}
+	return list, nil
+}
+
+// ValidatorStakes returns the total stake amount of every effective validator.
+func (c *Client) ValidatorStakes(blockNum string) (map[common.Address]*big.Int, error) {
+	validators := c.GetEffectiveValidators(blockNum)
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("failed to get effective validators")
+	}
+	stakes := make(map[common.Address]*big.Int)
+	for _, validator := range validators {
+		amount := c.GetValidatorTotalStakeAmount(validator, blockNum)
+		if amount == nil {
+			return nil, fmt.Errorf("failed to get total stake amount of %s", validator.Hex())
+		}
+		stakes[validator] = amount
+	}
+	return stakes, nil
+}
+
+// ProposalTally sum the stake of voters and compare it with the 2/3 threshold.
+func (c *Client) ProposalTally(voters []common.Address, blockNum string) (*proposal.Tally, error) {
+	stakes, err := c.ValidatorStakes(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return proposal.NewTally(stakes, voters), nil
+}
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/palettechain/onRobot/pkg/log"
+	"github.com/palettechain/onRobot/pkg/signer"
+)
+
+// RevertError is returned by `WaitTransaction` and all send helpers when the tx is packed with
+// failed receipt status. `Reason` is decoded from the data returned by replaying the tx.
+type RevertError struct {
+	Hash        common.Hash
+	BlockNumber uint64
+	Reason      string
+	// Kind is one of `ReasonKindXXX`, empty means the reason can not be recovered.
+	Kind string
+	Data []byte
+	// NotReproduced is true if the replayed tx succeed, e.g: the tx ran out of gas or state changed
+	// by the txs before it in the same block.
+	NotReproduced bool
+}
+
+func (e *RevertError) Error() string {
+	msg := fmt.Sprintf("receipt failed %s", e.Hash.Hex())
+	if e.Reason != "" {
+		msg = fmt.Sprintf("%s, revert reason: %s", msg, e.Reason)
+	} else if e.NotReproduced {
+		msg = fmt.Sprintf("%s, failure not reproduced by replay, maybe out of gas", msg)
+	}
+	return msg
+}
+
+// IsRevert check that whether the err or one of its wrapped errors is a `RevertError`.
+func IsRevert(err error) (*RevertError, bool) {
+	var e *RevertError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsRevertWith check that the tx reverted and the reason contains `substr`.
+func IsRevertWith(err error, substr string) bool {
+	e, ok := IsRevert(err)
+	return ok && strings.Contains(e.Reason, substr)
+}
+
+const executionReverted = "execution reverted"
+
+// RevertReason returns the decoded revert reason in err, which is either a `RevertError` of packed tx,
+// or the rejection of gas estimation and call by node, e.g: `execution reverted: xxx`. it returns false
+// if the tx failed for other reasons such as insufficient funds or invalid nonce.
+func RevertReason(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	if e, ok := IsRevert(err); ok {
+		return e.Reason, e.Reason != ""
+	}
+	var de dataError
+	if errors.As(err, &de) {
+		if s, ok := de.ErrorData().(string); ok {
+			if data, decErr := hexutil.Decode(s); decErr == nil {
+				if reason, kind := DecodeRevertReason(data); kind != ReasonKindNone {
+					return reason, true
+				}
+			}
+		}
+	}
+	msg := err.Error()
+	idx := strings.Index(msg, executionReverted)
+	if idx < 0 {
+		return "", false
+	}
+	reason := strings.TrimSpace(strings.TrimPrefix(msg[idx+len(executionReverted):], ":"))
+	return reason, reason != ""
+}
+
+// dataError is implemented by rpc json errors which carry revert data.
+type dataError interface {
+	ErrorData() interface{}
+}
+
+// checkReceipt returns a `RevertError` if receipt status is failed.
+func (c *Client) checkReceipt(receipt *types.Receipt) error {
+	if receipt.Status != types.ReceiptStatusFailed {
+		return nil
+	}
+	e := &RevertError{Hash: receipt.TxHash}
+	if receipt.BlockNumber != nil {
+		e.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	data, reason, err := c.replayTransaction(receipt)
+	if err == errNotReproduced {
+		e.NotReproduced = true
+		return e
+	}
+	if err != nil {
+		log.Warnf("failed to replay tx %s, err: %v", receipt.TxHash.Hex(), err)
+		return e
+	}
+	e.Data = data
+	if e.Reason, e.Kind = DecodeRevertReason(data); e.Kind == ReasonKindNone && reason != "" {
+		e.Reason, e.Kind = reason, ReasonKindNative
+	}
+	return e
+}
+
+var errNotReproduced = errors.New("replayed tx succeed")
+
+// replayTransaction execute the tx again with `eth_call` on the state of previous block, and
+// returns the revert data carried by the call error, or the error message if node doesn't return
+// any data. it returns `errNotReproduced` if the call succeed.
+func (c *Client) replayTransaction(receipt *types.Receipt) ([]byte, string, error) {
+	tx, _, err := c.backend.TransactionByHash(context.Background(), receipt.TxHash)
+	if err != nil {
+		return nil, "", err
+	}
+	from, err := signer.Sender(tx)
+	if err != nil {
+		return nil, "", err
+	}
+	msg := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+	blockNum := "latest"
+	if receipt.BlockNumber != nil && receipt.BlockNumber.Uint64() > 0 {
+		blockNum = hexutil.EncodeUint64(receipt.BlockNumber.Uint64() - 1)
+	}
+
+	var res hexutil.Bytes
+	err = c.CallContext(context.Background(), &res, "eth_call", toCallArg(msg), blockNum)
+	if err == nil {
+		return nil, "", errNotReproduced
+	}
+	if de, ok := err.(dataError); ok {
+		if s, ok := de.ErrorData().(string); ok {
+			if data, decErr := hexutil.Decode(s); decErr == nil {
+				return data, err.Error(), nil
+			}
+		}
+	}
+	return nil, strings.TrimPrefix(err.Error(), executionReverted+": "), nil
+}
@@ -0,0 +1,85 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// keccak256("Error(string)")[:4]
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// keccak256("Panic(uint256)")[:4]
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+const (
+	ReasonKindNone     = ""
+	ReasonKindSolidity = "solidity"
+	ReasonKindPanic    = "panic"
+	ReasonKindNative   = "native"
+	ReasonKindRaw      = "raw"
+)
+
+// DecodeRevertReason decode revert data returned by evm. solidity `Error(string)` and `Panic(uint256)` are
+// abi encoded, and palette native contracts return the plain error message.
+func DecodeRevertReason(data []byte) (reason string, kind string) {
+	if len(data) == 0 {
+		return "", ReasonKindNone
+	}
+	if bytes.HasPrefix(data, errorSelector) {
+		if s, ok := unpackAbiString(data[4:]); ok {
+			return s, ReasonKindSolidity
+		}
+	}
+	if bytes.HasPrefix(data, panicSelector) && len(data) == 4+32 {
+		code := new(big.Int).SetBytes(data[4:])
+		return fmt.Sprintf("panic code 0x%x", code), ReasonKindPanic
+	}
+	if isPrintable(data) {
+		return string(data), ReasonKindNative
+	}
+	return fmt.Sprintf("0x%x", data), ReasonKindRaw
+}
+
+// unpackAbiString decode abi encoded single string argument: offset(32) + length(32) + content.
+func unpackAbiString(data []byte) (string, bool) {
+	if len(data) < 64 {
+		return "", false
+	}
+	// compare without additions, offset and size from remote data may overflow
+	offset, ok := readAbiUint(data[:32])
+	if !ok || offset > uint64(len(data))-32 {
+		return "", false
+	}
+	start := offset + 32
+	size, ok := readAbiUint(data[offset:start])
+	if !ok || size > uint64(len(data))-start {
+		return "", false
+	}
+	return string(data[start : start+size]), true
+}
+
+func readAbiUint(word []byte) (uint64, bool) {
+	for _, b := range word[:24] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	return binary.BigEndian.Uint64(word[24:]), true
+}
+
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
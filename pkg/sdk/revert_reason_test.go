@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	// revert("Ownable: caller is not the owner")
+	enc, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"4f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e6572")
+	reason, kind := DecodeRevertReason(enc)
+	assert.Equal(t, "Ownable: caller is not the owner", reason)
+	assert.Equal(t, ReasonKindSolidity, kind)
+
+	// assert(false)
+	enc, _ = hex.DecodeString("4e487b71" +
+		"0000000000000000000000000000000000000000000000000000000000000001")
+	reason, kind = DecodeRevertReason(enc)
+	assert.Equal(t, "panic code 0x1", reason)
+	assert.Equal(t, ReasonKindPanic, kind)
+
+	reason, kind = DecodeRevertReason([]byte("propose, proposer is not validator"))
+	assert.Equal(t, "propose, proposer is not validator", reason)
+	assert.Equal(t, ReasonKindNative, kind)
+
+	reason, kind = DecodeRevertReason([]byte{0x01, 0xff})
+	assert.Equal(t, "0x01ff", reason)
+	assert.Equal(t, ReasonKindRaw, kind)
+
+	_, kind = DecodeRevertReason(nil)
+	assert.Equal(t, ReasonKindNone, kind)
+
+	// broken offset should not panic
+	enc, _ = hex.DecodeString("08c379a0" +
+		"00000000000000000000000000000000000000000000000000000000000000ff" +
+		"0000000000000000000000000000000000000000000000000000000000000020")
+	_, kind = DecodeRevertReason(enc)
+	assert.Equal(t, ReasonKindRaw, kind)
+
+	// offset and size close to max uint64 should not overflow
+	enc, _ = hex.DecodeString("08c379a0" +
+		"000000000000000000000000000000000000000000000000fffffffffffffff0" +
+		"0000000000000000000000000000000000000000000000000000000000000020")
+	_, kind = DecodeRevertReason(enc)
+	assert.Equal(t, ReasonKindRaw, kind)
+
+	enc, _ = hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000fffffffffffffff0" +
+		"4f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e6572")
+	_, kind = DecodeRevertReason(enc)
+	assert.Equal(t, ReasonKindRaw, kind)
+}
+
+type rpcDataError struct {
+	msg  string
+	data interface{}
+}
+
+func (e *rpcDataError) Error() string          { return e.msg }
+func (e *rpcDataError) ErrorData() interface{} { return e.data }
+
+func TestRevertReason(t *testing.T) {
+	reason, ok := RevertReason(fmt.Errorf("wait tx failed: %w", &RevertError{Reason: "proposal passed"}))
+	assert.True(t, ok)
+	assert.Equal(t, "proposal passed", reason)
+
+	// packed but the reason can not be recovered
+	_, ok = RevertReason(&RevertError{})
+	assert.False(t, ok)
+
+	reason, ok = RevertReason(&rpcDataError{
+		msg: "execution reverted: Ownable: caller is not the owner",
+		data: "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"4f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e6572",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "Ownable: caller is not the owner", reason)
+
+	reason, ok = RevertReason(fmt.Errorf("failed to estimate gas needed: execution reverted: id already used"))
+	assert.True(t, ok)
+	assert.Equal(t, "id already used", reason)
+
+	_, ok = RevertReason(fmt.Errorf("execution reverted"))
+	assert.False(t, ok)
+	_, ok = RevertReason(fmt.Errorf("insufficient funds for gas * price + value"))
+	assert.False(t, ok)
+	_, ok = RevertReason(nil)
+	assert.False(t, ok)
+
+	// the tx succeed in replay, there is no revert reason
+	err := fmt.Errorf("wrapped: %w", &RevertError{NotReproduced: true})
+	_, ok = RevertReason(err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), "failure not reproduced by replay")
+}
@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/contracts/native/governance"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/palettechain/onRobot/pkg/poll"
+)
+
+var pollOptions = poll.Default
+
+// SetPollOptions set the default options used by `WaitTransaction` and other waiting functions.
+func SetPollOptions(opt poll.Options) {
+	pollOptions = opt
+}
+
+func PollOptions() poll.Options {
+	return pollOptions
+}
+
+// BlockNumber is the error returned version of `GetBlockNumber`.
+func (c *Client) BlockNumber() (uint64, error) {
+	var raw hexutil.Uint64
+	if err := c.CallContext(context.Background(), &raw, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(raw), nil
+}
+
+// WaitBlockHeight wait until the current block number reach `height`.
+func (c *Client) WaitBlockHeight(height uint64, opt poll.Options) error {
+	return poll.Until(fmt.Sprintf("block height %d", height), opt, func() (bool, error) {
+		cur, err := c.BlockNumber()
+		if err != nil {
+			return false, err
+		}
+		return cur >= height, nil
+	})
+}
+
+// WaitBlocks wait for `n` new blocks from now on.
+func (c *Client) WaitBlocks(n uint64, opt poll.Options) error {
+	cur, err := c.BlockNumber()
+	if err != nil {
+		return err
+	}
+	return c.WaitBlockHeight(cur+n, opt)
+}
+
+// WaitReceipt wait until the tx is packed and return the receipt.
+func (c *Client) WaitReceipt(hash common.Hash, opt poll.Options) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := poll.Until(fmt.Sprintf("receipt %s", hash.Hex()), opt, func() (bool, error) {
+		raw, err := c.backend.TransactionReceipt(context.Background(), hash)
+		if err != nil {
+			return false, err
+		}
+		receipt = raw
+		return true, nil
+	})
+	return receipt, err
+}
+
+// WaitBalanceDelta wait until PLT balance of `owner` equals to `before + delta`, delta may be negative.
+func (c *Client) WaitBalanceDelta(owner common.Address, before, delta *big.Int, opt poll.Options) error {
+	expect := new(big.Int).Add(before, delta)
+	desc := fmt.Sprintf("%s balance %s", owner.Hex(), expect)
+	return poll.Until(desc, opt, func() (bool, error) {
+		cur, err := c.BalanceOf(owner, "latest")
+		if err != nil {
+			return false, err
+		}
+		return cur.Cmp(expect) == 0, nil
+	})
+}
+
+// WaitEffectiveValidators wait until the effective validators satisfy `cond`.
+func (c *Client) WaitEffectiveValidators(desc string, cond func(list []common.Address) bool, opt poll.Options) error {
+	return poll.Until("validators "+desc, opt, func() (bool, error) {
+		list := c.GetEffectiveValidators("latest")
+		if list == nil {
+			return false, fmt.Errorf("failed to get effective validators")
+		}
+		return cond(list), nil
+	})
+}
+
+// WaitProposal wait until the proposal state satisfy `cond`, e.g: passed.
+func (c *Client) WaitProposal(
+	proposalID common.Address,
+	desc string,
+	cond func(proposal *governance.MethodGetProposalOutput) bool,
+	opt poll.Options,
+) (*governance.MethodGetProposalOutput, error) {
+	var proposal *governance.MethodGetProposalOutput
+	err := poll.Until(fmt.Sprintf("proposal %s %s", proposalID.Hex(), desc), opt, func() (bool, error) {
+		data, err := c.GetProposal(proposalID, "latest")
+		if err != nil {
+			return false, err
+		}
+		proposal = data
+		return cond(data), nil
+	})
+	return proposal, err
+}
@@ -7,33 +7,41 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/palettechain/onRobot/pkg/polywrap"
 	nftwp "github.com/polynetwork/nft-contracts/go_abi/nft_native_wrap_abi"
 	pltwp "github.com/polynetwork/nft-contracts/go_abi/plt_native_wrap_abi"
 )
 
-func (c *Client) DeployPLTWrapper(owner, lockProxy common.Address, chainId *big.Int) (common.Address, error) {
-	auth := c.makeDeployAuth()
-	addr, tx, _, err := pltwp.DeployPolyWrapper(auth, c.backend, owner, lockProxy, chainId)
+func (c *Client) DeployPLTWrapper(owner, lockProxy common.Address, chainId *big.Int) (common.Address, common.Hash, error) {
+	auth, err := c.makeDeployAuth()
 	if err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
+	}
+	addr, tx, _, err := pltwp.DeployPolyWrapper(auth, c.sendBackend(), owner, lockProxy, chainId)
+	if err != nil {
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
 	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return utils.EmptyAddress, err
+		return utils.EmptyAddress, utils.EmptyHash, err
 	}
-	return addr, nil
+	return addr, tx.Hash(), nil
 }
 
 func (c *Client) PLTWrapLock(wrapAddr, fromAsset, toAddr common.Address, toChainId uint64, amount, fee, id *big.Int) (common.Hash, error) {
-	wrapper, err := pltwp.NewPolyWrapper(wrapAddr, c.backend)
+	wrapper, err := pltwp.NewPolyWrapper(wrapAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := wrapper.Lock(auth, fromAsset, toChainId, toAddr.Bytes(), amount, fee, id)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -46,12 +54,15 @@ func (c *Client) PLTWrapLock(wrapAddr, fromAsset, toAddr common.Address, toChain
 }
 
 func (c *Client) NFTWrapLock(wrapAddr, fromAsset, toAddr, feeToken common.Address, toChainId uint64, tokenId, fee, id *big.Int) (common.Hash, error) {
-	wrapper, err := nftwp.NewPolyNativeNFTWrapper(wrapAddr, c.backend)
+	wrapper, err := nftwp.NewPolyNativeNFTWrapper(wrapAddr, c.sendBackend())
 	if err != nil {
 		return utils.EmptyHash, err
 	}
 
-	auth := c.makeDeployAuth()
+	auth, err := c.makeDeployAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
 	tx, err := wrapper.Lock(auth, fromAsset, toChainId, toAddr, tokenId, feeToken, fee, id)
 	if err != nil {
 		return utils.EmptyHash, err
@@ -63,6 +74,128 @@ func (c *Client) NFTWrapLock(wrapAddr, fromAsset, toAddr, feeToken common.Addres
 	return tx.Hash(), nil
 }
 
+// WrapperSetFeeCollector set the collector who can extract fee from wrapper, `kind` is `polywrap.KindPLT`
+// or `polywrap.KindNFT`, only wrapper owner can call it. the other admin operations are the same.
+func (c *Client) WrapperSetFeeCollector(kind string, wrapAddr, collector common.Address) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SetFeeCollector(auth, collector)
+	})
+}
+
+func (c *Client) WrapperSetLockProxy(kind string, wrapAddr, lockProxy common.Address) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SetLockProxy(auth, lockProxy)
+	})
+}
+
+func (c *Client) WrapperPause(kind string, wrapAddr common.Address) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Pause(auth)
+	})
+}
+
+func (c *Client) WrapperUnpause(kind string, wrapAddr common.Address) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Unpause(auth)
+	})
+}
+
+// WrapperExtractFee transfer the fee of token kept by wrapper to fee collector, the client should be collector.
+func (c *Client) WrapperExtractFee(kind string, wrapAddr, token common.Address) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.ExtractFee(auth, token)
+	})
+}
+
+// WrapperSpeedUp pay more fee for the lock tx, the fee token should be approved to wrapper before.
+func (c *Client) WrapperSpeedUp(kind string, wrapAddr, token common.Address, lockTx common.Hash, fee *big.Int) (common.Hash, error) {
+	return c.sendWrapperTx(kind, wrapAddr, func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error) {
+		return w.SpeedUp(auth, token, lockTx, fee)
+	})
+}
+
+func (c *Client) WrapperFeeCollector(kind string, wrapAddr common.Address) (common.Address, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return utils.EmptyAddress, err
+	}
+	return w.FeeCollector(c.getCallOpts())
+}
+
+func (c *Client) WrapperLockProxy(kind string, wrapAddr common.Address) (common.Address, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return utils.EmptyAddress, err
+	}
+	return w.LockProxy(c.getCallOpts())
+}
+
+func (c *Client) WrapperPaused(kind string, wrapAddr common.Address) (bool, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return false, err
+	}
+	return w.Paused(c.getCallOpts())
+}
+
+// WrapperLockEvents returns the `PolyWrapperLock` events in tx.
+func (c *Client) WrapperLockEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.LockEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return w.LockEvents(receipt)
+}
+
+// WrapperSpeedUpEvents returns the `PolyWrapperSpeedUp` events in tx.
+func (c *Client) WrapperSpeedUpEvents(kind string, wrapAddr common.Address, hash common.Hash) ([]*polywrap.SpeedUpEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := c.GetReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+	return w.SpeedUpEvents(receipt)
+}
+
+// FindWrapperLock search the lock event with `id` from block `start` to the latest block.
+func (c *Client) FindWrapperLock(kind string, wrapAddr common.Address, start uint64, id *big.Int) (*polywrap.LockEvent, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.backend)
+	if err != nil {
+		return nil, err
+	}
+	return w.FindLock(context.Background(), start, 0, id)
+}
+
+func (c *Client) sendWrapperTx(
+	kind string,
+	wrapAddr common.Address,
+	send func(w *polywrap.Wrapper, auth *bind.TransactOpts) (*types.Transaction, error),
+) (common.Hash, error) {
+	w, err := polywrap.New(kind, wrapAddr, c.sendBackend())
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	auth, err := c.makeAuth()
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	tx, err := send(w, auth)
+	if err != nil {
+		return utils.EmptyHash, err
+	}
+	if err := c.WaitTransaction(tx.Hash()); err != nil {
+		return utils.EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
 var (
 	abiLockEvent   abi.Event
 	abiUnLockEvent abi.Event
@@ -0,0 +1,196 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SendTxArgs is the argument of clef `account_signTransaction`.
+type SendTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to,omitempty"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice hexutil.Big     `json:"gasPrice"`
+	Value    hexutil.Big     `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// SignTxResult is the response of clef `account_signTransaction`.
+type SignTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func newSendTxArgs(from common.Address, tx *types.Transaction) *SendTxArgs {
+	data := hexutil.Bytes(tx.Data())
+	return &SendTxArgs{
+		From:     from,
+		To:       tx.To(),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Data:     &data,
+	}
+}
+
+func (args *SendTxArgs) toTransaction() *types.Transaction {
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	if args.To == nil {
+		return types.NewContractCreation(uint64(args.Nonce), (*big.Int)(&args.Value), uint64(args.Gas), (*big.Int)(&args.GasPrice), data)
+	}
+	return types.NewTransaction(uint64(args.Nonce), *args.To, (*big.Int)(&args.Value), uint64(args.Gas), (*big.Int)(&args.GasPrice), data)
+}
+
+// RemoteSigner sign txs with a clef-style signer over json-rpc, the private key never
+// enter the robot's process.
+type RemoteSigner struct {
+	client  *rpc.Client
+	url     string
+	address common.Address
+}
+
+func NewRemoteSigner(url string, address common.Address) (*RemoteSigner, error) {
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer %s: [%v]", url, err)
+	}
+	return &RemoteSigner{client: client, url: url, address: address}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RemoteSigner) Url() string {
+	return s.url
+}
+
+// Version returns the version of remote signer, it's used to check the signer is reachable.
+func (s *RemoteSigner) Version(ctx context.Context) (string, error) {
+	var version string
+	err := s.client.CallContext(ctx, &version, "account_version")
+	return version, err
+}
+
+// SignTx send the tx fields to remote signer and check the signed tx carefully, the remote
+// signer is allowed to choose the chain id, but not to modify anything else.
+func (s *RemoteSigner) SignTx(_ types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	res := new(SignTxResult)
+	if err := s.client.CallContext(context.Background(), res, "account_signTransaction", newSendTxArgs(s.address, tx)); err != nil {
+		return nil, fmt.Errorf("remote signer %s sign tx failed: [%v]", s.url, err)
+	}
+
+	signed := new(types.Transaction)
+	if err := rlp.DecodeBytes(res.Raw, signed); err != nil {
+		return nil, fmt.Errorf("failed to decode signed tx: [%v]", err)
+	}
+	if err := sameTransaction(tx, signed); err != nil {
+		return nil, err
+	}
+	from, err := Sender(signed)
+	if err != nil {
+		return nil, err
+	}
+	if from != s.address {
+		return nil, fmt.Errorf("%w, expect %s, got %s", ErrAddressMismatch, s.address.Hex(), from.Hex())
+	}
+	return signed, nil
+}
+
+func sameTransaction(expect, got *types.Transaction) error {
+	switch {
+	case expect.Nonce() != got.Nonce():
+		return fmt.Errorf("remote signer modified nonce %d => %d", expect.Nonce(), got.Nonce())
+	case expect.Gas() != got.Gas():
+		return fmt.Errorf("remote signer modified gas %d => %d", expect.Gas(), got.Gas())
+	case expect.GasPrice().Cmp(got.GasPrice()) != 0:
+		return fmt.Errorf("remote signer modified gas price %s => %s", expect.GasPrice(), got.GasPrice())
+	case expect.Value().Cmp(got.Value()) != 0:
+		return fmt.Errorf("remote signer modified value %s => %s", expect.Value(), got.Value())
+	case (expect.To() == nil) != (got.To() == nil), expect.To() != nil && *expect.To() != *got.To():
+		return fmt.Errorf("remote signer modified recipient")
+	case !bytes.Equal(expect.Data(), got.Data()):
+		return fmt.Errorf("remote signer modified data")
+	}
+	return nil
+}
+
+// Stub is a tiny clef-style signer which stands in for clef in local test environment,
+// it signs everything without confirmation. never expose it to public network.
+type Stub struct {
+	server *rpc.Server
+}
+
+type stubService struct {
+	signers map[common.Address]Signer
+	chainID *big.Int
+}
+
+// NewStub serve `account_list` and `account_signTransaction` with signers, the txs are signed
+// with eip155 if chainID is not nil, otherwise homestead.
+func NewStub(chainID *big.Int, signers ...Signer) (*Stub, error) {
+	svc := &stubService{signers: make(map[common.Address]Signer), chainID: chainID}
+	for _, s := range signers {
+		svc.signers[s.Address()] = s
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", svc); err != nil {
+		return nil, err
+	}
+	return &Stub{server: server}, nil
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.ServeHTTP(w, r)
+}
+
+func (s *Stub) Stop() {
+	s.server.Stop()
+}
+
+func (svc *stubService) Version() string {
+	return "onrobot-stub"
+}
+
+func (svc *stubService) List() []common.Address {
+	list := make([]common.Address, 0, len(svc.signers))
+	for addr := range svc.signers {
+		list = append(list, addr)
+	}
+	return list
+}
+
+func (svc *stubService) SignTransaction(args SendTxArgs, methodSelector *string) (*SignTxResult, error) {
+	s, ok := svc.signers[args.From]
+	if !ok {
+		return nil, fmt.Errorf("unknown account %s", args.From.Hex())
+	}
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if svc.chainID != nil {
+		signer = types.NewEIP155Signer(svc.chainID)
+	}
+	tx, err := s.SignTx(signer, args.toTransaction())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SignTxResult{Raw: raw, Tx: tx}, nil
+}
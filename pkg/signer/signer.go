@@ -0,0 +1,120 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palettechain/onRobot/pkg/files"
+	"github.com/palettechain/onRobot/pkg/hdwallet"
+)
+
+// Signer sign txs for one account, the private key may be held in memory, or by another process.
+type Signer interface {
+	Address() common.Address
+	// SignTx sign tx with the given signer, e.g: `types.HomesteadSigner{}`, remote signer may
+	// ignore it and sign with the chain id which it's configured with.
+	SignTx(signer types.Signer, tx *types.Transaction) (*types.Transaction, error)
+}
+
+var (
+	ErrAddressMismatch = errors.New("signer address mismatch")
+	ErrNilKey          = errors.New("private key is nil")
+)
+
+// NewTransactor is the same as `bind.NewKeyedTransactor` except that the private key is hidden by signer.
+func NewTransactor(s Signer) *bind.TransactOpts {
+	from := s.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, ErrAddressMismatch
+			}
+			return s.SignTx(signer, tx)
+		},
+	}
+}
+
+// Sender recover tx sender, both eip155 and homestead txs are supported.
+func Sender(tx *types.Transaction) (common.Address, error) {
+	if tx.Protected() {
+		return types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	}
+	return types.Sender(types.HomesteadSigner{}, tx)
+}
+
+// KeySigner holds the plaintext private key in memory.
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner returns `ErrNilKey` if key is nil, e.g: the account is not found in keystore.
+func NewKeySigner(key *ecdsa.PrivateKey) (*KeySigner, error) {
+	if key == nil {
+		return nil, ErrNilKey
+	}
+	return &KeySigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KeySigner) SignTx(signer types.Signer, tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, signer, s.key)
+}
+
+// Key returns the private key, it's used by test cases which need raw key, e.g: poly relayer.
+func (s *KeySigner) Key() *ecdsa.PrivateKey {
+	return s.key
+}
+
+// KeystoreSigner decrypt the keystore file once while creating.
+type KeystoreSigner struct {
+	*KeySigner
+	File string
+}
+
+func NewKeystoreSigner(file, passphrase string) (*KeystoreSigner, error) {
+	enc, err := files.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(enc, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: [%v]", file, err)
+	}
+	s, err := NewKeySigner(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeystoreSigner{KeySigner: s, File: file}, nil
+}
+
+// HDSigner derive the private key from the robot's hd wallet, see `hdwallet.Init`.
+type HDSigner struct {
+	*KeySigner
+	Path string
+}
+
+func NewHDSigner(path string) (*HDSigner, error) {
+	_, key, err := hdwallet.DerivePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive %s: [%v]", path, err)
+	}
+	s, err := NewKeySigner(key)
+	if err != nil {
+		return nil, err
+	}
+	return &HDSigner{KeySigner: s, Path: path}, nil
+}
@@ -0,0 +1,71 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTx() *types.Transaction {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000103")
+	return types.NewTransaction(3, to, big.NewInt(1), 21000, big.NewInt(0), []byte{0x01, 0x02})
+}
+
+func TestKeySigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	s, err := NewKeySigner(key)
+	assert.NoError(t, err)
+
+	tx, err := s.SignTx(types.HomesteadSigner{}, newTestTx())
+	assert.NoError(t, err)
+	from, err := Sender(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Address(), from)
+
+	opts := NewTransactor(s)
+	_, err = opts.Signer(types.HomesteadSigner{}, common.Address{}, newTestTx())
+	assert.Equal(t, ErrAddressMismatch, err)
+
+	_, err = NewKeySigner(nil)
+	assert.Equal(t, ErrNilKey, err)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	local, err := NewKeySigner(key)
+	assert.NoError(t, err)
+
+	chainID := big.NewInt(101)
+	stub, err := NewStub(chainID, local)
+	assert.NoError(t, err)
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+	defer stub.Stop()
+
+	remote, err := NewRemoteSigner(srv.URL, local.Address())
+	assert.NoError(t, err)
+	version, err := remote.Version(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "onrobot-stub", version)
+	tx, err := remote.SignTx(types.HomesteadSigner{}, newTestTx())
+	assert.NoError(t, err)
+	assert.True(t, tx.Protected())
+	assert.Equal(t, chainID, tx.ChainId())
+	from, err := Sender(tx)
+	assert.NoError(t, err)
+	assert.Equal(t, local.Address(), from)
+
+	// unknown account
+	other, err := NewRemoteSigner(srv.URL, common.HexToAddress("0x01"))
+	assert.NoError(t, err)
+	_, err = other.SignTx(types.HomesteadSigner{}, newTestTx())
+	assert.Error(t, err)
+}
@@ -0,0 +1,315 @@
+// Package tracker follows a cross chain transaction through its hops: the `CrossChainEvent` emitted
+// by eccm on the source chain, the `makeProof` event of poly cross chain manager and the
+// `VerifyHeaderAndExecuteTx` on the target chain. the three hops are linked by the cross chain tx id
+// which is emitted by the source eccm and carried as `fromChainTxHash` by poly and the target eccm.
+package tracker
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/poll"
+)
+
+const (
+	HopSource = "source"
+	HopPoly   = "poly"
+	HopTarget = "target"
+)
+
+// DefaultWindow is the number of blocks scanned back from the latest block if the start height unknown.
+const DefaultWindow uint64 = 1000
+
+// CrossChainEvent is the cross chain request emitted by eccm of source chain.
+type CrossChainEvent struct {
+	TxHash    string
+	TxID      []byte
+	ToChainID uint64
+	Height    uint64
+}
+
+// Record is the tx found on poly or target chain.
+type Record struct {
+	TxHash string
+	Height uint64
+}
+
+// Chain is the side chain with an eccm contract, e.g: palette, ethereum.
+type Chain interface {
+	Name() string
+	SideChainID() uint64
+	Height() (uint64, error)
+	BlockTime(height uint64) (time.Time, error)
+	// CrossChainEvent decode the eccm event in tx receipt.
+	CrossChainEvent(hash string) (*CrossChainEvent, error)
+	// FindExecution scan blocks in [start, end] for the tx which executed the cross chain tx id,
+	// nil returned if not found.
+	FindExecution(txID []byte, start, end uint64) (*Record, error)
+}
+
+// Relay is the poly chain.
+type Relay interface {
+	Height() (uint64, error)
+	BlockTime(height uint64) (time.Time, error)
+	// FindProof scan blocks in [start, end] for the `makeProof` event of cross chain tx id, nil
+	// returned if not found.
+	FindProof(fromChainID uint64, txID []byte, start, end uint64) (*Record, error)
+}
+
+type Hop struct {
+	Name   string // source, poly or target
+	Chain  string
+	TxHash string
+	Height uint64
+	Time   time.Time
+	Found  bool
+	Err    error // the last error while searching this hop
+}
+
+func (h *Hop) String() string {
+	if !h.Found {
+		s := fmt.Sprintf("%-6s %-8s not found", h.Name, h.Chain)
+		if h.Err != nil {
+			s += fmt.Sprintf(", err: %v", h.Err)
+		}
+		return s
+	}
+	return fmt.Sprintf("%-6s %-8s %s height %d at %s", h.Name, h.Chain, h.TxHash, h.Height, h.Time.Format(time.RFC3339))
+}
+
+// Trace is the result of tracking, hops are always listed in order of source, poly and target.
+type Trace struct {
+	Hash        string // source tx hash
+	TxID        []byte // cross chain tx id
+	FromChainID uint64
+	ToChainID   uint64
+	Hops        []*Hop
+}
+
+func (t *Trace) Completed() bool {
+	return t.StoppedAt() == nil
+}
+
+// StoppedAt returns the first hop not found, nil if the transfer arrived.
+func (t *Trace) StoppedAt() *Hop {
+	for _, hop := range t.Hops {
+		if !hop.Found {
+			return hop
+		}
+	}
+	return nil
+}
+
+// Hop returns the hop with name.
+func (t *Trace) Hop(name string) *Hop {
+	for _, hop := range t.Hops {
+		if hop.Name == name {
+			return hop
+		}
+	}
+	return nil
+}
+
+// Duration returns the time from source tx to the last found hop.
+func (t *Trace) Duration() time.Duration {
+	var last *Hop
+	for _, hop := range t.Hops {
+		if hop.Found {
+			last = hop
+		}
+	}
+	if last == nil {
+		return 0
+	}
+	return last.Time.Sub(t.Hops[0].Time)
+}
+
+func (t *Trace) String() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "cross chain tx %s, chain %d => %d, tx id %s\n", t.Hash, t.FromChainID, t.ToChainID, hex.EncodeToString(t.TxID))
+	for _, hop := range t.Hops {
+		b.WriteString("  ")
+		b.WriteString(hop.String())
+		b.WriteString("\n")
+	}
+	if hop := t.StoppedAt(); hop != nil {
+		fmt.Fprintf(b, "stopped at %s on %s", hop.Name, hop.Chain)
+	} else {
+		fmt.Fprintf(b, "completed in %s", t.Duration())
+	}
+	return b.String()
+}
+
+// Start is the height to scan from, zero means `latest - Window`. it's recommended to record the
+// heights before sending source tx.
+type Start struct {
+	Poly   uint64
+	Target uint64
+}
+
+type Tracker struct {
+	relay  Relay
+	chains map[uint64]Chain
+	Window uint64
+}
+
+func New(relay Relay, chains ...Chain) *Tracker {
+	t := &Tracker{relay: relay, chains: make(map[uint64]Chain), Window: DefaultWindow}
+	for _, chain := range chains {
+		t.chains[chain.SideChainID()] = chain
+	}
+	return t
+}
+
+// Start record the latest heights of poly and target chain, call it before sending the source tx so
+// that only blocks after it are scanned.
+func (t *Tracker) Start(toChainID uint64) (Start, error) {
+	dst, ok := t.chains[toChainID]
+	if !ok {
+		return Start{}, fmt.Errorf("target chain %d not tracked", toChainID)
+	}
+	polyHeight, err := t.relay.Height()
+	if err != nil {
+		return Start{}, err
+	}
+	targetHeight, err := dst.Height()
+	if err != nil {
+		return Start{}, err
+	}
+	return Start{Poly: polyHeight, Target: targetHeight}, nil
+}
+
+// Track search all hops once. a hop not found yet is not an error, the trace is returned together
+// with the error of searching so that caller knows which hop failed.
+func (t *Tracker) Track(fromChainID uint64, hash string, start Start) (*Trace, error) {
+	s, err := t.session(fromChainID, hash, start)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.step(); err != nil {
+		return s.trace, err
+	}
+	return s.trace, nil
+}
+
+// Wait track the tx until completed or timeout, blocks are scanned incrementally between attempts.
+// the trace is returned even if timeout, so that caller knows where the transfer stopped.
+func (t *Tracker) Wait(fromChainID uint64, hash string, start Start, opt poll.Options) (*Trace, error) {
+	s, err := t.session(fromChainID, hash, start)
+	if err != nil {
+		return nil, err
+	}
+	err = poll.Until(fmt.Sprintf("cross chain tx %s", hash), opt, s.step)
+	return s.trace, err
+}
+
+func (t *Tracker) session(fromChainID uint64, hash string, start Start) (*session, error) {
+	src, ok := t.chains[fromChainID]
+	if !ok {
+		return nil, fmt.Errorf("source chain %d not tracked", fromChainID)
+	}
+	trace := &Trace{
+		Hash:        hash,
+		FromChainID: fromChainID,
+		Hops: []*Hop{
+			{Name: HopSource, Chain: src.Name()},
+			{Name: HopPoly, Chain: "poly"},
+			{Name: HopTarget},
+		},
+	}
+	return &session{t: t, src: src, trace: trace, polyNext: start.Poly, targetNext: start.Target}, nil
+}
+
+type session struct {
+	t          *Tracker
+	src, dst   Chain
+	trace      *Trace
+	polyNext   uint64
+	targetNext uint64
+}
+
+// step search the hops not found yet, the errors are transient except that target chain not tracked.
+func (s *session) step() (bool, error) {
+	trace := s.trace
+	source, proof, target := trace.Hops[0], trace.Hops[1], trace.Hops[2]
+
+	if !source.Found {
+		event, err := s.src.CrossChainEvent(trace.Hash)
+		if err != nil {
+			source.Err = err
+			return false, err
+		}
+		dst, ok := s.t.chains[event.ToChainID]
+		if !ok {
+			source.Err = fmt.Errorf("target chain %d not tracked", event.ToChainID)
+			return false, poll.Permanent(source.Err)
+		}
+		if err := s.found(source, s.src.BlockTime, event.TxHash, event.Height); err != nil {
+			return false, err
+		}
+		s.dst = dst
+		target.Chain = dst.Name()
+		trace.TxID, trace.ToChainID = event.TxID, event.ToChainID
+	}
+
+	if !proof.Found {
+		record, err := s.scan(s.t.relay.Height, &s.polyNext, func(start, end uint64) (*Record, error) {
+			return s.t.relay.FindProof(trace.FromChainID, trace.TxID, start, end)
+		})
+		if err != nil || record == nil {
+			proof.Err = err
+			return false, err
+		}
+		if err := s.found(proof, s.t.relay.BlockTime, record.TxHash, record.Height); err != nil {
+			return false, err
+		}
+	}
+
+	if !target.Found {
+		record, err := s.scan(s.dst.Height, &s.targetNext, func(start, end uint64) (*Record, error) {
+			return s.dst.FindExecution(trace.TxID, start, end)
+		})
+		if err != nil || record == nil {
+			target.Err = err
+			return false, err
+		}
+		if err := s.found(target, s.dst.BlockTime, record.TxHash, record.Height); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// scan search blocks from `next` to the latest one, and move `next` after the latest block if not found.
+func (s *session) scan(height func() (uint64, error), next *uint64, find func(start, end uint64) (*Record, error)) (*Record, error) {
+	latest, err := height()
+	if err != nil {
+		return nil, err
+	}
+	if *next == 0 && latest > s.t.Window {
+		*next = latest - s.t.Window
+	}
+	if *next > latest {
+		return nil, nil
+	}
+	record, err := find(*next, latest)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		*next = latest + 1
+	}
+	return record, nil
+}
+
+func (s *session) found(hop *Hop, blockTime func(uint64) (time.Time, error), hash string, height uint64) error {
+	tm, err := blockTime(height)
+	if err != nil {
+		hop.Err = err
+		return err
+	}
+	hop.TxHash, hop.Height, hop.Time, hop.Found, hop.Err = hash, height, tm, true, nil
+	return nil
+}
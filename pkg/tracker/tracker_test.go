@@ -0,0 +1,160 @@
+package tracker
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/palettechain/onRobot/pkg/poll"
+	"github.com/stretchr/testify/assert"
+)
+
+var genesis = time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+
+type fakeTx struct {
+	hash   string
+	txID   []byte
+	height uint64
+}
+
+// fakeChain produce one block per `Height` call, and the tx appears in blocks after it's mined.
+type fakeChain struct {
+	name     string
+	id       uint64
+	height   uint64
+	events   map[string]*CrossChainEvent
+	executed []*fakeTx
+	scanned  [][2]uint64
+}
+
+func (c *fakeChain) Name() string        { return c.name }
+func (c *fakeChain) SideChainID() uint64 { return c.id }
+
+func (c *fakeChain) Height() (uint64, error) {
+	c.height++
+	return c.height, nil
+}
+
+func (c *fakeChain) BlockTime(height uint64) (time.Time, error) {
+	return genesis.Add(time.Duration(height) * time.Second), nil
+}
+
+func (c *fakeChain) CrossChainEvent(hash string) (*CrossChainEvent, error) {
+	if event, ok := c.events[hash]; ok {
+		return event, nil
+	}
+	return nil, fmt.Errorf("tx %s not found", hash)
+}
+
+func (c *fakeChain) FindExecution(txID []byte, start, end uint64) (*Record, error) {
+	c.scanned = append(c.scanned, [2]uint64{start, end})
+	for _, tx := range c.executed {
+		if bytes.Equal(tx.txID, txID) && tx.height >= start && tx.height <= end {
+			return &Record{TxHash: tx.hash, Height: tx.height}, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeRelay struct {
+	fakeChain
+	proofs map[uint64][]*fakeTx
+}
+
+func (r *fakeRelay) FindProof(fromChainID uint64, txID []byte, start, end uint64) (*Record, error) {
+	r.scanned = append(r.scanned, [2]uint64{start, end})
+	for _, tx := range r.proofs[fromChainID] {
+		if bytes.Equal(tx.txID, txID) && tx.height >= start && tx.height <= end {
+			return &Record{TxHash: tx.hash, Height: tx.height}, nil
+		}
+	}
+	return nil, nil
+}
+
+func newFakes() (*fakeChain, *fakeChain, *fakeRelay) {
+	palette := &fakeChain{name: "palette", id: 101, events: map[string]*CrossChainEvent{
+		"0x01": {TxHash: "0x01", TxID: []byte{1}, ToChainID: 2, Height: 5},
+		"0x02": {TxHash: "0x02", TxID: []byte{2}, ToChainID: 99, Height: 6},
+	}}
+	ethereum := &fakeChain{name: "ethereum", id: 2, height: 100}
+	relay := &fakeRelay{fakeChain: fakeChain{name: "poly", height: 1000}, proofs: make(map[uint64][]*fakeTx)}
+	return palette, ethereum, relay
+}
+
+func TestTrack(t *testing.T) {
+	palette, ethereum, relay := newFakes()
+	tk := New(relay, palette, ethereum)
+
+	_, err := tk.Track(3, "0x01", Start{})
+	assert.Error(t, err)
+
+	// stopped at poly
+	trace, err := tk.Track(101, "0x01", Start{Poly: 990, Target: 90})
+	assert.NoError(t, err)
+	assert.False(t, trace.Completed())
+	assert.Equal(t, HopPoly, trace.StoppedAt().Name)
+	assert.Equal(t, uint64(2), trace.ToChainID)
+	assert.Equal(t, "ethereum", trace.Hop(HopTarget).Chain)
+	assert.Equal(t, genesis.Add(5*time.Second), trace.Hop(HopSource).Time)
+	t.Log(trace.String())
+
+	// completed
+	relay.proofs[101] = []*fakeTx{{hash: "poly-01", txID: []byte{1}, height: 995}}
+	ethereum.executed = []*fakeTx{{hash: "0xe1", txID: []byte{1}, height: 95}}
+	trace, err = tk.Track(101, "0x01", Start{Poly: 990, Target: 90})
+	assert.NoError(t, err)
+	assert.True(t, trace.Completed())
+	assert.Equal(t, "poly-01", trace.Hop(HopPoly).TxHash)
+	assert.Equal(t, "0xe1", trace.Hop(HopTarget).TxHash)
+	assert.Equal(t, 90*time.Second, trace.Duration())
+	t.Log(trace.String())
+
+	// target chain not tracked
+	trace, err = tk.Track(101, "0x02", Start{})
+	assert.Error(t, err)
+	assert.Equal(t, HopSource, trace.StoppedAt().Name)
+
+	// source tx not found
+	trace, err = tk.Track(101, "0x03", Start{})
+	assert.Error(t, err)
+	assert.Error(t, trace.Hop(HopSource).Err)
+}
+
+func TestWait(t *testing.T) {
+	palette, ethereum, relay := newFakes()
+	tk := New(relay, palette, ethereum)
+	tk.Window = 10
+	opt := poll.Options{Timeout: time.Second, Interval: time.Millisecond}
+
+	// the tx never arrives on target chain, blocks are scanned incrementally
+	relay.proofs[101] = []*fakeTx{{hash: "poly-01", txID: []byte{1}, height: 1003}}
+	trace, err := tk.Wait(101, "0x01", Start{}, poll.Options{Timeout: 50 * time.Millisecond, Interval: time.Millisecond})
+	assert.True(t, poll.IsTimeout(err))
+	assert.Equal(t, HopTarget, trace.StoppedAt().Name)
+	assert.Equal(t, [2]uint64{991, 1001}, relay.scanned[0])
+	for i := 1; i < len(relay.scanned); i++ {
+		assert.Equal(t, relay.scanned[i-1][1]+1, relay.scanned[i][0])
+	}
+	assert.Equal(t, [2]uint64{91, 101}, ethereum.scanned[0])
+	for i := 1; i < len(ethereum.scanned); i++ {
+		assert.Equal(t, ethereum.scanned[i-1][1]+1, ethereum.scanned[i][0])
+	}
+
+	// arrives after a few blocks
+	_, err = tk.Start(99)
+	assert.Error(t, err)
+	start, err := tk.Start(2)
+	assert.NoError(t, err)
+	assert.Equal(t, ethereum.height, start.Target)
+	ethereum.executed = []*fakeTx{{hash: "0xe1", txID: []byte{1}, height: start.Target + 3}}
+	start.Poly = 1000
+	trace, err = tk.Wait(101, "0x01", start, opt)
+	assert.NoError(t, err)
+	assert.True(t, trace.Completed())
+
+	// permanent error stops waiting
+	_, err = tk.Wait(101, "0x02", Start{}, opt)
+	assert.Error(t, err)
+	assert.False(t, poll.IsTimeout(err))
+}